@@ -2,86 +2,426 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/Olzerq/avito-pr-reviewer/internal/auth"
 	"github.com/Olzerq/avito-pr-reviewer/internal/config"
+	"github.com/Olzerq/avito-pr-reviewer/internal/logging"
 
+	"github.com/Olzerq/avito-pr-reviewer/internal/metrics"
+	"github.com/Olzerq/avito-pr-reviewer/internal/ratelimit"
 	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/scheduler"
 	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"github.com/Olzerq/avito-pr-reviewer/internal/tracing"
 	"github.com/go-chi/chi/v5"
-	"log"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
+	grpcapi "github.com/Olzerq/avito-pr-reviewer/internal/grpc"
 	httpapi "github.com/Olzerq/avito-pr-reviewer/internal/http"
 )
 
 func main() {
-	// Загрузка конфига
-	cfg := config.LoadConfig()
+	os.Exit(run())
+}
+
+// run запускает сервис и возвращает код выхода. os.Exit вызывается только в main,
+// поэтому отложенные вызовы (закрытие пула БД, отправка трейсов) выполняются и при ошибке.
+func run() int {
+	// Загрузка конфига: файл, переменные окружения, флаги
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	// Логи в JSON
+	if err := logging.Setup(cfg.LogLevel); err != nil {
+		fmt.Fprintln(os.Stderr, "invalid log level:", err)
+		return 1
+	}
+
+	// Подкоманды migrate up|down|status|force и config print
+	if len(args) > 0 {
+		switch args[0] {
+		case "migrate":
+			if err := runMigrate(cfg, args[1:]); err != nil {
+				slog.Error("migrate failed", slog.Any("error", err))
+				return 1
+			}
+		case "config":
+			if len(args) != 2 || args[1] != "print" {
+				fmt.Fprintln(os.Stderr, "usage: app [flags] config print")
+				return 2
+			}
+			if err := cfg.Print(os.Stdout); err != nil {
+				slog.Error("failed to print config", slog.Any("error", err))
+				return 1
+			}
+		default:
+			fmt.Fprintf(os.Stderr, "unknown command %q, expected migrate or config\n", args[0])
+			return 2
+		}
+		return 0
+	}
+
+	// Без токенов и ключа ни один запрос не пройдет, это ошибка конфигурации сервера
+	if cfg.AuthEnabled && len(cfg.AdminTokens()) == 0 && cfg.AuthJWTSecret == "" {
+		slog.Error("auth is enabled, set AUTH_ADMIN_TOKENS and/or AUTH_JWT_SECRET")
+		return 1
+	}
 
 	// Контекст для БД
 	ctx := context.Background()
 
+	// Контекст фоновых задач, отменяется при остановке
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+	defer stopJobs()
+	var jobs sync.WaitGroup
+
 	// log.Printf("Connecting to DB: host=%s port=%s user=%s db=%s",
 	//	cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBName,
 	// )
 
-	// Подключаемся к БД
-	db := repository.NewDB(ctx, cfg.DBConnStr())
-	defer db.Close()
+	// Трейсинг
+	shutdownTracing, err := tracing.Setup(ctx, cfg.TracingExporter, cfg.TracingEndpoint, cfg.TracingServiceName)
+	if err != nil {
+		slog.Error("failed to setup tracing", slog.Any("error", err))
+		return 1
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			slog.Error("failed to shutdown tracing", slog.Any("error", err))
+		}
+	}()
 
-	teamRepo := repository.NewTeamRepository(db)
-	teamService := service.NewTeamService(teamRepo)
+	// Хранилище: Postgres или память процесса
+	storage, storageChecks, closeStorage, err := openStorage(ctx, cfg)
+	if err != nil {
+		slog.Error("failed to open storage", slog.Any("error", err))
+		return 1
+	}
+	defer closeStorage()
+	// при выходе с ошибкой фоновые задачи останавливаются до закрытия хранилища
+	defer func() {
+		stopJobs()
+		jobs.Wait()
+	}()
+
+	teamService := service.NewTeamService(storage.Teams)
 	teamHandler := httpapi.NewTeamHandler(teamService)
 
-	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo)
+	userService := service.NewUserService(storage.Users)
 	userHandler := httpapi.NewUserHandler(userService)
 
-	prRepo := repository.NewPullRequestRepository(db)
-	prService := service.NewPullRequestService(prRepo, userRepo)
+	// Настройки команд: каналы уведомлений и SLA
+	teamsCfg, err := config.LoadTeamsConfig(cfg.TeamsFile)
+	if err != nil {
+		slog.Error("failed to load teams config", slog.Any("error", err))
+		return 1
+	}
+
+	// Уведомления отправляются в фоне через очередь с повторами
+	notifyQueue := service.NewQueueNotifier(newNotifier(cfg, teamsCfg), cfg.NotifyQueueSize, cfg.NotifyMaxAttempts, time.Second)
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		notifyQueue.Run(jobsCtx)
+	}()
+
+	// Сигналы об изменениях очередей ревью для потоков SSE и gRPC, с Postgres - между репликами
+	reviewFeed := service.NewReviewFeed(storage.ReviewBus)
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		reviewFeed.Run(jobsCtx)
+	}()
+
+	prService := service.NewPullRequestService(storage.Tx, storage.PullRequests, storage.Users, notifyQueue, reviewFeed)
 	prHandler := httpapi.NewPullRequestHandler(prService)
 
-	statsRepo := repository.NewStatsRepository(db)
-	statsService := service.NewStatsService(statsRepo)
+	// Напоминания и эскалация зависших ревью
+	if cfg.SchedulerEnabled {
+		sched := scheduler.New(storage.Locker, storage.PullRequests, prService, notifyQueue, cfg.SchedulerInterval,
+			scheduler.Policy{RemindAfter: cfg.ReviewRemindAfter, EscalateAfter: cfg.ReviewEscalateAfter},
+			teamPolicies(teamsCfg),
+		)
+		jobs.Add(1)
+		go func() {
+			defer jobs.Done()
+			sched.Run(jobsCtx)
+		}()
+	}
+
+	statsService := service.NewStatsService(storage.Stats)
 	statsHandler := httpapi.NewStatsHandler(statsService)
 
+	// Поток очереди ревью для IDE плагина вместо опроса /users/getReview
+	streamHandler := httpapi.NewReviewStreamHandler(prService, cfg.SSEHeartbeatInterval)
+
+	v1Handler := httpapi.NewV1Handler(teamHandler, userHandler, prHandler, statsHandler, streamHandler)
+
+	// API v2 поверх тех же сервисов
+	v2Handler := httpapi.NewV2Handler(teamService, userService, prService, statsService)
+
 	// Создаем роутер
 	r := chi.NewRouter()
+	r.Use(httpapi.RequestID)
+	r.Use(httpapi.Tracing)
+	r.Use(httpapi.RequestLogger)
+	r.Use(httpapi.Metrics)
+	// неизвестные маршруты отвечают в общем формате ошибок, а не текстом
+	r.NotFound(httpapi.NotFound)
+	r.MethodNotAllowed(httpapi.MethodNotAllowed)
+
+	// Проверки состояния: при остановке и при недоступности хранилища реплика не готова
+	healthHandler := httpapi.NewHealthHandler(cfg.ReadinessTimeout, storageChecks...)
+	r.Get("/health", healthHandler.Health)
+	r.Get("/livez", healthHandler.Livez)
+	r.Get("/readyz", healthHandler.Readyz)
+
+	// Метрики Prometheus
+	if cfg.MetricsEnabled {
+		r.Handle("/metrics", metrics.Handler())
+	}
+
+	// Ограничение частоты запросов по токену / IP, бюджеты уже проверены при загрузке конфига
+	defaultLimit, _ := ratelimit.ParseLimit(cfg.RateLimitDefault)
+	routeLimits, _ := ratelimit.ParseRoutes(cfg.RateLimitRoutes)
+	limiter := ratelimit.New(defaultLimit, routeLimits)
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		limiter.Run(jobsCtx)
+	}()
+	// Слоты одновременных запросов, общие для HTTP и gRPC
+	inFlight := ratelimit.NewConcurrencyLimiter(cfg.MaxInFlight(), cfg.HTTPInFlightWait)
+
+	// Ответы на запросы с Idempotency-Key, истекшие ключи удаляются в фоне
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		cleanupIdempotencyKeys(jobsCtx, storage.Idempotency, 10*time.Minute)
+	}()
+
+	// Аутентификация, при AUTH_ENABLED=false все запросы выполняются от администратора.
+	// Права на операции по ролям проверяют сервисы.
+	var authenticator *auth.Authenticator
+	if cfg.AuthEnabled {
+		authenticator = auth.NewAuthenticator(cfg.AdminTokens(), cfg.AuthJWTSecret, cfg.AuthJWTIssuer, cfg.AuthJWTAudience, userService)
+	} else {
+		slog.Warn("authentication is disabled")
+	}
+
+	// Проверка запросов v1 по спецификации до обработчиков, в режиме full и ответов
+	var validator func(http.Handler) http.Handler
+	if cfg.OpenAPIValidation != "off" {
+		validator, err = httpapi.OpenAPIValidator(httpapi.OpenAPIValidatorOptions{Responses: cfg.OpenAPIValidation == "full"})
+		if err != nil {
+			slog.Error("failed to load openapi spec", slog.Any("error", err))
+			return 1
+		}
+	}
+
+	r.Group(func(r chi.Router) {
+		if cfg.RateLimitEnabled {
+			r.Use(httpapi.RateLimit(limiter, cfg.RateLimitTrustProxy))
+		}
+		r.Use(httpapi.Concurrency(inFlight))
+		r.Use(httpapi.Authenticate(authenticator))
+		if validator != nil {
+			r.Use(validator)
+		}
+		r.Use(httpapi.Idempotency(storage.Idempotency, cfg.IdempotencyTTL, cfg.IdempotencyLockTimeout))
 
-	// Тестовый эндпоинт
-	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`{"status":"ok"}`))
+		// API v1 по openapi.yml: маршруты и разбор параметров сгенерированы в internal/http/api
+		v1Handler.Routes(r)
+
+		// Ресурсный API v2, v1 выше сохраняется для совместимости
+		r.Route("/v2", v2Handler.Routes)
 	})
 
-	// Добавление команды
-	r.Post("/team/add", teamHandler.TeamAdd)
+	srv := newHTTPServer(cfg, r)
+	// потоки SSE не заканчиваются сами, при остановке их закрывает обработчик
+	srv.RegisterOnShutdown(streamHandler.Shutdown)
 
-	// Получение команды
-	r.Get("/team/get", teamHandler.TeamGet)
+	serverErr := make(chan error, 2)
+	go func() {
+		slog.Info("starting server", slog.String("addr", srv.Addr))
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
 
-	// Обновление флага активности
-	r.Post("/users/setIsActive", userHandler.SetIsActive)
+	// gRPC API на отдельном порту поверх тех же сервисов
+	var grpcSrv *grpcapi.Server
+	if cfg.GRPCEnabled {
+		lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+		if err != nil {
+			slog.Error("failed to listen on grpc port", slog.Any("error", err))
+			_ = srv.Close()
+			return 1
+		}
+		// gRPC делит с HTTP бюджеты клиентов и слоты одновременных запросов
+		grpcLimits := grpcapi.Limits{InFlight: inFlight, TrustProxy: cfg.RateLimitTrustProxy}
+		if cfg.RateLimitEnabled {
+			grpcLimits.Rate = limiter
+		}
+		grpcSrv = grpcapi.NewServer(authenticator, grpcLimits, teamService, userService, prService, statsService)
+		go func() {
+			slog.Info("starting grpc server", slog.String("addr", lis.Addr().String()))
+			if err := grpcSrv.Serve(lis); err != nil {
+				serverErr <- err
+			}
+		}()
+	}
+	healthHandler.SetReady(true)
 
-	// Получает все PR'ы где юзер ревьювер
-	r.Get("/users/getReview", prHandler.GetUserReviews)
+	// Ждем сигнал остановки
+	sigCtx, stopSignals := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
 
-	// Создание PR
-	r.Post("/pullRequest/create", prHandler.Create)
+	code := 0
+	select {
+	case <-sigCtx.Done():
+		slog.Info("shutdown signal received")
+	case err := <-serverErr:
+		slog.Error("server failed", slog.Any("error", err))
+		code = 1
+	}
 
-	// Переназначение ревьюера
-	r.Post("/pullRequest/reassign", prHandler.Reassign)
+	if err := shutdown(srv, grpcSrv, healthHandler, stopJobs, &jobs, cfg.ShutdownDrainDelay, cfg.ShutdownTimeout); err != nil {
+		slog.Error("shutdown finished with error", slog.Any("error", err))
+		return 1
+	}
+	slog.Info("server stopped")
+	return code
+}
+
+// newHTTPServer HTTP сервер с таймаутами из конфига, медленный клиент не держит соединение бесконечно
+func newHTTPServer(cfg config.Config, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              ":" + cfg.AppPort,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+		ReadTimeout:       cfg.HTTPReadTimeout,
+		WriteTimeout:      cfg.HTTPWriteTimeout,
+		IdleTimeout:       cfg.HTTPIdleTimeout,
+	}
+}
+
+// shutdown снимает реплику с балансировки, перестает принимать запросы, дожидается текущих,
+// останавливает фоновые задачи. Хранилище и трейсинг закрываются отложенными вызовами в run.
+func shutdown(
+	srv *http.Server,
+	grpcSrv *grpcapi.Server,
+	health *httpapi.HealthHandler,
+	stopJobs context.CancelFunc,
+	jobs *sync.WaitGroup,
+	drainDelay, timeout time.Duration,
+) error {
+	// балансировщик перестает слать трафик, но узнает об этом только со следующей
+	// проверкой готовности, до тех пор запросы продолжают приходить и обслуживаются
+	health.SetReady(false)
+	if grpcSrv != nil {
+		grpcSrv.Drain()
+	}
+	if drainDelay > 0 {
+		slog.Info("draining before shutdown", slog.Duration("delay", drainDelay))
+		time.Sleep(drainDelay)
+	}
 
-	// Флаг Merged
-	r.Post("/pullRequest/merge", prHandler.Merge)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	// Статистика
-	r.Get("/stats/assignments", statsHandler.GetAssignments)
+	err := srv.Shutdown(ctx)
+	if grpcSrv != nil {
+		err = errors.Join(err, grpcSrv.Shutdown(ctx))
+	}
+
+	stopJobs()
+	done := make(chan struct{})
+	go func() {
+		jobs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = errors.Join(err, errors.New("background jobs did not stop in time"))
+	}
+	return err
+}
+
+// newNotifier собирает notifier по конфигу
+func newNotifier(cfg config.Config, teamsCfg config.TeamsConfig) service.Notifier {
+	switch cfg.Notifier {
+	case "webhook":
+		teams := make(map[string]service.TeamChannel, len(teamsCfg.Teams))
+		for name, t := range teamsCfg.Teams {
+			teams[name] = service.TeamChannel{
+				WebhookURL: t.WebhookURL,
+				Channel:    t.Channel,
+				Templates:  toEventTemplates(t.Templates),
+			}
+		}
+		return service.NewWebhookNotifier(cfg.NotifyWebhookURL, teams, toEventTemplates(teamsCfg.Templates))
+	case "none":
+		return service.NopNotifier{}
+	default:
+		return service.NewLogNotifier()
+	}
+}
+
+func toEventTemplates(templates map[string]string) map[service.NotificationEvent]string {
+	res := make(map[service.NotificationEvent]string, len(templates))
+	for event, tmpl := range templates {
+		res[service.NotificationEvent(event)] = tmpl
+	}
+	return res
+}
+
+func teamPolicies(teamsCfg config.TeamsConfig) map[string]scheduler.Policy {
+	res := make(map[string]scheduler.Policy, len(teamsCfg.Teams))
+	for name, t := range teamsCfg.Teams {
+		res[name] = scheduler.Policy{
+			RemindAfter:   time.Duration(t.RemindAfter),
+			EscalateAfter: time.Duration(t.EscalateAfter),
+		}
+	}
+	return res
+}
 
-	addr := ":" + cfg.AppPort
-	log.Printf("Starting server on %s", addr)
+// cleanupIdempotencyKeys периодически удаляет истекшие ключи идемпотентности
+func cleanupIdempotencyKeys(ctx context.Context, repo repository.IdempotencyKeys, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-	if err := http.ListenAndServe(addr, r); err != nil {
-		log.Fatalf("server failed: %v", err)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := repo.DeleteExpired(ctx)
+			if err != nil {
+				slog.Error("failed to delete expired idempotency keys", slog.Any("error", err))
+				continue
+			}
+			if n > 0 {
+				slog.Debug("expired idempotency keys deleted", slog.Int64("count", n))
+			}
+		}
 	}
 }
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/config"
+	httpapi "github.com/Olzerq/avito-pr-reviewer/internal/http"
+)
+
+// startServer запускает сервер из конфига на свободном порту
+func startServer(t *testing.T, cfg config.Config, handler http.Handler) (*http.Server, string) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := newHTTPServer(cfg, handler)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return srv, "http://" + lis.Addr().String()
+}
+
+func status(t *testing.T, url string) int {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestShutdownDrainsThenWaitsForRequests(t *testing.T) {
+	health := httpapi.NewHealthHandler(time.Second)
+	health.SetReady(true)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /readyz", health.Readyz)
+	mux.HandleFunc("GET /ping", func(w http.ResponseWriter, _ *http.Request) {})
+	mux.HandleFunc("GET /slow", func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		<-release
+	})
+	srv, url := startServer(t, config.Default(), mux)
+
+	// фоновая задача завершается по отмене контекста
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	var jobs sync.WaitGroup
+	jobStopped := false
+	jobs.Add(1)
+	go func() {
+		defer jobs.Done()
+		<-jobsCtx.Done()
+		jobStopped = true
+	}()
+
+	slow := make(chan int)
+	go func() {
+		resp, err := http.Get(url + "/slow")
+		if err != nil {
+			slow <- 0
+			return
+		}
+		resp.Body.Close()
+		slow <- resp.StatusCode
+	}()
+	<-started
+
+	const drain = 300 * time.Millisecond
+	done := make(chan error)
+	begin := time.Now()
+	go func() { done <- shutdown(srv, nil, health, stopJobs, &jobs, drain, 5*time.Second) }()
+
+	// во время drain реплика не готова, но новые запросы еще обслуживаются
+	time.Sleep(drain / 3)
+	if got := status(t, url+"/readyz"); got != http.StatusServiceUnavailable {
+		t.Fatalf("expected /readyz 503 while draining, got %d", got)
+	}
+	if got := status(t, url+"/ping"); got != http.StatusOK {
+		t.Fatalf("expected requests to be served while draining, got %d", got)
+	}
+
+	// shutdown дожидается текущего запроса
+	time.Sleep(drain)
+	select {
+	case err := <-done:
+		t.Fatalf("shutdown returned before in-flight request finished: %v", err)
+	default:
+	}
+	close(release)
+	if got := <-slow; got != http.StatusOK {
+		t.Fatalf("in-flight request was not completed, got %d", got)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if elapsed := time.Since(begin); elapsed < drain {
+		t.Fatalf("shutdown finished in %s, before drain delay %s", elapsed, drain)
+	}
+	if !jobStopped {
+		t.Fatal("background jobs were not stopped")
+	}
+	if _, err := http.Get(url + "/ping"); err == nil {
+		t.Fatal("server must not accept requests after shutdown")
+	}
+}
+
+func TestShutdownTimesOut(t *testing.T) {
+	health := httpapi.NewHealthHandler(time.Second)
+	srv, _ := startServer(t, config.Default(), http.NewServeMux())
+
+	// задача не реагирует на отмену
+	var jobs sync.WaitGroup
+	jobs.Add(1)
+	defer jobs.Done()
+
+	err := shutdown(srv, nil, health, func() {}, &jobs, 0, 100*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "background jobs did not stop in time") {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+}
+
+func TestHTTPServerTimeouts(t *testing.T) {
+	cfg := config.Default()
+	cfg.HTTPReadHeaderTimeout = 100 * time.Millisecond
+	cfg.HTTPIdleTimeout = 200 * time.Millisecond
+
+	srv, url := startServer(t, cfg, http.NewServeMux())
+	if srv.ReadHeaderTimeout != cfg.HTTPReadHeaderTimeout || srv.ReadTimeout != cfg.HTTPReadTimeout ||
+		srv.WriteTimeout != cfg.HTTPWriteTimeout || srv.IdleTimeout != cfg.HTTPIdleTimeout {
+		t.Fatalf("server timeouts do not match config: %+v", srv)
+	}
+
+	// клиент, который не дописывает заголовки, отключается по read_header_timeout
+	conn, err := net.Dial("tcp", strings.TrimPrefix(url, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: x\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	start := time.Now()
+	_, _ = io.ReadAll(conn)
+	if elapsed := time.Since(start); elapsed >= 2*time.Second {
+		t.Fatalf("slow client was not disconnected, waited %s", elapsed)
+	}
+}
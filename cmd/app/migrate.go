@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/config"
+	"github.com/Olzerq/avito-pr-reviewer/internal/migrator"
+)
+
+const migrateUsage = `usage: app migrate <command>
+
+commands:
+  up          apply all pending migrations
+  down [N]    roll back N migrations (default 1)
+  status      print current and latest migration versions
+  force V     set version V without running migrations (clears dirty state)`
+
+// runMigrate выполняет подкоманду migrate
+func runMigrate(cfg config.Config, args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	var m *migrator.Migrator
+	var err error
+	switch cfg.Storage {
+	case "sqlite":
+		m, err = migrator.NewSQLite(cfg.SQLitePath)
+	case "memory":
+		return errors.New("storage=memory has no migrations")
+	default:
+		m, err = migrator.New(cfg.DBConnStr())
+	}
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	switch args[0] {
+	case "up":
+		if err := m.Up(); err != nil {
+			return err
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		if err := m.Down(steps); err != nil {
+			return err
+		}
+	case "force":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		if err := m.Force(version); err != nil {
+			return err
+		}
+	case "status":
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	status, err := m.Status()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("version: %d\ndirty: %t\nlatest: %d\npending: %d\n",
+		status.Version, status.Dirty, status.Latest, pending(status))
+	return nil
+}
+
+func pending(s migrator.Status) uint {
+	if s.Latest <= s.Version {
+		return 0
+	}
+	return s.Latest - s.Version
+}
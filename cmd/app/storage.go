@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/config"
+	httpapi "github.com/Olzerq/avito-pr-reviewer/internal/http"
+	"github.com/Olzerq/avito-pr-reviewer/internal/metrics"
+	"github.com/Olzerq/avito-pr-reviewer/internal/migrator"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository/memory"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository/sqlite"
+)
+
+// openStorage открывает хранилище из конфига. Возвращает его проверки для /readyz
+// и функцию, которая освобождает ресурсы хранилища при остановке. При ошибке
+// уже открытые ресурсы закрываются здесь же.
+func openStorage(ctx context.Context, cfg config.Config) (repository.Storage, []httpapi.HealthCheck, func(), error) {
+	switch cfg.Storage {
+	case "memory":
+		slog.Warn("using in-memory storage, data will be lost on restart")
+		return memory.NewStorage(), nil, func() {}, nil
+	case "sqlite":
+		return openSQLite(ctx, cfg)
+	}
+
+	// Подключаемся к БД
+	db, err := repository.NewDB(ctx, cfg.DBConnStr(), repository.PoolOptions{
+		MinConns:        int32(cfg.DBMinConns),
+		MaxConns:        int32(cfg.DBMaxConns),
+		MaxConnLifetime: cfg.DBMaxConnLifetime,
+		MaxConnIdleTime: cfg.DBMaxConnIdleTime,
+	})
+	if err != nil {
+		return repository.Storage{}, nil, nil, err
+	}
+	metrics.RegisterPool(db)
+
+	// Миграции при старте, реплики применяют их по очереди
+	if cfg.MigrateOnStart {
+		if err := migrator.UpLocked(ctx, db, cfg.DBConnStr()); err != nil {
+			db.Close()
+			return repository.Storage{}, nil, nil, fmt.Errorf("apply migrations: %w", err)
+		}
+		slog.Info("migrations applied")
+	}
+
+	schemaVersion, err := migrator.LatestVersion()
+	if err != nil {
+		db.Close()
+		return repository.Storage{}, nil, nil, fmt.Errorf("read embedded migrations: %w", err)
+	}
+
+	// При недоступности БД реплика не готова
+	checks := []httpapi.HealthCheck{
+		{Name: "database", Critical: true, Check: db.Ping},
+		{Name: "migrations", Critical: true, Check: func(ctx context.Context) error {
+			return repository.CheckSchemaVersion(ctx, db, int64(schemaVersion))
+		}},
+		{Name: "pool", Critical: false, Check: func(context.Context) error {
+			return repository.CheckPoolSaturation(db, cfg.PoolSaturationThreshold)
+		}},
+	}
+	return repository.NewStorage(db), checks, db.Close, nil
+}
+
+// openSQLite открывает файл SQLite. Реплика должна быть одна, поэтому миграции
+// применяются без межпроцессной блокировки.
+func openSQLite(ctx context.Context, cfg config.Config) (repository.Storage, []httpapi.HealthCheck, func(), error) {
+	if cfg.MigrateOnStart {
+		if err := migrateSQLite(cfg.SQLitePath); err != nil {
+			return repository.Storage{}, nil, nil, fmt.Errorf("apply migrations: %w", err)
+		}
+		slog.Info("migrations applied")
+	}
+
+	db, err := sqlite.Open(ctx, cfg.SQLitePath)
+	if err != nil {
+		return repository.Storage{}, nil, nil, fmt.Errorf("open sqlite database: %w", err)
+	}
+	slog.Info("using sqlite storage", slog.String("path", cfg.SQLitePath))
+
+	schemaVersion, err := migrator.LatestSQLiteVersion()
+	if err != nil {
+		_ = db.Close()
+		return repository.Storage{}, nil, nil, fmt.Errorf("read embedded migrations: %w", err)
+	}
+
+	checks := []httpapi.HealthCheck{
+		{Name: "database", Critical: true, Check: db.PingContext},
+		{Name: "migrations", Critical: true, Check: func(ctx context.Context) error {
+			return sqlite.CheckSchemaVersion(ctx, db, int64(schemaVersion))
+		}},
+	}
+	return sqlite.NewStorage(db), checks, func() { _ = db.Close() }, nil
+}
+
+func migrateSQLite(path string) error {
+	m, err := migrator.NewSQLite(path)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+	return m.Up()
+}
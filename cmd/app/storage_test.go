@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/config"
+	httpapi "github.com/Olzerq/avito-pr-reviewer/internal/http"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository/sqlite"
+)
+
+type readyz struct {
+	Status string `json:"status"`
+	Checks map[string]struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	} `json:"checks"`
+}
+
+func getReadyz(t *testing.T, h *httpapi.HealthHandler) (int, readyz) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.Readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var body readyz
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("malformed /readyz response: %v", err)
+	}
+	return rec.Code, body
+}
+
+// openReadySQLite хранилище sqlite с примененными миграциями и его проверки готовности
+func openReadySQLite(t *testing.T) (*httpapi.HealthHandler, string, func()) {
+	t.Helper()
+
+	cfg := config.Default()
+	cfg.Storage = "sqlite"
+	cfg.SQLitePath = filepath.Join(t.TempDir(), "reviewer.db")
+	cfg.MigrateOnStart = true
+
+	_, checks, closeStorage, err := openStorage(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("failed to open storage: %v", err)
+	}
+	h := httpapi.NewHealthHandler(time.Second, checks...)
+	h.SetReady(true)
+
+	if code, body := getReadyz(t, h); code != http.StatusOK || body.Status != "ok" {
+		closeStorage()
+		t.Fatalf("expected ready storage, got %d %+v", code, body)
+	}
+	return h, cfg.SQLitePath, closeStorage
+}
+
+func TestReadyzFailsWhenDatabaseIsUnavailable(t *testing.T) {
+	h, _, closeStorage := openReadySQLite(t)
+	closeStorage()
+
+	code, body := getReadyz(t, h)
+	if code != http.StatusServiceUnavailable || body.Status != "fail" {
+		t.Fatalf("expected 503 fail, got %d %+v", code, body)
+	}
+	if body.Checks["database"].Status != "fail" || body.Checks["database"].Error == "" {
+		t.Fatalf("expected failed database check, got %+v", body.Checks)
+	}
+}
+
+func TestReadyzFailsOnSchemaVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		update string
+	}{
+		{"outdated", `UPDATE schema_migrations SET version = version - 1`},
+		{"dirty", `UPDATE schema_migrations SET dirty = 1`},
+		{"not applied", `DELETE FROM schema_migrations`},
+		{"newer but dirty", `UPDATE schema_migrations SET version = version + 1, dirty = 1`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, path, closeStorage := openReadySQLite(t)
+			defer closeStorage()
+
+			db, err := sqlite.Open(context.Background(), path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer db.Close()
+			if _, err := db.Exec(tt.update); err != nil {
+				t.Fatal(err)
+			}
+
+			code, body := getReadyz(t, h)
+			if code != http.StatusServiceUnavailable || body.Status != "fail" {
+				t.Fatalf("expected 503 fail, got %d %+v", code, body)
+			}
+			if body.Checks["migrations"].Status != "fail" || body.Checks["database"].Status != "ok" {
+				t.Fatalf("expected only migrations check to fail, got %+v", body.Checks)
+			}
+		})
+	}
+}
+
+func TestReadyzAllowsNewerSchema(t *testing.T) {
+	// новая реплика уже применила миграции, старая остается готовой
+	h, path, closeStorage := openReadySQLite(t)
+	defer closeStorage()
+
+	db, err := sqlite.Open(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(`UPDATE schema_migrations SET version = version + 1`); err != nil {
+		t.Fatal(err)
+	}
+
+	code, body := getReadyz(t, h)
+	if code != http.StatusOK || body.Checks["migrations"].Status != "ok" {
+		t.Fatalf("expected ready with newer schema, got %d %+v", code, body)
+	}
+}
+
+func TestReadyzDegradedOnNonCriticalCheck(t *testing.T) {
+	h := httpapi.NewHealthHandler(time.Second, httpapi.HealthCheck{
+		Name:  "pool",
+		Check: func(context.Context) error { return context.DeadlineExceeded },
+	})
+	h.SetReady(true)
+
+	// некритичная проверка не снимает реплику с трафика
+	code, body := getReadyz(t, h)
+	if code != http.StatusOK || body.Status != "degraded" || body.Checks["pool"].Status != "fail" {
+		t.Fatalf("expected 200 degraded, got %d %+v", code, body)
+	}
+}
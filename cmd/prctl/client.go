@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+)
+
+// httpBackend работает через HTTP API сервиса
+type httpBackend struct {
+	addr   string
+	token  string
+	client *http.Client
+}
+
+func newHTTPBackend(addr, token string, timeout time.Duration) *httpBackend {
+	return &httpBackend{
+		addr:   strings.TrimRight(addr, "/"),
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (b *httpBackend) Close() {}
+
+type errorResponse struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Details []struct {
+			Message string `json:"message"`
+		} `json:"details"`
+	} `json:"error"`
+}
+
+// do отправляет запрос и декодирует ответ в out, ошибки API превращает в apiError
+func (b *httpBackend) do(ctx context.Context, method, path string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, b.addr+path, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if b.token != "" {
+		req.Header.Set("Authorization", "Bearer "+b.token)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 400 {
+		var errResp errorResponse
+		if json.Unmarshal(data, &errResp) == nil && errResp.Error.Code != "" {
+			msg := errResp.Error.Message
+			// для ошибок валидации полезнее перечислить нарушения по полям
+			if len(errResp.Error.Details) > 0 {
+				msgs := make([]string, 0, len(errResp.Error.Details))
+				for _, d := range errResp.Error.Details {
+					msgs = append(msgs, d.Message)
+				}
+				msg = strings.Join(msgs, "; ")
+			}
+			return &apiError{Code: errResp.Error.Code, Message: msg}
+		}
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+func fromTeamView(v teamView) model.Team {
+	team := model.Team{Name: v.TeamName, Users: make([]model.User, 0, len(v.Members))}
+	for _, m := range v.Members {
+		team.Users = append(team.Users, model.User{
+			ID: m.UserID, Username: m.Username, TeamName: v.TeamName, IsActive: m.IsActive,
+		})
+	}
+	return team
+}
+
+func fromPRView(v prView) model.PullRequest {
+	pr := model.PullRequest{
+		ID:        v.ID,
+		Name:      v.Name,
+		AuthorID:  v.AuthorID,
+		Status:    v.Status,
+		CreatedAt: v.CreatedAt,
+		MergedAt:  v.MergedAt,
+		Reviewers: make([]model.User, 0, len(v.AssignedReviewers)),
+	}
+	for _, id := range v.AssignedReviewers {
+		pr.Reviewers = append(pr.Reviewers, model.User{ID: id})
+	}
+	return pr
+}
+
+func (b *httpBackend) CreateTeam(ctx context.Context, team model.Team) (model.Team, error) {
+	var resp struct {
+		Team teamView `json:"team"`
+	}
+	if err := b.do(ctx, http.MethodPost, "/team/add", toTeamView(team), &resp); err != nil {
+		return model.Team{}, err
+	}
+	return fromTeamView(resp.Team), nil
+}
+
+func (b *httpBackend) GetTeam(ctx context.Context, name string) (model.Team, error) {
+	var resp teamView
+	if err := b.do(ctx, http.MethodGet, "/team/get?team_name="+url.QueryEscape(name), nil, &resp); err != nil {
+		return model.Team{}, err
+	}
+	return fromTeamView(resp), nil
+}
+
+func (b *httpBackend) SetIsActive(ctx context.Context, userID string, isActive bool) (model.User, error) {
+	req := map[string]any{"user_id": userID, "is_active": isActive}
+	var resp struct {
+		User userView `json:"user"`
+	}
+	if err := b.do(ctx, http.MethodPost, "/users/setIsActive", req, &resp); err != nil {
+		return model.User{}, err
+	}
+	return model.User{
+		ID: resp.User.UserID, Username: resp.User.Username, TeamName: resp.User.TeamName, IsActive: resp.User.IsActive,
+	}, nil
+}
+
+func (b *httpBackend) SetRole(ctx context.Context, userID, role string) error {
+	req := map[string]any{"user_id": userID, "role": role}
+	return b.do(ctx, http.MethodPost, "/users/setRole", req, nil)
+}
+
+func (b *httpBackend) CreatePR(ctx context.Context, id, name, authorID string) (model.PullRequest, error) {
+	req := map[string]any{"pull_request_id": id, "pull_request_name": name, "author_id": authorID}
+	var resp prResultView
+	if err := b.do(ctx, http.MethodPost, "/pullRequest/create", req, &resp); err != nil {
+		return model.PullRequest{}, err
+	}
+	return fromPRView(resp.PR), nil
+}
+
+func (b *httpBackend) Reassign(ctx context.Context, prID, oldUserID string) (model.PullRequest, string, error) {
+	req := map[string]any{"pull_request_id": prID, "old_user_id": oldUserID}
+	var resp prResultView
+	if err := b.do(ctx, http.MethodPost, "/pullRequest/reassign", req, &resp); err != nil {
+		return model.PullRequest{}, "", err
+	}
+	return fromPRView(resp.PR), resp.ReplacedBy, nil
+}
+
+func (b *httpBackend) Merge(ctx context.Context, prID string) (model.PullRequest, error) {
+	req := map[string]any{"pull_request_id": prID}
+	var resp prResultView
+	if err := b.do(ctx, http.MethodPost, "/pullRequest/merge", req, &resp); err != nil {
+		return model.PullRequest{}, err
+	}
+	return fromPRView(resp.PR), nil
+}
+
+func (b *httpBackend) UserReviews(ctx context.Context, userID string) ([]model.PullRequestShort, error) {
+	// проходим все страницы, команда выводит полный список
+	prs := make([]model.PullRequestShort, 0)
+	cursor := ""
+	for {
+		path := "/users/getReview?limit=100&user_id=" + url.QueryEscape(userID)
+		if cursor != "" {
+			path += "&cursor=" + url.QueryEscape(cursor)
+		}
+		var resp reviewsView
+		if err := b.do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+			return nil, err
+		}
+		for _, pr := range resp.PullRequests {
+			prs = append(prs, model.PullRequestShort{ID: pr.ID, Name: pr.Name, AuthorID: pr.AuthorID, Status: pr.Status})
+		}
+		if resp.NextCursor == "" {
+			return prs, nil
+		}
+		cursor = resp.NextCursor
+	}
+}
+
+func (b *httpBackend) Stats(ctx context.Context) (statsView, error) {
+	var resp statsView
+	if err := b.do(ctx, http.MethodGet, "/stats/assignments", nil, &resp); err != nil {
+		return statsView{}, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,393 @@
+// prctl - консольная утилита для операторов сервиса назначения ревьюверов.
+// Работает через HTTP API или, с флагом --offline, напрямую с БД.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/auth"
+	"github.com/Olzerq/avito-pr-reviewer/internal/config"
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+)
+
+// Коды выхода, по одному на код ошибки API
+const (
+	exitOK          = 0
+	exitError       = 1
+	exitUsage       = 2
+	exitNotFound    = 3
+	exitTeamExists  = 4
+	exitPRExists    = 5
+	exitPRMerged    = 6
+	exitNotAssigned = 7
+	exitNoCandidate = 8
+	exitForbidden   = 9
+	exitUnauth      = 10
+	exitConflict    = 11
+	exitPrecond     = 12
+	exitRateLimited = 13
+	exitBadRequest  = 14
+)
+
+var exitCodes = map[string]int{
+	"NOT_FOUND":           exitNotFound,
+	"TEAM_EXISTS":         exitTeamExists,
+	"PR_EXISTS":           exitPRExists,
+	"PR_MERGED":           exitPRMerged,
+	"NOT_ASSIGNED":        exitNotAssigned,
+	"NO_CANDIDATE":        exitNoCandidate,
+	"FORBIDDEN":           exitForbidden,
+	"UNAUTHORIZED":        exitUnauth,
+	"CONFLICT":            exitConflict,
+	"PRECONDITION_FAILED": exitPrecond,
+	"RATE_LIMITED":        exitRateLimited,
+	"BAD_REQUEST":         exitBadRequest,
+}
+
+const usage = `usage: prctl [global flags] <command> [args]
+
+global flags:
+  --addr URL         API address (default $PRCTL_ADDR or http://localhost:8080)
+  --token TOKEN      bearer token (default $PRCTL_TOKEN)
+  --output FORMAT    table | json (default table)
+  --offline          work with the database directly (DB_* env vars)
+  --timeout DURATION request timeout (default 10s)
+
+commands:
+  team create --name NAME --member ID:USERNAME[:inactive] ...
+  team get NAME
+  user activate USER_ID
+  user deactivate USER_ID
+  user role USER_ID admin|team_lead|member|read_only
+  pr create --id ID --name NAME --author USER_ID
+  pr reassign --id ID --old USER_ID
+  pr merge ID
+  reviews USER_ID
+  stats
+  token --user USER_ID [--ttl DURATION]   issue a JWT (AUTH_JWT_SECRET), rights follow the user's role
+
+exit codes:
+  0 ok, 1 error, 2 usage, 3 NOT_FOUND, 4 TEAM_EXISTS, 5 PR_EXISTS,
+  6 PR_MERGED, 7 NOT_ASSIGNED, 8 NO_CANDIDATE, 9 FORBIDDEN, 10 UNAUTHORIZED,
+  11 CONFLICT, 12 PRECONDITION_FAILED, 13 RATE_LIMITED, 14 BAD_REQUEST`
+
+// apiError ошибка с кодом из API (или из доменной ошибки в offline режиме)
+type apiError struct {
+	Code    string
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+var errUsage = errors.New("invalid usage")
+
+// backend - HTTP API или прямой доступ к БД
+type backend interface {
+	CreateTeam(ctx context.Context, team model.Team) (model.Team, error)
+	GetTeam(ctx context.Context, name string) (model.Team, error)
+	SetIsActive(ctx context.Context, userID string, isActive bool) (model.User, error)
+	SetRole(ctx context.Context, userID, role string) error
+	CreatePR(ctx context.Context, id, name, authorID string) (model.PullRequest, error)
+	Reassign(ctx context.Context, prID, oldUserID string) (model.PullRequest, string, error)
+	Merge(ctx context.Context, prID string) (model.PullRequest, error)
+	UserReviews(ctx context.Context, userID string) ([]model.PullRequestShort, error)
+	Stats(ctx context.Context) (statsView, error)
+	Close()
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	global := flag.NewFlagSet("prctl", flag.ContinueOnError)
+	global.Usage = func() { fmt.Fprintln(os.Stderr, usage) }
+
+	defaultAddr := os.Getenv("PRCTL_ADDR")
+	if defaultAddr == "" {
+		defaultAddr = "http://localhost:8080"
+	}
+	addr := global.String("addr", defaultAddr, "API address")
+	token := global.String("token", os.Getenv("PRCTL_TOKEN"), "bearer token")
+	output := global.String("output", "table", "table | json")
+	offline := global.Bool("offline", false, "work with the database directly")
+	timeout := global.Duration("timeout", 10*time.Second, "request timeout")
+
+	if err := global.Parse(args); err != nil {
+		return exitUsage
+	}
+	if *output != "table" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "unknown output format %q\n", *output)
+		return exitUsage
+	}
+	if global.NArg() == 0 {
+		global.Usage()
+		return exitUsage
+	}
+
+	// выпуск токена не требует ни API, ни БД
+	if global.Arg(0) == "token" {
+		return exitCode(tokenCmd(global.Args()[1:]))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	var b backend
+	if *offline {
+		// в истории PR будет видно, что действие выполнено через prctl;
+		// доступ к БД напрямую и так дает все права, роли не проверяются
+		ctx = service.WithSystemIdentity(service.WithActor(ctx, "prctl"))
+
+		var err error
+		b, err = newOfflineBackend(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			return exitError
+		}
+	} else {
+		b = newHTTPBackend(*addr, *token, *timeout)
+	}
+	defer b.Close()
+
+	p := &printer{json: *output == "json", out: os.Stdout}
+	err := dispatch(ctx, b, p, global.Args())
+	return exitCode(err)
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	if errors.Is(err, errUsage) {
+		fmt.Fprintln(os.Stderr, usage)
+		return exitUsage
+	}
+
+	fmt.Fprintf(os.Stderr, "error: %v\n", err)
+
+	var apiErr *apiError
+	if errors.As(err, &apiErr) {
+		if code, ok := exitCodes[apiErr.Code]; ok {
+			return code
+		}
+	}
+	return exitError
+}
+
+func dispatch(ctx context.Context, b backend, p *printer, args []string) error {
+	cmd, rest := args[0], args[1:]
+
+	switch cmd {
+	case "team":
+		return teamCmd(ctx, b, p, rest)
+	case "user":
+		return userCmd(ctx, b, p, rest)
+	case "pr":
+		return prCmd(ctx, b, p, rest)
+	case "reviews":
+		if len(rest) != 1 {
+			return errUsage
+		}
+		prs, err := b.UserReviews(ctx, rest[0])
+		if err != nil {
+			return err
+		}
+		return p.reviews(rest[0], prs)
+	case "stats":
+		stats, err := b.Stats(ctx)
+		if err != nil {
+			return err
+		}
+		return p.stats(stats)
+	default:
+		return errUsage
+	}
+}
+
+// multiFlag флаг, который можно указать несколько раз
+type multiFlag []string
+
+func (f *multiFlag) String() string { return strings.Join(*f, ",") }
+
+func (f *multiFlag) Set(v string) error {
+	*f = append(*f, v)
+	return nil
+}
+
+func teamCmd(ctx context.Context, b backend, p *printer, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("team create", flag.ContinueOnError)
+		name := fs.String("name", "", "team name")
+		var members multiFlag
+		fs.Var(&members, "member", "ID:USERNAME[:inactive], can be repeated")
+		if err := fs.Parse(args[1:]); err != nil || *name == "" {
+			return errUsage
+		}
+
+		team := model.Team{Name: *name, Users: make([]model.User, 0, len(members))}
+		for _, m := range members {
+			u, err := parseMember(m)
+			if err != nil {
+				return err
+			}
+			u.TeamName = *name
+			team.Users = append(team.Users, u)
+		}
+
+		created, err := b.CreateTeam(ctx, team)
+		if err != nil {
+			return err
+		}
+		return p.team(created)
+	case "get":
+		if len(args) != 2 {
+			return errUsage
+		}
+		team, err := b.GetTeam(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		return p.team(team)
+	default:
+		return errUsage
+	}
+}
+
+// parseMember разбирает ID:USERNAME[:inactive]
+func parseMember(s string) (model.User, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return model.User{}, fmt.Errorf("invalid member %q, expected ID:USERNAME[:inactive]", s)
+	}
+
+	u := model.User{ID: parts[0], Username: parts[1], IsActive: true}
+	if len(parts) == 3 {
+		switch parts[2] {
+		case "inactive":
+			u.IsActive = false
+		case "active":
+		default:
+			return model.User{}, fmt.Errorf("invalid member %q, expected ID:USERNAME[:inactive]", s)
+		}
+	}
+	return u, nil
+}
+
+func userCmd(ctx context.Context, b backend, p *printer, args []string) error {
+	if len(args) == 3 && args[0] == "role" {
+		if err := b.SetRole(ctx, args[1], args[2]); err != nil {
+			return err
+		}
+		return p.role(args[1], args[2])
+	}
+	if len(args) != 2 {
+		return errUsage
+	}
+
+	var isActive bool
+	switch args[0] {
+	case "activate":
+		isActive = true
+	case "deactivate":
+		isActive = false
+	default:
+		return errUsage
+	}
+
+	user, err := b.SetIsActive(ctx, args[1], isActive)
+	if err != nil {
+		return err
+	}
+	return p.user(user)
+}
+
+func prCmd(ctx context.Context, b backend, p *printer, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("pr create", flag.ContinueOnError)
+		id := fs.String("id", "", "pull request id")
+		name := fs.String("name", "", "pull request name")
+		author := fs.String("author", "", "author user id")
+		if err := fs.Parse(args[1:]); err != nil || *id == "" || *name == "" || *author == "" {
+			return errUsage
+		}
+
+		pr, err := b.CreatePR(ctx, *id, *name, *author)
+		if err != nil {
+			return err
+		}
+		return p.pullRequest(pr, "")
+	case "reassign":
+		fs := flag.NewFlagSet("pr reassign", flag.ContinueOnError)
+		id := fs.String("id", "", "pull request id")
+		old := fs.String("old", "", "reviewer to replace")
+		if err := fs.Parse(args[1:]); err != nil || *id == "" || *old == "" {
+			return errUsage
+		}
+
+		pr, replacedBy, err := b.Reassign(ctx, *id, *old)
+		if err != nil {
+			return err
+		}
+		return p.pullRequest(pr, replacedBy)
+	case "merge":
+		if len(args) != 2 {
+			return errUsage
+		}
+		pr, err := b.Merge(ctx, args[1])
+		if err != nil {
+			return err
+		}
+		return p.pullRequest(pr, "")
+	default:
+		return errUsage
+	}
+}
+
+func tokenCmd(args []string) error {
+	fs := flag.NewFlagSet("token", flag.ContinueOnError)
+	user := fs.String("user", "", "user id (sub claim)")
+	ttl := fs.Duration("ttl", 24*time.Hour, "token lifetime")
+	if err := fs.Parse(args); err != nil || *user == "" || *ttl <= 0 {
+		return errUsage
+	}
+
+	cfg, _, err := config.Load(nil)
+	if err != nil {
+		return err
+	}
+
+	// права определяются ролью пользователя в БД на момент запроса,
+	// администратору JWT нужна роль admin (prctl user role USER_ID admin)
+	a := auth.NewAuthenticator(nil, cfg.AuthJWTSecret, cfg.AuthJWTIssuer, cfg.AuthJWTAudience, nil)
+	token, err := a.Issue(*user, *ttl)
+	if err != nil {
+		return err
+	}
+	fmt.Println(token)
+	return nil
+}
+
+func formatBool(v bool) string {
+	return strconv.FormatBool(v)
+}
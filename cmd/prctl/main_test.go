@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	httpapi "github.com/Olzerq/avito-pr-reviewer/internal/http"
+	"github.com/Olzerq/avito-pr-reviewer/internal/migrator"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository/memory"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// newAPI поднимает API на хранилище в памяти без аутентификации
+func newAPI(t *testing.T) *httpBackend {
+	t.Helper()
+
+	storage := memory.NewStorage()
+	prService := service.NewPullRequestService(storage.Tx, storage.PullRequests, storage.Users, nil, nil)
+	v1 := httpapi.NewV1Handler(
+		httpapi.NewTeamHandler(service.NewTeamService(storage.Teams)),
+		httpapi.NewUserHandler(service.NewUserService(storage.Users)),
+		httpapi.NewPullRequestHandler(prService),
+		httpapi.NewStatsHandler(service.NewStatsService(storage.Stats)),
+		httpapi.NewReviewStreamHandler(prService, time.Second),
+	)
+	r := chi.NewRouter()
+	r.Use(httpapi.Authenticate(nil))
+	v1.Routes(r)
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return newHTTPBackend(server.URL+"/", "", 5*time.Second)
+}
+
+// exec выполняет команду и возвращает ее вывод
+func exec(t *testing.T, b backend, asJSON bool, args ...string) (string, error) {
+	t.Helper()
+
+	// личность для offline режима, как в main; HTTP бэкенд ее не использует
+	ctx := service.WithSystemIdentity(context.Background())
+	var out bytes.Buffer
+	err := dispatch(ctx, b, &printer{json: asJSON, out: &out}, args)
+	return out.String(), err
+}
+
+// lines строки вывода без повторяющихся пробелов выравнивания
+func lines(out string) []string {
+	var res []string
+	for _, l := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		res = append(res, strings.Join(strings.Fields(l), " "))
+	}
+	return res
+}
+
+func mustExec(t *testing.T, b backend, asJSON bool, args ...string) string {
+	t.Helper()
+
+	out, err := exec(t, b, asJSON, args...)
+	if err != nil {
+		t.Fatalf("prctl %s: %v", strings.Join(args, " "), err)
+	}
+	return out
+}
+
+// testCommands общий сценарий для HTTP и offline режимов
+func testCommands(t *testing.T, b backend) {
+	out := mustExec(t, b, false, "team", "create", "--name", "backend",
+		"--member", "u1:alice", "--member", "u2:bob", "--member", "u3:carol:inactive")
+	want := []string{
+		"TEAM USER_ID USERNAME ACTIVE",
+		"backend u1 alice true",
+		"backend u2 bob true",
+		"backend u3 carol false",
+	}
+	if got := lines(out); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("unexpected team output:\n%s", out)
+	}
+
+	// единственный активный кандидат - u2
+	out = mustExec(t, b, false, "pr", "create", "--id", "pr-1", "--name", "Add search", "--author", "u1")
+	if got := lines(out); len(got) != 2 || got[0] != "PR_ID NAME AUTHOR STATUS REVIEWERS" || got[1] != "pr-1 Add search u1 OPEN u2" {
+		t.Fatalf("unexpected pr output:\n%s", out)
+	}
+
+	out = mustExec(t, b, true, "user", "activate", "u3")
+	var user struct {
+		User userView `json:"user"`
+	}
+	if err := json.Unmarshal([]byte(out), &user); err != nil || user.User != (userView{UserID: "u3", Username: "carol", TeamName: "backend", IsActive: true}) {
+		t.Fatalf("unexpected user json %q: %v", out, err)
+	}
+
+	out = mustExec(t, b, true, "pr", "reassign", "--id", "pr-1", "--old", "u2")
+	var reassigned prResultView
+	if err := json.Unmarshal([]byte(out), &reassigned); err != nil {
+		t.Fatalf("malformed json %q: %v", out, err)
+	}
+	if reassigned.ReplacedBy != "u3" || reassigned.PR.ID != "pr-1" || len(reassigned.PR.AssignedReviewers) != 1 || reassigned.PR.AssignedReviewers[0] != "u3" {
+		t.Fatalf("unexpected reassign result %+v", reassigned)
+	}
+
+	out = mustExec(t, b, false, "reviews", "u3")
+	if got := lines(out); len(got) != 2 || got[1] != "pr-1 Add search u1 OPEN" {
+		t.Fatalf("unexpected reviews output:\n%s", out)
+	}
+
+	out = mustExec(t, b, false, "pr", "merge", "pr-1")
+	if got := lines(out); len(got) != 2 || !strings.HasPrefix(got[1], "pr-1 Add search u1 MERGED") {
+		t.Fatalf("unexpected merge output:\n%s", out)
+	}
+
+	out = mustExec(t, b, false, "stats")
+	if !strings.Contains(out, "USER_ID") || !strings.Contains(out, "PR_ID") {
+		t.Fatalf("unexpected stats output:\n%s", out)
+	}
+
+	// ошибки API превращаются в коды выхода
+	errorCases := []struct {
+		args []string
+		code int
+	}{
+		{[]string{"team", "get", "missing"}, exitNotFound},
+		{[]string{"team", "create", "--name", "backend", "--member", "u9:x"}, exitTeamExists},
+		{[]string{"pr", "create", "--id", "pr-1", "--name", "again", "--author", "u1"}, exitPRExists},
+		{[]string{"pr", "reassign", "--id", "pr-1", "--old", "u3"}, exitPRMerged},
+		{[]string{"user", "role", "u1", "superuser"}, exitBadRequest},
+	}
+	for _, tc := range errorCases {
+		_, err := exec(t, b, false, tc.args...)
+		if got := exitCode(err); got != tc.code {
+			t.Errorf("prctl %s: exit code %d, want %d (%v)", strings.Join(tc.args, " "), got, tc.code, err)
+		}
+	}
+}
+
+func TestCommandsOverHTTP(t *testing.T) {
+	testCommands(t, newAPI(t))
+}
+
+func TestCommandsOffline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reviewer.db")
+	m, err := migrator.NewSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+	m.Close()
+
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("STORAGE", "sqlite")
+	t.Setenv("SQLITE_PATH", path)
+	b, err := newOfflineBackend(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+
+	testCommands(t, b)
+}
+
+func TestUsageErrors(t *testing.T) {
+	b := newAPI(t)
+
+	for _, args := range [][]string{
+		{"unknown"},
+		{"team"},
+		{"team", "create", "--member", "u1:alice"},
+		{"team", "get"},
+		{"user", "activate"},
+		{"user", "suspend", "u1"},
+		{"pr", "create", "--id", "pr-1", "--name", "x"},
+		{"pr", "reassign", "--id", "pr-1"},
+		{"pr", "merge"},
+		{"reviews"},
+	} {
+		_, err := exec(t, b, false, args...)
+		if !errors.Is(err, errUsage) {
+			t.Errorf("prctl %s: expected usage error, got %v", strings.Join(args, " "), err)
+		}
+		if got := exitCode(err); got != exitUsage {
+			t.Errorf("prctl %s: exit code %d, want %d", strings.Join(args, " "), got, exitUsage)
+		}
+	}
+
+	// глобальные флаги проверяются до подключения к API
+	for _, args := range [][]string{nil, {"--output", "yaml", "stats"}, {"--bogus"}} {
+		if got := run(args); got != exitUsage {
+			t.Errorf("prctl %v: exit code %d, want %d", args, got, exitUsage)
+		}
+	}
+}
+
+// Отказы, после которых команду можно повторить, получают свои коды выхода
+func TestRetryableExitCodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"code":"RATE_LIMITED","message":"too many requests, retry later"}}`))
+	}))
+	defer server.Close()
+
+	_, err := exec(t, newHTTPBackend(server.URL+"/", "", time.Second), false, "team", "get", "backend")
+	if got := exitCode(err); got != exitRateLimited {
+		t.Errorf("rate limited: exit code %d, want %d (%v)", got, exitRateLimited, err)
+	}
+
+	// offline режим отдает те же коды для доменных ошибок
+	for err, code := range map[error]int{
+		service.ErrPRConflict:         exitConflict,
+		service.ErrPreconditionFailed: exitPrecond,
+	} {
+		if got := exitCode(toAPIError(fmt.Errorf("merge: %w", err))); got != code {
+			t.Errorf("%v: exit code %d, want %d", err, got, code)
+		}
+	}
+}
+
+func TestParseMember(t *testing.T) {
+	tests := []struct {
+		in      string
+		id      string
+		active  bool
+		wantErr bool
+	}{
+		{in: "u1:alice", id: "u1", active: true},
+		{in: "u1:alice:active", id: "u1", active: true},
+		{in: "u1:alice:inactive", id: "u1", active: false},
+		{in: "u1", wantErr: true},
+		{in: ":alice", wantErr: true},
+		{in: "u1:", wantErr: true},
+		{in: "u1:alice:away", wantErr: true},
+		{in: "u1:alice:inactive:x", wantErr: true},
+	}
+	for _, tt := range tests {
+		u, err := parseMember(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseMember(%q): expected error", tt.in)
+			}
+			continue
+		}
+		if err != nil || u.ID != tt.id || u.Username != "alice" || u.IsActive != tt.active {
+			t.Errorf("parseMember(%q) = %+v, %v", tt.in, u, err)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/config"
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository/sqlite"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+)
+
+// offlineBackend работает с БД напрямую через сервисный слой, без запущенного сервера
+type offlineBackend struct {
+	close func()
+	teams *service.TeamService
+	users *service.UserService
+	prs   *service.PullRequestService
+	stats *service.StatsService
+}
+
+func newOfflineBackend(ctx context.Context) (*offlineBackend, error) {
+	cfg, _, err := config.Load(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		storage repository.Storage
+		closeDB func()
+	)
+	switch cfg.Storage {
+	case "memory":
+		// у хранилища в памяти нет данных вне процесса сервера
+		return nil, errors.New("--offline requires persistent storage, STORAGE=memory is only available through the API")
+	case "sqlite":
+		db, err := sqlite.Open(ctx, cfg.SQLitePath)
+		if err != nil {
+			return nil, err
+		}
+		storage, closeDB = sqlite.NewStorage(db), func() { _ = db.Close() }
+	default:
+		db, err := repository.NewDB(ctx, cfg.DBConnStr(), repository.PoolOptions{
+			MinConns:        int32(cfg.DBMinConns),
+			MaxConns:        int32(cfg.DBMaxConns),
+			MaxConnLifetime: cfg.DBMaxConnLifetime,
+			MaxConnIdleTime: cfg.DBMaxConnIdleTime,
+		})
+		if err != nil {
+			return nil, err
+		}
+		storage, closeDB = repository.NewStorage(db), db.Close
+	}
+
+	return &offlineBackend{
+		close: closeDB,
+		teams: service.NewTeamService(storage.Teams),
+		users: service.NewUserService(storage.Users),
+		prs:   service.NewPullRequestService(storage.Tx, storage.PullRequests, storage.Users, service.NopNotifier{}, nil),
+		stats: service.NewStatsService(storage.Stats),
+	}, nil
+}
+
+func (b *offlineBackend) Close() {
+	b.close()
+}
+
+// toAPIError переводит доменные ошибки в коды API, как это делают HTTP обработчики
+func toAPIError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, service.ErrValidation):
+		return &apiError{Code: "BAD_REQUEST", Message: err.Error()}
+	case errors.Is(err, repository.ErrUserNotFound),
+		errors.Is(err, repository.ErrTeamNotFound),
+		errors.Is(err, repository.ErrPRNotFound):
+		return &apiError{Code: "NOT_FOUND", Message: err.Error()}
+	case errors.Is(err, repository.ErrTeamExists):
+		return &apiError{Code: "TEAM_EXISTS", Message: err.Error()}
+	case errors.Is(err, repository.ErrPRExists):
+		return &apiError{Code: "PR_EXISTS", Message: err.Error()}
+	case errors.Is(err, service.ErrPRMerged):
+		return &apiError{Code: "PR_MERGED", Message: err.Error()}
+	case errors.Is(err, repository.ErrReviewerNotAssigned):
+		return &apiError{Code: "NOT_ASSIGNED", Message: err.Error()}
+	case errors.Is(err, service.ErrNoCandidate):
+		return &apiError{Code: "NO_CANDIDATE", Message: err.Error()}
+	case errors.Is(err, service.ErrForbidden):
+		return &apiError{Code: "FORBIDDEN", Message: err.Error()}
+	case errors.Is(err, service.ErrPRConflict):
+		return &apiError{Code: "CONFLICT", Message: err.Error()}
+	case errors.Is(err, service.ErrPreconditionFailed):
+		return &apiError{Code: "PRECONDITION_FAILED", Message: err.Error()}
+	default:
+		return err
+	}
+}
+
+func (b *offlineBackend) CreateTeam(ctx context.Context, team model.Team) (model.Team, error) {
+	if err := b.teams.CreateTeam(ctx, team); err != nil {
+		return model.Team{}, toAPIError(err)
+	}
+	return team, nil
+}
+
+func (b *offlineBackend) GetTeam(ctx context.Context, name string) (model.Team, error) {
+	team, err := b.teams.GetTeam(ctx, name)
+	return team, toAPIError(err)
+}
+
+func (b *offlineBackend) SetIsActive(ctx context.Context, userID string, isActive bool) (model.User, error) {
+	if err := b.users.SetIsActive(ctx, userID, isActive); err != nil {
+		return model.User{}, toAPIError(err)
+	}
+	user, err := b.users.GetByID(ctx, userID)
+	return user, toAPIError(err)
+}
+
+func (b *offlineBackend) SetRole(ctx context.Context, userID, role string) error {
+	return toAPIError(b.users.SetRole(ctx, userID, role))
+}
+
+func (b *offlineBackend) CreatePR(ctx context.Context, id, name, authorID string) (model.PullRequest, error) {
+	pr, err := b.prs.Create(ctx, id, name, authorID)
+	return pr, toAPIError(err)
+}
+
+func (b *offlineBackend) Reassign(ctx context.Context, prID, oldUserID string) (model.PullRequest, string, error) {
+	pr, replacedBy, err := b.prs.Reassign(ctx, prID, oldUserID)
+	return pr, replacedBy, toAPIError(err)
+}
+
+func (b *offlineBackend) Merge(ctx context.Context, prID string) (model.PullRequest, error) {
+	pr, err := b.prs.Merge(ctx, prID)
+	return pr, toAPIError(err)
+}
+
+func (b *offlineBackend) UserReviews(ctx context.Context, userID string) ([]model.PullRequestShort, error) {
+	prs := make([]model.PullRequestShort, 0)
+	q := service.ListQuery{Limit: service.MaxListLimit}
+	for {
+		res, err := b.prs.GetUserReviews(ctx, userID, "", q)
+		if err != nil {
+			return nil, toAPIError(err)
+		}
+		prs = append(prs, res.Items...)
+		if res.NextCursor == "" {
+			return prs, nil
+		}
+		q.Cursor = res.NextCursor
+	}
+}
+
+func (b *offlineBackend) Stats(ctx context.Context) (statsView, error) {
+	stats, err := b.stats.GetAssignmentsStats(ctx)
+	if err != nil {
+		return statsView{}, err
+	}
+
+	v := statsView{
+		ByUser: make([]userStatView, 0, len(stats.ByUser)),
+		ByPR:   make([]prStatView, 0, len(stats.ByPR)),
+	}
+	for _, s := range stats.ByUser {
+		v.ByUser = append(v.ByUser, userStatView{UserID: s.UserID, AssignedCount: s.AssignedCount})
+	}
+	for _, s := range stats.ByPR {
+		v.ByPR = append(v.ByPR, prStatView{PullRequestID: s.PullRequestID, ReviewersCount: s.ReviewersCount})
+	}
+	return v, nil
+}
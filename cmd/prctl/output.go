@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+)
+
+// JSON представления совпадают с ответами API
+
+type memberView struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	IsActive bool   `json:"is_active"`
+}
+
+type teamView struct {
+	TeamName string       `json:"team_name"`
+	Members  []memberView `json:"members"`
+}
+
+type userView struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	TeamName string `json:"team_name"`
+	IsActive bool   `json:"is_active"`
+}
+
+type prView struct {
+	ID                string     `json:"pull_request_id"`
+	Name              string     `json:"pull_request_name"`
+	AuthorID          string     `json:"author_id"`
+	Status            string     `json:"status"`
+	AssignedReviewers []string   `json:"assigned_reviewers"`
+	CreatedAt         *time.Time `json:"createdAt"`
+	MergedAt          *time.Time `json:"mergedAt"`
+}
+
+type prResultView struct {
+	PR         prView `json:"pr"`
+	ReplacedBy string `json:"replaced_by,omitempty"`
+}
+
+type prShortView struct {
+	ID       string `json:"pull_request_id"`
+	Name     string `json:"pull_request_name"`
+	AuthorID string `json:"author_id"`
+	Status   string `json:"status"`
+}
+
+type reviewsView struct {
+	UserID       string        `json:"user_id"`
+	PullRequests []prShortView `json:"pull_requests"`
+	NextCursor   string        `json:"next_cursor,omitempty"`
+}
+
+type userStatView struct {
+	UserID        string `json:"user_id"`
+	AssignedCount int    `json:"assigned_count"`
+}
+
+type prStatView struct {
+	PullRequestID  string `json:"pull_request_id"`
+	ReviewersCount int    `json:"reviewers_count"`
+}
+
+type statsView struct {
+	ByUser []userStatView `json:"by_user"`
+	ByPR   []prStatView   `json:"by_pr"`
+}
+
+// printer выводит результат таблицей или JSON
+type printer struct {
+	json bool
+	out  io.Writer
+}
+
+func (p *printer) printJSON(v any) error {
+	enc := json.NewEncoder(p.out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func (p *printer) table(header string, rows [][]string) error {
+	w := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, header)
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+func toTeamView(team model.Team) teamView {
+	v := teamView{TeamName: team.Name, Members: make([]memberView, 0, len(team.Users))}
+	for _, u := range team.Users {
+		v.Members = append(v.Members, memberView{UserID: u.ID, Username: u.Username, IsActive: u.IsActive})
+	}
+	return v
+}
+
+func toPRView(pr model.PullRequest) prView {
+	v := prView{
+		ID:                pr.ID,
+		Name:              pr.Name,
+		AuthorID:          pr.AuthorID,
+		Status:            pr.Status,
+		AssignedReviewers: make([]string, 0, len(pr.Reviewers)),
+		CreatedAt:         pr.CreatedAt,
+		MergedAt:          pr.MergedAt,
+	}
+	for _, u := range pr.Reviewers {
+		v.AssignedReviewers = append(v.AssignedReviewers, u.ID)
+	}
+	return v
+}
+
+func (p *printer) team(team model.Team) error {
+	v := toTeamView(team)
+	if p.json {
+		return p.printJSON(map[string]any{"team": v})
+	}
+
+	rows := make([][]string, 0, len(v.Members))
+	for _, m := range v.Members {
+		rows = append(rows, []string{v.TeamName, m.UserID, m.Username, formatBool(m.IsActive)})
+	}
+	return p.table("TEAM\tUSER_ID\tUSERNAME\tACTIVE", rows)
+}
+
+func (p *printer) user(u model.User) error {
+	if p.json {
+		return p.printJSON(map[string]any{"user": userView{
+			UserID: u.ID, Username: u.Username, TeamName: u.TeamName, IsActive: u.IsActive,
+		}})
+	}
+	return p.table("USER_ID\tUSERNAME\tTEAM\tACTIVE", [][]string{
+		{u.ID, u.Username, u.TeamName, formatBool(u.IsActive)},
+	})
+}
+
+func (p *printer) role(userID, role string) error {
+	if p.json {
+		return p.printJSON(map[string]any{"user_id": userID, "role": role})
+	}
+	return p.table("USER_ID\tROLE", [][]string{{userID, role}})
+}
+
+func (p *printer) pullRequest(pr model.PullRequest, replacedBy string) error {
+	v := toPRView(pr)
+	if p.json {
+		return p.printJSON(prResultView{PR: v, ReplacedBy: replacedBy})
+	}
+
+	header := "PR_ID\tNAME\tAUTHOR\tSTATUS\tREVIEWERS"
+	row := []string{v.ID, v.Name, v.AuthorID, v.Status, strings.Join(v.AssignedReviewers, ",")}
+	if replacedBy != "" {
+		header += "\tREPLACED_BY"
+		row = append(row, replacedBy)
+	}
+	return p.table(header, [][]string{row})
+}
+
+func (p *printer) reviews(userID string, prs []model.PullRequestShort) error {
+	v := reviewsView{UserID: userID, PullRequests: make([]prShortView, 0, len(prs))}
+	for _, pr := range prs {
+		v.PullRequests = append(v.PullRequests, prShortView{
+			ID: pr.ID, Name: pr.Name, AuthorID: pr.AuthorID, Status: pr.Status,
+		})
+	}
+	if p.json {
+		return p.printJSON(v)
+	}
+
+	rows := make([][]string, 0, len(v.PullRequests))
+	for _, pr := range v.PullRequests {
+		rows = append(rows, []string{pr.ID, pr.Name, pr.AuthorID, pr.Status})
+	}
+	return p.table("PR_ID\tNAME\tAUTHOR\tSTATUS", rows)
+}
+
+func (p *printer) stats(s statsView) error {
+	if p.json {
+		return p.printJSON(s)
+	}
+
+	rows := make([][]string, 0, len(s.ByUser))
+	for _, u := range s.ByUser {
+		rows = append(rows, []string{u.UserID, fmt.Sprint(u.AssignedCount)})
+	}
+	if err := p.table("USER_ID\tASSIGNED", rows); err != nil {
+		return err
+	}
+	fmt.Fprintln(p.out)
+
+	rows = make([][]string, 0, len(s.ByPR))
+	for _, pr := range s.ByPR {
+		rows = append(rows, []string{pr.PullRequestID, fmt.Sprint(pr.ReviewersCount)})
+	}
+	return p.table("PR_ID\tREVIEWERS", rows)
+}
@@ -0,0 +1,166 @@
+// Package auth проверяет bearer токены: статические токены администраторов
+// из конфига и JWT, подписанные общим HMAC ключом.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AdminSubject субъект, от имени которого действуют статические токены
+const AdminSubject = "admin"
+
+var (
+	ErrNoToken      = errors.New("missing bearer token")
+	ErrInvalidToken = errors.New("invalid token")
+	ErrUnknownUser  = errors.New("unknown user") // возвращает RoleStore, если пользователя нет
+
+	// ошибка конфигурации сервера, а не токена: клиент получает 500, а не 401
+	errRolesNotConfigured = errors.New("role store is not configured, jwt users cannot be authorized")
+)
+
+// Identity кто выполняет запрос
+type Identity struct {
+	Subject  string // user_id из JWT или admin для статических токенов
+	Role     string // model.Role*
+	TeamName string // команда пользователя, пусто для администраторов из конфига
+}
+
+func (i Identity) IsAdmin() bool {
+	return i.Role == model.RoleAdmin
+}
+
+// RoleStore источник ролей пользователей, для отсутствующего пользователя возвращает ErrUnknownUser
+type RoleStore interface {
+	GetRole(ctx context.Context, userID string) (role, teamName string, err error)
+}
+
+type identityKey struct{}
+
+// WithIdentity сохраняет личность вызывающего в контексте
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext возвращает личность вызывающего, ok=false для анонимного запроса
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// Claims полезная нагрузка JWT. Роль в токен не пишется и из него не читается:
+// она берется из БД при каждом запросе, поэтому понижение или удаление
+// пользователя действует сразу, а не после истечения токена.
+type Claims struct {
+	jwt.RegisteredClaims
+}
+
+// Authenticator проверяет токены
+type Authenticator struct {
+	adminTokens [][]byte
+	secret      []byte
+	issuer      string
+	audience    string
+	roles       RoleStore
+}
+
+// NewAuthenticator пустой secret отключает JWT, пустой список - статические токены.
+// Права администратора дают только статические токены и роль admin в БД, без roles
+// ни один JWT не проходит.
+func NewAuthenticator(adminTokens []string, secret, issuer, audience string, roles RoleStore) *Authenticator {
+	a := &Authenticator{
+		secret:   []byte(secret),
+		issuer:   issuer,
+		audience: audience,
+		roles:    roles,
+	}
+	for _, t := range adminTokens {
+		if t != "" {
+			a.adminTokens = append(a.adminTokens, []byte(t))
+		}
+	}
+	return a
+}
+
+// Authenticate возвращает личность по токену
+func (a *Authenticator) Authenticate(ctx context.Context, token string) (Identity, error) {
+	if token == "" {
+		return Identity{}, ErrNoToken
+	}
+
+	// сравниваем со всеми токенами за постоянное время
+	match := 0
+	for _, t := range a.adminTokens {
+		match |= subtle.ConstantTimeCompare(t, []byte(token))
+	}
+	if match == 1 {
+		return Identity{Subject: AdminSubject, Role: model.RoleAdmin}, nil
+	}
+
+	if len(a.secret) == 0 {
+		return Identity{}, ErrInvalidToken
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"HS256", "HS384", "HS512"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if a.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(a.issuer))
+	}
+	if a.audience != "" {
+		opts = append(opts, jwt.WithAudience(a.audience))
+	}
+
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return a.secret, nil
+	}, opts...)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.Subject == "" {
+		return Identity{}, fmt.Errorf("%w: sub claim is empty", ErrInvalidToken)
+	}
+
+	if a.roles == nil {
+		return Identity{}, errRolesNotConfigured
+	}
+
+	role, team, err := a.roles.GetRole(ctx, claims.Subject)
+	if err != nil {
+		if errors.Is(err, ErrUnknownUser) {
+			return Identity{}, fmt.Errorf("%w: unknown user %q", ErrInvalidToken, claims.Subject)
+		}
+		return Identity{}, err
+	}
+	return Identity{Subject: claims.Subject, Role: role, TeamName: team}, nil
+}
+
+// Issue выпускает JWT для пользователя, используется prctl
+func (a *Authenticator) Issue(subject string, ttl time.Duration) (string, error) {
+	if len(a.secret) == 0 {
+		return "", errors.New("jwt secret is not configured")
+	}
+
+	now := time.Now()
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			Issuer:    a.issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	if a.audience != "" {
+		claims.Audience = jwt.ClaimStrings{a.audience}
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(a.secret)
+}
@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// roleStore роли пользователей в памяти
+type roleStore map[string][2]string
+
+func (s roleStore) GetRole(_ context.Context, userID string) (string, string, error) {
+	if userID == "broken" {
+		return "", "", errors.New("db is down")
+	}
+	r, ok := s[userID]
+	if !ok {
+		return "", "", ErrUnknownUser
+	}
+	return r[0], r[1], nil
+}
+
+// sign подписывает произвольные claims
+func sign(t *testing.T, method jwt.SigningMethod, key any, claims jwt.Claims) string {
+	t.Helper()
+
+	token, err := jwt.NewWithClaims(method, claims).SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func registered(sub string, ttl time.Duration) jwt.RegisteredClaims {
+	now := time.Now()
+	return jwt.RegisteredClaims{
+		Subject:   sub,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	roles := roleStore{
+		"u1": {model.RoleMember, "backend"},
+		"u2": {model.RoleTeamLead, "backend"},
+	}
+	a := NewAuthenticator([]string{"static-admin", ""}, testSecret, "reviewer", "api", roles)
+	issue := func(sub string, ttl time.Duration) string {
+		token, err := a.Issue(sub, ttl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	withIssuer := func(iss, aud string) jwt.RegisteredClaims {
+		c := registered("u1", time.Hour)
+		c.Issuer, c.Audience = iss, jwt.ClaimStrings{aud}
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		want    Identity
+		wantErr error
+	}{
+		{name: "missing", token: "", wantErr: ErrNoToken},
+		{name: "static admin", token: "static-admin", want: Identity{Subject: AdminSubject, Role: model.RoleAdmin}},
+		{name: "empty static token is not accepted", token: " ", wantErr: ErrInvalidToken},
+		{name: "malformed", token: "not-a-jwt", wantErr: ErrInvalidToken},
+		{name: "member", token: issue("u1", time.Hour), want: Identity{Subject: "u1", Role: model.RoleMember, TeamName: "backend"}},
+		{name: "team lead", token: issue("u2", time.Hour), want: Identity{Subject: "u2", Role: model.RoleTeamLead, TeamName: "backend"}},
+		{name: "unknown subject", token: issue("ghost", time.Hour), wantErr: ErrInvalidToken},
+		{name: "expired", token: issue("u1", -time.Hour), wantErr: ErrInvalidToken},
+		{name: "bad signature", token: sign(t, jwt.SigningMethodHS256, []byte("another-secret-another-secret-xx"), withIssuer("reviewer", "api")), wantErr: ErrInvalidToken},
+		{name: "alg none", token: sign(t, jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, withIssuer("reviewer", "api")), wantErr: ErrInvalidToken},
+		{name: "wrong issuer", token: sign(t, jwt.SigningMethodHS256, []byte(testSecret), withIssuer("other", "api")), wantErr: ErrInvalidToken},
+		{name: "wrong audience", token: sign(t, jwt.SigningMethodHS256, []byte(testSecret), withIssuer("reviewer", "other")), wantErr: ErrInvalidToken},
+		{name: "no expiry", token: sign(t, jwt.SigningMethodHS256, []byte(testSecret), jwt.RegisteredClaims{Subject: "u1", Issuer: "reviewer", Audience: jwt.ClaimStrings{"api"}}), wantErr: ErrInvalidToken},
+		{name: "issuer and audience match", token: sign(t, jwt.SigningMethodHS256, []byte(testSecret), withIssuer("reviewer", "api")), want: Identity{Subject: "u1", Role: model.RoleMember, TeamName: "backend"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := a.Authenticate(context.Background(), tt.token)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v (%+v)", tt.wantErr, err, id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if id != tt.want {
+				t.Fatalf("expected %+v, got %+v", tt.want, id)
+			}
+		})
+	}
+}
+
+func TestAuthenticateEmptySubject(t *testing.T) {
+	a := NewAuthenticator(nil, testSecret, "", "", roleStore{})
+	token := sign(t, jwt.SigningMethodHS256, []byte(testSecret), registered("", time.Hour))
+	if _, err := a.Authenticate(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected invalid token for empty sub, got %v", err)
+	}
+}
+
+func TestAuthenticateRoleStoreFailure(t *testing.T) {
+	a := NewAuthenticator(nil, testSecret, "", "", roleStore{})
+	token, err := a.Issue("broken", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// сбой БД не выдается за неверный токен, иначе клиент получит 401 вместо 500
+	_, err = a.Authenticate(context.Background(), token)
+	if err == nil || errors.Is(err, ErrInvalidToken) || errors.Is(err, ErrNoToken) {
+		t.Fatalf("expected storage error, got %v", err)
+	}
+}
+
+func TestAuthenticateWithoutSecret(t *testing.T) {
+	// без ключа JWT принимаются только статические токены
+	a := NewAuthenticator([]string{"static-admin"}, "", "", "", roleStore{"u1": {model.RoleMember, "backend"}})
+	token := sign(t, jwt.SigningMethodHS256, []byte(""), registered("u1", time.Hour))
+	if _, err := a.Authenticate(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected invalid token, got %v", err)
+	}
+	if _, err := a.Issue("u1", time.Hour); err == nil {
+		t.Fatal("Issue must fail without a secret")
+	}
+}
+
+func TestRoleComesFromStore(t *testing.T) {
+	roles := roleStore{"u1": {model.RoleMember, "backend"}}
+	a := NewAuthenticator(nil, testSecret, "", "", roles)
+
+	// role в токене игнорируется, администратором JWT не сделать
+	forged := sign(t, jwt.SigningMethodHS256, []byte(testSecret), jwt.MapClaims{
+		"sub":  "u1",
+		"role": model.RoleAdmin,
+		"exp":  time.Now().Add(time.Hour).Unix(),
+	})
+	id, err := a.Authenticate(context.Background(), forged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id.IsAdmin() || id.Role != model.RoleMember {
+		t.Fatalf("role claim must be ignored, got %+v", id)
+	}
+
+	// смена роли в БД действует на уже выпущенный токен
+	token, err := a.Issue("u1", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roles["u1"] = [2]string{model.RoleAdmin, "backend"}
+	if id, err := a.Authenticate(context.Background(), token); err != nil || !id.IsAdmin() {
+		t.Fatalf("expected promoted user to be admin, got %+v, %v", id, err)
+	}
+	roles["u1"] = [2]string{model.RoleReadOnly, "backend"}
+	if id, err := a.Authenticate(context.Background(), token); err != nil || id.Role != model.RoleReadOnly {
+		t.Fatalf("expected demoted user to be read_only, got %+v, %v", id, err)
+	}
+	delete(roles, "u1")
+	if _, err := a.Authenticate(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected deleted user to be rejected, got %v", err)
+	}
+}
+
+func TestAuthenticateWithoutRoleStore(t *testing.T) {
+	a := NewAuthenticator([]string{"static-admin"}, testSecret, "", "", nil)
+	token, err := a.Issue("u1", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// без источника ролей JWT не проходит, а ошибка не выдается за неверный токен
+	_, err = a.Authenticate(context.Background(), token)
+	if err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Fatalf("expected configuration error, got %v", err)
+	}
+	if id, err := a.Authenticate(context.Background(), "static-admin"); err != nil || !id.IsAdmin() {
+		t.Fatalf("static tokens must work without role store, got %+v, %v", id, err)
+	}
+}
@@ -1,70 +1,577 @@
 package config
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"net"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/ratelimit"
 )
 
 type Config struct {
-	AppPort string
+	AppPort  string
+	LogLevel string // debug | info | warn | error
+
+	// gRPC API на отдельном порту
+	GRPCEnabled bool
+	GRPCPort    string
+
+	// Таймауты HTTP сервера
+	HTTPReadHeaderTimeout time.Duration
+	HTTPReadTimeout       time.Duration
+	HTTPWriteTimeout      time.Duration
+	HTTPIdleTimeout       time.Duration
+	ShutdownTimeout       time.Duration // сколько ждать завершения запросов при остановке
+	ShutdownDrainDelay    time.Duration // сколько принимать запросы после перехода в not ready
+
+	// Поток очереди ревью (SSE): комментарий heartbeat не дает прокси закрыть тихое соединение
+	SSEHeartbeatInterval time.Duration
+
+	// Ограничение нагрузки
+	RateLimitEnabled    bool
+	RateLimitDefault    string // rps:burst на клиента
+	RateLimitRoutes     string // "METHOD /path=rps:burst,..."
+	RateLimitTrustProxy bool   // брать IP клиента из X-Forwarded-For
+	HTTPMaxInFlight     int    // 0 - 2 × db_max_conns
+	HTTPInFlightWait    time.Duration
+
+	// Сколько хранить ответы на запросы с Idempotency-Key
+	IdempotencyTTL time.Duration
+	// Через сколько незавершенный запрос считается брошенным и ключ можно занять повтором
+	IdempotencyLockTimeout time.Duration
+
+	// Проверка v1 по openapi.yml: off | requests | full (и ответы, расхождения в лог)
+	OpenAPIValidation string
+
+	// Проверка готовности
+	ReadinessTimeout        time.Duration
+	PoolSaturationThreshold float64 // доля занятых соединений, после которой пул считается перегруженным
+
+	// Хранилище: postgres | sqlite (файл на одном узле) | memory (данные в памяти процесса, для демо и разработки)
+	Storage    string
+	SQLitePath string // файл БД для storage=sqlite
+
+	// Подключение к БД: либо готовый DSN, либо отдельные части
+	DBDSN         string
+	DBHost        string
+	DBPort        string
+	DBUser        string
+	DBPass        string
+	DBName        string
+	DBSSLMode     string // disable | allow | prefer | require | verify-ca | verify-full
+	DBSSLRootCert string
+
+	// Пул соединений
+	DBMinConns        int
+	DBMaxConns        int
+	DBMaxConnLifetime time.Duration
+	DBMaxConnIdleTime time.Duration
+
+	MigrateOnStart bool // применять встроенные миграции при старте
+	MetricsEnabled bool // отдавать /metrics
+
+	// Аутентификация: статические токены администраторов и/или JWT (HMAC)
+	AuthEnabled     bool
+	AuthAdminTokens string // через запятую
+	AuthJWTSecret   string
+	AuthJWTIssuer   string
+	AuthJWTAudience string
+
+	// Уведомления ревьюверам
+	Notifier          string // log | webhook | none
+	NotifyWebhookURL  string
+	NotifyQueueSize   int
+	NotifyMaxAttempts int
+
+	// JSON с настройками команд: каналы, шаблоны, SLA
+	TeamsFile string
+
+	// Напоминания и эскалация зависших ревью
+	SchedulerEnabled    bool
+	SchedulerInterval   time.Duration
+	ReviewRemindAfter   time.Duration // SLA по умолчанию
+	ReviewEscalateAfter time.Duration // 0 - без автопереназначения
 
-	DBHost string
-	DBPort string
-	DBUser string
-	DBPass string
-	DBName string
+	// Трейсинг OpenTelemetry
+	TracingExporter    string // otlp | stdout | none
+	TracingEndpoint    string // URL OTLP/HTTP коллектора
+	TracingServiceName string
 }
 
-func LoadConfig() Config {
+// Default значения по умолчанию, подходят для локального запуска
+func Default() Config {
 	return Config{
-		AppPort: validatePort(getEnv("APP_PORT", ""), "8080"),
+		AppPort:  "8080",
+		LogLevel: "info",
 
-		DBHost: getEnv("DB_HOST", "localhost"),
-		DBPort: validatePort(getEnv("DB_PORT", ""), "5432"),
-		DBUser: getEnv("DB_USER", "avito_user"),
-		DBPass: getEnv("DB_PASS", "avito_password"),
-		DBName: getEnv("DB_NAME", "avito_prs"),
+		GRPCEnabled: true,
+		GRPCPort:    "9090",
+
+		HTTPReadHeaderTimeout: 5 * time.Second,
+		HTTPReadTimeout:       10 * time.Second,
+		HTTPWriteTimeout:      15 * time.Second,
+		HTTPIdleTimeout:       60 * time.Second,
+		ShutdownTimeout:       20 * time.Second,
+		ShutdownDrainDelay:    5 * time.Second,
+
+		SSEHeartbeatInterval: 15 * time.Second,
+
+		RateLimitEnabled: true,
+		RateLimitDefault: "20:40",
+		RateLimitRoutes:  "POST /pullRequest/create=5:10,POST /pullRequest/batchCreate=5:500,POST /pullRequest/batchMerge=5:500,POST /v2/pull-requests=5:10",
+		HTTPInFlightWait: 100 * time.Millisecond,
+
+		IdempotencyTTL:         24 * time.Hour,
+		IdempotencyLockTimeout: time.Minute,
+
+		OpenAPIValidation: "requests",
+
+		ReadinessTimeout:        time.Second,
+		PoolSaturationThreshold: 0.9,
+
+		Storage:    "postgres",
+		SQLitePath: "reviewer.db",
+
+		DBHost:    "localhost",
+		DBPort:    "5432",
+		DBUser:    "avito_user",
+		DBPass:    "avito_password",
+		DBName:    "avito_prs",
+		DBSSLMode: "disable",
+
+		DBMinConns:        1,
+		DBMaxConns:        10,
+		DBMaxConnLifetime: time.Hour,
+		DBMaxConnIdleTime: 30 * time.Minute,
+
+		MetricsEnabled: true,
+
+		AuthEnabled: true,
+
+		Notifier:          "log",
+		NotifyQueueSize:   1000,
+		NotifyMaxAttempts: 5,
+
+		SchedulerEnabled:    true,
+		SchedulerInterval:   5 * time.Minute,
+		ReviewRemindAfter:   24 * time.Hour,
+		ReviewEscalateAfter: 0,
+
+		TracingExporter:    "none",
+		TracingServiceName: "avito-pr-reviewer",
 	}
 }
 
-func (c Config) DBConnStr() string {
-	return fmt.Sprintf(
-		"postgres://%s:%s@%s:%s/%s?sslmode=disable",
-		c.DBUser, c.DBPass, c.DBHost, c.DBPort, c.DBName,
-	)
+// option описывает один параметр. Имя в файле - key, переменная окружения -
+// KEY в верхнем регистре (или env), флаг - --key-через-дефисы.
+type option struct {
+	key    string
+	env    []string // переменные окружения по убыванию приоритета
+	usage  string
+	secret bool
+	ptr    any
 }
 
-func getEnv(key, fallback string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+func (o option) flagName() string {
+	return strings.ReplaceAll(o.key, "_", "-")
+}
+
+func (o option) envNames() []string {
+	if len(o.env) > 0 {
+		return o.env
 	}
-	return fallback
+	return []string{strings.ToUpper(o.key)}
 }
 
-func validatePort(port string, def string) string {
-	if port == "" {
-		return def
+func (c *Config) options() []option {
+	return []option{
+		{key: "app_port", usage: "HTTP port", ptr: &c.AppPort},
+		{key: "log_level", usage: "debug | info | warn | error", ptr: &c.LogLevel},
+
+		{key: "grpc_enabled", usage: "serve the gRPC API", ptr: &c.GRPCEnabled},
+		{key: "grpc_port", usage: "gRPC port", ptr: &c.GRPCPort},
+
+		{key: "http_read_header_timeout", usage: "time to read request headers", ptr: &c.HTTPReadHeaderTimeout},
+		{key: "http_read_timeout", usage: "time to read the whole request", ptr: &c.HTTPReadTimeout},
+		{key: "http_write_timeout", usage: "time to write the response", ptr: &c.HTTPWriteTimeout},
+		{key: "http_idle_timeout", usage: "keep-alive idle timeout", ptr: &c.HTTPIdleTimeout},
+		{key: "shutdown_timeout", usage: "graceful shutdown timeout", ptr: &c.ShutdownTimeout},
+		{key: "shutdown_drain_delay", usage: "time to keep serving after readiness is dropped", ptr: &c.ShutdownDrainDelay},
+
+		{key: "sse_heartbeat_interval", usage: "heartbeat interval of the review queue event stream", ptr: &c.SSEHeartbeatInterval},
+
+		{key: "rate_limit_enabled", usage: "limit request rate per token / client IP", ptr: &c.RateLimitEnabled},
+		{key: "rate_limit_default", usage: "default budget rps:burst", ptr: &c.RateLimitDefault},
+		{key: "rate_limit_routes", usage: "per-route budgets \"METHOD /path=rps:burst,...\"", ptr: &c.RateLimitRoutes},
+		{key: "rate_limit_trust_proxy", usage: "take client IP from X-Forwarded-For", ptr: &c.RateLimitTrustProxy},
+		{key: "http_max_in_flight", usage: "max concurrent API requests, 0 means 2 x db_max_conns", ptr: &c.HTTPMaxInFlight},
+		{key: "http_in_flight_wait", usage: "how long a request waits for a free slot before 503", ptr: &c.HTTPInFlightWait},
+
+		{key: "idempotency_ttl", usage: "how long responses to requests with Idempotency-Key are kept", ptr: &c.IdempotencyTTL},
+		{key: "idempotency_lock_timeout", usage: "after how long an unfinished request with Idempotency-Key may be taken over by a retry", ptr: &c.IdempotencyLockTimeout},
+
+		{key: "openapi_validation", usage: "check v1 against openapi.yml: off | requests | full", ptr: &c.OpenAPIValidation},
+
+		{key: "readiness_timeout", usage: "timeout of /readyz checks", ptr: &c.ReadinessTimeout},
+		{key: "pool_saturation_threshold", usage: "share of busy connections reported as degraded", ptr: &c.PoolSaturationThreshold},
+
+		{key: "storage", usage: "postgres | sqlite | memory", ptr: &c.Storage},
+		{key: "sqlite_path", usage: "database file for storage=sqlite", ptr: &c.SQLitePath},
+
+		{key: "db_dsn", usage: "postgres:// URL, overrides db_host..db_sslrootcert", secret: true, ptr: &c.DBDSN},
+		{key: "db_host", usage: "database host", ptr: &c.DBHost},
+		{key: "db_port", usage: "database port", ptr: &c.DBPort},
+		{key: "db_user", usage: "database user", ptr: &c.DBUser},
+		// DB_PASS оставлен для совместимости со старыми окружениями
+		{key: "db_password", env: []string{"DB_PASSWORD", "DB_PASS"}, usage: "database password", secret: true, ptr: &c.DBPass},
+		{key: "db_name", usage: "database name", ptr: &c.DBName},
+		{key: "db_sslmode", usage: "disable | allow | prefer | require | verify-ca | verify-full", ptr: &c.DBSSLMode},
+		{key: "db_sslrootcert", usage: "CA certificate for verify-ca / verify-full", ptr: &c.DBSSLRootCert},
+
+		{key: "db_min_conns", usage: "minimum pool connections", ptr: &c.DBMinConns},
+		{key: "db_max_conns", usage: "maximum pool connections", ptr: &c.DBMaxConns},
+		{key: "db_max_conn_lifetime", usage: "connection lifetime", ptr: &c.DBMaxConnLifetime},
+		{key: "db_max_conn_idle_time", usage: "idle connection lifetime", ptr: &c.DBMaxConnIdleTime},
+
+		{key: "migrate_on_start", usage: "apply embedded migrations on start", ptr: &c.MigrateOnStart},
+		{key: "metrics_enabled", usage: "serve /metrics", ptr: &c.MetricsEnabled},
+
+		{key: "auth_enabled", usage: "require bearer tokens, false gives every caller admin rights", ptr: &c.AuthEnabled},
+		{key: "auth_admin_tokens", usage: "comma-separated static admin tokens", secret: true, ptr: &c.AuthAdminTokens},
+		{key: "auth_jwt_secret", usage: "HMAC key for user JWTs, at least 32 bytes", secret: true, ptr: &c.AuthJWTSecret},
+		{key: "auth_jwt_issuer", usage: "expected iss claim", ptr: &c.AuthJWTIssuer},
+		{key: "auth_jwt_audience", usage: "expected aud claim", ptr: &c.AuthJWTAudience},
+
+		{key: "notifier", usage: "log | webhook | none", ptr: &c.Notifier},
+		{key: "notify_webhook_url", usage: "default webhook URL", secret: true, ptr: &c.NotifyWebhookURL},
+		{key: "notify_queue_size", usage: "notification queue size", ptr: &c.NotifyQueueSize},
+		{key: "notify_max_attempts", usage: "notification delivery attempts", ptr: &c.NotifyMaxAttempts},
+
+		{key: "teams_file", env: []string{"TEAMS_FILE", "NOTIFY_TEAMS_FILE"}, usage: "JSON file with per-team settings", ptr: &c.TeamsFile},
+
+		{key: "scheduler_enabled", usage: "send reminders and escalate stale reviews", ptr: &c.SchedulerEnabled},
+		{key: "scheduler_interval", usage: "scheduler tick interval", ptr: &c.SchedulerInterval},
+		{key: "review_remind_after", usage: "default review SLA", ptr: &c.ReviewRemindAfter},
+		{key: "review_escalate_after", usage: "reassign stale reviewer after, 0 disables", ptr: &c.ReviewEscalateAfter},
+
+		{key: "tracing_exporter", env: []string{"OTEL_TRACES_EXPORTER"}, usage: "otlp | stdout | none", ptr: &c.TracingExporter},
+		{key: "tracing_endpoint", env: []string{"OTEL_EXPORTER_OTLP_ENDPOINT"}, usage: "OTLP/HTTP collector URL", ptr: &c.TracingEndpoint},
+		{key: "tracing_service_name", env: []string{"OTEL_SERVICE_NAME"}, usage: "service.name resource attribute", ptr: &c.TracingServiceName},
 	}
+}
 
-	portNum, err := strconv.Atoi(port)
-	if err != nil {
-		log.Printf("Ошибка: значение порта '%s' не является числом. Используется порт по умолчанию: %s",
-			port, def)
-		return def
+// Load собирает конфиг из источников по возрастанию приоритета:
+// значения по умолчанию, файл (--config или CONFIG_FILE), переменные окружения, флаги.
+// Возвращает аргументы, оставшиеся после флагов. Все ошибки источников и
+// валидации возвращаются одной пачкой.
+func Load(args []string) (Config, []string, error) {
+	cfg := Default()
+	opts := cfg.options()
+
+	fs := flag.NewFlagSet("app", flag.ContinueOnError)
+	configFile := fs.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML config file")
+
+	// флаги применяем последними, поэтому пока только запоминаем
+	type flagValue struct {
+		opt   option
+		value string
+	}
+	var flagValues []flagValue
+	for _, o := range opts {
+		o := o
+		set := func(v string) error {
+			flagValues = append(flagValues, flagValue{opt: o, value: v})
+			return nil
+		}
+		if _, ok := o.ptr.(*bool); ok {
+			fs.BoolFunc(o.flagName(), o.usage, set)
+		} else {
+			fs.Func(o.flagName(), o.usage, set)
+		}
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return Config{}, nil, err
+	}
+
+	var errs []error
+
+	if *configFile != "" {
+		values, err := readFile(*configFile)
+		if err != nil {
+			return Config{}, nil, err
+		}
+		byKey := make(map[string]option, len(opts))
+		for _, o := range opts {
+			byKey[o.key] = o
+		}
+		for key, value := range values {
+			o, ok := byKey[key]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: unknown key %q", *configFile, key))
+				continue
+			}
+			if err := setValue(o.ptr, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %s: %w", *configFile, key, err))
+			}
+		}
+	}
+
+	for _, o := range opts {
+		for _, name := range o.envNames() {
+			value, ok := os.LookupEnv(name)
+			if !ok || value == "" {
+				continue
+			}
+			if err := setValue(o.ptr, value); err != nil {
+				errs = append(errs, fmt.Errorf("env %s: %w", name, err))
+			}
+			break
+		}
+	}
+
+	for _, f := range flagValues {
+		if err := setValue(f.opt.ptr, f.value); err != nil {
+			errs = append(errs, fmt.Errorf("flag --%s: %w", f.opt.flagName(), err))
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return Config{}, nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return cfg, fs.Args(), nil
+}
+
+func setValue(ptr any, value string) error {
+	switch p := ptr.(type) {
+	case *string:
+		*p = value
+	case *int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		*p = n
+	case *float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		*p = f
+	case *bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a boolean", value)
+		}
+		*p = b
+	case *time.Duration:
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("%q is not a duration", value)
+		}
+		*p = d
+	default:
+		return fmt.Errorf("unsupported option type %T", ptr)
+	}
+	return nil
+}
+
+// Validate проверяет конфиг целиком и возвращает все найденные ошибки
+func (c Config) Validate() error {
+	var errs []error
+	add := func(key, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	validatePort(c.AppPort, "app_port", add)
+	if c.GRPCEnabled {
+		validatePort(c.GRPCPort, "grpc_port", add)
+		if c.GRPCPort == c.AppPort {
+			add("grpc_port", "must differ from app_port %s", c.AppPort)
+		}
+	}
+	oneOf(c.LogLevel, "log_level", add, "debug", "info", "warn", "error")
+
+	timeouts := []struct {
+		key   string
+		value time.Duration
+	}{
+		{"http_read_header_timeout", c.HTTPReadHeaderTimeout},
+		{"http_read_timeout", c.HTTPReadTimeout},
+		{"http_write_timeout", c.HTTPWriteTimeout},
+		{"http_idle_timeout", c.HTTPIdleTimeout},
+		{"shutdown_timeout", c.ShutdownTimeout},
+		{"sse_heartbeat_interval", c.SSEHeartbeatInterval},
+		{"readiness_timeout", c.ReadinessTimeout},
+	}
+	for _, t := range timeouts {
+		if t.value <= 0 {
+			add(t.key, "must be positive, got %s", t.value)
+		}
+	}
+	if c.ShutdownDrainDelay < 0 {
+		add("shutdown_drain_delay", "must not be negative, got %s", c.ShutdownDrainDelay)
+	}
+	if _, err := ratelimit.ParseLimit(c.RateLimitDefault); err != nil {
+		add("rate_limit_default", "%v", err)
+	}
+	if _, err := ratelimit.ParseRoutes(c.RateLimitRoutes); err != nil {
+		add("rate_limit_routes", "%v", err)
+	}
+	if c.HTTPMaxInFlight < 0 {
+		add("http_max_in_flight", "must not be negative, got %d", c.HTTPMaxInFlight)
+	}
+	if c.HTTPInFlightWait < 0 {
+		add("http_in_flight_wait", "must not be negative, got %s", c.HTTPInFlightWait)
+	}
+	if c.IdempotencyTTL <= 0 {
+		add("idempotency_ttl", "must be positive, got %s", c.IdempotencyTTL)
+	}
+	// раньше конца записи ответа повтор выполнил бы еще живой запрос второй раз
+	if c.IdempotencyLockTimeout < c.HTTPWriteTimeout {
+		add("idempotency_lock_timeout", "must be at least http_write_timeout (%s), got %s", c.HTTPWriteTimeout, c.IdempotencyLockTimeout)
+	}
+	oneOf(c.OpenAPIValidation, "openapi_validation", add, "off", "requests", "full")
+	if c.PoolSaturationThreshold <= 0 || c.PoolSaturationThreshold > 1 {
+		add("pool_saturation_threshold", "must be in (0, 1], got %v", c.PoolSaturationThreshold)
+	}
+
+	oneOf(c.Storage, "storage", add, "postgres", "sqlite", "memory")
+	if c.Storage == "sqlite" && c.SQLitePath == "" {
+		add("sqlite_path", "must not be empty when storage is sqlite")
+	}
+
+	if c.DBDSN != "" {
+		u, err := url.Parse(c.DBDSN)
+		if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+			add("db_dsn", "must be a postgres:// URL")
+		}
+	} else {
+		if c.DBHost == "" {
+			add("db_host", "must not be empty")
+		}
+		validatePort(c.DBPort, "db_port", add)
+		if c.DBUser == "" {
+			add("db_user", "must not be empty")
+		}
+		if c.DBName == "" {
+			add("db_name", "must not be empty")
+		}
+		oneOf(c.DBSSLMode, "db_sslmode", add, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+	}
+
+	if c.DBMinConns < 0 {
+		add("db_min_conns", "must not be negative, got %d", c.DBMinConns)
+	}
+	if c.DBMaxConns < 1 {
+		add("db_max_conns", "must be at least 1, got %d", c.DBMaxConns)
+	}
+	if c.DBMinConns > c.DBMaxConns {
+		add("db_min_conns", "must not exceed db_max_conns (%d > %d)", c.DBMinConns, c.DBMaxConns)
+	}
+	if c.DBMaxConnLifetime <= 0 {
+		add("db_max_conn_lifetime", "must be positive, got %s", c.DBMaxConnLifetime)
+	}
+	if c.DBMaxConnIdleTime <= 0 {
+		add("db_max_conn_idle_time", "must be positive, got %s", c.DBMaxConnIdleTime)
+	}
+
+	if c.AuthJWTSecret != "" && len(c.AuthJWTSecret) < 32 {
+		add("auth_jwt_secret", "must be at least 32 bytes")
+	}
+
+	oneOf(c.Notifier, "notifier", add, "log", "webhook", "none")
+	if c.Notifier == "webhook" && c.NotifyWebhookURL == "" && c.TeamsFile == "" {
+		add("notify_webhook_url", "required for webhook notifier unless teams_file is set")
+	}
+	if c.NotifyQueueSize < 1 {
+		add("notify_queue_size", "must be at least 1, got %d", c.NotifyQueueSize)
+	}
+	if c.NotifyMaxAttempts < 1 {
+		add("notify_max_attempts", "must be at least 1, got %d", c.NotifyMaxAttempts)
 	}
 
+	if c.SchedulerEnabled && c.SchedulerInterval <= 0 {
+		add("scheduler_interval", "must be positive, got %s", c.SchedulerInterval)
+	}
+	if c.ReviewRemindAfter < 0 {
+		add("review_remind_after", "must not be negative, got %s", c.ReviewRemindAfter)
+	}
+	if c.ReviewEscalateAfter < 0 {
+		add("review_escalate_after", "must not be negative, got %s", c.ReviewEscalateAfter)
+	}
+
+	oneOf(c.TracingExporter, "tracing_exporter", add, "none", "stdout", "otlp")
+
+	return errors.Join(errs...)
+}
+
+func validatePort(port, key string, add func(key, format string, args ...any)) {
+	n, err := strconv.Atoi(port)
+	if err != nil {
+		add(key, "%q is not a number", port)
+		return
+	}
 	// Диапазон 1-65535
-	if portNum < 1 || portNum > 65535 {
-		log.Printf("Ошибка: значение порта '%d' должно быть в диапазоне от 1 до 65535. Используется порт по умолчанию: %s",
-			portNum, def)
-		return def
+	if n < 1 || n > 65535 {
+		add(key, "must be in range 1-65535, got %d", n)
+	}
+}
+
+func oneOf(value, key string, add func(key, format string, args ...any), allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
 	}
+	add(key, "%q is not one of %s", value, strings.Join(allowed, ", "))
+}
 
-	if portNum < 1024 {
-		log.Printf("Предупреждение: порт %d — системный. Рекомендуется использовать порт > 1024.", portNum)
+// MaxInFlight предел одновременных запросов к API
+func (c Config) MaxInFlight() int {
+	if c.HTTPMaxInFlight > 0 {
+		return c.HTTPMaxInFlight
 	}
+	return 2 * c.DBMaxConns
+}
 
-	return port
+// AdminTokens список статических токенов администраторов
+func (c Config) AdminTokens() []string {
+	var tokens []string
+	for _, t := range strings.Split(c.AuthAdminTokens, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
+func (c Config) DBConnStr() string {
+	if c.DBDSN != "" {
+		return c.DBDSN
+	}
+
+	query := url.Values{}
+	query.Set("sslmode", c.DBSSLMode)
+	if c.DBSSLRootCert != "" {
+		query.Set("sslrootcert", c.DBSSLRootCert)
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.DBUser, c.DBPass),
+		Host:     net.JoinHostPort(c.DBHost, c.DBPort),
+		Path:     "/" + c.DBName,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
 }
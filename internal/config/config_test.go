@@ -0,0 +1,281 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv скрывает переменные окружения машины, на которой идут тесты
+func clearEnv(t *testing.T) {
+	t.Helper()
+
+	t.Setenv("CONFIG_FILE", "")
+	for _, o := range new(Config).options() {
+		for _, name := range o.envNames() {
+			t.Setenv(name, "")
+		}
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	yamlFile := "app_port: \"8081\"\nlog_level: warn\nhttp:\n  read_timeout: 3s\ndb:\n  max_conns: 7\n"
+	tomlFile := "app_port = \"8081\"\nlog_level = \"warn\"\n[http]\nread_timeout = \"3s\"\n[db]\nmax_conns = 7\n"
+
+	tests := []struct {
+		name  string
+		file  string // имя файла с расширением, пусто - без файла
+		env   map[string]string
+		flags []string
+		check func(t *testing.T, c Config)
+	}{
+		{
+			name: "defaults",
+			check: func(t *testing.T, c Config) {
+				if !reflect.DeepEqual(c, Default()) {
+					t.Fatalf("expected defaults, got %+v", c)
+				}
+			},
+		},
+		{
+			name: "yaml file over defaults",
+			file: "config.yaml",
+			check: func(t *testing.T, c Config) {
+				if c.AppPort != "8081" || c.LogLevel != "warn" || c.HTTPReadTimeout != 3*time.Second || c.DBMaxConns != 7 {
+					t.Fatalf("file values not applied: %+v", c)
+				}
+				if c.HTTPWriteTimeout != Default().HTTPWriteTimeout {
+					t.Fatalf("keys missing in file must keep defaults, got %s", c.HTTPWriteTimeout)
+				}
+			},
+		},
+		{
+			name: "toml file over defaults",
+			file: "config.toml",
+			check: func(t *testing.T, c Config) {
+				if c.AppPort != "8081" || c.LogLevel != "warn" || c.HTTPReadTimeout != 3*time.Second || c.DBMaxConns != 7 {
+					t.Fatalf("file values not applied: %+v", c)
+				}
+			},
+		},
+		{
+			name: "env over file",
+			file: "config.yaml",
+			env:  map[string]string{"APP_PORT": "8082", "HTTP_READ_TIMEOUT": "4s"},
+			check: func(t *testing.T, c Config) {
+				if c.AppPort != "8082" || c.HTTPReadTimeout != 4*time.Second || c.LogLevel != "warn" {
+					t.Fatalf("env values not applied over file: %+v", c)
+				}
+			},
+		},
+		{
+			name:  "flags over env",
+			file:  "config.yaml",
+			env:   map[string]string{"APP_PORT": "8082", "LOG_LEVEL": "error"},
+			flags: []string{"--app-port", "8083", "--db-max-conns=9"},
+			check: func(t *testing.T, c Config) {
+				if c.AppPort != "8083" || c.DBMaxConns != 9 || c.LogLevel != "error" {
+					t.Fatalf("flag values not applied over env: %+v", c)
+				}
+			},
+		},
+		{
+			name: "primary env name over legacy",
+			env:  map[string]string{"DB_PASSWORD": "new", "DB_PASS": "old", "NOTIFY_TEAMS_FILE": "teams.json"},
+			check: func(t *testing.T, c Config) {
+				if c.DBPass != "new" || c.TeamsFile != "teams.json" {
+					t.Fatalf("unexpected env aliases: pass=%q teams=%q", c.DBPass, c.TeamsFile)
+				}
+			},
+		},
+		{
+			name: "empty env keeps lower layer",
+			file: "config.yaml",
+			env:  map[string]string{"APP_PORT": ""},
+			check: func(t *testing.T, c Config) {
+				if c.AppPort != "8081" {
+					t.Fatalf("empty env must not override file, got %q", c.AppPort)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			args := tt.flags
+			switch filepath.Ext(tt.file) {
+			case ".yaml":
+				args = append([]string{"--config", writeFile(t, tt.file, yamlFile)}, args...)
+			case ".toml":
+				// файл можно задать и через окружение
+				t.Setenv("CONFIG_FILE", writeFile(t, tt.file, tomlFile))
+			}
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg, rest, err := Load(append(args, "migrate", "up"))
+			if err != nil {
+				t.Fatalf("Load: %v", err)
+			}
+			if !reflect.DeepEqual(rest, []string{"migrate", "up"}) {
+				t.Fatalf("unexpected positional args %v", rest)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestLoadReportsAllErrors(t *testing.T) {
+	clearEnv(t)
+	file := writeFile(t, "config.yaml", "app_port: \"8081\"\nunknown_key: 1\ndb:\n  max_conns: many\n")
+	t.Setenv("HTTP_READ_TIMEOUT", "soon")
+	t.Setenv("LOG_LEVEL", "loud")
+
+	_, _, err := Load([]string{"--config", file, "--grpc-enabled=maybe", "--storage", "mongo"})
+	if err == nil {
+		t.Fatal("expected configuration error")
+	}
+	// одна ошибка перечисляет все проблемы всех источников
+	for _, want := range []string{
+		`unknown key "unknown_key"`,
+		`db_max_conns: "many" is not a number`,
+		`env HTTP_READ_TIMEOUT: "soon" is not a duration`,
+		`flag --grpc-enabled: "maybe" is not a boolean`,
+		"log_level:",
+		"storage:",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error does not mention %q:\n%v", want, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		keys   []string // ключи, которые должны попасть в ошибку
+	}{
+		{"defaults", func(*Config) {}, nil},
+		{"ports", func(c *Config) { c.AppPort = "0"; c.GRPCPort = "http" }, []string{"app_port", "grpc_port"}},
+		{"same ports", func(c *Config) { c.GRPCPort = c.AppPort }, []string{"grpc_port"}},
+		{"grpc port ignored when disabled", func(c *Config) { c.GRPCEnabled = false; c.GRPCPort = "" }, nil},
+		{"timeouts", func(c *Config) { c.HTTPReadTimeout = 0; c.ShutdownDrainDelay = -time.Second },
+			[]string{"http_read_timeout", "shutdown_drain_delay"}},
+		{"rate limits", func(c *Config) { c.RateLimitDefault = "fast"; c.HTTPMaxInFlight = -1 },
+			[]string{"rate_limit_default", "http_max_in_flight"}},
+		{"idempotency lock shorter than write timeout", func(c *Config) { c.IdempotencyLockTimeout = time.Second }, []string{"idempotency_lock_timeout"}},
+		{"sqlite without path", func(c *Config) { c.Storage = "sqlite"; c.SQLitePath = "" }, []string{"sqlite_path"}},
+		{"dsn scheme", func(c *Config) { c.DBDSN = "mysql://db" }, []string{"db_dsn"}},
+		{"dsn replaces parts", func(c *Config) { c.DBDSN = "postgres://db/app"; c.DBHost = ""; c.DBUser = "" }, nil},
+		{"db parts", func(c *Config) { c.DBHost = ""; c.DBSSLMode = "on" }, []string{"db_host", "db_sslmode"}},
+		{"pool", func(c *Config) { c.DBMinConns = 5; c.DBMaxConns = 2 }, []string{"db_min_conns"}},
+		{"short jwt secret", func(c *Config) { c.AuthJWTSecret = "short" }, []string{"auth_jwt_secret"}},
+		{"webhook without url", func(c *Config) { c.Notifier = "webhook" }, []string{"notify_webhook_url"}},
+		{"tracing exporter", func(c *Config) { c.TracingExporter = "zipkin" }, []string{"tracing_exporter"}},
+		{"all at once", func(c *Config) {
+			c.LogLevel = "loud"
+			c.IdempotencyTTL = 0
+			c.PoolSaturationThreshold = 2
+			c.NotifyQueueSize = 0
+			c.ReviewRemindAfter = -time.Hour
+		}, []string{"log_level", "idempotency_ttl", "pool_saturation_threshold", "notify_queue_size", "review_remind_after"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			tt.modify(&cfg)
+
+			err := cfg.Validate()
+			if len(tt.keys) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("expected errors for %v", tt.keys)
+			}
+			for _, key := range tt.keys {
+				if !strings.Contains(err.Error(), key+":") {
+					t.Errorf("error does not mention %s:\n%v", key, err)
+				}
+			}
+			if got := strings.Count(err.Error(), "\n") + 1; got != len(tt.keys) {
+				t.Errorf("expected %d errors, got %d:\n%v", len(tt.keys), got, err)
+			}
+		})
+	}
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.DBDSN = "postgres://app:hunter2@db:5432/reviewer"
+	cfg.DBPass = "hunter2"
+	cfg.AuthAdminTokens = "admin-token-1,admin-token-2"
+	cfg.AuthJWTSecret = strings.Repeat("s", 32)
+	cfg.NotifyWebhookURL = "https://hooks.example.com/T000/secret-path"
+
+	var out bytes.Buffer
+	if err := cfg.Print(&out); err != nil {
+		t.Fatal(err)
+	}
+	text := out.String()
+
+	for _, secret := range []string{"hunter2", "admin-token-1", cfg.AuthJWTSecret, "secret-path"} {
+		if strings.Contains(text, secret) {
+			t.Errorf("config print leaks %q:\n%s", secret, text)
+		}
+	}
+	for _, want := range []string{
+		"db_dsn: postgres://app:xxxxx@db:5432/reviewer",
+		"db_password: '******'",
+		"auth_jwt_secret: '******'",
+		"db_host: localhost",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("config print does not contain %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestPrintRoundTrip(t *testing.T) {
+	clearEnv(t)
+
+	// вывод config print читается обратно как файл конфигурации, кроме
+	// скрытых секретов, поэтому пароль по умолчанию убираем
+	cfg := Default()
+	cfg.DBPass = ""
+	cfg.AppPort = "8181"
+	cfg.HTTPReadTimeout = 7 * time.Second
+	cfg.PoolSaturationThreshold = 0.75
+	cfg.SchedulerEnabled = true
+	cfg.DBMaxConns = 12
+
+	var out bytes.Buffer
+	if err := cfg.Print(&out); err != nil {
+		t.Fatal(err)
+	}
+	loaded, _, err := Load([]string{"--config", writeFile(t, "printed.yaml", out.String())})
+	if err != nil {
+		t.Fatalf("printed config does not load: %v\n%s", err, out.String())
+	}
+	if !reflect.DeepEqual(loaded, cfg) {
+		t.Fatalf("round trip changed config:\nwant %+v\ngot  %+v", cfg, loaded)
+	}
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"io"
+	"net/url"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "******"
+
+// Print выводит итоговый конфиг в YAML, секреты заменяются на звездочки.
+// Вывод можно использовать как файл конфигурации.
+func (c Config) Print(w io.Writer) error {
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+	for _, o := range c.options() {
+		var value any
+		switch p := o.ptr.(type) {
+		case *string:
+			value = *p
+			if o.secret && *p != "" {
+				value = redactSecret(o.key, *p)
+			}
+		case *int:
+			value = *p
+		case *float64:
+			value = *p
+		case *bool:
+			value = *p
+		default:
+			// time.Duration и прочее печатаем строкой, чтобы файл читался обратно
+			value = valueString(o.ptr)
+		}
+
+		keyNode := &yaml.Node{Kind: yaml.ScalarNode, Value: o.key}
+		valueNode := &yaml.Node{}
+		if err := valueNode.Encode(value); err != nil {
+			return err
+		}
+		doc.Content = append(doc.Content, keyNode, valueNode)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// redactSecret в DSN прячет только пароль, остальные секреты целиком
+func redactSecret(key, value string) string {
+	if key == "db_dsn" {
+		if u, err := url.Parse(value); err == nil {
+			return u.Redacted()
+		}
+	}
+	return redacted
+}
+
+func valueString(ptr any) string {
+	if s, ok := ptr.(interface{ String() string }); ok {
+		return s.String()
+	}
+	return ""
+}
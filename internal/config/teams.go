@@ -0,0 +1,63 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// TeamConfig настройки отдельной команды
+type TeamConfig struct {
+	WebhookURL string            `json:"webhook_url"`
+	Channel    string            `json:"channel"`
+	Templates  map[string]string `json:"templates"`
+
+	// SLA на ревью, пустое значение - берется общее
+	RemindAfter   Duration `json:"remind_after"`
+	EscalateAfter Duration `json:"escalate_after"`
+}
+
+// TeamsConfig содержимое файла TEAMS_FILE
+type TeamsConfig struct {
+	Templates map[string]string     `json:"templates"` // общие шаблоны по событиям (ASSIGNED, REASSIGNED, REMINDER)
+	Teams     map[string]TeamConfig `json:"teams"`
+}
+
+// Duration длительность в JSON в виде строки, например "36h"
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if s == "" {
+		*d = 0
+		return nil
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// LoadTeamsConfig читает настройки команд, пустой путь - пустые настройки
+func LoadTeamsConfig(path string) (TeamsConfig, error) {
+	if path == "" {
+		return TeamsConfig{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return TeamsConfig{}, err
+	}
+
+	var cfg TeamsConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return TeamsConfig{}, err
+	}
+	return cfg, nil
+}
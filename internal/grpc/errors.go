@@ -0,0 +1,90 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/logging"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain домен в google.rpc.ErrorInfo
+const errorDomain = "avito-pr-reviewer"
+
+// domainErrors коды статусов для доменных ошибок, reason совпадает с кодом
+// ошибки HTTP API, чтобы клиенты обоих API разбирали ошибки одинаково
+var domainErrors = []struct {
+	err     error
+	code    codes.Code
+	reason  string
+	message string
+}{
+	{service.ErrForbidden, codes.PermissionDenied, "FORBIDDEN", ""},
+	{repository.ErrUserNotFound, codes.NotFound, "NOT_FOUND", ""},
+	{repository.ErrTeamNotFound, codes.NotFound, "NOT_FOUND", ""},
+	{repository.ErrPRNotFound, codes.NotFound, "NOT_FOUND", ""},
+	{repository.ErrTeamExists, codes.AlreadyExists, "TEAM_EXISTS", "team_name already exists"},
+	{repository.ErrPRExists, codes.AlreadyExists, "PR_EXISTS", "PR id already exists"},
+	{service.ErrPRMerged, codes.FailedPrecondition, "PR_MERGED", "cannot reassign on merged PR"},
+	{repository.ErrReviewerNotAssigned, codes.FailedPrecondition, "NOT_ASSIGNED", "reviewer is not assigned to this PR"},
+	{service.ErrNoCandidate, codes.FailedPrecondition, "NO_CANDIDATE", "no active replacement candidate in team"},
+	{service.ErrPRConflict, codes.Aborted, "CONFLICT", ""},
+	{service.ErrPreconditionFailed, codes.Aborted, "PRECONDITION_FAILED",
+		"pull request was modified, fetch it again and retry with the new version"},
+}
+
+// toStatus переводит ошибку сервиса в статус gRPC. Неизвестные ошибки
+// логируются, клиенту отдается INTERNAL без подробностей.
+func toStatus(ctx context.Context, err error) error {
+	var verr *service.ValidationError
+	if errors.As(err, &verr) {
+		br := &errdetails.BadRequest{}
+		for _, f := range verr.Fields {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       f.Field,
+				Description: f.Message,
+				Reason:      f.Rule,
+			})
+		}
+		return withDetails(codes.InvalidArgument, "request validation failed", "BAD_REQUEST", br)
+	}
+
+	for _, d := range domainErrors {
+		if errors.Is(err, d.err) {
+			msg := d.message
+			if msg == "" {
+				msg = err.Error()
+			}
+			return withDetails(d.code, msg, d.reason)
+		}
+	}
+
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "request canceled")
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	}
+
+	logging.FromContext(ctx).Error("internal error",
+		slog.String("method", methodName(ctx)),
+		slog.String("error", err.Error()),
+	)
+	return withDetails(codes.Internal, "internal server error", "INTERNAL")
+}
+
+// withDetails статус с ErrorInfo и дополнительными деталями
+func withDetails(code codes.Code, msg, reason string, details ...protoadapt.MessageV1) error {
+	details = append([]protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: reason, Domain: errorDomain}}, details...)
+	st, err := status.New(code, msg).WithDetails(details...)
+	if err != nil {
+		return status.Error(code, msg)
+	}
+	return st.Err()
+}
@@ -0,0 +1,179 @@
+package grpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/auth"
+	"github.com/Olzerq/avito-pr-reviewer/internal/logging"
+	"github.com/Olzerq/avito-pr-reviewer/internal/metrics"
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestTimeout предел на unary вызов, как у обработчиков HTTP
+const requestTimeout = 5 * time.Second
+
+// requestIDKey метаданные с id запроса, аналог X-Request-ID
+const requestIDKey = "x-request-id"
+
+// publicServices служебные сервисы, доступные без токена
+var publicServices = []string{"/grpc.health.v1.Health/", "/grpc.reflection."}
+
+func unaryInterceptor(a *auth.Authenticator, l Limits) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var resp any
+		err := observe(ctx, info.FullMethod, func(ctx context.Context) error {
+			release, err := l.limit(ctx, info.FullMethod)
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			ctx, err = authenticate(ctx, a, info.FullMethod)
+			if err != nil {
+				return err
+			}
+			ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+			defer cancel()
+
+			resp, err = handler(ctx, req)
+			return err
+		})
+		return resp, err
+	}
+}
+
+// streamInterceptor списывает с бюджета открытие потока. Слот InFlight поток
+// отдает сам после подписки, см. releaseInFlight.
+func streamInterceptor(a *auth.Authenticator, l Limits) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return observe(ss.Context(), info.FullMethod, func(ctx context.Context) error {
+			release, err := l.limit(ctx, info.FullMethod)
+			if err != nil {
+				return err
+			}
+			defer release()
+
+			ctx, err = authenticate(ctx, a, info.FullMethod)
+			if err != nil {
+				return err
+			}
+			ctx = context.WithValue(ctx, inFlightKey{}, release)
+			return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+		})
+	}
+}
+
+// contextStream поток с контекстом, дополненным интерсепторами
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context { return s.ctx }
+
+// observe присваивает вызову id, пишет запись в лог и метрики
+func observe(ctx context.Context, method string, call func(context.Context) error) error {
+	start := time.Now()
+
+	id := firstMetadata(ctx, requestIDKey)
+	if id == "" || len(id) > 128 {
+		id = newRequestID()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, id))
+	ctx = logging.WithRequestID(ctx, id)
+	ctx = logging.WithAttrsHolder(ctx)
+
+	err := call(ctx)
+	code := status.Code(err)
+
+	metrics.GRPCRequests.WithLabelValues(method, code.String()).Inc()
+	metrics.GRPCDuration.WithLabelValues(method, code.String()).Observe(time.Since(start).Seconds())
+
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000),
+	}
+	attrs = append(attrs, logging.Attrs(ctx)...)
+
+	level := slog.LevelInfo
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss:
+		level = slog.LevelError
+	}
+	logging.FromContext(ctx).LogAttrs(ctx, level, "grpc request", attrs...)
+	return err
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// authenticate проверяет токен из метаданных authorization и кладет личность в
+// контекст, как Authenticate в HTTP API. Если a == nil, аутентификация выключена
+// и вызовы выполняются с правами администратора.
+func authenticate(ctx context.Context, a *auth.Authenticator, method string) (context.Context, error) {
+	if isPublic(method) {
+		return ctx, nil
+	}
+
+	if a == nil {
+		return auth.WithIdentity(ctx, auth.Identity{Subject: service.ActorAPI, Role: model.RoleAdmin}), nil
+	}
+
+	id, err := a.Authenticate(ctx, bearerToken(ctx))
+	if err != nil && !errors.Is(err, auth.ErrNoToken) && !errors.Is(err, auth.ErrInvalidToken) {
+		// не удалось получить роль из БД
+		return nil, toStatus(ctx, err)
+	}
+	if err != nil {
+		logging.FromContext(ctx).Info("authentication failed", slog.String("error", err.Error()))
+		return nil, withDetails(codes.Unauthenticated, "valid bearer token required", "UNAUTHORIZED")
+	}
+
+	logging.AddAttrs(ctx, slog.String("subject", id.Subject), slog.String("role", id.Role))
+
+	// в истории PR будет видно, кто выполнил действие
+	ctx = auth.WithIdentity(ctx, id)
+	return service.WithActor(ctx, id.Subject), nil
+}
+
+func bearerToken(ctx context.Context) string {
+	scheme, token, ok := strings.Cut(firstMetadata(ctx, "authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// methodName полное имя вызываемого метода для логов
+func methodName(ctx context.Context) string {
+	if m, ok := grpc.Method(ctx); ok {
+		return m
+	}
+	return "unknown"
+}
@@ -0,0 +1,5 @@
+// Package pb сообщения и сервисы gRPC API, сгенерированные из proto/reviewer/v1.
+// Сначала меняется .proto, затем go generate ./... и только потом реализация в internal/grpc.
+package pb
+
+//go:generate go run github.com/bufbuild/buf/cmd/buf@v1.50.0 generate --template buf.gen.yaml ../../../proto
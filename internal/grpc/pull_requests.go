@@ -0,0 +1,110 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/grpc/pb"
+	"github.com/Olzerq/avito-pr-reviewer/internal/logging"
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type pullRequestServer struct {
+	pb.UnimplementedPullRequestServiceServer
+	prs *service.PullRequestService
+}
+
+func (s *pullRequestServer) CreatePullRequest(ctx context.Context, req *pb.CreatePullRequestRequest) (*pb.PullRequest, error) {
+	logging.AddAttrs(ctx, slog.String("pull_request_id", req.GetPullRequestId()))
+
+	pr, err := s.prs.Create(ctx, req.GetPullRequestId(), req.GetPullRequestName(), req.GetAuthorId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toPBPullRequest(pr), nil
+}
+
+func (s *pullRequestServer) GetPullRequest(ctx context.Context, req *pb.GetPullRequestRequest) (*pb.PullRequest, error) {
+	pr, err := s.prs.Get(ctx, req.GetPullRequestId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toPBPullRequest(pr), nil
+}
+
+func (s *pullRequestServer) ReassignReviewer(ctx context.Context, req *pb.ReassignReviewerRequest) (*pb.ReassignReviewerResponse, error) {
+	logging.AddAttrs(ctx, slog.String("pull_request_id", req.GetPullRequestId()))
+
+	if req.ExpectedVersion != nil {
+		ctx = service.WithExpectedVersion(ctx, req.GetExpectedVersion())
+	}
+	pr, replacedBy, err := s.prs.Reassign(ctx, req.GetPullRequestId(), req.GetOldUserId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &pb.ReassignReviewerResponse{Pr: toPBPullRequest(pr), ReplacedBy: replacedBy}, nil
+}
+
+func (s *pullRequestServer) MergePullRequest(ctx context.Context, req *pb.MergePullRequestRequest) (*pb.PullRequest, error) {
+	logging.AddAttrs(ctx, slog.String("pull_request_id", req.GetPullRequestId()))
+
+	if req.ExpectedVersion != nil {
+		ctx = service.WithExpectedVersion(ctx, req.GetExpectedVersion())
+	}
+	pr, err := s.prs.Merge(ctx, req.GetPullRequestId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toPBPullRequest(pr), nil
+}
+
+var pullRequestStatuses = map[string]pb.PullRequestStatus{
+	"OPEN":   pb.PullRequestStatus_PULL_REQUEST_STATUS_OPEN,
+	"MERGED": pb.PullRequestStatus_PULL_REQUEST_STATUS_MERGED,
+}
+
+// fromPBStatus статус для фильтра, UNSPECIFIED не фильтрует. Неизвестное значение
+// передается как есть, его отклонит валидация сервиса.
+func fromPBStatus(st pb.PullRequestStatus) string {
+	if st == pb.PullRequestStatus_PULL_REQUEST_STATUS_UNSPECIFIED {
+		return ""
+	}
+	for s, v := range pullRequestStatuses {
+		if v == st {
+			return s
+		}
+	}
+	return st.String()
+}
+
+func toPBPullRequest(pr model.PullRequest) *pb.PullRequest {
+	res := &pb.PullRequest{
+		PullRequestId:     pr.ID,
+		PullRequestName:   pr.Name,
+		AuthorId:          pr.AuthorID,
+		Status:            pullRequestStatuses[pr.Status],
+		AssignedReviewers: make([]string, 0, len(pr.Reviewers)),
+		Version:           pr.Version,
+	}
+	for _, u := range pr.Reviewers {
+		res.AssignedReviewers = append(res.AssignedReviewers, u.ID)
+	}
+	if pr.CreatedAt != nil {
+		res.CreatedAt = timestamppb.New(*pr.CreatedAt)
+	}
+	if pr.MergedAt != nil {
+		res.MergedAt = timestamppb.New(*pr.MergedAt)
+	}
+	return res
+}
+
+func toPBPullRequestShort(pr model.PullRequestShort) *pb.PullRequestShort {
+	return &pb.PullRequestShort{
+		PullRequestId:   pr.ID,
+		PullRequestName: pr.Name,
+		AuthorId:        pr.AuthorID,
+		Status:          pullRequestStatuses[pr.Status],
+	}
+}
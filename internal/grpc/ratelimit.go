@@ -0,0 +1,157 @@
+package grpc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/grpc/pb"
+	"github.com/Olzerq/avito-pr-reviewer/internal/logging"
+	"github.com/Olzerq/avito-pr-reviewer/internal/metrics"
+	"github.com/Olzerq/avito-pr-reviewer/internal/ratelimit"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Limits ограничения нагрузки gRPC API. Лимитеры те же, что у HTTP API, поэтому
+// клиент не получает второй бюджет, переходя на gRPC. Нулевое значение - без ограничений.
+type Limits struct {
+	// Rate частота вызовов на клиента (токен или IP) и маршрут
+	Rate *ratelimit.Limiter
+	// TrustProxy берет IP клиента из метаданных x-forwarded-for
+	TrustProxy bool
+	// InFlight слоты одновременно обрабатываемых вызовов
+	InFlight *ratelimit.ConcurrencyLimiter
+}
+
+// grpcMethod метка method в метриках ограничения нагрузки для вызовов gRPC
+const grpcMethod = "GRPC"
+
+// methodRoutes маршруты HTTP API v1 для методов gRPC. Вызов списывается с того же
+// bucket'а, что и запрос HTTP, и подчиняется тем же RATE_LIMIT_ROUTES.
+// Методы без пары в v1 идут как "GRPC /<полное имя метода>", так их можно
+// указать в RATE_LIMIT_ROUTES.
+var methodRoutes = map[string]string{
+	pb.TeamService_CreateTeam_FullMethodName:               "POST /team/add",
+	pb.TeamService_GetTeam_FullMethodName:                  "GET /team/get",
+	pb.TeamService_ListTeams_FullMethodName:                "GET /teams",
+	pb.UserService_SetUserIsActive_FullMethodName:          "POST /users/setIsActive",
+	pb.UserService_SetUserRole_FullMethodName:              "POST /users/setRole",
+	pb.UserService_ListUsers_FullMethodName:                "GET /users",
+	pb.UserService_GetUserReviews_FullMethodName:           "GET /users/getReview",
+	pb.UserService_WatchReviewQueue_FullMethodName:         "GET /users/reviews/stream",
+	pb.PullRequestService_CreatePullRequest_FullMethodName: "POST /pullRequest/create",
+	pb.PullRequestService_ReassignReviewer_FullMethodName:  "POST /pullRequest/reassign",
+	pb.PullRequestService_MergePullRequest_FullMethodName:  "POST /pullRequest/merge",
+	pb.StatsService_GetAssignmentStats_FullMethodName:      "GET /stats/assignments",
+}
+
+func methodRoute(method string) string {
+	if route, ok := methodRoutes[method]; ok {
+		return route
+	}
+	return grpcMethod + " " + method
+}
+
+// limit списывает вызов с бюджета клиента и занимает слот InFlight. Возвращает
+// функцию освобождения слота, ее можно вызвать несколько раз.
+func (l Limits) limit(ctx context.Context, method string) (release func(), err error) {
+	release = func() {}
+	if isPublic(method) {
+		return release, nil
+	}
+
+	if l.Rate != nil {
+		route := methodRoute(method)
+		res := l.Rate.Allow(clientKey(ctx, l.TrustProxy), route, time.Now())
+		if !res.Allowed {
+			metrics.RateLimited.WithLabelValues(grpcMethod, method).Inc()
+			logging.FromContext(ctx).Info("rate limit exceeded", slog.String("route", route))
+
+			_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(ceilSeconds(res.RetryAfter))))
+			return nil, withDetails(codes.ResourceExhausted, "too many requests, retry later", "RATE_LIMITED",
+				&errdetails.RetryInfo{RetryDelay: durationpb.New(res.RetryAfter)})
+		}
+	}
+
+	if l.InFlight != nil {
+		if !l.InFlight.Acquire(ctx) {
+			metrics.LoadShed.WithLabelValues(grpcMethod, method).Inc()
+			logging.FromContext(ctx).Warn("request shed, too many requests in flight",
+				slog.Int("in_flight", l.InFlight.InFlight()))
+
+			return nil, withDetails(codes.Unavailable, "server is overloaded, retry later", "OVERLOADED",
+				&errdetails.RetryInfo{RetryDelay: durationpb.New(time.Second)})
+		}
+		metrics.HTTPInFlight.Inc()
+		var once sync.Once
+		release = func() {
+			once.Do(func() {
+				metrics.HTTPInFlight.Dec()
+				l.InFlight.Release()
+			})
+		}
+	}
+	return release, nil
+}
+
+type inFlightKey struct{}
+
+// releaseInFlight отдает слот InFlight до конца вызова. Потоки очередей ревью
+// ждут изменений без соединения с БД и не должны занимать слоты обычных вызовов.
+func releaseInFlight(ctx context.Context) {
+	if release, ok := ctx.Value(inFlightKey{}).(func()); ok {
+		release()
+	}
+}
+
+func isPublic(method string) bool {
+	for _, prefix := range publicServices {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientKey ключ клиента как в HTTP API: хеш bearer токена, без токена - IP
+func clientKey(ctx context.Context, trustProxy bool) string {
+	if token := bearerToken(ctx); token != "" {
+		sum := sha256.Sum256([]byte(token))
+		return "token:" + hex.EncodeToString(sum[:8])
+	}
+	return "ip:" + clientIP(ctx, trustProxy)
+}
+
+func clientIP(ctx context.Context, trustProxy bool) string {
+	if trustProxy {
+		if xff := firstMetadata(ctx, "x-forwarded-for"); xff != "" {
+			first, _, _ := strings.Cut(xff, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
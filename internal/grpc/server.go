@@ -0,0 +1,96 @@
+// Package grpc gRPC API поверх тех же сервисов, что и HTTP API. Сообщения и
+// интерфейсы серверов генерируются из proto/reviewer/v1 в пакет pb.
+package grpc
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/auth"
+	"github.com/Olzerq/avito-pr-reviewer/internal/grpc/pb"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server gRPC сервер со всеми сервисами API, health и reflection
+type Server struct {
+	srv    *grpc.Server
+	health *health.Server
+
+	// закрывается при остановке, чтобы потоки очередей ревью завершились
+	// и GracefulStop не ждал их бесконечно
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewServer собирает сервер. Если a == nil, аутентификация выключена и все
+// вызовы выполняются с правами администратора. limits ограничивают частоту и
+// число одновременных вызовов вместе с HTTP API.
+func NewServer(
+	a *auth.Authenticator,
+	limits Limits,
+	teams *service.TeamService,
+	users *service.UserService,
+	prs *service.PullRequestService,
+	stats *service.StatsService,
+) *Server {
+	s := &Server{
+		srv: grpc.NewServer(
+			grpc.ChainUnaryInterceptor(unaryInterceptor(a, limits)),
+			grpc.ChainStreamInterceptor(streamInterceptor(a, limits)),
+			// мертвые клиенты долгих потоков обнаруживаются пингами
+			grpc.KeepaliveParams(keepalive.ServerParameters{Time: time.Minute, Timeout: 20 * time.Second}),
+			grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{MinTime: 20 * time.Second, PermitWithoutStream: true}),
+		),
+		health: health.NewServer(),
+		done:   make(chan struct{}),
+	}
+
+	pb.RegisterTeamServiceServer(s.srv, &teamServer{teams: teams})
+	pb.RegisterUserServiceServer(s.srv, &userServer{users: users, prs: prs, done: s.done})
+	pb.RegisterPullRequestServiceServer(s.srv, &pullRequestServer{prs: prs})
+	pb.RegisterStatsServiceServer(s.srv, &statsServer{stats: stats})
+	healthpb.RegisterHealthServer(s.srv, s.health)
+	reflection.Register(s.srv)
+
+	return s
+}
+
+// Serve принимает соединения до остановки
+func (s *Server) Serve(lis net.Listener) error {
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	return s.srv.Serve(lis)
+}
+
+// Drain переводит health в NOT_SERVING, чтобы балансировщик перестал слать
+// вызовы, но продолжает их обслуживать до Shutdown
+func (s *Server) Drain() {
+	s.health.Shutdown()
+}
+
+// Shutdown переводит health в NOT_SERVING, завершает потоки и дожидается
+// текущих вызовов. Если ctx истекает раньше, соединения закрываются сразу.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.health.Shutdown()
+	s.stopOnce.Do(func() { close(s.done) })
+
+	stopped := make(chan struct{})
+	go func() {
+		s.srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.srv.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,35 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/grpc/pb"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+)
+
+type statsServer struct {
+	pb.UnimplementedStatsServiceServer
+	stats *service.StatsService
+}
+
+func (s *statsServer) GetAssignmentStats(ctx context.Context, _ *pb.GetAssignmentStatsRequest) (*pb.AssignmentStats, error) {
+	stats, err := s.stats.GetAssignmentsStats(ctx)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	res := &pb.AssignmentStats{
+		ByUser:        make([]*pb.UserAssignmentCount, 0, len(stats.ByUser)),
+		ByPullRequest: make([]*pb.PullRequestReviewersCount, 0, len(stats.ByPR)),
+	}
+	for _, u := range stats.ByUser {
+		res.ByUser = append(res.ByUser, &pb.UserAssignmentCount{UserId: u.UserID, AssignedCount: int32(u.AssignedCount)})
+	}
+	for _, pr := range stats.ByPR {
+		res.ByPullRequest = append(res.ByPullRequest, &pb.PullRequestReviewersCount{
+			PullRequestId:  pr.PullRequestID,
+			ReviewersCount: int32(pr.ReviewersCount),
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,70 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/grpc/pb"
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+)
+
+type teamServer struct {
+	pb.UnimplementedTeamServiceServer
+	teams *service.TeamService
+}
+
+func (s *teamServer) CreateTeam(ctx context.Context, req *pb.CreateTeamRequest) (*pb.Team, error) {
+	in := req.GetTeam()
+	team := model.Team{
+		Name:  in.GetTeamName(),
+		Users: make([]model.User, 0, len(in.GetMembers())),
+	}
+	for _, m := range in.GetMembers() {
+		team.Users = append(team.Users, model.User{
+			ID:       m.GetUserId(),
+			Username: m.GetUsername(),
+			TeamName: team.Name,
+			IsActive: m.GetIsActive(),
+		})
+	}
+
+	if err := s.teams.CreateTeam(ctx, team); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toPBTeam(team), nil
+}
+
+func (s *teamServer) GetTeam(ctx context.Context, req *pb.GetTeamRequest) (*pb.Team, error) {
+	team, err := s.teams.GetTeam(ctx, req.GetTeamName())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toPBTeam(team), nil
+}
+
+func (s *teamServer) ListTeams(ctx context.Context, req *pb.ListTeamsRequest) (*pb.ListTeamsResponse, error) {
+	res, err := s.teams.List(ctx, repository.TeamFilter{Name: req.GetName()}, listQuery(req.GetPage()))
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	items := make([]*pb.Team, 0, len(res.Items))
+	for _, team := range res.Items {
+		items = append(items, toPBTeam(team))
+	}
+	return &pb.ListTeamsResponse{Items: items, NextCursor: res.NextCursor}, nil
+}
+
+func toPBTeam(team model.Team) *pb.Team {
+	members := make([]*pb.TeamMember, 0, len(team.Users))
+	for _, u := range team.Users {
+		members = append(members, &pb.TeamMember{UserId: u.ID, Username: u.Username, IsActive: u.IsActive})
+	}
+	return &pb.Team{TeamName: team.Name, Members: members}
+}
+
+// listQuery параметры страницы, nil - первая страница по умолчанию
+func listQuery(p *pb.PageRequest) service.ListQuery {
+	return service.ListQuery{Limit: int(p.GetLimit()), Sort: p.GetSort(), Cursor: p.GetCursor()}
+}
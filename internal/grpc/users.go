@@ -0,0 +1,144 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/grpc/pb"
+	"github.com/Olzerq/avito-pr-reviewer/internal/metrics"
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type userServer struct {
+	pb.UnimplementedUserServiceServer
+	users *service.UserService
+	prs   *service.PullRequestService
+	done  <-chan struct{} // остановка сервера
+}
+
+func (s *userServer) SetUserIsActive(ctx context.Context, req *pb.SetUserIsActiveRequest) (*pb.User, error) {
+	if err := s.users.SetIsActive(ctx, req.GetUserId(), req.GetIsActive()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	user, err := s.users.GetByID(ctx, req.GetUserId())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return toPBUser(user), nil
+}
+
+func (s *userServer) SetUserRole(ctx context.Context, req *pb.SetUserRoleRequest) (*pb.SetUserRoleResponse, error) {
+	if err := s.users.SetRole(ctx, req.GetUserId(), req.GetRole()); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+	return &pb.SetUserRoleResponse{UserId: req.GetUserId(), Role: req.GetRole()}, nil
+}
+
+func (s *userServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	f := repository.UserFilter{
+		TeamName: req.GetTeamName(),
+		Name:     req.GetName(),
+		IsActive: req.IsActive,
+	}
+	res, err := s.users.List(ctx, f, listQuery(req.GetPage()))
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	items := make([]*pb.User, 0, len(res.Items))
+	for _, u := range res.Items {
+		items = append(items, toPBUser(u))
+	}
+	return &pb.ListUsersResponse{Items: items, NextCursor: res.NextCursor}, nil
+}
+
+func (s *userServer) GetUserReviews(ctx context.Context, req *pb.GetUserReviewsRequest) (*pb.GetUserReviewsResponse, error) {
+	res, err := s.prs.GetUserReviews(ctx, req.GetUserId(), fromPBStatus(req.GetStatus()), listQuery(req.GetPage()))
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	items := make([]*pb.PullRequestShort, 0, len(res.Items))
+	for _, pr := range res.Items {
+		items = append(items, toPBPullRequestShort(pr))
+	}
+	return &pb.GetUserReviewsResponse{UserId: req.GetUserId(), PullRequests: items, NextCursor: res.NextCursor}, nil
+}
+
+// WatchReviewQueue снимок открытых ревью или продолжение после last_event_id, затем
+// изменения до отключения клиента или остановки сервера
+func (s *userServer) WatchReviewQueue(req *pb.WatchReviewQueueRequest, stream grpc.ServerStreamingServer[pb.ReviewQueueEvent]) error {
+	ctx := stream.Context()
+
+	watch, err := s.prs.WatchReviewQueue(ctx, req.GetUserId(), req.GetLastEventId())
+	if err != nil {
+		return toStatus(ctx, err)
+	}
+	defer watch.Close()
+
+	releaseInFlight(ctx)
+	metrics.GRPCStreams.Inc()
+	defer metrics.GRPCStreams.Dec()
+
+	if watch.Snapshot != nil {
+		snapshot := &pb.ReviewQueueEvent{
+			Type:       pb.ReviewQueueEventType_REVIEW_QUEUE_EVENT_TYPE_SNAPSHOT,
+			UserId:     req.GetUserId(),
+			Queue:      make([]*pb.PullRequestShort, 0, len(watch.Snapshot)),
+			OccurredAt: timestamppb.Now(),
+			EventId:    watch.LastEventID,
+		}
+		for _, pr := range watch.Snapshot {
+			snapshot.Queue = append(snapshot.Queue, toPBPullRequestShort(pr))
+		}
+		if err := stream.Send(snapshot); err != nil {
+			return err
+		}
+	}
+
+	for {
+		events, err := watch.Next(ctx)
+		for _, e := range events {
+			if err := stream.Send(toPBReviewEvent(e)); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			return toStatus(ctx, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-s.done:
+			return status.Error(codes.Unavailable, "server is shutting down, subscribe again")
+		case <-watch.Changed():
+		}
+	}
+}
+
+var reviewEventTypes = map[string]pb.ReviewQueueEventType{
+	model.ReviewQueueAssigned:   pb.ReviewQueueEventType_REVIEW_QUEUE_EVENT_TYPE_ASSIGNED,
+	model.ReviewQueueUnassigned: pb.ReviewQueueEventType_REVIEW_QUEUE_EVENT_TYPE_UNASSIGNED,
+	model.ReviewQueueMerged:     pb.ReviewQueueEventType_REVIEW_QUEUE_EVENT_TYPE_MERGED,
+}
+
+func toPBReviewEvent(e model.ReviewQueueEvent) *pb.ReviewQueueEvent {
+	return &pb.ReviewQueueEvent{
+		Type:        reviewEventTypes[e.Type],
+		UserId:      e.UserID,
+		PullRequest: toPBPullRequestShort(e.PullRequest),
+		OccurredAt:  timestamppb.New(e.At),
+		EventId:     e.ID,
+	}
+}
+
+func toPBUser(u model.User) *pb.User {
+	return &pb.User{UserId: u.ID, Username: u.Username, TeamName: u.TeamName, IsActive: u.IsActive}
+}
@@ -0,0 +1,2155 @@
+// Package api provides primitives to interact with the openapi HTTP API.
+//
+// Code generated by github.com/oapi-codegen/oapi-codegen/v2 version v2.5.1 DO NOT EDIT.
+package api
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi/v5"
+	"github.com/oapi-codegen/runtime"
+)
+
+const (
+	AdminTokenScopes = "AdminToken.Scopes"
+	UserTokenScopes  = "UserToken.Scopes"
+)
+
+// Defines values for BatchItemResultStatus.
+const (
+	BatchItemResultStatusALREADYMERGED  BatchItemResultStatus = "ALREADY_MERGED"
+	BatchItemResultStatusAUTHORNOTFOUND BatchItemResultStatus = "AUTHOR_NOT_FOUND"
+	BatchItemResultStatusCONFLICT       BatchItemResultStatus = "CONFLICT"
+	BatchItemResultStatusCREATED        BatchItemResultStatus = "CREATED"
+	BatchItemResultStatusEXISTS         BatchItemResultStatus = "EXISTS"
+	BatchItemResultStatusFORBIDDEN      BatchItemResultStatus = "FORBIDDEN"
+	BatchItemResultStatusMERGED         BatchItemResultStatus = "MERGED"
+	BatchItemResultStatusNOTFOUND       BatchItemResultStatus = "NOT_FOUND"
+	BatchItemResultStatusROLLEDBACK     BatchItemResultStatus = "ROLLED_BACK"
+)
+
+// Defines values for BatchMode.
+const (
+	Atomic     BatchMode = "atomic"
+	BestEffort BatchMode = "best_effort"
+)
+
+// Defines values for ErrorResponseErrorCode.
+const (
+	ErrorResponseErrorCodeBADREQUEST           ErrorResponseErrorCode = "BAD_REQUEST"
+	ErrorResponseErrorCodeCONFLICT             ErrorResponseErrorCode = "CONFLICT"
+	ErrorResponseErrorCodeFORBIDDEN            ErrorResponseErrorCode = "FORBIDDEN"
+	ErrorResponseErrorCodeIDEMPOTENCYKEYINUSE  ErrorResponseErrorCode = "IDEMPOTENCY_KEY_IN_USE"
+	ErrorResponseErrorCodeIDEMPOTENCYKEYREUSED ErrorResponseErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	ErrorResponseErrorCodeINTERNAL             ErrorResponseErrorCode = "INTERNAL"
+	ErrorResponseErrorCodeMETHODNOTALLOWED     ErrorResponseErrorCode = "METHOD_NOT_ALLOWED"
+	ErrorResponseErrorCodeNOCANDIDATE          ErrorResponseErrorCode = "NO_CANDIDATE"
+	ErrorResponseErrorCodeNOTASSIGNED          ErrorResponseErrorCode = "NOT_ASSIGNED"
+	ErrorResponseErrorCodeNOTFOUND             ErrorResponseErrorCode = "NOT_FOUND"
+	ErrorResponseErrorCodeOVERLOADED           ErrorResponseErrorCode = "OVERLOADED"
+	ErrorResponseErrorCodePAYLOADTOOLARGE      ErrorResponseErrorCode = "PAYLOAD_TOO_LARGE"
+	ErrorResponseErrorCodePRECONDITIONFAILED   ErrorResponseErrorCode = "PRECONDITION_FAILED"
+	ErrorResponseErrorCodePREXISTS             ErrorResponseErrorCode = "PR_EXISTS"
+	ErrorResponseErrorCodePRMERGED             ErrorResponseErrorCode = "PR_MERGED"
+	ErrorResponseErrorCodeRATELIMITED          ErrorResponseErrorCode = "RATE_LIMITED"
+	ErrorResponseErrorCodeTEAMEXISTS           ErrorResponseErrorCode = "TEAM_EXISTS"
+	ErrorResponseErrorCodeUNAUTHORIZED         ErrorResponseErrorCode = "UNAUTHORIZED"
+)
+
+// Defines values for FieldErrorRule.
+const (
+	Format   FieldErrorRule = "format"
+	OneOf    FieldErrorRule = "one_of"
+	Range    FieldErrorRule = "range"
+	Required FieldErrorRule = "required"
+	Type     FieldErrorRule = "type"
+	Unique   FieldErrorRule = "unique"
+	Unknown  FieldErrorRule = "unknown"
+)
+
+// Defines values for PullRequestStatus.
+const (
+	PullRequestStatusMERGED PullRequestStatus = "MERGED"
+	PullRequestStatusOPEN   PullRequestStatus = "OPEN"
+)
+
+// Defines values for PullRequestEventEventType.
+const (
+	PullRequestEventEventTypeCREATED            PullRequestEventEventType = "CREATED"
+	PullRequestEventEventTypeESCALATED          PullRequestEventEventType = "ESCALATED"
+	PullRequestEventEventTypeMERGED             PullRequestEventEventType = "MERGED"
+	PullRequestEventEventTypeREMINDERSENT       PullRequestEventEventType = "REMINDER_SENT"
+	PullRequestEventEventTypeREVIEWERASSIGNED   PullRequestEventEventType = "REVIEWER_ASSIGNED"
+	PullRequestEventEventTypeREVIEWERREASSIGNED PullRequestEventEventType = "REVIEWER_REASSIGNED"
+)
+
+// Defines values for PullRequestShortStatus.
+const (
+	PullRequestShortStatusMERGED PullRequestShortStatus = "MERGED"
+	PullRequestShortStatusOPEN   PullRequestShortStatus = "OPEN"
+)
+
+// Defines values for Role.
+const (
+	Admin    Role = "admin"
+	Member   Role = "member"
+	ReadOnly Role = "read_only"
+	TeamLead Role = "team_lead"
+)
+
+// Defines values for ListPullRequestsParamsStatus.
+const (
+	ListPullRequestsParamsStatusMERGED ListPullRequestsParamsStatus = "MERGED"
+	ListPullRequestsParamsStatusOPEN   ListPullRequestsParamsStatus = "OPEN"
+)
+
+// Defines values for ListPullRequestsParamsSort.
+const (
+	ListPullRequestsParamsSortCreatedAt      ListPullRequestsParamsSort = "created_at"
+	ListPullRequestsParamsSortID             ListPullRequestsParamsSort = "id"
+	ListPullRequestsParamsSortMinusCreatedAt ListPullRequestsParamsSort = "-created_at"
+	ListPullRequestsParamsSortMinusID        ListPullRequestsParamsSort = "-id"
+	ListPullRequestsParamsSortMinusName      ListPullRequestsParamsSort = "-name"
+	ListPullRequestsParamsSortName           ListPullRequestsParamsSort = "name"
+)
+
+// Defines values for ListTeamsParamsSort.
+const (
+	ListTeamsParamsSortMinusName ListTeamsParamsSort = "-name"
+	ListTeamsParamsSortName      ListTeamsParamsSort = "name"
+)
+
+// Defines values for ListUsersParamsSort.
+const (
+	ListUsersParamsSortID        ListUsersParamsSort = "id"
+	ListUsersParamsSortMinusID   ListUsersParamsSort = "-id"
+	ListUsersParamsSortMinusName ListUsersParamsSort = "-name"
+	ListUsersParamsSortName      ListUsersParamsSort = "name"
+)
+
+// Defines values for GetUserReviewsParamsStatus.
+const (
+	MERGED GetUserReviewsParamsStatus = "MERGED"
+	OPEN   GetUserReviewsParamsStatus = "OPEN"
+)
+
+// Defines values for GetUserReviewsParamsSort.
+const (
+	GetUserReviewsParamsSortCreatedAt      GetUserReviewsParamsSort = "created_at"
+	GetUserReviewsParamsSortID             GetUserReviewsParamsSort = "id"
+	GetUserReviewsParamsSortMinusCreatedAt GetUserReviewsParamsSort = "-created_at"
+	GetUserReviewsParamsSortMinusID        GetUserReviewsParamsSort = "-id"
+)
+
+// AssignmentStats defines model for AssignmentStats.
+type AssignmentStats struct {
+	ByPr   []PullRequestReviewersCount `json:"by_pr"`
+	ByUser []UserAssignmentCount       `json:"by_user"`
+}
+
+// BatchCreatePullRequestsRequest defines model for BatchCreatePullRequestsRequest.
+type BatchCreatePullRequestsRequest struct {
+	Items []CreatePullRequestRequest `json:"items"`
+
+	// Mode atomic - все или ничего: если хоть один элемент не выполнился, пакет откатывается.
+	// best_effort - выполняется все, что можно, остальное отражено в результатах.
+	Mode *BatchMode `json:"mode,omitempty"`
+}
+
+// BatchItemResult defines model for BatchItemResult.
+type BatchItemResult struct {
+	Pr            *PullRequest `json:"pr,omitempty"`
+	PullRequestID string       `json:"pull_request_id"`
+
+	// Status CREATED, MERGED - элемент выполнен; ALREADY_MERGED - PR уже был смержен;
+	// EXISTS, AUTHOR_NOT_FOUND, NOT_FOUND - элемент не выполнен;
+	// FORBIDDEN - элемент недоступен вызывающему по правам (только best_effort);
+	// CONFLICT - PR изменил параллельный запрос во время merge (только best_effort);
+	// ROLLED_BACK - элемент выполнился бы, но атомарный пакет откатан.
+	Status BatchItemResultStatus `json:"status"`
+}
+
+// BatchItemResultStatus CREATED, MERGED - элемент выполнен; ALREADY_MERGED - PR уже был смержен;
+// EXISTS, AUTHOR_NOT_FOUND, NOT_FOUND - элемент не выполнен;
+// FORBIDDEN - элемент недоступен вызывающему по правам (только best_effort);
+// CONFLICT - PR изменил параллельный запрос во время merge (только best_effort);
+// ROLLED_BACK - элемент выполнился бы, но атомарный пакет откатан.
+type BatchItemResultStatus string
+
+// BatchMergePullRequestsRequest defines model for BatchMergePullRequestsRequest.
+type BatchMergePullRequestsRequest struct {
+	// Mode atomic - все или ничего: если хоть один элемент не выполнился, пакет откатывается.
+	// best_effort - выполняется все, что можно, остальное отражено в результатах.
+	Mode           *BatchMode `json:"mode,omitempty"`
+	PullRequestIds []string   `json:"pull_request_ids"`
+}
+
+// BatchMode atomic - все или ничего: если хоть один элемент не выполнился, пакет откатывается.
+// best_effort - выполняется все, что можно, остальное отражено в результатах.
+type BatchMode string
+
+// BatchResponse defines model for BatchResponse.
+type BatchResponse struct {
+	// Applied false, если атомарный пакет откатан
+	Applied bool `json:"applied"`
+
+	// Mode atomic - все или ничего: если хоть один элемент не выполнился, пакет откатывается.
+	// best_effort - выполняется все, что можно, остальное отражено в результатах.
+	Mode BatchMode `json:"mode"`
+
+	// Results Результаты в порядке элементов запроса
+	Results []BatchItemResult `json:"results"`
+}
+
+// CreatePullRequestRequest defines model for CreatePullRequestRequest.
+type CreatePullRequestRequest struct {
+	AuthorID        string `json:"author_id"`
+	PullRequestID   string `json:"pull_request_id"`
+	PullRequestName string `json:"pull_request_name"`
+}
+
+// ErrorResponse defines model for ErrorResponse.
+type ErrorResponse struct {
+	Error struct {
+		Code ErrorResponseErrorCode `json:"code"`
+
+		// Details Нарушения по полям, только для BAD_REQUEST
+		Details *[]FieldError `json:"details,omitempty"`
+		Message string        `json:"message"`
+
+		// RequestID Идентификатор запроса (X-Request-ID), заполняется для внутренних ошибок
+		RequestID *string `json:"request_id,omitempty"`
+	} `json:"error"`
+}
+
+// ErrorResponseErrorCode defines model for ErrorResponse.Error.Code.
+type ErrorResponseErrorCode string
+
+// FieldError defines model for FieldError.
+type FieldError struct {
+	// Field Путь к полю в запросе, например members[1].user_id или limit
+	Field   string         `json:"field"`
+	Message string         `json:"message"`
+	Rule    FieldErrorRule `json:"rule"`
+}
+
+// FieldErrorRule defines model for FieldError.Rule.
+type FieldErrorRule string
+
+// MergePullRequestRequest defines model for MergePullRequestRequest.
+type MergePullRequestRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+}
+
+// NextCursor Курсор следующей страницы, null на последней
+type NextCursor = string
+
+// ProblemDetails Ошибка в формате RFC 7807. Отдается вместо ErrorResponse, если клиент прислал
+// Accept: application/problem+json.
+type ProblemDetails struct {
+	// Code То же, что error.code в ErrorResponse
+	Code      string        `json:"code"`
+	Detail    string        `json:"detail"`
+	Errors    *[]FieldError `json:"errors,omitempty"`
+	RequestID *string       `json:"request_id,omitempty"`
+	Status    int           `json:"status"`
+	Title     string        `json:"title"`
+	Type      string        `json:"type"`
+}
+
+// PullRequest defines model for PullRequest.
+type PullRequest struct {
+	// AssignedReviewers user_id назначенных ревьюверов (0..2)
+	AssignedReviewers []string   `json:"assigned_reviewers"`
+	AuthorID          string     `json:"author_id"`
+	CreatedAt         *time.Time `json:"createdAt"`
+
+	// MergedAt null, пока PR не в MERGED
+	MergedAt        *time.Time        `json:"mergedAt"`
+	PullRequestID   string            `json:"pull_request_id"`
+	PullRequestName string            `json:"pull_request_name"`
+	Status          PullRequestStatus `json:"status"`
+}
+
+// PullRequestStatus defines model for PullRequest.Status.
+type PullRequestStatus string
+
+// PullRequestEvent defines model for PullRequestEvent.
+type PullRequestEvent struct {
+	// Actor Инициатор события (api, scheduler)
+	Actor         string                    `json:"actor"`
+	CreatedAt     time.Time                 `json:"created_at"`
+	EventType     PullRequestEventEventType `json:"event_type"`
+	ID            int64                     `json:"id"`
+	OldUserID     *string                   `json:"old_user_id,omitempty"`
+	PullRequestID string                    `json:"pull_request_id"`
+	UserID        *string                   `json:"user_id,omitempty"`
+}
+
+// PullRequestEventEventType defines model for PullRequestEvent.EventType.
+type PullRequestEventEventType string
+
+// PullRequestHistory defines model for PullRequestHistory.
+type PullRequestHistory struct {
+	Events        []PullRequestEvent `json:"events"`
+	PullRequestID string             `json:"pull_request_id"`
+}
+
+// PullRequestPage defines model for PullRequestPage.
+type PullRequestPage struct {
+	Items []PullRequest `json:"items"`
+
+	// NextCursor Курсор следующей страницы, null на последней
+	NextCursor *NextCursor `json:"next_cursor"`
+}
+
+// PullRequestResponse defines model for PullRequestResponse.
+type PullRequestResponse struct {
+	Pr PullRequest `json:"pr"`
+}
+
+// PullRequestReviewersCount defines model for PullRequestReviewersCount.
+type PullRequestReviewersCount struct {
+	PullRequestID  string `json:"pull_request_id"`
+	ReviewersCount int    `json:"reviewers_count"`
+}
+
+// PullRequestShort defines model for PullRequestShort.
+type PullRequestShort struct {
+	AuthorID        string                 `json:"author_id"`
+	PullRequestID   string                 `json:"pull_request_id"`
+	PullRequestName string                 `json:"pull_request_name"`
+	Status          PullRequestShortStatus `json:"status"`
+}
+
+// PullRequestShortStatus defines model for PullRequestShort.Status.
+type PullRequestShortStatus string
+
+// ReassignRequest defines model for ReassignRequest.
+type ReassignRequest struct {
+	// OldUserID user_id ревьювера, которого нужно заменить
+	OldUserID     string `json:"old_user_id"`
+	PullRequestID string `json:"pull_request_id"`
+}
+
+// ReassignResponse defines model for ReassignResponse.
+type ReassignResponse struct {
+	Pr PullRequest `json:"pr"`
+
+	// ReplacedBy user_id нового ревьювера
+	ReplacedBy string `json:"replaced_by"`
+}
+
+// ReviewQueueChange Данные событий assigned, unassigned и merged в потоке очереди ревью. Статус PR -
+// на момент события: OPEN для assigned и unassigned, MERGED для merged.
+type ReviewQueueChange struct {
+	OccurredAt  time.Time        `json:"occurred_at"`
+	PullRequest PullRequestShort `json:"pull_request"`
+	UserID      string           `json:"user_id"`
+}
+
+// ReviewQueueSnapshot Данные события snapshot в потоке очереди ревью
+type ReviewQueueSnapshot struct {
+	PullRequests []PullRequestShort `json:"pull_requests"`
+	UserID       string             `json:"user_id"`
+}
+
+// Role defines model for Role.
+type Role string
+
+// SetIsActiveRequest defines model for SetIsActiveRequest.
+type SetIsActiveRequest struct {
+	IsActive bool   `json:"is_active"`
+	UserID   string `json:"user_id"`
+}
+
+// SetRoleRequest defines model for SetRoleRequest.
+type SetRoleRequest struct {
+	Role   Role   `json:"role"`
+	UserID string `json:"user_id"`
+}
+
+// SetRoleResponse defines model for SetRoleResponse.
+type SetRoleResponse struct {
+	Role   Role   `json:"role"`
+	UserID string `json:"user_id"`
+}
+
+// Team defines model for Team.
+type Team struct {
+	Members  []TeamMember `json:"members"`
+	TeamName string       `json:"team_name"`
+}
+
+// TeamMember defines model for TeamMember.
+type TeamMember struct {
+	IsActive bool   `json:"is_active"`
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// TeamPage defines model for TeamPage.
+type TeamPage struct {
+	Items []Team `json:"items"`
+
+	// NextCursor Курсор следующей страницы, null на последней
+	NextCursor *NextCursor `json:"next_cursor"`
+}
+
+// TeamResponse defines model for TeamResponse.
+type TeamResponse struct {
+	Team Team `json:"team"`
+}
+
+// User defines model for User.
+type User struct {
+	IsActive bool   `json:"is_active"`
+	TeamName string `json:"team_name"`
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+}
+
+// UserAssignmentCount defines model for UserAssignmentCount.
+type UserAssignmentCount struct {
+	// AssignedCount Сколько раз пользователь назначался ревьювером
+	AssignedCount int    `json:"assigned_count"`
+	UserID        string `json:"user_id"`
+}
+
+// UserPage defines model for UserPage.
+type UserPage struct {
+	Items []User `json:"items"`
+
+	// NextCursor Курсор следующей страницы, null на последней
+	NextCursor *NextCursor `json:"next_cursor"`
+}
+
+// UserResponse defines model for UserResponse.
+type UserResponse struct {
+	User User `json:"user"`
+}
+
+// UserReviewsPage defines model for UserReviewsPage.
+type UserReviewsPage struct {
+	// NextCursor Курсор следующей страницы, null на последней
+	NextCursor   *NextCursor        `json:"next_cursor"`
+	PullRequests []PullRequestShort `json:"pull_requests"`
+	UserID       string             `json:"user_id"`
+}
+
+// Cursor defines model for Cursor.
+type Cursor = string
+
+// IdempotencyKey defines model for IdempotencyKey.
+type IdempotencyKey = string
+
+// IfMatch defines model for IfMatch.
+type IfMatch = string
+
+// Limit defines model for Limit.
+type Limit = int
+
+// TeamNameQuery defines model for TeamNameQuery.
+type TeamNameQuery = string
+
+// UserIDQuery defines model for UserIdQuery.
+type UserIDQuery = string
+
+// BadListQueryApplicationJSON defines model for BadListQuery.
+type BadListQueryApplicationJSON = ErrorResponse
+
+// BadListQueryApplicationProblemPlusJSON Ошибка в формате RFC 7807. Отдается вместо ErrorResponse, если клиент прислал
+// Accept: application/problem+json.
+type BadListQueryApplicationProblemPlusJSON = ProblemDetails
+
+// Forbidden defines model for Forbidden.
+type Forbidden = ErrorResponse
+
+// IdempotencyKeyInUse defines model for IdempotencyKeyInUse.
+type IdempotencyKeyInUse = ErrorResponse
+
+// IdempotencyKeyReused defines model for IdempotencyKeyReused.
+type IdempotencyKeyReused = ErrorResponse
+
+// NotFound defines model for NotFound.
+type NotFound = ErrorResponse
+
+// Overloaded defines model for Overloaded.
+type Overloaded = ErrorResponse
+
+// PreconditionFailed defines model for PreconditionFailed.
+type PreconditionFailed = ErrorResponse
+
+// RateLimited defines model for RateLimited.
+type RateLimited = ErrorResponse
+
+// Unauthorized defines model for Unauthorized.
+type Unauthorized = ErrorResponse
+
+// ValidationErrorApplicationJSON defines model for ValidationError.
+type ValidationErrorApplicationJSON = ErrorResponse
+
+// ValidationErrorApplicationProblemPlusJSON Ошибка в формате RFC 7807. Отдается вместо ErrorResponse, если клиент прислал
+// Accept: application/problem+json.
+type ValidationErrorApplicationProblemPlusJSON = ProblemDetails
+
+// BatchCreatePullRequestsParams defines parameters for BatchCreatePullRequests.
+type BatchCreatePullRequestsParams struct {
+	// IdempotencyKey Повтор запроса с тем же ключом в течение IDEMPOTENCY_TTL возвращает сохраненный ответ
+	// (заголовок Idempotency-Replayed: true) и не выполняет операцию заново. Тот же ключ с другим
+	// телом - 422, пока первый запрос выполняется - 409 IDEMPOTENCY_KEY_IN_USE. Ответы 5xx не сохраняются.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// BatchMergePullRequestsParams defines parameters for BatchMergePullRequests.
+type BatchMergePullRequestsParams struct {
+	// IdempotencyKey Повтор запроса с тем же ключом в течение IDEMPOTENCY_TTL возвращает сохраненный ответ
+	// (заголовок Idempotency-Replayed: true) и не выполняет операцию заново. Тот же ключ с другим
+	// телом - 422, пока первый запрос выполняется - 409 IDEMPOTENCY_KEY_IN_USE. Ответы 5xx не сохраняются.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// CreatePullRequestParams defines parameters for CreatePullRequest.
+type CreatePullRequestParams struct {
+	// IdempotencyKey Повтор запроса с тем же ключом в течение IDEMPOTENCY_TTL возвращает сохраненный ответ
+	// (заголовок Idempotency-Replayed: true) и не выполняет операцию заново. Тот же ключ с другим
+	// телом - 422, пока первый запрос выполняется - 409 IDEMPOTENCY_KEY_IN_USE. Ответы 5xx не сохраняются.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// GetPullRequestHistoryParams defines parameters for GetPullRequestHistory.
+type GetPullRequestHistoryParams struct {
+	PullRequestID string `form:"pull_request_id" json:"pull_request_id"`
+}
+
+// MergePullRequestParams defines parameters for MergePullRequest.
+type MergePullRequestParams struct {
+	// IdempotencyKey Повтор запроса с тем же ключом в течение IDEMPOTENCY_TTL возвращает сохраненный ответ
+	// (заголовок Idempotency-Replayed: true) и не выполняет операцию заново. Тот же ключ с другим
+	// телом - 422, пока первый запрос выполняется - 409 IDEMPOTENCY_KEY_IN_USE. Ответы 5xx не сохраняются.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+
+	// IfMatch ETag из предыдущего ответа по PR. Если PR с тех пор изменился - 412 PRECONDITION_FAILED.
+	// Без заголовка конфликтующие изменения перепроверяются на сервере, а если PR
+	// все время меняется параллельно, возвращается 409 CONFLICT.
+	IfMatch *IfMatch `json:"If-Match,omitempty"`
+}
+
+// ReassignReviewerParams defines parameters for ReassignReviewer.
+type ReassignReviewerParams struct {
+	// IdempotencyKey Повтор запроса с тем же ключом в течение IDEMPOTENCY_TTL возвращает сохраненный ответ
+	// (заголовок Idempotency-Replayed: true) и не выполняет операцию заново. Тот же ключ с другим
+	// телом - 422, пока первый запрос выполняется - 409 IDEMPOTENCY_KEY_IN_USE. Ответы 5xx не сохраняются.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+
+	// IfMatch ETag из предыдущего ответа по PR. Если PR с тех пор изменился - 412 PRECONDITION_FAILED.
+	// Без заголовка конфликтующие изменения перепроверяются на сервере, а если PR
+	// все время меняется параллельно, возвращается 409 CONFLICT.
+	IfMatch *IfMatch `json:"If-Match,omitempty"`
+}
+
+// ListPullRequestsParams defines parameters for ListPullRequests.
+type ListPullRequestsParams struct {
+	Status     *ListPullRequestsParamsStatus `form:"status,omitempty" json:"status,omitempty"`
+	AuthorID   *string                       `form:"author_id,omitempty" json:"author_id,omitempty"`
+	ReviewerID *string                       `form:"reviewer_id,omitempty" json:"reviewer_id,omitempty"`
+
+	// TeamName Команда автора PR
+	TeamName *string `form:"team_name,omitempty" json:"team_name,omitempty"`
+
+	// CreatedFrom Создан не раньше (включительно)
+	CreatedFrom *time.Time `form:"created_from,omitempty" json:"created_from,omitempty"`
+
+	// CreatedTo Создан раньше (не включительно)
+	CreatedTo *time.Time `form:"created_to,omitempty" json:"created_to,omitempty"`
+
+	// Name Подстрока названия без учета регистра
+	Name *string                     `form:"name,omitempty" json:"name,omitempty"`
+	Sort *ListPullRequestsParamsSort `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Limit Размер страницы
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor next_cursor из предыдущей страницы. Курсор непрозрачный и действует только с той же
+	// сортировкой, с которой был выдан; фильтры между страницами менять не следует.
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// ListPullRequestsParamsStatus defines parameters for ListPullRequests.
+type ListPullRequestsParamsStatus string
+
+// ListPullRequestsParamsSort defines parameters for ListPullRequests.
+type ListPullRequestsParamsSort string
+
+// CreateTeamParams defines parameters for CreateTeam.
+type CreateTeamParams struct {
+	// IdempotencyKey Повтор запроса с тем же ключом в течение IDEMPOTENCY_TTL возвращает сохраненный ответ
+	// (заголовок Idempotency-Replayed: true) и не выполняет операцию заново. Тот же ключ с другим
+	// телом - 422, пока первый запрос выполняется - 409 IDEMPOTENCY_KEY_IN_USE. Ответы 5xx не сохраняются.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// GetTeamParams defines parameters for GetTeam.
+type GetTeamParams struct {
+	// TeamName Уникальное имя команды
+	TeamName TeamNameQuery `form:"team_name" json:"team_name"`
+}
+
+// ListTeamsParams defines parameters for ListTeams.
+type ListTeamsParams struct {
+	// Name Подстрока имени команды без учета регистра
+	Name *string              `form:"name,omitempty" json:"name,omitempty"`
+	Sort *ListTeamsParamsSort `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Limit Размер страницы
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor next_cursor из предыдущей страницы. Курсор непрозрачный и действует только с той же
+	// сортировкой, с которой был выдан; фильтры между страницами менять не следует.
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// ListTeamsParamsSort defines parameters for ListTeams.
+type ListTeamsParamsSort string
+
+// ListUsersParams defines parameters for ListUsers.
+type ListUsersParams struct {
+	TeamName *string `form:"team_name,omitempty" json:"team_name,omitempty"`
+	IsActive *bool   `form:"is_active,omitempty" json:"is_active,omitempty"`
+
+	// Name Подстрока username без учета регистра
+	Name *string              `form:"name,omitempty" json:"name,omitempty"`
+	Sort *ListUsersParamsSort `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Limit Размер страницы
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor next_cursor из предыдущей страницы. Курсор непрозрачный и действует только с той же
+	// сортировкой, с которой был выдан; фильтры между страницами менять не следует.
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// ListUsersParamsSort defines parameters for ListUsers.
+type ListUsersParamsSort string
+
+// GetUserReviewsParams defines parameters for GetUserReviews.
+type GetUserReviewsParams struct {
+	// UserID Идентификатор пользователя
+	UserID UserIDQuery                 `form:"user_id" json:"user_id"`
+	Status *GetUserReviewsParamsStatus `form:"status,omitempty" json:"status,omitempty"`
+	Sort   *GetUserReviewsParamsSort   `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Limit Размер страницы
+	Limit *Limit `form:"limit,omitempty" json:"limit,omitempty"`
+
+	// Cursor next_cursor из предыдущей страницы. Курсор непрозрачный и действует только с той же
+	// сортировкой, с которой был выдан; фильтры между страницами менять не следует.
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+}
+
+// GetUserReviewsParamsStatus defines parameters for GetUserReviews.
+type GetUserReviewsParamsStatus string
+
+// GetUserReviewsParamsSort defines parameters for GetUserReviews.
+type GetUserReviewsParamsSort string
+
+// StreamUserReviewsParams defines parameters for StreamUserReviews.
+type StreamUserReviewsParams struct {
+	// UserID Идентификатор пользователя
+	UserID UserIDQuery `form:"user_id" json:"user_id"`
+
+	// LastEventID id последнего полученного события
+	LastEventID *int64 `json:"Last-Event-ID,omitempty"`
+}
+
+// SetUserIsActiveParams defines parameters for SetUserIsActive.
+type SetUserIsActiveParams struct {
+	// IdempotencyKey Повтор запроса с тем же ключом в течение IDEMPOTENCY_TTL возвращает сохраненный ответ
+	// (заголовок Idempotency-Replayed: true) и не выполняет операцию заново. Тот же ключ с другим
+	// телом - 422, пока первый запрос выполняется - 409 IDEMPOTENCY_KEY_IN_USE. Ответы 5xx не сохраняются.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// SetUserRoleParams defines parameters for SetUserRole.
+type SetUserRoleParams struct {
+	// IdempotencyKey Повтор запроса с тем же ключом в течение IDEMPOTENCY_TTL возвращает сохраненный ответ
+	// (заголовок Idempotency-Replayed: true) и не выполняет операцию заново. Тот же ключ с другим
+	// телом - 422, пока первый запрос выполняется - 409 IDEMPOTENCY_KEY_IN_USE. Ответы 5xx не сохраняются.
+	IdempotencyKey *IdempotencyKey `json:"Idempotency-Key,omitempty"`
+}
+
+// BatchCreatePullRequestsJSONRequestBody defines body for BatchCreatePullRequests for application/json ContentType.
+type BatchCreatePullRequestsJSONRequestBody = BatchCreatePullRequestsRequest
+
+// BatchMergePullRequestsJSONRequestBody defines body for BatchMergePullRequests for application/json ContentType.
+type BatchMergePullRequestsJSONRequestBody = BatchMergePullRequestsRequest
+
+// CreatePullRequestJSONRequestBody defines body for CreatePullRequest for application/json ContentType.
+type CreatePullRequestJSONRequestBody = CreatePullRequestRequest
+
+// MergePullRequestJSONRequestBody defines body for MergePullRequest for application/json ContentType.
+type MergePullRequestJSONRequestBody = MergePullRequestRequest
+
+// ReassignReviewerJSONRequestBody defines body for ReassignReviewer for application/json ContentType.
+type ReassignReviewerJSONRequestBody = ReassignRequest
+
+// CreateTeamJSONRequestBody defines body for CreateTeam for application/json ContentType.
+type CreateTeamJSONRequestBody = Team
+
+// SetUserIsActiveJSONRequestBody defines body for SetUserIsActive for application/json ContentType.
+type SetUserIsActiveJSONRequestBody = SetIsActiveRequest
+
+// SetUserRoleJSONRequestBody defines body for SetUserRole for application/json ContentType.
+type SetUserRoleJSONRequestBody = SetRoleRequest
+
+// ServerInterface represents all server handlers.
+type ServerInterface interface {
+	// Создать пакет PR с распределением ревьюверов по всему пакету
+	// (POST /pullRequest/batchCreate)
+	BatchCreatePullRequests(w http.ResponseWriter, r *http.Request, params BatchCreatePullRequestsParams)
+	// Пометить пакет PR как MERGED
+	// (POST /pullRequest/batchMerge)
+	BatchMergePullRequests(w http.ResponseWriter, r *http.Request, params BatchMergePullRequestsParams)
+	// Создать PR и автоматически назначить до 2 ревьюверов из команды автора
+	// (POST /pullRequest/create)
+	CreatePullRequest(w http.ResponseWriter, r *http.Request, params CreatePullRequestParams)
+	// История событий PR (назначения, напоминания, эскалации, merge)
+	// (GET /pullRequest/history)
+	GetPullRequestHistory(w http.ResponseWriter, r *http.Request, params GetPullRequestHistoryParams)
+	// Пометить PR как MERGED (идемпотентная операция)
+	// (POST /pullRequest/merge)
+	MergePullRequest(w http.ResponseWriter, r *http.Request, params MergePullRequestParams)
+	// Переназначить конкретного ревьювера на другого из его команды
+	// (POST /pullRequest/reassign)
+	ReassignReviewer(w http.ResponseWriter, r *http.Request, params ReassignReviewerParams)
+	// Список PR с фильтрами и пагинацией
+	// (GET /pullRequests)
+	ListPullRequests(w http.ResponseWriter, r *http.Request, params ListPullRequestsParams)
+	// Число назначений по ревьюверам и число ревьюверов по PR
+	// (GET /stats/assignments)
+	GetAssignmentStats(w http.ResponseWriter, r *http.Request)
+	// Создать команду с участниками (создаёт/обновляет пользователей)
+	// (POST /team/add)
+	CreateTeam(w http.ResponseWriter, r *http.Request, params CreateTeamParams)
+	// Получить команду с участниками
+	// (GET /team/get)
+	GetTeam(w http.ResponseWriter, r *http.Request, params GetTeamParams)
+	// Список команд с участниками, сортировка по имени
+	// (GET /teams)
+	ListTeams(w http.ResponseWriter, r *http.Request, params ListTeamsParams)
+	// Список пользователей с фильтрами и пагинацией
+	// (GET /users)
+	ListUsers(w http.ResponseWriter, r *http.Request, params ListUsersParams)
+	// Получить PR'ы, где пользователь назначен ревьювером
+	// (GET /users/getReview)
+	GetUserReviews(w http.ResponseWriter, r *http.Request, params GetUserReviewsParams)
+	// Поток изменений очереди ревью пользователя (Server-Sent Events)
+	// (GET /users/reviews/stream)
+	StreamUserReviews(w http.ResponseWriter, r *http.Request, params StreamUserReviewsParams)
+	// Установить флаг активности пользователя
+	// (POST /users/setIsActive)
+	SetUserIsActive(w http.ResponseWriter, r *http.Request, params SetUserIsActiveParams)
+	// Назначить роль пользователю
+	// (POST /users/setRole)
+	SetUserRole(w http.ResponseWriter, r *http.Request, params SetUserRoleParams)
+}
+
+// Unimplemented server implementation that returns http.StatusNotImplemented for each endpoint.
+
+type Unimplemented struct{}
+
+// Создать пакет PR с распределением ревьюверов по всему пакету
+// (POST /pullRequest/batchCreate)
+func (_ Unimplemented) BatchCreatePullRequests(w http.ResponseWriter, r *http.Request, params BatchCreatePullRequestsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Пометить пакет PR как MERGED
+// (POST /pullRequest/batchMerge)
+func (_ Unimplemented) BatchMergePullRequests(w http.ResponseWriter, r *http.Request, params BatchMergePullRequestsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Создать PR и автоматически назначить до 2 ревьюверов из команды автора
+// (POST /pullRequest/create)
+func (_ Unimplemented) CreatePullRequest(w http.ResponseWriter, r *http.Request, params CreatePullRequestParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// История событий PR (назначения, напоминания, эскалации, merge)
+// (GET /pullRequest/history)
+func (_ Unimplemented) GetPullRequestHistory(w http.ResponseWriter, r *http.Request, params GetPullRequestHistoryParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Пометить PR как MERGED (идемпотентная операция)
+// (POST /pullRequest/merge)
+func (_ Unimplemented) MergePullRequest(w http.ResponseWriter, r *http.Request, params MergePullRequestParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Переназначить конкретного ревьювера на другого из его команды
+// (POST /pullRequest/reassign)
+func (_ Unimplemented) ReassignReviewer(w http.ResponseWriter, r *http.Request, params ReassignReviewerParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Список PR с фильтрами и пагинацией
+// (GET /pullRequests)
+func (_ Unimplemented) ListPullRequests(w http.ResponseWriter, r *http.Request, params ListPullRequestsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Число назначений по ревьюверам и число ревьюверов по PR
+// (GET /stats/assignments)
+func (_ Unimplemented) GetAssignmentStats(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Создать команду с участниками (создаёт/обновляет пользователей)
+// (POST /team/add)
+func (_ Unimplemented) CreateTeam(w http.ResponseWriter, r *http.Request, params CreateTeamParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Получить команду с участниками
+// (GET /team/get)
+func (_ Unimplemented) GetTeam(w http.ResponseWriter, r *http.Request, params GetTeamParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Список команд с участниками, сортировка по имени
+// (GET /teams)
+func (_ Unimplemented) ListTeams(w http.ResponseWriter, r *http.Request, params ListTeamsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Список пользователей с фильтрами и пагинацией
+// (GET /users)
+func (_ Unimplemented) ListUsers(w http.ResponseWriter, r *http.Request, params ListUsersParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Получить PR'ы, где пользователь назначен ревьювером
+// (GET /users/getReview)
+func (_ Unimplemented) GetUserReviews(w http.ResponseWriter, r *http.Request, params GetUserReviewsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Поток изменений очереди ревью пользователя (Server-Sent Events)
+// (GET /users/reviews/stream)
+func (_ Unimplemented) StreamUserReviews(w http.ResponseWriter, r *http.Request, params StreamUserReviewsParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Установить флаг активности пользователя
+// (POST /users/setIsActive)
+func (_ Unimplemented) SetUserIsActive(w http.ResponseWriter, r *http.Request, params SetUserIsActiveParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// Назначить роль пользователю
+// (POST /users/setRole)
+func (_ Unimplemented) SetUserRole(w http.ResponseWriter, r *http.Request, params SetUserRoleParams) {
+	w.WriteHeader(http.StatusNotImplemented)
+}
+
+// ServerInterfaceWrapper converts contexts to parameters.
+type ServerInterfaceWrapper struct {
+	Handler            ServerInterface
+	HandlerMiddlewares []MiddlewareFunc
+	ErrorHandlerFunc   func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+type MiddlewareFunc func(http.Handler) http.Handler
+
+// BatchCreatePullRequests operation middleware
+func (siw *ServerInterfaceWrapper) BatchCreatePullRequests(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, AdminTokenScopes, []string{})
+
+	ctx = context.WithValue(ctx, UserTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params BatchCreatePullRequestsParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Idempotency-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Idempotency-Key", Err: err})
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.BatchCreatePullRequests(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// BatchMergePullRequests operation middleware
+func (siw *ServerInterfaceWrapper) BatchMergePullRequests(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, AdminTokenScopes, []string{})
+
+	ctx = context.WithValue(ctx, UserTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params BatchMergePullRequestsParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Idempotency-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Idempotency-Key", Err: err})
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.BatchMergePullRequests(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// CreatePullRequest operation middleware
+func (siw *ServerInterfaceWrapper) CreatePullRequest(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, AdminTokenScopes, []string{})
+
+	ctx = context.WithValue(ctx, UserTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CreatePullRequestParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Idempotency-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Idempotency-Key", Err: err})
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CreatePullRequest(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetPullRequestHistory operation middleware
+func (siw *ServerInterfaceWrapper) GetPullRequestHistory(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, AdminTokenScopes, []string{})
+
+	ctx = context.WithValue(ctx, UserTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetPullRequestHistoryParams
+
+	// ------------- Required query parameter "pull_request_id" -------------
+
+	if paramValue := r.URL.Query().Get("pull_request_id"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "pull_request_id"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "pull_request_id", r.URL.Query(), &params.PullRequestID)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "pull_request_id", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetPullRequestHistory(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// MergePullRequest operation middleware
+func (siw *ServerInterfaceWrapper) MergePullRequest(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, AdminTokenScopes, []string{})
+
+	ctx = context.WithValue(ctx, UserTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params MergePullRequestParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Idempotency-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Idempotency-Key", Err: err})
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch IfMatch
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-Match", valueList[0], &IfMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
+
+		params.IfMatch = &IfMatch
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.MergePullRequest(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ReassignReviewer operation middleware
+func (siw *ServerInterfaceWrapper) ReassignReviewer(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, AdminTokenScopes, []string{})
+
+	ctx = context.WithValue(ctx, UserTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ReassignReviewerParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Idempotency-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Idempotency-Key", Err: err})
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch IfMatch
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "If-Match", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "If-Match", valueList[0], &IfMatch, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "If-Match", Err: err})
+			return
+		}
+
+		params.IfMatch = &IfMatch
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ReassignReviewer(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListPullRequests operation middleware
+func (siw *ServerInterfaceWrapper) ListPullRequests(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, AdminTokenScopes, []string{})
+
+	ctx = context.WithValue(ctx, UserTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListPullRequestsParams
+
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", r.URL.Query(), &params.Status)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "status", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "author_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "author_id", r.URL.Query(), &params.AuthorID)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "author_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "reviewer_id" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "reviewer_id", r.URL.Query(), &params.ReviewerID)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "reviewer_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "team_name" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "team_name", r.URL.Query(), &params.TeamName)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "team_name", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "created_from" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "created_from", r.URL.Query(), &params.CreatedFrom)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "created_from", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "created_to" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "created_to", r.URL.Query(), &params.CreatedTo)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "created_to", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "name" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "name", r.URL.Query(), &params.Name)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "name", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", r.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sort", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListPullRequests(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetAssignmentStats operation middleware
+func (siw *ServerInterfaceWrapper) GetAssignmentStats(w http.ResponseWriter, r *http.Request) {
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, AdminTokenScopes, []string{})
+
+	ctx = context.WithValue(ctx, UserTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetAssignmentStats(w, r)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// CreateTeam operation middleware
+func (siw *ServerInterfaceWrapper) CreateTeam(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, AdminTokenScopes, []string{})
+
+	ctx = context.WithValue(ctx, UserTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params CreateTeamParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Idempotency-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Idempotency-Key", Err: err})
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.CreateTeam(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetTeam operation middleware
+func (siw *ServerInterfaceWrapper) GetTeam(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, AdminTokenScopes, []string{})
+
+	ctx = context.WithValue(ctx, UserTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetTeamParams
+
+	// ------------- Required query parameter "team_name" -------------
+
+	if paramValue := r.URL.Query().Get("team_name"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "team_name"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "team_name", r.URL.Query(), &params.TeamName)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "team_name", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetTeam(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListTeams operation middleware
+func (siw *ServerInterfaceWrapper) ListTeams(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, AdminTokenScopes, []string{})
+
+	ctx = context.WithValue(ctx, UserTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListTeamsParams
+
+	// ------------- Optional query parameter "name" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "name", r.URL.Query(), &params.Name)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "name", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", r.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sort", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListTeams(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// ListUsers operation middleware
+func (siw *ServerInterfaceWrapper) ListUsers(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, AdminTokenScopes, []string{})
+
+	ctx = context.WithValue(ctx, UserTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ListUsersParams
+
+	// ------------- Optional query parameter "team_name" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "team_name", r.URL.Query(), &params.TeamName)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "team_name", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "is_active" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "is_active", r.URL.Query(), &params.IsActive)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "is_active", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "name" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "name", r.URL.Query(), &params.Name)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "name", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", r.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sort", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.ListUsers(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// GetUserReviews operation middleware
+func (siw *ServerInterfaceWrapper) GetUserReviews(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, AdminTokenScopes, []string{})
+
+	ctx = context.WithValue(ctx, UserTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetUserReviewsParams
+
+	// ------------- Required query parameter "user_id" -------------
+
+	if paramValue := r.URL.Query().Get("user_id"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "user_id"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "user_id", r.URL.Query(), &params.UserID)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "user_id", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "status" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "status", r.URL.Query(), &params.Status)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "status", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", r.URL.Query(), &params.Sort)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "sort", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "limit", r.URL.Query(), &params.Limit)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "limit", Err: err})
+		return
+	}
+
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", r.URL.Query(), &params.Cursor)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "cursor", Err: err})
+		return
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.GetUserReviews(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// StreamUserReviews operation middleware
+func (siw *ServerInterfaceWrapper) StreamUserReviews(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, AdminTokenScopes, []string{})
+
+	ctx = context.WithValue(ctx, UserTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params StreamUserReviewsParams
+
+	// ------------- Required query parameter "user_id" -------------
+
+	if paramValue := r.URL.Query().Get("user_id"); paramValue != "" {
+
+	} else {
+		siw.ErrorHandlerFunc(w, r, &RequiredParamError{ParamName: "user_id"})
+		return
+	}
+
+	err = runtime.BindQueryParameter("form", true, true, "user_id", r.URL.Query(), &params.UserID)
+	if err != nil {
+		siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "user_id", Err: err})
+		return
+	}
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Last-Event-ID" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Last-Event-ID")]; found {
+		var LastEventID int64
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Last-Event-ID", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Last-Event-ID", valueList[0], &LastEventID, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Last-Event-ID", Err: err})
+			return
+		}
+
+		params.LastEventID = &LastEventID
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.StreamUserReviews(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// SetUserIsActive operation middleware
+func (siw *ServerInterfaceWrapper) SetUserIsActive(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, AdminTokenScopes, []string{})
+
+	ctx = context.WithValue(ctx, UserTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SetUserIsActiveParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Idempotency-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Idempotency-Key", Err: err})
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.SetUserIsActive(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+// SetUserRole operation middleware
+func (siw *ServerInterfaceWrapper) SetUserRole(w http.ResponseWriter, r *http.Request) {
+
+	var err error
+
+	ctx := r.Context()
+
+	ctx = context.WithValue(ctx, AdminTokenScopes, []string{})
+
+	ctx = context.WithValue(ctx, UserTokenScopes, []string{})
+
+	r = r.WithContext(ctx)
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SetUserRoleParams
+
+	headers := r.Header
+
+	// ------------- Optional header parameter "Idempotency-Key" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("Idempotency-Key")]; found {
+		var IdempotencyKey IdempotencyKey
+		n := len(valueList)
+		if n != 1 {
+			siw.ErrorHandlerFunc(w, r, &TooManyValuesForParamError{ParamName: "Idempotency-Key", Count: n})
+			return
+		}
+
+		err = runtime.BindStyledParameterWithOptions("simple", "Idempotency-Key", valueList[0], &IdempotencyKey, runtime.BindStyledParameterOptions{ParamLocation: runtime.ParamLocationHeader, Explode: false, Required: false})
+		if err != nil {
+			siw.ErrorHandlerFunc(w, r, &InvalidParamFormatError{ParamName: "Idempotency-Key", Err: err})
+			return
+		}
+
+		params.IdempotencyKey = &IdempotencyKey
+
+	}
+
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		siw.Handler.SetUserRole(w, r, params)
+	}))
+
+	for _, middleware := range siw.HandlerMiddlewares {
+		handler = middleware(handler)
+	}
+
+	handler.ServeHTTP(w, r)
+}
+
+type UnescapedCookieParamError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *UnescapedCookieParamError) Error() string {
+	return fmt.Sprintf("error unescaping cookie parameter '%s'", e.ParamName)
+}
+
+func (e *UnescapedCookieParamError) Unwrap() error {
+	return e.Err
+}
+
+type UnmarshalingParamError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *UnmarshalingParamError) Error() string {
+	return fmt.Sprintf("Error unmarshaling parameter %s as JSON: %s", e.ParamName, e.Err.Error())
+}
+
+func (e *UnmarshalingParamError) Unwrap() error {
+	return e.Err
+}
+
+type RequiredParamError struct {
+	ParamName string
+}
+
+func (e *RequiredParamError) Error() string {
+	return fmt.Sprintf("Query argument %s is required, but not found", e.ParamName)
+}
+
+type RequiredHeaderError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *RequiredHeaderError) Error() string {
+	return fmt.Sprintf("Header parameter %s is required, but not found", e.ParamName)
+}
+
+func (e *RequiredHeaderError) Unwrap() error {
+	return e.Err
+}
+
+type InvalidParamFormatError struct {
+	ParamName string
+	Err       error
+}
+
+func (e *InvalidParamFormatError) Error() string {
+	return fmt.Sprintf("Invalid format for parameter %s: %s", e.ParamName, e.Err.Error())
+}
+
+func (e *InvalidParamFormatError) Unwrap() error {
+	return e.Err
+}
+
+type TooManyValuesForParamError struct {
+	ParamName string
+	Count     int
+}
+
+func (e *TooManyValuesForParamError) Error() string {
+	return fmt.Sprintf("Expected one value for %s, got %d", e.ParamName, e.Count)
+}
+
+// Handler creates http.Handler with routing matching OpenAPI spec.
+func Handler(si ServerInterface) http.Handler {
+	return HandlerWithOptions(si, ChiServerOptions{})
+}
+
+type ChiServerOptions struct {
+	BaseURL          string
+	BaseRouter       chi.Router
+	Middlewares      []MiddlewareFunc
+	ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, err error)
+}
+
+// HandlerFromMux creates http.Handler with routing matching OpenAPI spec based on the provided mux.
+func HandlerFromMux(si ServerInterface, r chi.Router) http.Handler {
+	return HandlerWithOptions(si, ChiServerOptions{
+		BaseRouter: r,
+	})
+}
+
+func HandlerFromMuxWithBaseURL(si ServerInterface, r chi.Router, baseURL string) http.Handler {
+	return HandlerWithOptions(si, ChiServerOptions{
+		BaseURL:    baseURL,
+		BaseRouter: r,
+	})
+}
+
+// HandlerWithOptions creates http.Handler with additional options
+func HandlerWithOptions(si ServerInterface, options ChiServerOptions) http.Handler {
+	r := options.BaseRouter
+
+	if r == nil {
+		r = chi.NewRouter()
+	}
+	if options.ErrorHandlerFunc == nil {
+		options.ErrorHandlerFunc = func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}
+	wrapper := ServerInterfaceWrapper{
+		Handler:            si,
+		HandlerMiddlewares: options.Middlewares,
+		ErrorHandlerFunc:   options.ErrorHandlerFunc,
+	}
+
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/pullRequest/batchCreate", wrapper.BatchCreatePullRequests)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/pullRequest/batchMerge", wrapper.BatchMergePullRequests)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/pullRequest/create", wrapper.CreatePullRequest)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/pullRequest/history", wrapper.GetPullRequestHistory)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/pullRequest/merge", wrapper.MergePullRequest)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/pullRequest/reassign", wrapper.ReassignReviewer)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/pullRequests", wrapper.ListPullRequests)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/stats/assignments", wrapper.GetAssignmentStats)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/team/add", wrapper.CreateTeam)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/team/get", wrapper.GetTeam)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/teams", wrapper.ListTeams)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/users", wrapper.ListUsers)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/users/getReview", wrapper.GetUserReviews)
+	})
+	r.Group(func(r chi.Router) {
+		r.Get(options.BaseURL+"/users/reviews/stream", wrapper.StreamUserReviews)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/users/setIsActive", wrapper.SetUserIsActive)
+	})
+	r.Group(func(r chi.Router) {
+		r.Post(options.BaseURL+"/users/setRole", wrapper.SetUserRole)
+	})
+
+	return r
+}
+
+// Base64 encoded, gzipped, json marshaled Swagger object
+var swaggerSpec = []string{
+
+	"H4sIAAAAAAAC/+x9e2/bRrb4Vxnw9wPWwaVt2Ul2b9W/FFtp1Tq2V1b6igKFlsYxW4lUSSqJNzAQx+12",
+	"e5ONbxe92OICu223f+y/imM3imM7X2H4jS7OmSE5w4detpukDdAilkTOnDlzXnNec1er2622bVHLc7X8",
+	"XW2dGg3q4J/LnWazTD/vUNcrVoyb8FWDunXHbHumbWl5jf2N7fv3/C3W83fIclkn/j3W9bf8+2zfv0/Y",
+	"C/8e6xF2wLrsZ7bHjtkhYT32lB2yfXaE//dYb4qw72EUts/2WBde9Lf8HcJ2SWlt8orh1denNF1z6+u0",
+	"ZQAE9I7Rajeplteq2vmqpumat9GGj67nmNZNbXNzU9fahmO0qCfWMddxXNtJgm/RO16tjj8iYBzifbbn",
+	"P2B7/rb/NdtnzwisB9YF4Pp/9h9MEfa//jYu+9i/R3Al8N4xe4rL/4od+Q/YMwJL34MRECG7/jYixb/P",
+	"jtlz/yE7YMfE3+KfnxH2M9uvWnxI/z7r4Xi78BB7psNz8Cc8iz88I+yx/4A9J2wXQAXY3ib+F6wHIwO0",
+	"/gOCWP4Z1hFbAeuyQ9bjvx/5O/59/yEugvhb7DmuHiGdqlqarpmAp8871NnQdM0yWoBojjFlU+JboGul",
+	"Bm21bY9a9Y336UYK6XwPC+QrIuwp63Ic+lusK9CyD+TyM9uHpT/3H/lfcQLaxd/8rwT97JPSfPHK8lKl",
+	"uDj3ca1SWSBsF7aC7eJmfM1JiiBmvxRIgDfFHgFOd+GJqjWBUDyB7UHcH7MDIq1iskzbTWODNvLEczr0",
+	"HO4voA334AW+duTvcMo/Zi+Qprv+n1nPf8QXeMSHnSLsXzCvujjc4z3/nr/NnrAeO6xaiILnuOhJcmF2",
+	"Vic4ywHrEjH6Ll+DhLwUYJCbJsmF3FsKpt4vflwrLdaurhSnCPtngAX/Abl4505IDiHK/B3/ER9KIgsu",
+	"KSK6kJEFey4TSMu4s0Ctm966lp+9eFFPI5g1ZPYkpYDoyWTPJ+xY2kSOmmOyXJ4i7H+QnntkuRwQlP8l",
+	"/uzfk8UQ8IxA0cwsWS4X55YW50uV0tJi7XKhtFCcn6pa7Bu2z55yTEcEgjsBHHrkfwEzsQP/vr/tP/K/",
+	"RrqMizp/J9i3QF4AzPci1ALagfj53vIndQKT7IdLqVpsF54gSN/77BBeE5wcyc4XrIv79hwZGqTNETvW",
+	"UzkDXwDimFtavLxQmqv022AhkEeUx7q2YLZML0UG/MC6HEf+vYSYzRA+TRxKBqBB14xO09Pyszkd6Mxs",
+	"dVpafiYHn0xLfAqhMi2P3qQOglWhRmvRaNE/4hRJ8H5C8gD1JVDIdxVxDvt+iNDuZcLqUaNVw791zaGf",
+	"d0yHNrQ8SI/+svOqS51SIwuq71CrHKGW+ILDF4jRF0K3PEXi6nIJ4u9kgNdxqVMzGyMBtwkPu23bcimq",
+	"1ktGY8F0vRDWum151MK9Ntrtplk3AOzpT13bUojlrkYdh+vkut2AKS4V5mvl4h+vFlcqmq41qGeYTVfL",
+	"X7urrZm02ZD1Tou6rnEzUkXktuES03U7tEHWbIcYpGGurVGHWh5xbQfIxenArJpt0Zq9pm1elwdxuIlD",
+	"bhlNs4HwkjXDbNIGbkaEi//v0DUtr/2/6chgmua/utNFWE1ZYAbfk5ffduzVJm39R4CG4cZc5m/NC1Qg",
+	"8mO08A+2j4bBPRQGB/591Gv7BLlEx8XrJLJwQIaoZoK2qWuXbWfVbDSodbINvLxUvlSany8uKju0Fgye",
+	"J0ajZVrEdgjyRZMaDWKv4QdS1VaN+mfUalQ1EhLj+NhPR9QeaEj/vmCXr4ChuUrpsl0tYbKUrKsuPRlG",
+	"0vWtlkZ7t01vnXjrpktiWpSYLnE9s9kkpkXajn3Toa57qqj5u2Q+wH9/BcnCDhOAsH3/a654UiyMJP7K",
+	"tOPSBsfGaSGwXLy6UpxXEBiHEkSB0XSo0dggAADHrCwRBMpPE4dxIPxtbtv1/K24TGZHcUtPtX6P2SHg",
+	"ctH2Ltsd64T4W1yq1C4vXV1UUdbuNJsBFohle2QNZzpNmvoBTBZ+SBJG8hHrsmdcc8H6lm5Rp2kbjZNS",
+	"yNIHxfLCUmE+RhUudW5RB3jHjiYC1eU5G6RpeNTR8lan2TzNNf8Y2W2RofcE9/lnWLYes9TBaj1AUxIN",
+	"RDgsgi3LHgvzLDQaDwj7hn2r6fLRvAwrmSys4UruJhV2ZOYApMsOrdtWwwRQL3PVdiKsp1jJ2RQGHNmy",
+	"G+aaSRs6WaNefZ2YHjFuGqZFDKtB+K4ICUiJRW8TdDec5uaojorogMN2wVhmz/mpMzBwdf4M7hzXEnw7",
+	"/a9YjysQ/yFQcdnwKNq2J0VouVAp1hZKV0qVGCY92yYtw9oIsOmeNRV/j2S76z/w/8Kp8rH/iO0BBfv3",
+	"FQIG3OHplfWEOdolE+jNOIDPrBsYHKXlc31pNwbAvwXnPIX9OVCcJWjibIPJDWee42hzQk8C63F3hgSn",
+	"pvfjDl37aDLcxclhzikyQmDJqx3H9c6NMkuZtgzTAns6OdOPypKT+BYWDIgNfwtWuqsCtD8aIC71xt8C",
+	"hTJ2ESAOHHd09KLTKBoLHHb/of+oP4x4ArKMjrduO+afTspZVxcLVyvvLpVLn8Q4C619skoNhzrEsz+j",
+	"1tlYnv8KOEIowheSs/Mo4BH4SXYWsp7w/gh1+UF4NClG6zu7k1aLtlap416buT4VHRIj1InvSFXrzFQ1",
+	"YsYsrtUNEgyQux6dvTqW+XmHapt65jziqBxNlPI7zBbuUzh2tHO/lpPdv7jzLySQwKVzyPYDB6/qNBXU",
+	"hZ//It6JvEzAtVOE/Y2I7VZVGnf8HsGg6F2qWiDAwXThWiBwXh0HLoYddqiTYFLFpR24JsFZAq6kCNEo",
+	"+wuua960WtTyVjyDBxzajt2mjmdyh8LqRq2NxGp6tOUORGQUoSjTWya9TR13zu5YHjCNkCyG4xgb8Hl1",
+	"owZUNPTg4IaJ4M0YdlP2nlwL59DFQq6Hz9urn9I6DnAJTIw5hxoeleB3xb9JlITADgV1YmDxD8zcMu6U",
+	"+BgXhXtMfJxJYquFwqL/XLiSK/BgHA8c1MzVw7Rl6naaKcttO4OmlRYHI4K1WROsDqIq6bbSNdczvI6b",
+	"1HVz5WKhUpzXyZVi+Z3iPJkk/l+RGbhP9b5y3IWv3iaFhXKxMP9xLXwDHMziwMejMv4WtxW43f921Sp+",
+	"VFqprOiEq6JaeCzTSfhnyszx4IIYLPSzpL8SuDn8bWBFdsSHeOo/QI/gIzxYHPrbATtz9wcIFjKhMPIq",
+	"YJOurdmOd+7tqhW4h/l6Vf95qsM5LTYBM0Z+6xZ1btL+s5aXFhaK87VLhbn3B2xN4MaHLdAJNwzR0QNe",
+	"Wv9eAA8Y+wciRoPnry63WLjXm1rgLL4WUIWma3znNF2Lb52ma5wA4DeFIjRdOXfLbrEAiZquSSuT2ERy",
+	"AsvcFKfwkJ4zGewK4HYo6TIinyfYTZVMCcYbXuT0X3O/tYoVhIEAzfDsllnX4kqVf00mSRBDCY2vHsYT",
+	"n7DjfBRs8b8EEgEb+5jtsR47StBfgkEFEerpdMZZMPCYTVUtidjJpDKSHMxBWHXifwXULB18dOk0EIUn",
+	"jkUUBUUP8hvhtry/zb2+nOL9L1WKDzEmwZRClwLjoTmUoCa0jbjNruJ+zWi6VI+wOwpzRjGlVdtuUsMa",
+	"Qz9h3KLT9NzU410MP2gIiVihv8P2AKrY7vPjmGKCafpwGjquAQfZFLhSPURttJI0jshU/8mtwhNWlsIc",
+	"Rqkqz/Cg0t2RJVlyEF0CLW2JqkU+jgfUoa7dceo05v1U8RMOpX5dF3QXcE6lWLhSC9XEcln5W9EIhZWV",
+	"0juL4mNtrrA4X5ovVIoxfRE7sGaoD/UMl+6PWy58DK7RWmVpqbZQKL9TRJ1VeXdpHtVYYWFh6UN8MNPN",
+	"nhnAiPmrFC9sabFSLC8WFlLFR3javJuM0Aw8cKgHjT34mqiIGIr/LsPpk5+jU04JIZWkELzKD8PHZdWT",
+	"2sRHk4IpJ0vz5wK3cFLw8wWyXXBEolTHvBXWgzQGOOP12GPwLWiDrIc6lx/BwpIsFXueE34a50mYS/CF",
+	"ONMn83wQ+IfoxuZ7+YjERee+zgME8Lkn/GxJH0Sgr4MUgKSt0W/rOk2FbSUfgnBM6EF0WNccw7oJX6zZ",
+	"TssIp4InP7Ps29Zgg40jQ8zaD/W6FrfUMuX1YIk8QNamzb5I73hZ6XFKmluUHOY/ykiM0wk4own3/b5g",
+	"x8EreCp5puka/GysNmmQZpDYpJhfJAnRPwXZY+rNLsSywel7yFMdSPnyHPnDf+b+IDKa1HRCJKsttKEU",
+	"BSLbJJI3W2Qv4i9d9rxqFep12vbyJMv7w+2pdF2RdAli8ldo1CHHTcHTsCoFPC1TiCqar4+rK2UAnG94",
+	"l0J/gTn0yTsE9gKcBOJ+X13zTK+p6nNILyEBQ6Ssg38hv2Cs2h0vv9o0rM8GykXB1HzaENAQvTrfvjSu",
+	"kdg1xa5ClxFt1JzAI5UkgVCiHWFk4QjTRkVaov8lETGYh/4j4b0DY3MiNzU1e05Wchn4iLamv4lXR1Ox",
+	"UcA1CFGX1xqGRyc9s0WHYVg8wosR1BXCq1LGYhBzY7sktInGm/K0DFOFMoVSWFpGO0tAOMaxvL8xK9FY",
+	"CpHIGyJhdgD9FW9RK40I616qSP9O5AD3QuMEI0uP/Qdot+yQCaNt6gQ4v9FpUudcGtsJOGtGNuUk3qEA",
+	"Zy1k2ISjpVz8oFT8sFiWreTwu3JR+jakn3LxSmlxvliurRQXwfgrrswVFnC4NMvTbCjAmpb3+wtamhiy",
+	"m42a4M+xT0bZ78f9pAmqwW8kbOliLxW0DyCKd03Xs52NJFnguO447nVOZyky5sRmiR5ANWBRy8K0O4lz",
+	"POY5jq9FKggYNJJkOKX7vtXRBqwt26Eyojc8jushZlbCJmNYnTCjGKNWDwZJiTz3p4H4GAPAXlm3nZfm",
+	"03iZqiMNL2XK1UmmSRITaem2SMLo6Opq1Qnm2ktJMU95OBI1CiTEjKWsB+JFhr3/4k+JhQCgdtOo00Zt",
+	"daOv6carRAAtSdwNtD7bjqbOlL444Io/dmiHzq3joTSp0r/FExjP+5WVOXtGAitDJx0r+BvqVrhxEfo4",
+	"RdIOwazYIDuhJy1qirAfhWd0298CY26Sh4i5OzpwiKumRJ4ATwRuDHn6CJgw7iae4oClHaXser3jOCMa",
+	"HTI1jUADXLiMosaDB2NT6grYAzZ4xTLa7rrtjbDF/g5xxVvDb2YCtTLIY6nSEF9xfXpC/GVIO1v15GBa",
+	"Oex+kFWO1jM4j5DBjEbNtpobqTbhCvVKbqHumbdodvTdrRn4hLQKKQwxxhKjEdOWt0I9WGEmPI5Yfr+9",
+	"QRSNBxwO3xeuLDH7sgCDKp4kNMJ9ODQ5wyhX8J00Qo4qeQZCHD2qh0BkgS0mPD2a478NB2iE2vCdQbQJ",
+	"MJ+GGY5b9tLtb4Aim5w9QVaD15FCAKnzXXXH2Ot+lHemlCDTcX+qSMuTyvaKhUeEvpmv6Fd+mlXR9lB1",
+	"nHVF7keK2+ww9Yg/hviJwZ+Fh9PgDhjn5XMHQJHNHUEi3eB1pGC0z3xgDbnpSBxz+fqrbN8M2gY4atJ6",
+	"xzG9jRWAi2OiABZPBVKW0/hIpHhgSgvmb8NJIErMJ6zL9qADAJ7bRAhHnPG6os46KGrusSf+dlDGznpk",
+	"AkLTtcL8ldJirbL0fnFxJUx8R7GFydQRx617XjvY2hBc/tDlwH5/78NKIk/nvQ8rwnW8x14AkKH5+4y8",
+	"e6Uwp3YEQJje+7BSWynOlYuVKeJ2VskkESjXCb3T5mU1O+xpIEEAEVDX/QMXL4Q9FtmwIlqEWPiGfYtQ",
+	"4ICpzSyUGCaZQEtUj8obdRHE1KtWaIee05W9yKjYTT15i0R7BExX6oeiqDE/Og3YESAr01qzkW550EVb",
+	"LpPAFUQiYU5WqHPLrFMyUYHgUsVwP9PJZaPZJLO52Yuw+beo4/Jdm5nKTeXQgdqmltE2tbx2fio3dR6I",
+	"3vDWkXKn2xFXTa9GebDwW9t202svIDDey5M4dmM12EDXuyHGdqFpBmY1Rl+TCb4d56TmANKvacmTVSs9",
+	"e1IfLn8ptjnE/zMSX49DTiYu5M6f43nUIkvrZx7+ltMgq5aaeOQ/4CWagiqiRXcDatoGlQhNBYi/JZ3a",
+	"w0ySqapVtdj3EbBZiWc88+0IG46EoV6g+QMuD6YI+0HVuDyD/AF7jB1MumFnAwCDHYigJ/LQAWG7VUuh",
+	"B+7fDpI345GxHlfwXSipFKdaZJVwDmRVfK0Xtl3YFz0boio83KtDcVoWGMBMdfYjbykRdGsRfRye8XRX",
+	"Th2Q9borHhF7rtJPlJiBEWol7wM3WtkRdWNZlzMv6DwM4JYaGAFNTRbX1B4319J1WfTIdKwfC9RJCC10",
+	"yW6MWLov9Oc1xd+qdWZSQhl5re1MzuRyM6kezrxWaDSISw2nvo6FIcp4b/UZbzZjvMvmHdK0b5oWrpDn",
+	"CCo5jUPXfQzI0t9UVTwEK+O9EWZzudHwGiZP8tBnCvRSFuO1wLGZFm++pnUAQ53zYFMkNkkK/Gogxydn",
+	"cpOzFyozs/lcLp/LfSKHH7HA8ET7GnnLuZN8s/9o4cNBbBDooh8ZhC8kUrQ3r4+22/0LIyXhzmt0If2K",
+	"J6jqKTm2/gMukeJMzuu7L+RyWSCFNDQdr/rC92YGv6dUz+FL5we/FHWBwDfeGvxGWr8EeHd2dtR3Ra8A",
+	"fHmIieWy201duzjM8qSCc9mqRj6S7elr14HeJIv12nWQJG6n1TKcDX5QhSY6e7wKWFb6otFQQkcFnarY",
+	"YXpiB1dcmOYdVGaIIf1tTdc84yZytCL+r8MiktYUJpOdvjGldlMaocokzU6qWmdjKCXS8yVrSPQAi3Sv",
+	"VEYzhZ3rYt2aIosupa4l2bJBly2clEX1Nxer1oTcgemcVPSXvYKoYRNhP+G0atmRmGW5nDRMkpaIWsGS",
+	"aYckqkpeqhmSLES5Jmsl/Ou8NqIOyCycOVOFz6sjAo0fFmMoyn4YnSlXFfXTm+fll94ozFNTmMNtvcgN",
+	"tdaaZh2fjbTLcllogvEavD1VevmgyOkFolBpkQAnd6PZSa2ZkMoMhmjpQeq2hTFOy2tu6KKZB/TxcGTG",
+	"+UxqqBSt9pR6D/VZy1m2YDrFLgEn2fZQWzw9rUZOv3YL7nues4BO0qQNJ9wTYZrhkPZX/SwcWd2+bqyE",
+	"kk4cV1+qfj5d58CQrJZd/j6M/p4Z0QJ5PQ/gQyMzLUMxXXr5W8GpiB2p3X6CztFp84jHpuONpjc3XweN",
+	"f+EsmrNB2OKMmrL9dyBBpmWBE7YOkTq0+Q/Gs2l+lVoejhLFO6aIHSqmmmj74G+prmMAuR+4cpFoBOFy",
+	"mZiNsJMO5TOetpXRB+I3dsTJPEEiTiD0NDuMR2HVkIawPKDn32yGWygMxaabBsObJutROcBN3vBLNRve",
+	"oV5K8UDCdEjrIZyWyD1sL+HrYx2fR9ZcwXrSezbKuZRohcfL/vHjDtsN/HiCtF8L5dT/jbDH6OvBbd/5",
+	"W+EJdiee6bxcJhNpIcOgsJgdi5SHbvC9/1dkS+z9yMOZOk8/Pjc8Y7XOwN3aTZr1cZ/Uia16ffAbohv/",
+	"aTroJJt1aJ2WVR19Ft64X8Sajz1x/kL+4u8/0U7LvheH1rOw8MHzviU6zoBAxMtbolPyb8fiH0GovnEK",
+	"vnEK/mqcgjNDGPMpjaZ/g/7EuBORTLAeHqwPRYUOj2yAobITvytoZwTzwxGlb2fndAya1arZ1kCJEFuM",
+	"H1v6eCajKj2uVF8XE0apnORWwClYNfGCzV/cmoH4X+fiq5kcpFRfApRjoLVvWDJJ0sFVYvFum+z4jWXz",
+	"xrJ5Y9kEjlDO+LFF/UNsyVNp64KwmugBFR0T+vlEw4ciYOuGBb7wQNER2wpql5fLHCTLnjOsBvANTcIF",
+	"tCAnKvHuOuxAeL573HcHxNIPtFizvgg6yya8LIoImYUp8/UAHsCfxyvEEFCvIIRxDNDvs2ucwpbCcXGV",
+	"VevUZxFKA0KZHkTWv+li3CEs1PZsTh4c06d6F5PS6Q8WuRdUkQdbFPQlFt2FM0T2G8t0oGWaxJzwPGON",
+	"DzvgVS/sSNQTJBsZiH5uwYVF/DHumxbXL8avwhvOenUld3RfoxW86mH5DEGa2AMJE2oOZX7FxO2JAhzo",
+	"Ha3eu4r3VTxK2qlwmV3/BLc0L3jY1ilikeH7gqQPqbT+yL4rMP3lgKeHeD3e8k8NDMrVJcvlIa45HGWu",
+	"H6OQsbhIgN81+hAkA5lgu2GZVy+QiUCn5zLACNojrTl2S4FkmF4RA8CLQcY7mY0HnmefBnCgM/ZE9Z64",
+	"E5bz+G7g4caaNrjCZdv/StxVw2+kiqr+MiAdZitTuYBXKKTczCm3rop6TitfTiqfkG4nzUYwuK5N4r/p",
+	"/DPgIIjid5gTY1Ayev2kZy+pQOZ1yw25Hqsz1ujGe38qfWqbH59/r/nxR+XmJ5ffWm/MldyS9bG5ZJY2",
+	"rsyXZhYqxdsLlVLug/nixtKnhdvw/4dmyS21PjOXzPdu183cnSvzhTslK6eN45rGguDU+J16sTXaKkOe",
+	"tpSbS3/Bo9brENbmRbdwAbaoaZDuDA2uD+d30oA4YUcigsa7vPZR/0Br7rQRlpe6/ULS8atiTsqU4maZ",
+	"AanUiV5ms4Cd8OqYa8lOBuelOnDg7BGSqONLzCBxkUkAB9yeLOtl58Wz8Wn41afIf4tGvMepKxdp4wnL",
+	"FRyYPRJecHTcp/pmuSwRLt8LTrFg20wbjcZYLlZRp/ssZqJm5W1iN5GXmbAZ9sy5prQl4XkcEo3PyJ1C",
+	"8lqhadb5vV79XppVX7pkryKwUn8TrW1scKkwNANVwuP1KWd2Bt1fXjZKxLXI2uZIKOl7/FZNfDlxk3V/",
+	"lZUWv1JnHJBJWl5ifIPHy1FUr7WIIA6J80wzFYdfw5usxRPWr8qqaRsxx/tHYI8Dbm+gwTcRSQr/G//+",
+	"NKRfiR6Yz4Mix9RuJmAWykFNEFCKehUGYJYdOJZahJcWjRYV5v2JT3SvjB4YXTPGr1Bgj/3/wmtc78dd",
+	"d2/yGl9W9gAv8u2NxJF9WCr7XAVHXv50gqMGe5iCviq9RPLAK+RqEkMGTqbXxn00PFsP7Q2Rd+kX9ou8",
+	"Xl4OGVHZDKfzBMh7eBjnN+seiK5LEm9ksCXI9/5seRWfGCreMLTHPe3lSBOlvBx2nhzK5xxeyvwqCQCz",
+	"IbH/6+hIHk4ShN0mh5IEmZbZG3/p6UqSTESP7UflckGSImAw8zy6YUOoehRA7ZcCmNmMMEoHTM1USDq0",
+	"3qGe1M9zZOsd3i01AlL7xWKuYwiVzBjWaxKpUmI9yWjS6Xd5Sws4qe5zfQQBKDeMHS4+9LvQ6ZtG6q+8",
+	"NPwNnH+Wy7/DK7yfQMr0kB2g+2REZYtRHupxp13PEd7eV0GWYnPOv4dXq3QxQZz7tfxtEtcAU0Tk+Ozi",
+	"9c9KVdw+uRHcDnFDuvQam1NBq068kXpfwlvVyoBqh0ykXFQBHbn2+O3TmBdxI4iM3dDJjeiOjxug427w",
+	"mPUNYS3zPlX7VSvWdguiOROJS0+gYet36nMCk7y/WnQ5BiRk4K2oIjn9RdhE7Jke3Oj4JTsOE4jCxmt8",
+	"6aIzL29siv3Hd8nKSrH2brFQrlwqFio1vNz2g8KCMhhPOuUEELYtgp/ZM3IjT9ap4Xir1PBu8M39Se4i",
+	"/IQdK7sGiRvo4PQfkhtm4wZsMO88HGTB8b7IQe7JvijE8re4C/QJEis/m0DrtBsLhutN4u1dk6X5G9Id",
+	"IVWL0xWC8Zz9LN+WGWVuSr1m42AKm38LITgULoLgazH2C2gvhqDsvp3VQ9Vs8MtkQAKwIw6GBCfOq0wD",
+	"19GooEjwKuDA7TTQwlrNCQ7yU/ejZe2Jxmk9fGVfr1opl4HKicmE38N7EL9nJaPS4zjax+DS9iOxS/sq",
+	"qfbULeLtdu+pd8fr8atKU0kj2k9/iyhkkNbgbQWl4GlabaoYNRvJS2GfRBc8bwcXYKYSW3B65En2kcWm",
+	"rCo9ryq46K9lWmYL7LeUu0eHsKM8esebxovqJiN9IV+/6jkbeXI+l8sBj5uNPLkwU7XwhXx4SU/Vahie",
+	"kSd3q4HNU9XyVa0zW9X0qmp4VQHj1bilhY8LWyvxDlpb+ERkb+FDof3GZ+NvcjMMvwFDrAp5CyHosyHo",
+	"gRQfHvSqlu8L+GwfwMP2vaPArVfla5bwF544NTOZm6nM5ETiVFXD9UnyuGopJBO319NqR2SZJJWfJ6nj",
+	"jS/9JdmSYoNSDIvMG7FIttkD/e+pM7lCLY+gnHHP9bUp3ehGqVOpyssALHniXuEn7nDyl9ofPIp1icaW",
+	"453wUq7nOotKueAukyTYaRG4gZG7EQ+wfYLg/5QirN/ITWlTTw7ar7fO7E0f6hRZ95NiR/Kzs/8FHoee",
+	"ELQaQTftwu/4ZC/b6zFAogU37vWXZmSSZF6AEp2Zpwj7Vq7IAlNPLs3AITLFG0LyMkUbv+ROuWtQif6P",
+	"Itvka/7OQq6dPqx9ZFV4k048QbP7Ri79tuTSPxK1Zv69gDjSBdCjNAE0BhBijLvB6ZDHXzf18As+uPSF",
+	"kqMufS/SsaMv3qVG04NeApv/NwDRMYNsMK0AAA==",
+}
+
+// GetSwagger returns the content of the embedded swagger specification file
+// or error if failed to decode
+func decodeSpec() ([]byte, error) {
+	zipped, err := base64.StdEncoding.DecodeString(strings.Join(swaggerSpec, ""))
+	if err != nil {
+		return nil, fmt.Errorf("error base64 decoding spec: %w", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(zipped))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %w", err)
+	}
+	var buf bytes.Buffer
+	_, err = buf.ReadFrom(zr)
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing spec: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+var rawSpec = decodeSpecCached()
+
+// a naive cached of a decoded swagger spec
+func decodeSpecCached() func() ([]byte, error) {
+	data, err := decodeSpec()
+	return func() ([]byte, error) {
+		return data, err
+	}
+}
+
+// Constructs a synthetic filesystem for resolving external references when loading openapi specifications.
+func PathToRawSpec(pathToFile string) map[string]func() ([]byte, error) {
+	res := make(map[string]func() ([]byte, error))
+	if len(pathToFile) > 0 {
+		res[pathToFile] = rawSpec
+	}
+
+	return res
+}
+
+// GetSwagger returns the Swagger specification corresponding to the generated code
+// in this file. The external references of Swagger specification are resolved.
+// The logic of resolving external references is tightly connected to "import-mapping" feature.
+// Externally referenced files must be embedded in the corresponding golang packages.
+// Urls can be supported but this task was out of the scope.
+func GetSwagger() (swagger *openapi3.T, err error) {
+	resolvePath := PathToRawSpec("")
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	loader.ReadFromURIFunc = func(loader *openapi3.Loader, url *url.URL) ([]byte, error) {
+		pathToFile := url.String()
+		pathToFile = path.Clean(pathToFile)
+		getSpec, ok := resolvePath[pathToFile]
+		if !ok {
+			err1 := fmt.Errorf("path not found: %s", pathToFile)
+			return nil, err1
+		}
+		return getSpec()
+	}
+	var specData []byte
+	specData, err = rawSpec()
+	if err != nil {
+		return
+	}
+	swagger, err = loader.LoadFromData(specData)
+	if err != nil {
+		return
+	}
+	return
+}
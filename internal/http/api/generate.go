@@ -0,0 +1,6 @@
+// Package api модели и серверный интерфейс v1, сгенерированные из openapi.yml.
+// Спецификация - источник правды: сначала меняется openapi.yml, затем
+// go generate ./... и только потом обработчики в internal/http.
+package api
+
+//go:generate go run github.com/oapi-codegen/oapi-codegen/v2/cmd/oapi-codegen@v2.5.1 -config oapi-codegen.yaml ../../../openapi.yml
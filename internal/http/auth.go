@@ -0,0 +1,56 @@
+package http
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/auth"
+	"github.com/Olzerq/avito-pr-reviewer/internal/logging"
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+)
+
+// Authenticate проверяет bearer токен и кладет личность вызывающего в контекст,
+// права на операции проверяют сервисы.
+// Если a == nil, аутентификация выключена и все запросы выполняются с правами администратора.
+func Authenticate(a *auth.Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if a == nil {
+				ctx := auth.WithIdentity(r.Context(), auth.Identity{Subject: service.ActorAPI, Role: model.RoleAdmin})
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			id, err := a.Authenticate(r.Context(), bearerToken(r))
+			if err != nil && !errors.Is(err, auth.ErrNoToken) && !errors.Is(err, auth.ErrInvalidToken) {
+				// не удалось получить роль из БД
+				writeInternalError(w, r, err)
+				return
+			}
+			if err != nil {
+				logging.FromContext(r.Context()).Info("authentication failed", slog.String("error", err.Error()))
+				w.Header().Set("WWW-Authenticate", `Bearer realm="avito-pr-reviewer"`)
+				writeError(w, r, http.StatusUnauthorized, "UNAUTHORIZED", "valid bearer token required")
+				return
+			}
+
+			logging.AddAttrs(r.Context(), slog.String("subject", id.Subject), slog.String("role", id.Role))
+
+			// в истории PR будет видно, кто выполнил действие
+			ctx := auth.WithIdentity(r.Context(), id)
+			ctx = service.WithActor(ctx, id.Subject)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,238 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/logging"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+)
+
+// Все ошибки API отдаются в одном формате ErrorResponse. Клиент, приславший
+// Accept: application/problem+json, получает то же самое в виде RFC 7807.
+
+const problemContentType = "application/problem+json"
+
+// Правила в ошибках полей, которые проверяет HTTP слой, остальные см. service.Rule*
+const (
+	ruleType    = "type"    // в JSON значение не того типа
+	ruleUnknown = "unknown" // в JSON поле, которого нет в схеме запроса
+)
+
+type ErrorBody struct {
+	Code      string           `json:"code"`
+	Message   string           `json:"message"`
+	RequestID string           `json:"request_id,omitempty"`
+	Details   []FieldErrorBody `json:"details,omitempty"`
+}
+
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// FieldErrorBody нарушение в одном поле запроса
+type FieldErrorBody struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// problemResponse ошибка в формате RFC 7807, code и details - расширения
+type problemResponse struct {
+	Type      string           `json:"type"`
+	Title     string           `json:"title"`
+	Status    int              `json:"status"`
+	Detail    string           `json:"detail"`
+	Code      string           `json:"code"`
+	RequestID string           `json:"request_id,omitempty"`
+	Errors    []FieldErrorBody `json:"errors,omitempty"`
+}
+
+// APIError ошибка, готовая к отправке клиенту
+type APIError struct {
+	Status  int
+	Code    string
+	Message string
+	Details []FieldErrorBody
+}
+
+func (e *APIError) Error() string {
+	return e.Code + ": " + e.Message
+}
+
+// domainErrors статусы и коды доменных ошибок. Сообщение берется из ошибки,
+// если message не задан.
+var domainErrors = []struct {
+	err     error
+	status  int
+	code    string
+	message string
+}{
+	{service.ErrForbidden, http.StatusForbidden, "FORBIDDEN", ""},
+	{repository.ErrUserNotFound, http.StatusNotFound, "NOT_FOUND", ""},
+	{repository.ErrTeamNotFound, http.StatusNotFound, "NOT_FOUND", ""},
+	{repository.ErrPRNotFound, http.StatusNotFound, "NOT_FOUND", ""},
+	{repository.ErrTeamExists, http.StatusConflict, "TEAM_EXISTS", "team_name already exists"},
+	{repository.ErrPRExists, http.StatusConflict, "PR_EXISTS", "PR id already exists"},
+	{service.ErrPRMerged, http.StatusConflict, "PR_MERGED", "cannot reassign on merged PR"},
+	{repository.ErrReviewerNotAssigned, http.StatusConflict, "NOT_ASSIGNED", "reviewer is not assigned to this PR"},
+	{service.ErrNoCandidate, http.StatusConflict, "NO_CANDIDATE", "no active replacement candidate in team"},
+	{service.ErrPRConflict, http.StatusConflict, "CONFLICT", ""},
+	{service.ErrPreconditionFailed, http.StatusPreconditionFailed, "PRECONDITION_FAILED",
+		"pull request was modified, fetch it again and retry with the new ETag"},
+}
+
+// toAPIError переводит ошибку в ответ клиенту, nil - ошибка внутренняя
+func toAPIError(err error) *APIError {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	var verr *service.ValidationError
+	if errors.As(err, &verr) {
+		details := make([]FieldErrorBody, 0, len(verr.Fields))
+		for _, f := range verr.Fields {
+			details = append(details, FieldErrorBody{Field: f.Field, Rule: f.Rule, Message: f.Message})
+		}
+		return &APIError{Status: http.StatusBadRequest, Code: "BAD_REQUEST", Message: "request validation failed", Details: details}
+	}
+
+	for _, d := range domainErrors {
+		if errors.Is(err, d.err) {
+			msg := d.message
+			if msg == "" {
+				msg = err.Error()
+			}
+			return &APIError{Status: d.status, Code: d.code, Message: msg}
+		}
+	}
+	return nil
+}
+
+// writeServiceError отдает ошибку сервиса клиенту, неизвестные ошибки - 500 с записью в лог
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	if apiErr := toAPIError(err); apiErr != nil {
+		writeAPIError(w, r, apiErr)
+		return
+	}
+	writeInternalError(w, r, err)
+}
+
+// writeInternalError логирует ошибку с контекстом запроса, а клиенту отдает общее сообщение и id запроса
+func writeInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	logging.FromContext(r.Context()).Error("internal error",
+		slog.String("method", r.Method),
+		slog.String("route", routePattern(r)),
+		slog.String("error", err.Error()),
+	)
+
+	writeAPIError(w, r, &APIError{
+		Status:  http.StatusInternalServerError,
+		Code:    "INTERNAL",
+		Message: "internal server error",
+	})
+}
+
+// writeError отдает ошибку в общем формате ErrorResponse
+func writeError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	writeAPIError(w, r, &APIError{Status: status, Code: code, Message: message})
+}
+
+func writeAPIError(w http.ResponseWriter, r *http.Request, e *APIError) {
+	// id запроса нужен, чтобы найти запись в логах, для 4xx причина и так в сообщении
+	var requestID string
+	if e.Status >= http.StatusInternalServerError {
+		requestID = logging.RequestIDFromContext(r.Context())
+	}
+
+	if wantsProblem(r) {
+		w.Header().Set("Content-Type", problemContentType)
+		w.WriteHeader(e.Status)
+		_ = json.NewEncoder(w).Encode(problemResponse{
+			Type:      "about:blank",
+			Title:     http.StatusText(e.Status),
+			Status:    e.Status,
+			Detail:    e.Message,
+			Code:      e.Code,
+			RequestID: requestID,
+			Errors:    e.Details,
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.Status)
+	_ = json.NewEncoder(w).Encode(ErrorResponse{
+		Error: ErrorBody{
+			Code:      e.Code,
+			Message:   e.Message,
+			RequestID: requestID,
+			Details:   e.Details,
+		},
+	})
+}
+
+// wantsProblem клиент явно просит RFC 7807
+func wantsProblem(r *http.Request) bool {
+	for _, v := range r.Header.Values("Accept") {
+		for _, mt := range strings.Split(v, ",") {
+			mt, _, _ = strings.Cut(mt, ";")
+			if strings.EqualFold(strings.TrimSpace(mt), problemContentType) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// decodeJSON читает тело запроса. strict запрещает неизвестные поля, чтобы опечатки
+// в именах не терялись молча. Ошибки типов полей возвращаются как ошибки валидации.
+func decodeJSON(w http.ResponseWriter, r *http.Request, dst any, strict bool) error {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	if strict {
+		dec.DisallowUnknownFields()
+	}
+	err := dec.Decode(dst)
+	if err == nil {
+		return nil
+	}
+
+	var (
+		typeErr *json.UnmarshalTypeError
+		sizeErr *http.MaxBytesError
+		v       service.ValidationError
+	)
+	switch {
+	case errors.Is(err, io.EOF):
+		return &APIError{Status: http.StatusBadRequest, Code: "BAD_REQUEST", Message: "request body is required"}
+	case errors.As(err, &sizeErr):
+		return &APIError{Status: http.StatusRequestEntityTooLarge, Code: "PAYLOAD_TOO_LARGE", Message: "request body is too large"}
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		v.Add(typeErr.Field, ruleType, typeErr.Field+" must be of type "+typeErr.Type.String())
+		return &v
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		v.Add(field, ruleUnknown, "unknown field "+field)
+		return &v
+	default:
+		return &APIError{Status: http.StatusBadRequest, Code: "BAD_REQUEST", Message: "invalid json"}
+	}
+}
+
+// maxRequestBody ограничение тела запроса
+const maxRequestBody = 1 << 20
+
+// NotFound и MethodNotAllowed отвечают на неизвестные маршруты в общем формате
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusNotFound, "NOT_FOUND", "route not found")
+}
+
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "method not allowed")
+}
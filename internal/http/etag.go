@@ -0,0 +1,37 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+)
+
+// prETag версия PR в виде сильного ETag
+func prETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// withIfMatch переносит If-Match в контекст сервиса. ok=false, если заголовок не
+// может совпасть ни с одной версией PR, тогда клиент сразу получает 412.
+func withIfMatch(r *http.Request) (*http.Request, bool) {
+	h := strings.TrimSpace(r.Header.Get("If-Match"))
+	if h == "" || h == "*" {
+		return r, true
+	}
+	// слабые ETag для If-Match не подходят, список версий не поддерживаем
+	if !strings.HasPrefix(h, `"`) || !strings.HasSuffix(h, `"`) || len(h) < 2 {
+		return r, false
+	}
+	v, err := strconv.ParseInt(h[1:len(h)-1], 10, 64)
+	if err != nil {
+		return r, false
+	}
+	return r.WithContext(service.WithExpectedVersion(r.Context(), v)), true
+}
+
+func writePreconditionFailed(w http.ResponseWriter, r *http.Request) {
+	writeError(w, r, http.StatusPreconditionFailed, "PRECONDITION_FAILED",
+		"pull request was modified, fetch it again and retry with the new ETag")
+}
@@ -0,0 +1,136 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HealthCheck проверка готовности. Провал критичной проверки переводит /readyz в 503.
+type HealthCheck struct {
+	Name     string
+	Critical bool
+	Check    func(ctx context.Context) error
+}
+
+// HealthHandler отдает состояние сервиса, при остановке сервис перестает быть готовым
+type HealthHandler struct {
+	ready   atomic.Bool
+	checks  []HealthCheck
+	timeout time.Duration
+}
+
+func NewHealthHandler(timeout time.Duration, checks ...HealthCheck) *HealthHandler {
+	return &HealthHandler{
+		checks:  checks,
+		timeout: timeout,
+	}
+}
+
+// SetReady переключает готовность принимать трафик
+func (h *HealthHandler) SetReady(ready bool) {
+	h.ready.Store(ready)
+}
+
+// GET /health
+func (h *HealthHandler) Health(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if !h.ready.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_ = json.NewEncoder(w).Encode(map[string]any{"status": "shutting_down"})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+}
+
+// GET /livez - процесс жив, зависимости не проверяются
+func (h *HealthHandler) Livez(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(map[string]any{"status": "ok"})
+}
+
+type checkResult struct {
+	Status    string  `json:"status"` // ok | fail
+	Critical  bool    `json:"critical"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type readyResponse struct {
+	Status string                 `json:"status"` // ok | degraded | fail
+	Checks map[string]checkResult `json:"checks"`
+}
+
+// GET /readyz - можно ли слать трафик на реплику
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), h.timeout)
+	defer cancel()
+
+	resp := readyResponse{
+		Status: "ok",
+		Checks: make(map[string]checkResult, len(h.checks)+1),
+	}
+
+	shutdown := checkResult{Status: "ok", Critical: true}
+	if !h.ready.Load() {
+		shutdown.Status = "fail"
+		shutdown.Error = "server is shutting down"
+	}
+	resp.Checks["shutdown"] = shutdown
+
+	// проверки независимы, запускаем параллельно
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, c := range h.checks {
+		wg.Add(1)
+		go func(c HealthCheck) {
+			defer wg.Done()
+
+			start := time.Now()
+			err := c.Check(ctx)
+			res := checkResult{
+				Status:    "ok",
+				Critical:  c.Critical,
+				LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+			}
+			if err != nil {
+				res.Status = "fail"
+				res.Error = err.Error()
+			}
+
+			mu.Lock()
+			resp.Checks[c.Name] = res
+			mu.Unlock()
+		}(c)
+	}
+	wg.Wait()
+
+	for _, res := range resp.Checks {
+		if res.Status == "ok" {
+			continue
+		}
+		if res.Critical {
+			resp.Status = "fail"
+			break
+		}
+		resp.Status = "degraded"
+	}
+
+	status := http.StatusOK
+	if resp.Status == "fail" {
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,172 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/auth"
+	"github.com/Olzerq/avito-pr-reviewer/internal/logging"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"github.com/go-chi/chi/v5/middleware"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotencyReplayedHeader = "Idempotency-Replayed"
+
+	maxIdempotencyKeyLen = 255
+	maxIdempotentBody    = 1 << 20
+)
+
+// IdempotencyStore хранилище ключей идемпотентности
+type IdempotencyStore interface {
+	Claim(ctx context.Context, scope, key string, rec repository.IdempotencyRecord, ttl, lockTimeout time.Duration) (bool, repository.IdempotencyRecord, error)
+	Complete(ctx context.Context, scope, key string, resp repository.IdempotencyRecord) error
+	Release(ctx context.Context, scope, key string, lockedAt time.Time) error
+}
+
+// Idempotency для POST запросов с заголовком Idempotency-Key сохраняет ответ
+// и на повтор в течение ttl отдает его же, не выполняя запрос заново.
+// Ключи разделены по вызывающему, поэтому middleware ставится после Authenticate.
+// Запрос, не завершившийся за lockTimeout (например, процесс упал), больше не
+// держит ключ: повтор с тем же телом выполняется заново.
+func Idempotency(store IdempotencyStore, ttl, lockTimeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := r.Header.Get(IdempotencyKeyHeader)
+			if r.Method != http.MethodPost || key == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLen {
+				writeError(w, r, http.StatusBadRequest, "BAD_REQUEST", "Idempotency-Key must not exceed 255 characters")
+				return
+			}
+
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBody+1))
+			if err != nil {
+				writeError(w, r, http.StatusBadRequest, "BAD_REQUEST", "failed to read request body")
+				return
+			}
+			if len(body) > maxIdempotentBody {
+				writeError(w, r, http.StatusRequestEntityTooLarge, "PAYLOAD_TOO_LARGE", "request body is too large")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			scope := idempotencyScope(r)
+			rec := repository.IdempotencyRecord{
+				Method:      r.Method,
+				Path:        r.URL.Path,
+				RequestHash: requestHash(r.Method, r.URL.RequestURI(), body),
+			}
+
+			claimed, existing, err := store.Claim(r.Context(), scope, key, rec, ttl, lockTimeout)
+			if err != nil {
+				writeInternalError(w, r, err)
+				return
+			}
+			logging.AddAttrs(r.Context(), slog.String("idempotency_key", key))
+
+			if !claimed {
+				switch {
+				case existing.RequestHash != rec.RequestHash:
+					writeError(w, r, http.StatusUnprocessableEntity, "IDEMPOTENCY_KEY_REUSED",
+						"Idempotency-Key was already used with a different request")
+				case !existing.Completed():
+					w.Header().Set("Retry-After", "1")
+					writeError(w, r, http.StatusConflict, "IDEMPOTENCY_KEY_IN_USE",
+						"request with this Idempotency-Key is still in progress")
+				default:
+					h := w.Header()
+					for name, v := range map[string]string{
+						"Content-Type": existing.ContentType,
+						"ETag":         existing.ETag,
+						"Location":     existing.Location,
+					} {
+						if v != "" {
+							h.Set(name, v)
+						}
+					}
+					h.Set(IdempotencyReplayedHeader, "true")
+					w.WriteHeader(existing.StatusCode)
+					_, _ = w.Write(existing.ResponseBody)
+				}
+				return
+			}
+
+			var buf bytes.Buffer
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			ww.Tee(&buf)
+
+			completed := false
+			defer func() {
+				if completed {
+					return
+				}
+				// ответ не сохранен, ключ освобождаем для повтора
+				ctx := context.WithoutCancel(r.Context())
+				if err := store.Release(ctx, scope, key, existing.LockedAt); err != nil {
+					logging.FromContext(ctx).Error("failed to release idempotency key", slog.Any("error", err))
+				}
+			}()
+
+			next.ServeHTTP(ww, r)
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			// на 5xx повтор может пройти успешно, поэтому такие ответы не сохраняем
+			if status >= http.StatusInternalServerError {
+				return
+			}
+
+			// ответ сохраняем даже если клиент уже отключился
+			ctx := context.WithoutCancel(r.Context())
+			h := ww.Header()
+			resp := repository.IdempotencyRecord{
+				StatusCode:   status,
+				ContentType:  h.Get("Content-Type"),
+				ETag:         h.Get("ETag"),
+				Location:     h.Get("Location"),
+				ResponseBody: buf.Bytes(),
+				LockedAt:     existing.LockedAt,
+			}
+			if err := store.Complete(ctx, scope, key, resp); err != nil {
+				logging.FromContext(ctx).Error("failed to save idempotent response", slog.Any("error", err))
+				return
+			}
+			completed = true
+		})
+	}
+}
+
+// idempotencyScope область ключей вызывающего. Пользователь JWT один во всех своих
+// токенах. Статические токены администраторов и все клиенты при выключенной
+// аутентификации действуют от одного субъекта, поэтому их ключи разделяются по
+// токену или IP, иначе разные клиенты делили бы одно пространство ключей.
+func idempotencyScope(r *http.Request) string {
+	if id, ok := auth.FromContext(r.Context()); ok && id.Subject != auth.AdminSubject && id.Subject != service.ActorAPI {
+		return "sub:" + id.Subject
+	}
+	return clientKey(r, false)
+}
+
+// requestHash отпечаток запроса, uri вместе со строкой запроса
+func requestHash(method, uri string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(uri))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
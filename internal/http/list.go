@@ -0,0 +1,50 @@
+package http
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+)
+
+// parseListQuery читает limit, sort и cursor, ошибки разбора добавляет в v
+func parseListQuery(q url.Values, v *service.ValidationError) service.ListQuery {
+	lq := service.ListQuery{Sort: q.Get("sort"), Cursor: q.Get("cursor")}
+	if s := q.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit <= 0 {
+			v.Add("limit", service.RuleFormat, "limit must be a positive integer")
+		}
+		lq.Limit = limit
+	}
+	return lq
+}
+
+// parseTimeParam время в RFC3339, пустой параметр - nil
+func parseTimeParam(q url.Values, name string, v *service.ValidationError) *time.Time {
+	s := q.Get(name)
+	if s == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		v.Add(name, service.RuleFormat, name+" must be an RFC 3339 timestamp")
+		return nil
+	}
+	return &t
+}
+
+// parseBoolParam пустой параметр - nil
+func parseBoolParam(q url.Values, name string, v *service.ValidationError) *bool {
+	s := q.Get(name)
+	if s == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		v.Add(name, service.RuleFormat, name+" must be true or false")
+		return nil
+	}
+	return &b
+}
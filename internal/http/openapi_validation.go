@@ -0,0 +1,252 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/http/api"
+	"github.com/Olzerq/avito-pr-reviewer/internal/logging"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+)
+
+// OpenAPIValidatorOptions что проверять кроме запросов
+type OpenAPIValidatorOptions struct {
+	Responses bool // ответы тоже сверяются со спецификацией, расхождения пишутся в лог
+	Strict    bool // ответ, не совпавший со спецификацией, заменяется на 500 (для тестов)
+}
+
+// OpenAPIValidator сверяет запросы v1 с openapi.yml до обработчиков. Нарушения
+// отдаются как ошибки валидации с перечнем полей. Маршруты, которых нет в
+// спецификации (v2, служебные), пропускаются без проверки.
+func OpenAPIValidator(opts OpenAPIValidatorOptions) (func(http.Handler) http.Handler, error) {
+	doc, err := api.GetSwagger()
+	if err != nil {
+		return nil, fmt.Errorf("load openapi spec: %w", err)
+	}
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("build openapi router: %w", err)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if r.Body != nil {
+				r.Body = http.MaxBytesReader(w, r.Body, maxRequestBody)
+			}
+
+			// v1 всегда читал тело как JSON независимо от Content-Type (curl -d без -H
+			// отправляет form-urlencoded), поэтому и проверяется тело как JSON
+			vr := r
+			if !isJSON(r.Header.Get("Content-Type")) {
+				vr = r.Clone(r.Context())
+				vr.Header.Set("Content-Type", "application/json")
+			}
+
+			input := &openapi3filter.RequestValidationInput{
+				Request:    vr,
+				PathParams: pathParams,
+				Route:      route,
+				Options: &openapi3filter.Options{
+					// токены проверяет Authenticate, права - сервисы
+					AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+					MultiError:         true,
+				},
+			}
+			err = openapi3filter.ValidateRequest(r.Context(), input)
+			// тело прочитано при проверке, обработчику достается его копия
+			r.Body = vr.Body
+			if err != nil {
+				writeServiceError(w, r, requestValidationError(err))
+				return
+			}
+
+			// поток событий не буферизуется: он не кончается, пока клиент подключен
+			if !opts.Responses || isEventStream(route) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			buf := newResponseBuffer()
+			next.ServeHTTP(buf, r)
+
+			if err := validateResponse(r, input, buf); err != nil {
+				if opts.Strict {
+					writeInternalError(w, r, fmt.Errorf("response does not match openapi spec: %w", err))
+					return
+				}
+				logging.FromContext(r.Context()).Warn("response does not match openapi spec",
+					slog.String("method", r.Method),
+					slog.String("route", route.Path),
+					slog.Int("status", buf.status),
+					slog.String("error", err.Error()),
+				)
+			}
+			buf.flush(w)
+		})
+	}, nil
+}
+
+// isEventStream успешный ответ операции - поток text/event-stream
+func isEventStream(route *routers.Route) bool {
+	ok := route.Operation.Responses.Status(http.StatusOK)
+	return ok != nil && ok.Value != nil && ok.Value.Content.Get("text/event-stream") != nil
+}
+
+func isJSON(contentType string) bool {
+	mt, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mt == "application/json" || strings.HasSuffix(mt, "+json"))
+}
+
+func validateResponse(r *http.Request, input *openapi3filter.RequestValidationInput, buf *responseBuffer) error {
+	// RFC 7807 - общий формат всех ошибок по запросу клиента, в спецификации он
+	// описан один раз схемой ProblemDetails, а не в каждом ответе
+	if mt, _, _ := mime.ParseMediaType(buf.header.Get("Content-Type")); mt == problemContentType {
+		return nil
+	}
+	return openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 buf.status,
+		Header:                 buf.header,
+		Body:                   buf.body(),
+		Options: &openapi3filter.Options{
+			IncludeResponseStatus: true,
+			MultiError:            true,
+		},
+	})
+}
+
+// requestValidationError переводит ошибки kin-openapi в ошибки валидации по полям
+func requestValidationError(err error) error {
+	var v service.ValidationError
+	for _, e := range unwrapMulti(err) {
+		var reqErr *openapi3filter.RequestError
+		if !errors.As(e, &reqErr) {
+			return &APIError{Status: http.StatusBadRequest, Code: "BAD_REQUEST", Message: e.Error()}
+		}
+
+		var field string
+		if reqErr.Parameter != nil {
+			field = reqErr.Parameter.Name
+		}
+
+		var (
+			parseErr *openapi3filter.ParseError
+			sizeErr  *http.MaxBytesError
+		)
+		switch {
+		case reqErr.Parameter == nil && errors.As(reqErr.Err, &sizeErr):
+			return &APIError{Status: http.StatusRequestEntityTooLarge, Code: "PAYLOAD_TOO_LARGE", Message: "request body is too large"}
+		case reqErr.Parameter == nil && errors.Is(reqErr.Err, openapi3filter.ErrInvalidRequired):
+			return &APIError{Status: http.StatusBadRequest, Code: "BAD_REQUEST", Message: "request body is required"}
+		case reqErr.Parameter == nil && errors.As(reqErr.Err, &parseErr):
+			return &APIError{Status: http.StatusBadRequest, Code: "BAD_REQUEST", Message: "invalid json"}
+		case errors.Is(reqErr.Err, openapi3filter.ErrInvalidRequired), errors.Is(reqErr.Err, openapi3filter.ErrInvalidEmptyValue):
+			v.Required(field, "")
+		case errors.As(reqErr.Err, &parseErr):
+			v.Add(field, service.RuleFormat, paramFormat(field))
+		case reqErr.Err == nil:
+			return &APIError{Status: http.StatusBadRequest, Code: "BAD_REQUEST", Message: reqErr.Error()}
+		default:
+			for _, se := range unwrapMulti(reqErr.Err) {
+				var schemaErr *openapi3.SchemaError
+				if !errors.As(se, &schemaErr) {
+					v.Add(field, service.RuleFormat, se.Error())
+					continue
+				}
+				addSchemaError(&v, field, schemaErr)
+			}
+		}
+	}
+	return v.Err()
+}
+
+// addSchemaError нарушение схемы в поле base, путь внутри значения берется из ошибки
+func addSchemaError(v *service.ValidationError, base string, e *openapi3.SchemaError) {
+	field := base
+	for _, p := range e.JSONPointer() {
+		switch {
+		case isIndex(p):
+			field += "[" + p + "]"
+		case field == "":
+			field = p
+		default:
+			field += "." + p
+		}
+	}
+
+	switch e.SchemaField {
+	case "required":
+		v.Required(field, "")
+	case "enum":
+		v.Add(field, service.RuleOneOf, field+": "+e.Reason)
+	case "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum",
+		"minLength", "maxLength", "minItems", "maxItems":
+		v.Add(field, service.RuleRange, field+": "+e.Reason)
+	case "type", "nullable":
+		v.Add(field, ruleType, field+": "+e.Reason)
+	default:
+		v.Add(field, service.RuleFormat, field+": "+e.Reason)
+	}
+}
+
+func isIndex(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+// unwrapMulti раскрывает только сам MultiError: errors.As нашел бы его и внутри
+// RequestError, и имя параметра потерялось бы
+func unwrapMulti(err error) []error {
+	if me, ok := err.(openapi3.MultiError); ok {
+		var errs []error
+		for _, e := range me {
+			errs = append(errs, unwrapMulti(e)...)
+		}
+		return errs
+	}
+	return []error{err}
+}
+
+// responseBuffer придерживает ответ до проверки по спецификации
+type responseBuffer struct {
+	header http.Header
+	status int
+	buf    bytes.Buffer
+}
+
+func newResponseBuffer() *responseBuffer {
+	return &responseBuffer{header: make(http.Header), status: http.StatusOK}
+}
+
+func (b *responseBuffer) Header() http.Header { return b.header }
+
+func (b *responseBuffer) WriteHeader(status int) { b.status = status }
+
+func (b *responseBuffer) Write(p []byte) (int, error) { return b.buf.Write(p) }
+
+func (b *responseBuffer) body() io.ReadCloser { return io.NopCloser(bytes.NewReader(b.buf.Bytes())) }
+
+func (b *responseBuffer) flush(w http.ResponseWriter) {
+	for k, vs := range b.header {
+		w.Header()[k] = vs
+	}
+	w.WriteHeader(b.status)
+	_, _ = w.Write(b.buf.Bytes())
+}
@@ -2,10 +2,11 @@ package http
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
+	"github.com/Olzerq/avito-pr-reviewer/internal/http/api"
+	"github.com/Olzerq/avito-pr-reviewer/internal/logging"
 	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
 	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"log/slog"
 	"net/http"
 	"time"
 )
@@ -18,288 +19,233 @@ func NewPullRequestHandler(prService *service.PullRequestService) *PullRequestHa
 	return &PullRequestHandler{prService: prService}
 }
 
-type prCreateRequest struct {
-	ID       string `json:"pull_request_id"`
-	Name     string `json:"pull_request_name"`
-	AuthorID string `json:"author_id"`
-}
+// POST /pullRequest/create
+func (h *PullRequestHandler) CreatePullRequest(w http.ResponseWriter, r *http.Request, _ api.CreatePullRequestParams) {
+	var req api.CreatePullRequestJSONRequestBody
+	if err := decodeJSON(w, r, &req, false); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
 
-type prReassignRequest struct {
-	PullRequestID string `json:"pull_request_id"`
-	OldUserID     string `json:"old_user_id"` // тут почему-то в example в openapi стоит другое название :(
-}
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pr, err := h.prService.Create(ctx, req.PullRequestID, req.PullRequestName, req.AuthorID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
 
-type prMergeRequest struct {
-	PullRequestID string `json:"pull_request_id"`
+	w.Header().Set("ETag", prETag(pr.Version))
+	writeJSON(w, http.StatusCreated, api.PullRequestResponse{Pr: toAPIPullRequest(pr)})
 }
 
-// POST /pullRequest/create
-func (h *PullRequestHandler) Create(w http.ResponseWriter, r *http.Request) {
-	var req prCreateRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+// POST /pullRequest/reassign
+func (h *PullRequestHandler) ReassignReviewer(w http.ResponseWriter, r *http.Request, _ api.ReassignReviewerParams) {
+	var req api.ReassignReviewerJSONRequestBody
+	if err := decodeJSON(w, r, &req, false); err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
-	defer r.Body.Close()
 
-	if req.ID == "" || req.Name == "" || req.AuthorID == "" {
-		http.Error(w, "missing required fields", http.StatusBadRequest)
+	r, ok := withIfMatch(r)
+	if !ok {
+		writePreconditionFailed(w, r)
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	pr, err := h.prService.Create(ctx, req.ID, req.Name, req.AuthorID)
+	pr, replacedBy, err := h.prService.Reassign(ctx, req.PullRequestID, req.OldUserID)
 	if err != nil {
-		switch {
-		case errors.Is(err, repository.ErrUserNotFound),
-			errors.Is(err, repository.ErrTeamNotFound):
-			// 404 Автор/команда не найдены
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusNotFound)
-			_ = json.NewEncoder(w).Encode(ErrorResponse{
-				Error: ErrorBody{
-					Code:    "NOT_FOUND",
-					Message: "resource not found",
-				},
-			})
-			return
-		case errors.Is(err, repository.ErrPRExists):
-			// 409 PR_EXISTS
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusConflict)
-			_ = json.NewEncoder(w).Encode(ErrorResponse{
-				Error: ErrorBody{
-					Code:    "PR_EXISTS",
-					Message: "PR id already exists",
-				},
-			})
-			return
-		default:
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-	}
-
-	assigned := make([]string, 0, len(pr.Reviewers))
-	for _, u := range pr.Reviewers {
-		assigned = append(assigned, u.ID)
-	}
-	resp := map[string]any{
-		"pr": map[string]any{
-			"pull_request_id":    pr.ID,
-			"pull_request_name":  pr.Name,
-			"author_id":          pr.AuthorID,
-			"status":             pr.Status,
-			"assigned_reviewers": assigned,
-			"createdAt":          nil, // эту колонку еще не сделал
-			"mergedAt":           nil,
-		},
+		writeServiceError(w, r, err)
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	_ = json.NewEncoder(w).Encode(resp)
+	w.Header().Set("ETag", prETag(pr.Version))
+	writeJSON(w, http.StatusOK, api.ReassignResponse{Pr: toAPIPullRequest(pr), ReplacedBy: replacedBy})
 }
 
-func (h *PullRequestHandler) Reassign(w http.ResponseWriter, r *http.Request) {
-	var req prReassignRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+// POST /pullRequest/merge
+func (h *PullRequestHandler) MergePullRequest(w http.ResponseWriter, r *http.Request, _ api.MergePullRequestParams) {
+	var req api.MergePullRequestJSONRequestBody
+	if err := decodeJSON(w, r, &req, false); err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
-	defer r.Body.Close()
 
-	if req.PullRequestID == "" || req.OldUserID == "" {
-		http.Error(w, "pull_request_id and old_reviewer_id are required", http.StatusBadRequest)
+	r, ok := withIfMatch(r)
+	if !ok {
+		writePreconditionFailed(w, r)
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	pr, replacedBy, err := h.prService.Reassign(ctx, req.PullRequestID, req.OldUserID)
+	pr, err := h.prService.Merge(ctx, req.PullRequestID)
 	if err != nil {
-		switch {
-		// 404
-		case errors.Is(err, repository.ErrPRNotFound),
-			errors.Is(err, repository.ErrUserNotFound):
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusNotFound)
-			_ = json.NewEncoder(w).Encode(ErrorResponse{
-				Error: ErrorBody{
-					Code:    "NOT_FOUND",
-					Message: "resource not found",
-				},
-			})
-			return
-
-		// 409
-		case errors.Is(err, service.ErrPRMerged):
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusConflict)
-			_ = json.NewEncoder(w).Encode(ErrorResponse{
-				Error: ErrorBody{
-					Code:    "PR_MERGED",
-					Message: "cannot reassign on merged PR",
-				},
-			})
-			return
-
-		// 409
-		case errors.Is(err, repository.ErrReviewerNotAssigned):
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusConflict)
-			_ = json.NewEncoder(w).Encode(ErrorResponse{
-				Error: ErrorBody{
-					Code:    "NOT_ASSIGNED",
-					Message: "reviewer is not assigned to this PR",
-				},
-			})
-			return
-
-		// 409
-		case errors.Is(err, service.ErrNoCandidate):
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusConflict)
-			_ = json.NewEncoder(w).Encode(ErrorResponse{
-				Error: ErrorBody{
-					Code:    "NO_CANDIDATE",
-					Message: "no active replacement candidate in team",
-				},
-			})
-			return
-
-		default:
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
+		writeServiceError(w, r, err)
+		return
 	}
 
-	assigned := make([]string, 0, len(pr.Reviewers))
-	for _, u := range pr.Reviewers {
-		assigned = append(assigned, u.ID)
+	w.Header().Set("ETag", prETag(pr.Version))
+	writeJSON(w, http.StatusOK, api.PullRequestResponse{Pr: toAPIPullRequest(pr)})
+}
+
+// batchTimeout время на пакетную операцию, пакет до service.MaxBatchSize PR пишется одной транзакцией
+const batchTimeout = 10 * time.Second
+
+// POST /pullRequest/batchCreate
+func (h *PullRequestHandler) BatchCreatePullRequests(w http.ResponseWriter, r *http.Request, _ api.BatchCreatePullRequestsParams) {
+	var req api.BatchCreatePullRequestsJSONRequestBody
+	if err := decodeJSON(w, r, &req, false); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if !chargeItems(w, r, len(req.Items)) {
+		return
 	}
 
-	resp := map[string]any{
-		"pr": map[string]any{
-			"pull_request_id":    pr.ID,
-			"pull_request_name":  pr.Name,
-			"author_id":          pr.AuthorID,
-			"status":             pr.Status,
-			"assigned_reviewers": assigned,
-			"createdAt":          nil,
-			"mergedAt":           pr.MergedAt,
-		},
-		"replaced_by": replacedBy,
+	items := make([]service.BatchCreateItem, 0, len(req.Items))
+	for _, it := range req.Items {
+		items = append(items, service.BatchCreateItem{ID: it.PullRequestID, Name: it.PullRequestName, AuthorID: it.AuthorID})
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(resp)
-}
+	ctx, cancel := context.WithTimeout(r.Context(), batchTimeout)
+	defer cancel()
 
-// POST /pullRequest/merge
-func (h *PullRequestHandler) Merge(w http.ResponseWriter, r *http.Request) {
-	var req prMergeRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+	res, err := h.prService.BatchCreate(ctx, items, service.BatchMode(value(req.Mode)))
+	if err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
-	defer r.Body.Close()
 
-	if req.PullRequestID == "" {
-		http.Error(w, "pull_request_id is required", http.StatusBadRequest)
+	writeJSON(w, http.StatusOK, toAPIBatchResponse(res))
+}
+
+// POST /pullRequest/batchMerge
+func (h *PullRequestHandler) BatchMergePullRequests(w http.ResponseWriter, r *http.Request, _ api.BatchMergePullRequestsParams) {
+	var req api.BatchMergePullRequestsJSONRequestBody
+	if err := decodeJSON(w, r, &req, false); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if !chargeItems(w, r, len(req.PullRequestIds)) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(r.Context(), batchTimeout)
 	defer cancel()
 
-	pr, err := h.prService.Merge(ctx, req.PullRequestID)
+	res, err := h.prService.BatchMerge(ctx, req.PullRequestIds, service.BatchMode(value(req.Mode)))
 	if err != nil {
-		if errors.Is(err, repository.ErrPRNotFound) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusNotFound)
-			_ = json.NewEncoder(w).Encode(ErrorResponse{
-				Error: ErrorBody{
-					Code:    "NOT_FOUND",
-					Message: "resource not found",
-				},
-			})
-			return
-		}
+		writeServiceError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toAPIBatchResponse(res))
+}
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+// GET /users/getReview
+func (h *PullRequestHandler) GetUserReviews(w http.ResponseWriter, r *http.Request, params api.GetUserReviewsParams) {
+	logging.AddAttrs(r.Context(), slog.String("user_id", params.UserID))
+
+	var v service.ValidationError
+	lq := listQuery(params.Limit, params.Sort, params.Cursor, &v)
+	if err := v.Err(); err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
-	assigned := make([]string, 0, len(pr.Reviewers))
-	for _, u := range pr.Reviewers {
-		assigned = append(assigned, u.ID)
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	res, err := h.prService.GetUserReviews(ctx, params.UserID, value(params.Status), lq)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
 	}
 
-	resp := map[string]any{
-		"pr": map[string]any{
-			"pull_request_id":    pr.ID,
-			"pull_request_name":  pr.Name,
-			"author_id":          pr.AuthorID,
-			"status":             pr.Status,
-			"assigned_reviewers": assigned,
-			"createdAt":          nil,
-			"mergedAt":           pr.MergedAt,
-		},
+	prs := make([]api.PullRequestShort, 0, len(res.Items))
+	for _, pr := range res.Items {
+		prs = append(prs, toAPIPullRequestShort(pr))
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(resp)
+	writeJSON(w, http.StatusOK, api.UserReviewsPage{
+		UserID:       params.UserID,
+		PullRequests: prs,
+		NextCursor:   nextCursor(res.NextCursor),
+	})
 }
 
-// GET /users/getReview
-func (h *PullRequestHandler) GetUserReviews(w http.ResponseWriter, r *http.Request) {
-	userID := r.URL.Query().Get("user_id")
-	if userID == "" {
-		http.Error(w, "user_id is required", http.StatusBadRequest)
+// GET /pullRequests
+func (h *PullRequestHandler) ListPullRequests(w http.ResponseWriter, r *http.Request, params api.ListPullRequestsParams) {
+	var v service.ValidationError
+	lq := listQuery(params.Limit, params.Sort, params.Cursor, &v)
+	if err := v.Err(); err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
+	f := repository.PullRequestFilter{
+		Status:      value(params.Status),
+		AuthorID:    value(params.AuthorID),
+		ReviewerID:  value(params.ReviewerID),
+		TeamName:    value(params.TeamName),
+		Name:        value(params.Name),
+		CreatedFrom: params.CreatedFrom,
+		CreatedTo:   params.CreatedTo,
+	}
 
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	prs, err := h.prService.GetUserReviews(ctx, userID)
+	res, err := h.prService.List(ctx, f, lq)
 	if err != nil {
-		if errors.Is(err, repository.ErrUserNotFound) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusNotFound)
-			_ = json.NewEncoder(w).Encode(ErrorResponse{
-				Error: ErrorBody{
-					Code:    "NOT_FOUND",
-					Message: "resource not found",
-				},
-			})
-			return
-		}
+		writeServiceError(w, r, err)
+		return
+	}
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	items := make([]api.PullRequest, 0, len(res.Items))
+	for _, pr := range res.Items {
+		items = append(items, toAPIPullRequest(pr))
+	}
+
+	writeJSON(w, http.StatusOK, api.PullRequestPage{Items: items, NextCursor: nextCursor(res.NextCursor)})
+}
+
+// GET /pullRequest/history
+func (h *PullRequestHandler) GetPullRequestHistory(w http.ResponseWriter, r *http.Request, params api.GetPullRequestHistoryParams) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	events, err := h.prService.GetHistory(ctx, params.PullRequestID)
+	if err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
-	respPRs := make([]map[string]any, 0, len(prs))
-	for _, pr := range prs {
-		respPRs = append(respPRs, map[string]any{
-			"pull_request_id":   pr.ID,
-			"pull_request_name": pr.Name,
-			"author_id":         pr.AuthorID,
-			"status":            pr.Status,
-		})
+	resp := api.PullRequestHistory{
+		PullRequestID: params.PullRequestID,
+		Events:        make([]api.PullRequestEvent, 0, len(events)),
+	}
+	for _, e := range events {
+		ev := api.PullRequestEvent{
+			ID:            e.ID,
+			PullRequestID: e.PullRequestID,
+			EventType:     api.PullRequestEventEventType(e.Type),
+			Actor:         e.Actor,
+			CreatedAt:     e.CreatedAt,
+		}
+		if e.UserID != "" {
+			ev.UserID = &e.UserID
+		}
+		if e.OldUserID != "" {
+			ev.OldUserID = &e.OldUserID
+		}
+		resp.Events = append(resp.Events, ev)
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(map[string]any{
-		"user_id":       userID,
-		"pull_requests": respPRs,
-	})
+	writeJSON(w, http.StatusOK, resp)
 }
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/logging"
+	"github.com/Olzerq/avito-pr-reviewer/internal/metrics"
+	"github.com/Olzerq/avito-pr-reviewer/internal/ratelimit"
+)
+
+// RateLimit ограничивает частоту запросов клиента. Клиент - bearer токен,
+// а без токена - IP адрес. При trustProxy IP берется из X-Forwarded-For.
+func RateLimit(l *ratelimit.Limiter, trustProxy bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pattern := fullRoutePattern(r)
+			route := r.Method + " " + pattern
+			key := clientKey(r, trustProxy)
+			if !rateLimited(w, r, pattern, l.Allow(key, route, time.Now())) {
+				return
+			}
+			charge := func(n int) ratelimit.Result {
+				return l.AllowN(key, route, n, time.Now())
+			}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), rateChargeKey{}, charge)))
+		})
+	}
+}
+
+type rateChargeKey struct{}
+
+// chargeItems списывает с бюджета маршрута остальные элементы пакета, один уже
+// списан за сам запрос. Иначе пакет из service.MaxBatchSize элементов стоил бы как
+// один запрос. При нехватке бюджета отвечает 429 и возвращает false.
+func chargeItems(w http.ResponseWriter, r *http.Request, items int) bool {
+	charge, ok := r.Context().Value(rateChargeKey{}).(func(int) ratelimit.Result)
+	if !ok || items <= 1 {
+		return true
+	}
+	return rateLimited(w, r, fullRoutePattern(r), charge(items-1))
+}
+
+// rateLimited выставляет заголовки X-RateLimit-* и отвечает 429, если бюджета не хватило
+func rateLimited(w http.ResponseWriter, r *http.Request, pattern string, res ratelimit.Result) bool {
+	h := w.Header()
+	h.Set("X-RateLimit-Limit", strconv.Itoa(res.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+	h.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+
+	if !res.Allowed {
+		metrics.RateLimited.WithLabelValues(r.Method, pattern).Inc()
+		logging.FromContext(r.Context()).Info("rate limit exceeded", slog.String("route", r.Method+" "+pattern))
+
+		h.Set("Retry-After", strconv.Itoa(ceilSeconds(res.RetryAfter)))
+		writeError(w, r, http.StatusTooManyRequests, "RATE_LIMITED", "too many requests, retry later")
+		return false
+	}
+	return true
+}
+
+// Concurrency отклоняет запросы с 503, когда одновременно обрабатывается слишком много,
+// чтобы не исчерпать пул соединений БД
+func Concurrency(c *ratelimit.ConcurrencyLimiter) func(http.Handler) http.Handler {
+	metrics.HTTPInFlightLimit.Set(float64(c.Max()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !c.Acquire(r.Context()) {
+				metrics.LoadShed.WithLabelValues(r.Method, fullRoutePattern(r)).Inc()
+				logging.FromContext(r.Context()).Warn("request shed, too many requests in flight",
+					slog.Int("in_flight", c.InFlight()))
+
+				w.Header().Set("Retry-After", "1")
+				writeError(w, r, http.StatusServiceUnavailable, "OVERLOADED", "server is overloaded, retry later")
+				return
+			}
+			metrics.HTTPInFlight.Inc()
+			var once sync.Once
+			release := func() {
+				once.Do(func() {
+					metrics.HTTPInFlight.Dec()
+					c.Release()
+				})
+			}
+			defer release()
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), inFlightKey{}, release)))
+		})
+	}
+}
+
+type inFlightKey struct{}
+
+// releaseInFlight отдает слот Concurrency до конца запроса. Потоки событий ждут
+// изменений без соединения с БД и не должны занимать слоты обычных запросов.
+func releaseInFlight(r *http.Request) {
+	if release, ok := r.Context().Value(inFlightKey{}).(func()); ok {
+		release()
+	}
+}
+
+func clientKey(r *http.Request, trustProxy bool) string {
+	if token := bearerToken(r); token != "" {
+		// сам токен в памяти не держим
+		sum := sha256.Sum256([]byte(token))
+		return "token:" + hex.EncodeToString(sum[:8])
+	}
+	return "ip:" + clientIP(r, trustProxy)
+}
+
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			first, _, _ := strings.Cut(xff, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/http/api"
+	"github.com/Olzerq/avito-pr-reviewer/internal/logging"
+	"github.com/Olzerq/avito-pr-reviewer/internal/metrics"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+)
+
+// sseRetry через сколько EventSource переподключается после обрыва потока
+const sseRetry = 3 * time.Second
+
+// ReviewStreamHandler поток очереди ревью пользователя по Server-Sent Events
+type ReviewStreamHandler struct {
+	prService *service.PullRequestService
+	heartbeat time.Duration
+
+	done     chan struct{} // закрывается при остановке сервера
+	stopOnce sync.Once
+}
+
+func NewReviewStreamHandler(prService *service.PullRequestService, heartbeat time.Duration) *ReviewStreamHandler {
+	return &ReviewStreamHandler{prService: prService, heartbeat: heartbeat, done: make(chan struct{})}
+}
+
+// Shutdown закрывает открытые потоки, клиенты переподключатся с Last-Event-ID. Сам
+// http.Server.Shutdown активные запросы не прерывает, поэтому вызывается через RegisterOnShutdown.
+func (h *ReviewStreamHandler) Shutdown() {
+	h.stopOnce.Do(func() { close(h.done) })
+}
+
+// GET /users/reviews/stream
+func (h *ReviewStreamHandler) StreamUserReviews(w http.ResponseWriter, r *http.Request, params api.StreamUserReviewsParams) {
+	logging.AddAttrs(r.Context(), slog.String("user_id", params.UserID))
+
+	var lastEventID int64
+	if params.LastEventID != nil {
+		lastEventID = *params.LastEventID
+	}
+
+	// подписка и снимок ограничены по времени, как обычный запрос
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	watch, err := h.prService.WatchReviewQueue(ctx, params.UserID, lastEventID)
+	cancel()
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	defer watch.Close()
+
+	releaseInFlight(r)
+	metrics.SSEStreams.Inc()
+	defer metrics.SSEStreams.Dec()
+
+	rc := http.NewResponseController(w)
+	// поток живет дольше http_write_timeout, обрыв клиента заметит запись heartbeat
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	hdr := w.Header()
+	hdr.Set("Content-Type", "text/event-stream")
+	hdr.Set("Cache-Control", "no-cache")
+	// nginx иначе копит поток в буфере
+	hdr.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	sse := &sseWriter{w: w, rc: rc}
+	sse.retry(sseRetry)
+	if watch.Snapshot != nil {
+		prs := make([]api.PullRequestShort, 0, len(watch.Snapshot))
+		for _, pr := range watch.Snapshot {
+			prs = append(prs, toAPIPullRequestShort(pr))
+		}
+		sse.event(watch.LastEventID, "snapshot", api.ReviewQueueSnapshot{UserID: params.UserID, PullRequests: prs})
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		events, err := watch.Next(r.Context())
+		for _, e := range events {
+			sse.event(e.ID, strings.ToLower(e.Type), api.ReviewQueueChange{
+				UserID:      e.UserID,
+				PullRequest: toAPIPullRequestShort(e.PullRequest),
+				OccurredAt:  e.At,
+			})
+		}
+		if err != nil {
+			// ответ уже начат, клиент переподключится и продолжит с последнего полученного id
+			if r.Context().Err() == nil {
+				logging.FromContext(r.Context()).Error("review queue stream failed", slog.Any("error", err))
+			}
+			return
+		}
+		if err := sse.flush(); err != nil {
+			return
+		}
+
+	wait:
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case <-h.done:
+				return
+			case <-watch.Changed():
+				break wait
+			case <-heartbeat.C:
+				// heartbeat только держит соединение, историю перечитываем по сигналу:
+				// после обрыва шины ReviewFeed будит всех подписчиков сам
+				sse.comment("heartbeat")
+				if err := sse.flush(); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// sseWriter пишет события text/event-stream, первая ошибка записи запоминается и возвращается из flush
+type sseWriter struct {
+	w   http.ResponseWriter
+	rc  *http.ResponseController
+	err error
+}
+
+func (s *sseWriter) retry(d time.Duration) {
+	s.printf("retry: %d\n\n", d.Milliseconds())
+}
+
+// event событие с id для Last-Event-ID, data - JSON в одну строку
+func (s *sseWriter) event(id int64, name string, data any) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.printf("id: %d\nevent: %s\ndata: %s\n\n", id, name, b)
+}
+
+// comment строка, которую клиенты пропускают, держит соединение живым через прокси
+func (s *sseWriter) comment(text string) {
+	s.printf(": %s\n\n", text)
+}
+
+func (s *sseWriter) printf(format string, args ...any) {
+	if s.err == nil {
+		_, s.err = fmt.Fprintf(s.w, format, args...)
+	}
+}
+
+func (s *sseWriter) flush() error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.rc.Flush()
+}
@@ -2,7 +2,7 @@ package http
 
 import (
 	"context"
-	"encoding/json"
+	"github.com/Olzerq/avito-pr-reviewer/internal/http/api"
 	"github.com/Olzerq/avito-pr-reviewer/internal/service"
 	"net/http"
 	"time"
@@ -17,38 +17,26 @@ func NewStatsHandler(statsService *service.StatsService) *StatsHandler {
 }
 
 // GET /stats/assignments
-func (h *StatsHandler) GetAssignments(w http.ResponseWriter, r *http.Request) {
+func (h *StatsHandler) GetAssignmentStats(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
 	stats, err := h.statsService.GetAssignmentsStats(ctx)
 	if err != nil {
-		http.Error(w, "failed to get stats", http.StatusInternalServerError)
+		writeInternalError(w, r, err)
 		return
 	}
-	
-	byUser := make([]map[string]any, 0, len(stats.ByUser))
+
+	resp := api.AssignmentStats{
+		ByUser: make([]api.UserAssignmentCount, 0, len(stats.ByUser)),
+		ByPr:   make([]api.PullRequestReviewersCount, 0, len(stats.ByPR)),
+	}
 	for _, s := range stats.ByUser {
-		byUser = append(byUser, map[string]any{
-			"user_id":        s.UserID,
-			"assigned_count": s.AssignedCount,
-		})
+		resp.ByUser = append(resp.ByUser, api.UserAssignmentCount{UserID: s.UserID, AssignedCount: s.AssignedCount})
 	}
-
-	byPR := make([]map[string]any, 0, len(stats.ByPR))
 	for _, s := range stats.ByPR {
-		byPR = append(byPR, map[string]any{
-			"pull_request_id": s.PullRequestID,
-			"reviewers_count": s.ReviewersCount,
-		})
-	}
-
-	resp := map[string]any{
-		"by_user": byUser,
-		"by_pr":   byPR,
+		resp.ByPr = append(resp.ByPr, api.PullRequestReviewersCount{PullRequestID: s.PullRequestID, ReviewersCount: s.ReviewersCount})
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(resp)
+	writeJSON(w, http.StatusOK, resp)
 }
@@ -2,10 +2,11 @@ package http
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
+	"github.com/Olzerq/avito-pr-reviewer/internal/http/api"
+	"github.com/Olzerq/avito-pr-reviewer/internal/logging"
 	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
 	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"log/slog"
 	"net/http"
 	"time"
 )
@@ -18,66 +19,81 @@ func NewUserHandler(userService *service.UserService) *UserHandler {
 	return &UserHandler{userService: userService}
 }
 
-type setIsActiveRequest struct {
-	UserID   string `json:"user_id"`
-	IsActive bool   `json:"is_active"`
-}
+// POST /users/setIsActive
+func (h *UserHandler) SetUserIsActive(w http.ResponseWriter, r *http.Request, _ api.SetUserIsActiveParams) {
+	var req api.SetUserIsActiveJSONRequestBody
+	if err := decodeJSON(w, r, &req, false); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
 
-type userResponse struct {
-	User struct {
-		UserID   string `json:"user_id"`
-		Username string `json:"username"`
-		TeamName string `json:"team_name"`
-		IsActive bool   `json:"is_active"`
-	} `json:"user"`
-}
+	logging.AddAttrs(r.Context(), slog.String("user_id", req.UserID))
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
 
-// POST /user/setIsActive
-func (h *UserHandler) SetIsActive(w http.ResponseWriter, r *http.Request) {
-	var req setIsActiveRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "invalid json", http.StatusBadRequest)
+	if err := h.userService.SetIsActive(ctx, req.UserID, req.IsActive); err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
-	defer r.Body.Close()
 
-	if req.UserID == "" {
-		http.Error(w, "user_id is empty", http.StatusBadRequest)
+	user, err := h.userService.GetByID(ctx, req.UserID)
+	if err != nil {
+		writeInternalError(w, r, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, api.UserResponse{User: toAPIUser(user)})
+}
+
+// POST /users/setRole
+func (h *UserHandler) SetUserRole(w http.ResponseWriter, r *http.Request, _ api.SetUserRoleParams) {
+	var req api.SetUserRoleJSONRequestBody
+	if err := decodeJSON(w, r, &req, false); err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
+	logging.AddAttrs(r.Context(), slog.String("user_id", req.UserID))
+
 	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
 	defer cancel()
 
-	if err := h.userService.SetIsActive(ctx, req.UserID, req.IsActive); err != nil {
-		if errors.Is(err, repository.ErrUserNotFound) {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusNotFound)
-			_ = json.NewEncoder(w).Encode(ErrorResponse{
-				Error: ErrorBody{
-					Code:    "NOT_FOUND",
-					Message: "resource not found",
-				},
-			})
-			return
-		}
-		http.Error(w, "failed to set is_active", http.StatusInternalServerError)
+	if err := h.userService.SetRole(ctx, req.UserID, string(req.Role)); err != nil {
+		writeServiceError(w, r, err)
 		return
 	}
 
-	user, err := h.userService.GetByID(ctx, req.UserID)
+	writeJSON(w, http.StatusOK, api.SetRoleResponse{UserID: req.UserID, Role: req.Role})
+}
+
+// GET /users
+func (h *UserHandler) ListUsers(w http.ResponseWriter, r *http.Request, params api.ListUsersParams) {
+	var v service.ValidationError
+	lq := listQuery(params.Limit, params.Sort, params.Cursor, &v)
+	if err := v.Err(); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	f := repository.UserFilter{
+		TeamName: value(params.TeamName),
+		Name:     value(params.Name),
+		IsActive: params.IsActive,
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	res, err := h.userService.List(ctx, f, lq)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeServiceError(w, r, err)
 		return
 	}
 
-	var resp userResponse
-	resp.User.UserID = user.ID
-	resp.User.Username = user.Username
-	resp.User.TeamName = user.TeamName
-	resp.User.IsActive = user.IsActive
+	items := make([]api.User, 0, len(res.Items))
+	for _, u := range res.Items {
+		items = append(items, toAPIUser(u))
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	_ = json.NewEncoder(w).Encode(resp)
+	writeJSON(w, http.StatusOK, api.UserPage{Items: items, NextCursor: nextCursor(res.NextCursor)})
 }
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/http/api"
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// API v1 описан в openapi.yml. Маршруты, разбор параметров и типы запросов и
+// ответов генерируются в пакет api, здесь только реализация api.ServerInterface.
+
+// V1Handler собирает обработчики v1 в одну реализацию api.ServerInterface
+type V1Handler struct {
+	*TeamHandler
+	*UserHandler
+	*PullRequestHandler
+	*StatsHandler
+	*ReviewStreamHandler
+}
+
+var _ api.ServerInterface = (*V1Handler)(nil)
+
+func NewV1Handler(
+	teams *TeamHandler,
+	users *UserHandler,
+	prs *PullRequestHandler,
+	stats *StatsHandler,
+	streams *ReviewStreamHandler,
+) *V1Handler {
+	return &V1Handler{
+		TeamHandler:         teams,
+		UserHandler:         users,
+		PullRequestHandler:  prs,
+		StatsHandler:        stats,
+		ReviewStreamHandler: streams,
+	}
+}
+
+// Routes регистрирует маршруты v1 из спецификации на r
+func (h *V1Handler) Routes(r chi.Router) {
+	api.HandlerWithOptions(h, api.ChiServerOptions{
+		BaseRouter:       r,
+		ErrorHandlerFunc: writeParamError,
+	})
+}
+
+// paramFormats сообщения для параметров, которые не удалось разобрать
+var paramFormats = map[string]string{
+	"limit":         "limit must be a positive integer",
+	"is_active":     "is_active must be true or false",
+	"created_from":  "created_from must be an RFC 3339 timestamp",
+	"created_to":    "created_to must be an RFC 3339 timestamp",
+	"Last-Event-ID": "Last-Event-ID must be a non-negative integer",
+}
+
+// writeParamError отдает ошибки разбора параметров из сгенерированного кода как ошибки валидации
+func writeParamError(w http.ResponseWriter, r *http.Request, err error) {
+	var (
+		v           service.ValidationError
+		required    *api.RequiredParamError
+		requiredHdr *api.RequiredHeaderError
+		format      *api.InvalidParamFormatError
+		unmarshal   *api.UnmarshalingParamError
+		tooMany     *api.TooManyValuesForParamError
+	)
+	switch {
+	case errors.As(err, &required):
+		v.Required(required.ParamName, "")
+	case errors.As(err, &requiredHdr):
+		v.Required(requiredHdr.ParamName, "")
+	case errors.As(err, &format):
+		v.Add(format.ParamName, service.RuleFormat, paramFormat(format.ParamName))
+	case errors.As(err, &unmarshal):
+		v.Add(unmarshal.ParamName, service.RuleFormat, paramFormat(unmarshal.ParamName))
+	case errors.As(err, &tooMany):
+		v.Add(tooMany.ParamName, service.RuleFormat, tooMany.ParamName+" must be passed once")
+	default:
+		writeError(w, r, http.StatusBadRequest, "BAD_REQUEST", err.Error())
+		return
+	}
+	writeServiceError(w, r, v.Err())
+}
+
+func paramFormat(name string) string {
+	if msg, ok := paramFormats[name]; ok {
+		return msg
+	}
+	return name + " has invalid format"
+}
+
+// listQuery параметры страницы из сгенерированных параметров запроса
+func listQuery[S ~string](limit *int, sort *S, cursor *string, v *service.ValidationError) service.ListQuery {
+	lq := service.ListQuery{Sort: value(sort), Cursor: value(cursor)}
+	if limit != nil {
+		if *limit <= 0 {
+			v.Add("limit", service.RuleFormat, paramFormats["limit"])
+		}
+		lq.Limit = *limit
+	}
+	return lq
+}
+
+// value необязательный строковый параметр, nil - пустая строка
+func value[S ~string](p *S) string {
+	if p == nil {
+		return ""
+	}
+	return string(*p)
+}
+
+// nextCursor курсор следующей страницы, nil на последней
+func nextCursor(c string) *api.NextCursor {
+	if c == "" {
+		return nil
+	}
+	return &c
+}
+
+func toAPITeam(team model.Team) api.Team {
+	members := make([]api.TeamMember, 0, len(team.Users))
+	for _, u := range team.Users {
+		members = append(members, api.TeamMember{UserID: u.ID, Username: u.Username, IsActive: u.IsActive})
+	}
+	return api.Team{TeamName: team.Name, Members: members}
+}
+
+func toAPIUser(u model.User) api.User {
+	return api.User{UserID: u.ID, Username: u.Username, TeamName: u.TeamName, IsActive: u.IsActive}
+}
+
+func toAPIPullRequest(pr model.PullRequest) api.PullRequest {
+	assigned := make([]string, 0, len(pr.Reviewers))
+	for _, u := range pr.Reviewers {
+		assigned = append(assigned, u.ID)
+	}
+	return api.PullRequest{
+		PullRequestID:     pr.ID,
+		PullRequestName:   pr.Name,
+		AuthorID:          pr.AuthorID,
+		Status:            api.PullRequestStatus(pr.Status),
+		AssignedReviewers: assigned,
+		CreatedAt:         pr.CreatedAt,
+		MergedAt:          pr.MergedAt,
+	}
+}
+
+func toAPIPullRequestShort(pr model.PullRequestShort) api.PullRequestShort {
+	return api.PullRequestShort{
+		PullRequestID:   pr.ID,
+		PullRequestName: pr.Name,
+		AuthorID:        pr.AuthorID,
+		Status:          api.PullRequestShortStatus(pr.Status),
+	}
+}
+
+func toAPIBatchResponse(res service.BatchResult) api.BatchResponse {
+	results := make([]api.BatchItemResult, 0, len(res.Items))
+	for _, it := range res.Items {
+		item := api.BatchItemResult{PullRequestID: it.PullRequestID, Status: api.BatchItemResultStatus(it.Status)}
+		if it.PR != nil {
+			pr := toAPIPullRequest(*it.PR)
+			item.Pr = &pr
+		}
+		results = append(results, item)
+	}
+	return api.BatchResponse{Mode: api.BatchMode(res.Mode), Applied: res.Applied, Results: results}
+}
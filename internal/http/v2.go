@@ -0,0 +1,187 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// API v2: ресурсные пути, типизированные ответы, поля в snake_case.
+// Обработчики v1 остаются для совместимости и вызывают те же сервисы.
+
+type V2Handler struct {
+	teams *service.TeamService
+	users *service.UserService
+	prs   *service.PullRequestService
+	stats *service.StatsService
+}
+
+func NewV2Handler(teams *service.TeamService, users *service.UserService, prs *service.PullRequestService, stats *service.StatsService) *V2Handler {
+	return &V2Handler{teams: teams, users: users, prs: prs, stats: stats}
+}
+
+// Routes регистрирует маршруты v2, монтируется как r.Route("/v2", h.Routes).
+// Маршруты плоские, чтобы шаблоны в метриках и лимитах не заканчивались на "/".
+func (h *V2Handler) Routes(r chi.Router) {
+	r.Get("/teams", h.listTeams)
+	r.Post("/teams", h.createTeam)
+	r.Get("/teams/{team_name}", h.getTeam)
+
+	r.Get("/users", h.listUsers)
+	r.Get("/users/{user_id}", h.getUser)
+	r.Patch("/users/{user_id}", h.updateUser)
+	r.Get("/users/{user_id}/reviews", h.listUserReviews)
+
+	r.Get("/pull-requests", h.listPullRequests)
+	r.Post("/pull-requests", h.createPullRequest)
+	r.Get("/pull-requests/{pull_request_id}", h.getPullRequest)
+	r.Post("/pull-requests/{pull_request_id}/merge", h.mergePullRequest)
+	r.Get("/pull-requests/{pull_request_id}/reviewers", h.listReviewers)
+	r.Post("/pull-requests/{pull_request_id}/reviewers/{user_id}/reassign", h.reassignReviewer)
+	r.Get("/pull-requests/{pull_request_id}/events", h.listEvents)
+
+	r.Get("/stats/assignments", h.getAssignmentStats)
+}
+
+type teamMemberV2 struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	IsActive bool   `json:"is_active"`
+}
+
+type teamV2 struct {
+	TeamName string         `json:"team_name"`
+	Members  []teamMemberV2 `json:"members"`
+}
+
+type userV2 struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	TeamName string `json:"team_name"`
+	IsActive bool   `json:"is_active"`
+}
+
+type pullRequestV2 struct {
+	PullRequestID     string     `json:"pull_request_id"`
+	PullRequestName   string     `json:"pull_request_name"`
+	AuthorID          string     `json:"author_id"`
+	Status            string     `json:"status"`
+	AssignedReviewers []string   `json:"assigned_reviewers"`
+	CreatedAt         *time.Time `json:"created_at"`
+	MergedAt          *time.Time `json:"merged_at"`
+	Version           int64      `json:"version"`
+}
+
+type pullRequestShortV2 struct {
+	PullRequestID   string `json:"pull_request_id"`
+	PullRequestName string `json:"pull_request_name"`
+	AuthorID        string `json:"author_id"`
+	Status          string `json:"status"`
+}
+
+type reassignResultV2 struct {
+	PullRequest pullRequestV2 `json:"pull_request"`
+	ReplacedBy  string        `json:"replaced_by"`
+}
+
+type pullRequestEventV2 struct {
+	ID            int64     `json:"id"`
+	PullRequestID string    `json:"pull_request_id"`
+	EventType     string    `json:"event_type"`
+	UserID        *string   `json:"user_id"`
+	OldUserID     *string   `json:"old_user_id"`
+	Actor         string    `json:"actor"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type userAssignmentsV2 struct {
+	UserID        string `json:"user_id"`
+	AssignedCount int    `json:"assigned_count"`
+}
+
+type pullRequestAssignmentsV2 struct {
+	PullRequestID  string `json:"pull_request_id"`
+	ReviewersCount int    `json:"reviewers_count"`
+}
+
+type assignmentStatsV2 struct {
+	ByUser        []userAssignmentsV2        `json:"by_user"`
+	ByPullRequest []pullRequestAssignmentsV2 `json:"by_pull_request"`
+}
+
+// listV2 страница списка, next_cursor null на последней странице
+type listV2[T any] struct {
+	Items      []T     `json:"items"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+func newListV2[T, M any](items []M, next string, conv func(M) T) listV2[T] {
+	l := listV2[T]{Items: make([]T, 0, len(items))}
+	for _, item := range items {
+		l.Items = append(l.Items, conv(item))
+	}
+	if next != "" {
+		l.NextCursor = &next
+	}
+	return l
+}
+
+func toTeamV2(t model.Team) teamV2 {
+	v := teamV2{TeamName: t.Name, Members: make([]teamMemberV2, 0, len(t.Users))}
+	for _, u := range t.Users {
+		v.Members = append(v.Members, teamMemberV2{UserID: u.ID, Username: u.Username, IsActive: u.IsActive})
+	}
+	return v
+}
+
+func toUserV2(u model.User) userV2 {
+	return userV2{UserID: u.ID, Username: u.Username, TeamName: u.TeamName, IsActive: u.IsActive}
+}
+
+func toPullRequestV2(pr model.PullRequest) pullRequestV2 {
+	v := pullRequestV2{
+		PullRequestID:     pr.ID,
+		PullRequestName:   pr.Name,
+		AuthorID:          pr.AuthorID,
+		Status:            pr.Status,
+		AssignedReviewers: make([]string, 0, len(pr.Reviewers)),
+		CreatedAt:         pr.CreatedAt,
+		MergedAt:          pr.MergedAt,
+		Version:           pr.Version,
+	}
+	for _, u := range pr.Reviewers {
+		v.AssignedReviewers = append(v.AssignedReviewers, u.ID)
+	}
+	return v
+}
+
+func toPullRequestShortV2(pr model.PullRequestShort) pullRequestShortV2 {
+	return pullRequestShortV2{PullRequestID: pr.ID, PullRequestName: pr.Name, AuthorID: pr.AuthorID, Status: pr.Status}
+}
+
+func toPullRequestEventV2(e model.PullRequestEvent) pullRequestEventV2 {
+	v := pullRequestEventV2{
+		ID:            e.ID,
+		PullRequestID: e.PullRequestID,
+		EventType:     e.Type,
+		Actor:         e.Actor,
+		CreatedAt:     e.CreatedAt,
+	}
+	if e.UserID != "" {
+		v.UserID = &e.UserID
+	}
+	if e.OldUserID != "" {
+		v.OldUserID = &e.OldUserID
+	}
+	return v
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,180 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+type createPullRequestV2Request struct {
+	PullRequestID   string `json:"pull_request_id"`
+	PullRequestName string `json:"pull_request_name"`
+	AuthorID        string `json:"author_id"`
+}
+
+// GET /v2/pull-requests
+func (h *V2Handler) listPullRequests(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var v service.ValidationError
+	lq := parseListQuery(q, &v)
+	f := repository.PullRequestFilter{
+		Status:     q.Get("status"),
+		AuthorID:   q.Get("author_id"),
+		ReviewerID: q.Get("reviewer_id"),
+		TeamName:   q.Get("team_name"),
+		Name:       q.Get("name"),
+	}
+	f.CreatedFrom = parseTimeParam(q, "created_from", &v)
+	f.CreatedTo = parseTimeParam(q, "created_to", &v)
+
+	if err := v.Err(); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	res, err := h.prs.List(ctx, f, lq)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newListV2(res.Items, res.NextCursor, toPullRequestV2))
+}
+
+// POST /v2/pull-requests
+func (h *V2Handler) createPullRequest(w http.ResponseWriter, r *http.Request) {
+	var req createPullRequestV2Request
+	if err := decodeJSON(w, r, &req, true); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pr, err := h.prs.Create(ctx, req.PullRequestID, req.PullRequestName, req.AuthorID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/v2/pull-requests/"+url.PathEscape(pr.ID))
+	w.Header().Set("ETag", prETag(pr.Version))
+	writeJSON(w, http.StatusCreated, toPullRequestV2(pr))
+}
+
+// GET /v2/pull-requests/{pull_request_id}
+func (h *V2Handler) getPullRequest(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pr, err := h.prs.Get(ctx, chi.URLParam(r, "pull_request_id"))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", prETag(pr.Version))
+	writeJSON(w, http.StatusOK, toPullRequestV2(pr))
+}
+
+// POST /v2/pull-requests/{pull_request_id}/merge
+func (h *V2Handler) mergePullRequest(w http.ResponseWriter, r *http.Request) {
+	r, ok := withIfMatch(r)
+	if !ok {
+		writePreconditionFailed(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pr, err := h.prs.Merge(ctx, chi.URLParam(r, "pull_request_id"))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", prETag(pr.Version))
+	writeJSON(w, http.StatusOK, toPullRequestV2(pr))
+}
+
+// GET /v2/pull-requests/{pull_request_id}/reviewers
+func (h *V2Handler) listReviewers(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pr, err := h.prs.Get(ctx, chi.URLParam(r, "pull_request_id"))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", prETag(pr.Version))
+	writeJSON(w, http.StatusOK, newListV2(pr.Reviewers, "", toUserV2))
+}
+
+// POST /v2/pull-requests/{pull_request_id}/reviewers/{user_id}/reassign
+func (h *V2Handler) reassignReviewer(w http.ResponseWriter, r *http.Request) {
+	r, ok := withIfMatch(r)
+	if !ok {
+		writePreconditionFailed(w, r)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	pr, replacedBy, err := h.prs.Reassign(ctx, chi.URLParam(r, "pull_request_id"), chi.URLParam(r, "user_id"))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", prETag(pr.Version))
+	writeJSON(w, http.StatusOK, reassignResultV2{PullRequest: toPullRequestV2(pr), ReplacedBy: replacedBy})
+}
+
+// GET /v2/pull-requests/{pull_request_id}/events
+func (h *V2Handler) listEvents(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	events, err := h.prs.GetHistory(ctx, chi.URLParam(r, "pull_request_id"))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newListV2(events, "", toPullRequestEventV2))
+}
+
+// GET /v2/stats/assignments
+func (h *V2Handler) getAssignmentStats(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	stats, err := h.stats.GetAssignmentsStats(ctx)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	resp := assignmentStatsV2{
+		ByUser:        make([]userAssignmentsV2, 0, len(stats.ByUser)),
+		ByPullRequest: make([]pullRequestAssignmentsV2, 0, len(stats.ByPR)),
+	}
+	for _, s := range stats.ByUser {
+		resp.ByUser = append(resp.ByUser, userAssignmentsV2{UserID: s.UserID, AssignedCount: s.AssignedCount})
+	}
+	for _, s := range stats.ByPR {
+		resp.ByPullRequest = append(resp.ByPullRequest, pullRequestAssignmentsV2{PullRequestID: s.PullRequestID, ReviewersCount: s.ReviewersCount})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
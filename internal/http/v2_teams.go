@@ -0,0 +1,72 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// GET /v2/teams
+func (h *V2Handler) listTeams(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var v service.ValidationError
+	lq := parseListQuery(q, &v)
+	if err := v.Err(); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	res, err := h.teams.List(ctx, repository.TeamFilter{Name: q.Get("name")}, lq)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newListV2(res.Items, res.NextCursor, toTeamV2))
+}
+
+// POST /v2/teams
+func (h *V2Handler) createTeam(w http.ResponseWriter, r *http.Request) {
+	var req teamV2
+	if err := decodeJSON(w, r, &req, true); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	team := model.Team{Name: req.TeamName, Users: make([]model.User, 0, len(req.Members))}
+	for _, m := range req.Members {
+		team.Users = append(team.Users, model.User{ID: m.UserID, Username: m.Username, TeamName: req.TeamName, IsActive: m.IsActive})
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.teams.CreateTeam(ctx, team); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/v2/teams/"+url.PathEscape(team.Name))
+	writeJSON(w, http.StatusCreated, toTeamV2(team))
+}
+
+// GET /v2/teams/{team_name}
+func (h *V2Handler) getTeam(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	team, err := h.teams.GetTeam(ctx, chi.URLParam(r, "team_name"))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toTeamV2(team))
+}
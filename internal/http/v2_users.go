@@ -0,0 +1,121 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/logging"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// updateUserV2Request частичное обновление, отсутствующие поля не меняются
+type updateUserV2Request struct {
+	IsActive *bool   `json:"is_active"`
+	Role     *string `json:"role"`
+}
+
+// GET /v2/users
+func (h *V2Handler) listUsers(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var v service.ValidationError
+	lq := parseListQuery(q, &v)
+	f := repository.UserFilter{TeamName: q.Get("team_name"), Name: q.Get("name")}
+	f.IsActive = parseBoolParam(q, "is_active", &v)
+
+	if err := v.Err(); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	res, err := h.users.List(ctx, f, lq)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newListV2(res.Items, res.NextCursor, toUserV2))
+}
+
+// GET /v2/users/{user_id}
+func (h *V2Handler) getUser(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	user, err := h.users.GetByID(ctx, chi.URLParam(r, "user_id"))
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toUserV2(user))
+}
+
+// PATCH /v2/users/{user_id}
+func (h *V2Handler) updateUser(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "user_id")
+	logging.AddAttrs(r.Context(), slog.String("user_id", userID))
+
+	var req updateUserV2Request
+	if err := decodeJSON(w, r, &req, true); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	if req.IsActive == nil && req.Role == nil {
+		writeError(w, r, http.StatusBadRequest, "BAD_REQUEST", "at least one of is_active, role is required")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	// роль меняет только администратор, поэтому она проверяется первой:
+	// запрос без прав не должен успеть поменять активность
+	if req.Role != nil {
+		if err := h.users.SetRole(ctx, userID, *req.Role); err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+	}
+	if req.IsActive != nil {
+		if err := h.users.SetIsActive(ctx, userID, *req.IsActive); err != nil {
+			writeServiceError(w, r, err)
+			return
+		}
+	}
+
+	user, err := h.users.GetByID(ctx, userID)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toUserV2(user))
+}
+
+// GET /v2/users/{user_id}/reviews
+func (h *V2Handler) listUserReviews(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "user_id")
+	logging.AddAttrs(r.Context(), slog.String("user_id", userID))
+
+	q := r.URL.Query()
+	var v service.ValidationError
+	lq := parseListQuery(q, &v)
+	if err := v.Err(); err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	res, err := h.prs.GetUserReviews(ctx, userID, q.Get("status"), lq)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newListV2(res.Items, res.NextCursor, toPullRequestShortV2))
+}
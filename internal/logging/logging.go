@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Setup настраивает JSON логгер по умолчанию с указанным уровнем (debug, info, warn, error)
+func Setup(level string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: lvl}))
+	slog.SetDefault(logger)
+	return nil
+}
+
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return slog.LevelInfo, fmt.Errorf("unknown log level %q", level)
+	}
+}
+
+// Fatal пишет ошибку и завершает процесс
+func Fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+type requestIDKey struct{}
+
+type attrsKey struct{}
+
+// attrs поля, которые обработчики добавляют к логу запроса
+type attrs struct {
+	mu    sync.Mutex
+	items []slog.Attr
+}
+
+// WithRequestID сохраняет id запроса в контексте
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext возвращает id запроса или пустую строку
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithAttrsHolder подготавливает контекст запроса к сбору полей лога
+func WithAttrsHolder(ctx context.Context) context.Context {
+	return context.WithValue(ctx, attrsKey{}, &attrs{})
+}
+
+// AddAttrs добавляет поля в итоговую запись лога запроса
+func AddAttrs(ctx context.Context, items ...slog.Attr) {
+	holder, ok := ctx.Value(attrsKey{}).(*attrs)
+	if !ok {
+		return
+	}
+	holder.mu.Lock()
+	holder.items = append(holder.items, items...)
+	holder.mu.Unlock()
+}
+
+// Attrs возвращает поля, добавленные через AddAttrs
+func Attrs(ctx context.Context) []slog.Attr {
+	holder, ok := ctx.Value(attrsKey{}).(*attrs)
+	if !ok {
+		return nil
+	}
+	holder.mu.Lock()
+	defer holder.mu.Unlock()
+	return append([]slog.Attr(nil), holder.items...)
+}
+
+// FromContext возвращает логгер с id запроса
+func FromContext(ctx context.Context) *slog.Logger {
+	logger := slog.Default()
+	if id := RequestIDFromContext(ctx); id != "" {
+		logger = logger.With(slog.String("request_id", id))
+	}
+	return logger
+}
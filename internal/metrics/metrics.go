@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry реестр метрик сервиса, отдается на /metrics
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// HTTP
+var (
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total HTTP requests by route and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "HTTP request latency in seconds.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	SSEStreams = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "http_sse_streams_active",
+		Help: "Review queue Server-Sent Events streams currently open.",
+	})
+)
+
+// gRPC
+var (
+	GRPCRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_requests_total",
+		Help: "Total gRPC calls by method and status code.",
+	}, []string{"method", "code"})
+
+	GRPCDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_request_duration_seconds",
+		Help:    "gRPC call latency in seconds, streams are measured until they end.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	GRPCStreams = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "grpc_streams_active",
+		Help: "Review queue streams currently open.",
+	})
+)
+
+// Ограничение нагрузки
+var (
+	RateLimited = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_rate_limited_total",
+		Help: "Requests rejected with 429 by the rate limiter.",
+	}, []string{"method", "route"})
+
+	LoadShed = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_load_shed_total",
+		Help: "Requests rejected with 503 by the concurrency limiter.",
+	}, []string{"method", "route"})
+
+	HTTPInFlight = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "API requests currently being processed.",
+	})
+
+	HTTPInFlightLimit = factory.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight_limit",
+		Help: "Maximum API requests processed concurrently.",
+	})
+)
+
+// Доменные метрики
+var (
+	PRsCreated = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "pr_created_total",
+		Help: "Total pull requests created.",
+	}, []string{"team"})
+
+	PRReassignments = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "pr_reassignments_total",
+		Help: "Total reviewer reassignments.",
+	}, []string{"team"})
+
+	PRNoCandidate = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "pr_reassign_no_candidate_total",
+		Help: "Total reassignments failed with NO_CANDIDATE.",
+	}, []string{"team"})
+
+	PRsMerged = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "pr_merged_total",
+		Help: "Total pull requests merged.",
+	}, []string{"team"})
+
+	PRVersionConflicts = factory.NewCounter(prometheus.CounterOpts{
+		Name: "pr_version_conflicts_total",
+		Help: "Total pull request updates retried after a concurrent modification.",
+	})
+)
+
+// Handler отдает метрики в текстовом формате Prometheus
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolCollector снимает статистику пула pgx в момент запроса метрик
+type PoolCollector struct {
+	pool *pgxpool.Pool
+
+	acquired    *prometheus.Desc
+	idle        *prometheus.Desc
+	total       *prometheus.Desc
+	max         *prometheus.Desc
+	waits       *prometheus.Desc
+	waitSeconds *prometheus.Desc
+}
+
+func NewPoolCollector(pool *pgxpool.Pool) *PoolCollector {
+	return &PoolCollector{
+		pool: pool,
+		acquired: prometheus.NewDesc("db_pool_acquired_conns",
+			"Currently acquired connections in the pool.", nil, nil),
+		idle: prometheus.NewDesc("db_pool_idle_conns",
+			"Currently idle connections in the pool.", nil, nil),
+		total: prometheus.NewDesc("db_pool_total_conns",
+			"Total connections in the pool.", nil, nil),
+		max: prometheus.NewDesc("db_pool_max_conns",
+			"Maximum size of the pool.", nil, nil),
+		waits: prometheus.NewDesc("db_pool_acquire_waits_total",
+			"Cumulative acquires that had to wait for a free connection since start, use rate() to see current contention.", nil, nil),
+		waitSeconds: prometheus.NewDesc("db_pool_acquire_wait_seconds_total",
+			"Cumulative time spent waiting for a free connection since start.", nil, nil),
+	}
+}
+
+// RegisterPool регистрирует статистику пула в реестре
+func RegisterPool(pool *pgxpool.Pool) {
+	Registry.MustRegister(NewPoolCollector(pool))
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquired
+	ch <- c.idle
+	ch <- c.total
+	ch <- c.max
+	ch <- c.waits
+	ch <- c.waitSeconds
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquired, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.total, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.max, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.waits, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.waitSeconds, prometheus.CounterValue, stat.EmptyAcquireWaitTime().Seconds())
+}
@@ -0,0 +1,185 @@
+package migrator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/migrations"
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5" // драйвер pgx5://
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite" // драйвер sqlite:// (modernc, без cgo)
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Ключ advisory lock для миграций при старте
+const lockKey int64 = 727002
+
+// Status состояние миграций в БД
+type Status struct {
+	Version uint // 0 - миграции не применялись
+	Dirty   bool
+	Latest  uint // последняя встроенная миграция
+}
+
+// Migrator применяет встроенные миграции
+type Migrator struct {
+	m      *migrate.Migrate
+	latest uint
+}
+
+// New создает мигратор для строки подключения вида postgres://...
+func New(connString string) (*Migrator, error) {
+	m, err := newMigrator(migrations.FS, toMigrateURL(connString))
+	if err != nil {
+		return nil, redactPassword(err, connString)
+	}
+	return m, nil
+}
+
+// NewSQLite создает мигратор для файла SQLite, у него свой набор миграций
+func NewSQLite(path string) (*Migrator, error) {
+	fsys, err := fs.Sub(migrations.SQLiteFS, "sqlite")
+	if err != nil {
+		return nil, err
+	}
+	return newMigrator(fsys, "sqlite://"+path+"?_pragma=busy_timeout(5000)")
+}
+
+func newMigrator(fsys fs.FS, databaseURL string) (*Migrator, error) {
+	latest, err := latestVersion(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	src, err := iofs.New(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", src, databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{m: m, latest: latest}, nil
+}
+
+// redactPassword убирает пароль из ошибки, golang-migrate кладет в нее строку подключения целиком
+func redactPassword(err error, connString string) error {
+	u, parseErr := url.Parse(connString)
+	if parseErr != nil || u.User == nil {
+		return err
+	}
+	password, ok := u.User.Password()
+	if !ok || password == "" {
+		return err
+	}
+	return errors.New(strings.ReplaceAll(err.Error(), password, "xxxxx"))
+}
+
+// toMigrateURL переводит DSN на драйвер pgx/v5 у golang-migrate
+func toMigrateURL(connString string) string {
+	for _, prefix := range []string{"postgres://", "postgresql://"} {
+		if strings.HasPrefix(connString, prefix) {
+			return "pgx5://" + strings.TrimPrefix(connString, prefix)
+		}
+	}
+	return connString
+}
+
+func (m *Migrator) Close() error {
+	srcErr, dbErr := m.m.Close()
+	return errors.Join(srcErr, dbErr)
+}
+
+// Up применяет все новые миграции
+func (m *Migrator) Up() error {
+	if err := m.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Down откатывает steps последних миграций
+func (m *Migrator) Down(steps int) error {
+	if steps < 1 {
+		return fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	if err := m.m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Force выставляет версию без применения миграций (снимает dirty после ручной починки)
+func (m *Migrator) Force(version int) error {
+	return m.m.Force(version)
+}
+
+func (m *Migrator) Status() (Status, error) {
+	version, dirty, err := m.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return Status{}, err
+	}
+	return Status{Version: version, Dirty: dirty, Latest: m.latest}, nil
+}
+
+// LatestVersion возвращает номер последней встроенной миграции Postgres
+func LatestVersion() (uint, error) {
+	return latestVersion(migrations.FS)
+}
+
+// LatestSQLiteVersion возвращает номер последней встроенной миграции SQLite
+func LatestSQLiteVersion() (uint, error) {
+	fsys, err := fs.Sub(migrations.SQLiteFS, "sqlite")
+	if err != nil {
+		return 0, err
+	}
+	return latestVersion(fsys)
+}
+
+func latestVersion(fsys fs.FS) (uint, error) {
+	files, err := fs.Glob(fsys, "*.up.sql")
+	if err != nil {
+		return 0, err
+	}
+
+	var latest uint
+	for _, name := range files {
+		prefix, _, ok := strings.Cut(name, "_")
+		if !ok {
+			return 0, fmt.Errorf("unexpected migration file name %q", name)
+		}
+		v, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("unexpected migration file name %q: %w", name, err)
+		}
+		if uint(v) > latest {
+			latest = uint(v)
+		}
+	}
+	return latest, nil
+}
+
+// UpLocked применяет миграции под advisory lock, чтобы одновременно стартующие реплики не мешали друг другу
+func UpLocked(ctx context.Context, db *pgxpool.Pool, connString string) error {
+	unlock, err := repository.AdvisoryLock(ctx, db, lockKey)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	m, err := New(connString)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	return m.Up()
+}
@@ -10,6 +10,23 @@ type User struct {
 	IsActive bool   `json:"is_active"`
 }
 
+// Роли пользователей
+const (
+	RoleAdmin    = "admin"     // все операции
+	RoleTeamLead = "team_lead" // управление участниками и PR своей команды
+	RoleMember   = "member"    // только свои PR и ревью
+	RoleReadOnly = "read_only" // только чтение
+)
+
+// ValidRole проверяет, что роль известна
+func ValidRole(role string) bool {
+	switch role {
+	case RoleAdmin, RoleTeamLead, RoleMember, RoleReadOnly:
+		return true
+	}
+	return false
+}
+
 // Команда
 type Team struct {
 	Name  string `json:"team_name"`
@@ -17,11 +34,13 @@ type Team struct {
 }
 
 type PullRequest struct {
-	ID       string     `json:"pull_request_id"`
-	Name     string     `json:"pull_request_name"`
-	AuthorID string     `json:"author_id"`
-	Status   string     `json:"status"` // "OPEN" или "MERGED"
-	MergedAt *time.Time `json:"merged_at,omitempty"`
+	ID        string     `json:"pull_request_id"`
+	Name      string     `json:"pull_request_name"`
+	AuthorID  string     `json:"author_id"`
+	Status    string     `json:"status"` // "OPEN" или "MERGED"
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	MergedAt  *time.Time `json:"merged_at,omitempty"`
+	Version   int64      `json:"version"` // растет при каждом изменении PR, отдается как ETag
 
 	Reviewers []User `json:"reviewers"`
 }
@@ -32,3 +51,66 @@ type PullRequestShort struct {
 	AuthorID string `json:"author_id"`
 	Status   string `json:"status"`
 }
+
+// Типы событий в истории PR
+const (
+	EventPRCreated         = "CREATED"
+	EventReviewerAssigned  = "REVIEWER_ASSIGNED"
+	EventReviewerReplaced  = "REVIEWER_REASSIGNED"
+	EventPRMerged          = "MERGED"
+	EventReviewerReminded  = "REMINDER_SENT"
+	EventReviewerEscalated = "ESCALATED"
+)
+
+// Событие в истории PR
+type PullRequestEvent struct {
+	ID            int64     `json:"id"`
+	PullRequestID string    `json:"pull_request_id"`
+	Type          string    `json:"event_type"`
+	UserID        string    `json:"user_id,omitempty"`
+	OldUserID     string    `json:"old_user_id,omitempty"`
+	Actor         string    `json:"actor"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Назначение ревьювера на открытый PR
+type ReviewAssignment struct {
+	PullRequestID   string
+	PullRequestName string
+	AuthorID        string
+	TeamName        string // команда автора
+	ReviewerID      string
+	ReviewerName    string
+	AssignedAt      time.Time
+	RemindedAt      *time.Time
+}
+
+// Изменения очереди ревью пользователя
+const (
+	ReviewQueueAssigned   = "ASSIGNED"   // пользователь назначен ревьювером
+	ReviewQueueUnassigned = "UNASSIGNED" // пользователь снят с ревью при переназначении
+	ReviewQueueMerged     = "MERGED"     // PR, который пользователь ревьюит, смержен
+)
+
+// ReviewQueueEvent изменение очереди ревью одного пользователя, построенное по истории PR
+type ReviewQueueEvent struct {
+	ID          int64 // id события в истории PR, по нему продолжают чтение
+	Type        string
+	UserID      string // ревьювер, чья очередь изменилась
+	PullRequest PullRequestShort
+	At          time.Time
+}
+
+// ChangesReviewQueue сообщает, меняет ли событие истории PR чью-то очередь ревью
+func ChangesReviewQueue(eventType string) bool {
+	switch eventType {
+	case EventReviewerAssigned, EventReviewerReplaced, EventReviewerEscalated, EventPRMerged:
+		return true
+	}
+	return false
+}
+
+// ReplacesReviewer сообщает, что событие заменяет ревьювера: ручное переназначение или эскалация
+func ReplacesReviewer(eventType string) bool {
+	return eventType == EventReviewerReplaced || eventType == EventReviewerEscalated
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// ConcurrencyLimiter ограничивает число запросов, обрабатываемых одновременно.
+// Лишние запросы ждут освобождения слота не дольше wait, затем отклоняются,
+// чтобы очередь не копилась в пуле соединений БД.
+type ConcurrencyLimiter struct {
+	slots chan struct{}
+	wait  time.Duration
+}
+
+func NewConcurrencyLimiter(max int, wait time.Duration) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		slots: make(chan struct{}, max),
+		wait:  wait,
+	}
+}
+
+// Acquire занимает слот, false - слотов нет
+func (c *ConcurrencyLimiter) Acquire(ctx context.Context) bool {
+	select {
+	case c.slots <- struct{}{}:
+		return true
+	default:
+	}
+	if c.wait <= 0 {
+		return false
+	}
+
+	timer := time.NewTimer(c.wait)
+	defer timer.Stop()
+
+	select {
+	case c.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+		return false
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// Release освобождает слот
+func (c *ConcurrencyLimiter) Release() {
+	<-c.slots
+}
+
+// InFlight число занятых слотов
+func (c *ConcurrencyLimiter) InFlight() int {
+	return len(c.slots)
+}
+
+// Max максимальное число одновременных запросов
+func (c *ConcurrencyLimiter) Max() int {
+	return cap(c.slots)
+}
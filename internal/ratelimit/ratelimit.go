@@ -0,0 +1,177 @@
+// Package ratelimit ограничивает частоту запросов (token bucket на клиента и маршрут)
+// и число одновременно обрабатываемых запросов.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Limit бюджет: RPS запросов в секунду в среднем, Burst подряд
+type Limit struct {
+	RPS   float64
+	Burst int
+}
+
+func (l Limit) String() string {
+	return strconv.FormatFloat(l.RPS, 'f', -1, 64) + ":" + strconv.Itoa(l.Burst)
+}
+
+// ParseLimit разбирает бюджет вида "rps:burst", например "5:10"
+func ParseLimit(s string) (Limit, error) {
+	rpsStr, burstStr, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return Limit{}, fmt.Errorf("limit %q: expected rps:burst", s)
+	}
+	rps, err := strconv.ParseFloat(rpsStr, 64)
+	if err != nil || rps <= 0 {
+		return Limit{}, fmt.Errorf("limit %q: rps must be a positive number", s)
+	}
+	burst, err := strconv.Atoi(burstStr)
+	if err != nil || burst < 1 {
+		return Limit{}, fmt.Errorf("limit %q: burst must be a positive integer", s)
+	}
+	return Limit{RPS: rps, Burst: burst}, nil
+}
+
+// ParseRoutes разбирает бюджеты маршрутов вида
+// "POST /pullRequest/create=5:10,GET /users/getReview=20:40"
+func ParseRoutes(s string) (map[string]Limit, error) {
+	routes := map[string]Limit{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		route, limit, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("route limit %q: expected \"METHOD /path=rps:burst\"", item)
+		}
+		method, path, ok := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || method == "" || !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("route limit %q: expected \"METHOD /path=rps:burst\"", item)
+		}
+		l, err := ParseLimit(limit)
+		if err != nil {
+			return nil, err
+		}
+		routes[strings.ToUpper(method)+" "+path] = l
+	}
+	return routes, nil
+}
+
+// Result итог проверки, значения для заголовков X-RateLimit-*
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // через сколько бюджет восстановится полностью
+	RetryAfter time.Duration // через сколько можно повторить, если запрос отклонен
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	limit    Limit
+	lastSeen time.Time
+}
+
+// Limiter хранит bucket на пару клиент + маршрут. Маршруты без своего бюджета
+// делят общий bucket клиента.
+type Limiter struct {
+	mu      sync.Mutex
+	def     Limit
+	routes  map[string]Limit
+	buckets map[string]*bucket
+	idleTTL time.Duration
+}
+
+func New(def Limit, routes map[string]Limit) *Limiter {
+	return &Limiter{
+		def:     def,
+		routes:  routes,
+		buckets: make(map[string]*bucket),
+		idleTTL: 10 * time.Minute,
+	}
+}
+
+// Allow списывает запрос клиента key на маршруте route ("METHOD /pattern")
+func (l *Limiter) Allow(key, route string, now time.Time) Result {
+	return l.AllowN(key, route, 1, now)
+}
+
+// AllowN списывает n единиц бюджета сразу, например элементы пакетного запроса.
+// Больше Burst bucket не вмещает, поэтому такой запрос забирает весь бюджет.
+func (l *Limiter) AllowN(key, route string, n int, now time.Time) Result {
+	limit, ok := l.routes[route]
+	bucketKey := key
+	if ok {
+		bucketKey = key + "|" + route
+	} else {
+		limit = l.def
+	}
+
+	l.mu.Lock()
+	b, ok := l.buckets[bucketKey]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(limit.RPS), limit.Burst), limit: limit}
+		l.buckets[bucketKey] = b
+	}
+	b.lastSeen = now
+	l.mu.Unlock()
+
+	res := Result{Limit: limit.Burst}
+
+	r := b.limiter.ReserveN(now, min(n, limit.Burst))
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		res.RetryAfter = delay
+	} else {
+		res.Allowed = true
+	}
+
+	tokens := b.limiter.TokensAt(now)
+	res.Remaining = int(math.Max(0, math.Floor(tokens)))
+	missing := float64(limit.Burst) - math.Max(0, tokens)
+	res.Reset = time.Duration(missing / limit.RPS * float64(time.Second))
+	return res
+}
+
+// Size число активных bucket'ов
+func (l *Limiter) Size() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// Run периодически удаляет bucket'ы клиентов, которые давно не приходили
+func (l *Limiter) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			l.sweep(now)
+		}
+	}
+}
+
+func (l *Limiter) sweep(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for k, b := range l.buckets {
+		if now.Sub(b.lastSeen) > l.idleTTL {
+			delete(l.buckets, k)
+		}
+	}
+}
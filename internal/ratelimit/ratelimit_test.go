@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestAllow(t *testing.T) {
+	now := time.Now()
+	l := New(Limit{RPS: 2, Burst: 2}, map[string]Limit{"POST /pullRequest/create": {RPS: 1, Burst: 1}})
+
+	for i := 0; i < 2; i++ {
+		if res := l.Allow("a", "GET /team/get", now); !res.Allowed || res.Remaining != 1-i {
+			t.Fatalf("request %d: %+v", i, res)
+		}
+	}
+	res := l.Allow("a", "GET /users/getReview", now)
+	if res.Allowed || res.RetryAfter != 500*time.Millisecond || res.Reset != time.Second {
+		t.Fatalf("expected rejection with retry after 500ms, got %+v", res)
+	}
+	if res := l.Allow("a", "GET /team/get", now.Add(500*time.Millisecond)); !res.Allowed {
+		t.Fatalf("budget must refill after Retry-After, got %+v", res)
+	}
+
+	// у маршрута свой bucket, у другого клиента свой
+	if res := l.Allow("a", "POST /pullRequest/create", now); !res.Allowed || res.Limit != 1 {
+		t.Fatalf("route budget must be separate, got %+v", res)
+	}
+	if res := l.Allow("a", "POST /pullRequest/create", now); res.Allowed || res.RetryAfter != time.Second {
+		t.Fatalf("expected route budget exhausted, got %+v", res)
+	}
+	if res := l.Allow("b", "GET /team/get", now); !res.Allowed {
+		t.Fatalf("other client must have its own budget, got %+v", res)
+	}
+}
+
+func TestAllowN(t *testing.T) {
+	now := time.Now()
+	l := New(Limit{RPS: 10, Burst: 5}, nil)
+
+	if res := l.AllowN("a", "POST /pullRequest/batchCreate", 3, now); !res.Allowed || res.Remaining != 2 {
+		t.Fatalf("expected 3 units charged, got %+v", res)
+	}
+	if res := l.AllowN("a", "POST /pullRequest/batchCreate", 3, now); res.Allowed || res.RetryAfter != 100*time.Millisecond {
+		t.Fatalf("expected rejection, got %+v", res)
+	}
+	// отклоненный запрос бюджет не тратит
+	if res := l.AllowN("a", "POST /pullRequest/batchCreate", 2, now); !res.Allowed {
+		t.Fatalf("rejected request must not consume budget, got %+v", res)
+	}
+
+	// запрос больше всплеска забирает весь бюджет, а не отклоняется навсегда
+	if res := l.AllowN("b", "POST /pullRequest/batchCreate", 50, now); !res.Allowed || res.Remaining != 0 {
+		t.Fatalf("expected full budget charged, got %+v", res)
+	}
+}
+
+func TestSweep(t *testing.T) {
+	now := time.Now()
+	l := New(Limit{RPS: 1, Burst: 1}, nil)
+	l.Allow("old", "GET /team/get", now)
+	l.Allow("new", "GET /team/get", now.Add(l.idleTTL))
+
+	l.sweep(now.Add(l.idleTTL + time.Second))
+	if l.Size() != 1 {
+		t.Fatalf("expected only the recent bucket to remain, got %d", l.Size())
+	}
+}
+
+func TestConcurrencyLimiter(t *testing.T) {
+	c := NewConcurrencyLimiter(1, 20*time.Millisecond)
+	if !c.Acquire(context.Background()) {
+		t.Fatal("first acquire must succeed")
+	}
+	if c.Acquire(context.Background()) {
+		t.Fatal("acquire over the limit must fail after wait")
+	}
+
+	// ожидающий запрос получает освободившийся слот
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		c.Release()
+	}()
+	if !c.Acquire(context.Background()) {
+		t.Fatal("acquire must get the released slot")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if c.Acquire(ctx) {
+		t.Fatal("acquire must stop waiting on canceled context")
+	}
+	if c.InFlight() != 1 || c.Max() != 1 {
+		t.Fatalf("unexpected state: in flight %d, max %d", c.InFlight(), c.Max())
+	}
+}
@@ -2,25 +2,47 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"github.com/Olzerq/avito-pr-reviewer/internal/tracing"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
-	"log"
+	"log/slog"
 	"time"
 )
 
-func NewDB(ctx context.Context, connString string) *pgxpool.Pool {
+// PoolOptions лимиты пула соединений
+type PoolOptions struct {
+	MinConns        int32
+	MaxConns        int32
+	MaxConnLifetime time.Duration
+	MaxConnIdleTime time.Duration
+}
+
+// NewDB создает пул соединений и проверяет доступность БД. Если БД недоступна, пул закрывается.
+func NewDB(ctx context.Context, connString string, opts PoolOptions) (*pgxpool.Pool, error) {
 	cfg, err := pgxpool.ParseConfig(connString)
 	if err != nil {
-		log.Fatalf("Failed to parse db conf: %v", err)
+		return nil, fmt.Errorf("parse db config: %w", err)
 	}
 
 	// Настройки пула, минимальное и максимальное кол-во соединений
-	cfg.MinConns = 1
-	cfg.MaxConns = 10
+	cfg.MinConns = opts.MinConns
+	cfg.MaxConns = opts.MaxConns
+	if opts.MaxConnLifetime > 0 {
+		cfg.MaxConnLifetime = opts.MaxConnLifetime
+	}
+	if opts.MaxConnIdleTime > 0 {
+		cfg.MaxConnIdleTime = opts.MaxConnIdleTime
+	}
+
+	// Трейсинг запросов и ожидания соединений из пула
+	cfg.ConnConfig.Tracer = tracing.NewPgxTracer()
 
 	// Создаем пул
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
 	if err != nil {
-		log.Fatalf("Failed to create db pool: %v", err)
+		return nil, fmt.Errorf("create db pool: %w", err)
 	}
 
 	// Проверяем соединение
@@ -28,10 +50,98 @@ func NewDB(ctx context.Context, connString string) *pgxpool.Pool {
 	defer cancel()
 
 	if err := pool.Ping(ctxPing); err != nil {
-		log.Fatalf("Failed to ping db: %v", err)
+		pool.Close()
+		return nil, fmt.Errorf("ping db: %w", err)
+	}
+
+	slog.Info("connected to db")
+
+	return pool, nil
+}
+
+// AdvisoryLock берет сессионный advisory lock в Postgres, дожидаясь его освобождения
+func AdvisoryLock(ctx context.Context, db *pgxpool.Pool, key int64) (unlock func(), err error) {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, key); err != nil {
+		conn.Release()
+		return nil, err
+	}
+	return advisoryUnlock(conn, key), nil
+}
+
+// TryAdvisoryLock пытается взять сессионный advisory lock в Postgres.
+// Если лок занят другой репликой, возвращает ok=false.
+func TryAdvisoryLock(ctx context.Context, db *pgxpool.Pool, key int64) (unlock func(), ok bool, err error) {
+	conn, err := db.Acquire(ctx)
+	if err != nil {
+		return nil, false, err
 	}
 
-	log.Println("Successfully connected to db")
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&ok); err != nil {
+		conn.Release()
+		return nil, false, err
+	}
+	if !ok {
+		conn.Release()
+		return nil, false, nil
+	}
 
-	return pool
+	return advisoryUnlock(conn, key), true, nil
+}
+
+// advisoryUnlock снимает лок на том же соединении, на котором его брали
+func advisoryUnlock(conn *pgxpool.Conn, key int64) func() {
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, key); err != nil {
+			slog.Error("failed to release advisory lock", slog.Int64("key", key), slog.Any("error", err))
+			// закрываем соединение, чтобы лок не остался висеть в пуле
+			_ = conn.Conn().Close(ctx)
+		}
+		conn.Release()
+	}
+}
+
+// CheckSchemaVersion проверяет, что миграции применены не ниже ожидаемой версии и не в грязном состоянии.
+// Более новая схема допустима: при rolling deploy старые реплики продолжают работать,
+// пока новая применяет миграции (миграции обязаны быть обратно совместимыми).
+func CheckSchemaVersion(ctx context.Context, db *pgxpool.Pool, expected int64) error {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := db.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("migrations are not applied")
+		}
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	if version < expected {
+		return fmt.Errorf("schema version is %d, expected %d", version, expected)
+	}
+	return nil
+}
+
+// CheckPoolSaturation возвращает ошибку, если занята доля соединений пула не меньше threshold
+func CheckPoolSaturation(db *pgxpool.Pool, threshold float64) error {
+	stat := db.Stat()
+	if stat.MaxConns() == 0 {
+		return nil
+	}
+
+	saturation := float64(stat.AcquiredConns()) / float64(stat.MaxConns())
+	if saturation >= threshold {
+		return fmt.Errorf("pool saturated: %d of %d connections in use", stat.AcquiredConns(), stat.MaxConns())
+	}
+	return nil
 }
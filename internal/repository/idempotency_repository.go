@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// IdempotencyRecord сохраненный запрос с Idempotency-Key
+type IdempotencyRecord struct {
+	Method       string
+	Path         string
+	RequestHash  string
+	StatusCode   int // 0 пока запрос выполняется
+	ContentType  string
+	ETag         string
+	Location     string
+	ResponseBody []byte
+	// LockedAt время захвата ключа. Claim возвращает его владельцу, а Complete и
+	// Release по нему проверяют, что ключ не забрал повтор после таймаута блокировки.
+	LockedAt time.Time
+}
+
+// Completed ответ уже сохранен
+func (r IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
+
+type IdempotencyRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewIdempotencyRepository(db *pgxpool.Pool) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Claim занимает ключ под новый запрос. Если ключ уже занят и не истек,
+// возвращает claimed=false и сохраненную запись. Незавершенный запрос с тем же
+// телом, захваченный раньше lockTimeout назад, считается брошенным упавшим
+// процессом, и ключ занимается заново. Занятый ключ возвращается с LockedAt.
+func (r *IdempotencyRepository) Claim(
+	ctx context.Context,
+	scope, key string,
+	rec IdempotencyRecord,
+	ttl, lockTimeout time.Duration,
+) (claimed bool, existing IdempotencyRecord, err error) {
+	// истекший ключ переиспользуем, как будто его не было
+	err = r.db.QueryRow(ctx,
+		`INSERT INTO idempotency_keys (scope, key, method, path, request_hash, created_at, expires_at, locked_at)
+         VALUES ($1, $2, $3, $4, $5, now(), now() + make_interval(secs => $6), now())
+         ON CONFLICT (scope, key) DO UPDATE
+         SET method        = EXCLUDED.method,
+             path          = EXCLUDED.path,
+             request_hash  = EXCLUDED.request_hash,
+             status_code   = NULL,
+             content_type  = NULL,
+             etag          = NULL,
+             location      = NULL,
+             response_body = NULL,
+             created_at    = EXCLUDED.created_at,
+             expires_at    = EXCLUDED.expires_at,
+             locked_at     = EXCLUDED.locked_at
+         WHERE idempotency_keys.expires_at < now()
+            OR (idempotency_keys.status_code IS NULL
+                AND idempotency_keys.request_hash = EXCLUDED.request_hash
+                AND idempotency_keys.locked_at < now() - make_interval(secs => $7))
+         RETURNING locked_at`,
+		scope, key, rec.Method, rec.Path, rec.RequestHash, ttl.Seconds(), lockTimeout.Seconds(),
+	).Scan(&existing.LockedAt)
+	if err == nil {
+		return true, existing, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return false, IdempotencyRecord{}, err
+	}
+
+	var status *int
+	var contentType, etag, location *string
+	err = r.db.QueryRow(ctx,
+		`SELECT method, path, request_hash, status_code, content_type, etag, location, response_body
+         FROM idempotency_keys
+         WHERE scope = $1 AND key = $2`,
+		scope, key,
+	).Scan(&existing.Method, &existing.Path, &existing.RequestHash, &status, &contentType, &etag, &location, &existing.ResponseBody)
+	if err != nil {
+		// ключ успели удалить между запросами, клиенту достаточно повторить
+		return false, IdempotencyRecord{}, err
+	}
+	if status != nil {
+		existing.StatusCode = *status
+	}
+	if contentType != nil {
+		existing.ContentType = *contentType
+	}
+	if etag != nil {
+		existing.ETag = *etag
+	}
+	if location != nil {
+		existing.Location = *location
+	}
+	return false, existing, nil
+}
+
+// Complete сохраняет ответ на запрос: статус, тело и заголовки из resp. Если ключ
+// после таймаута блокировки занял повтор (LockedAt не совпадает), ничего не меняет.
+func (r *IdempotencyRepository) Complete(ctx context.Context, scope, key string, resp IdempotencyRecord) error {
+	_, err := r.db.Exec(ctx,
+		`UPDATE idempotency_keys
+         SET status_code = $3, content_type = $4, etag = $5, location = $6, response_body = $7
+         WHERE scope = $1 AND key = $2 AND locked_at = $8`,
+		scope, key, resp.StatusCode, resp.ContentType, resp.ETag, resp.Location, resp.ResponseBody, resp.LockedAt,
+	)
+	return err
+}
+
+// Release освобождает ключ, если запрос не удалось выполнить и его можно повторить.
+// Ключ, который уже занял повтор, не трогает.
+func (r *IdempotencyRepository) Release(ctx context.Context, scope, key string, lockedAt time.Time) error {
+	_, err := r.db.Exec(ctx,
+		`DELETE FROM idempotency_keys
+         WHERE scope = $1 AND key = $2 AND locked_at = $3`,
+		scope, key, lockedAt,
+	)
+	return err
+}
+
+// DeleteExpired удаляет истекшие ключи
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	tag, err := r.db.Exec(ctx, `DELETE FROM idempotency_keys WHERE expires_at < now()`)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
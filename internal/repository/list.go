@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+)
+
+// Поля сортировки списков. Последним ключом сортировки всегда идет id,
+// поэтому порядок однозначный и страницы не пересекаются.
+const (
+	SortByID        = "id"
+	SortByName      = "name"
+	SortByCreatedAt = "created_at"
+)
+
+// Page запрос страницы списка (keyset пагинация)
+type Page struct {
+	Limit int
+	Sort  string // одно из SortBy*
+	Desc  bool
+	After *Cursor // последняя запись предыдущей страницы, nil - с начала
+}
+
+// Cursor позиция в списке: значение поля сортировки в текстовом виде и id записи.
+// Клиентам он отдается закодированным, см. service.
+type Cursor struct {
+	Value string
+	ID    string
+}
+
+// PullRequestFilter фильтры списка PR, пустые поля не ограничивают выборку
+type PullRequestFilter struct {
+	Status      string
+	AuthorID    string
+	ReviewerID  string
+	TeamName    string     // команда автора
+	CreatedFrom *time.Time // включительно
+	CreatedTo   *time.Time // не включительно
+	Name        string     // подстрока названия без учета регистра
+}
+
+// TeamFilter фильтры списка команд
+type TeamFilter struct {
+	TeamName string // точное название, им список ограничивается по правам
+	Name     string // подстрока названия без учета регистра
+}
+
+// UserFilter фильтры списка пользователей
+type UserFilter struct {
+	TeamName string
+	IsActive *bool
+	Name     string // подстрока username без учета регистра
+}
+
+// ContainsPattern шаблон LIKE для поиска подстроки, спецсимволы экранируются '\'
+func ContainsPattern(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return "%" + r.Replace(s) + "%"
+}
+
+// queryArgs аргументы собираемого запроса, add возвращает плейсхолдер $N
+type queryArgs []any
+
+func (a *queryArgs) add(v any) string {
+	*a = append(*a, v)
+	return "$" + strconv.Itoa(len(*a))
+}
+
+// keysetCondition условие "после курсора" для сортировки по (col, idCol)
+func keysetCondition(col, idCol string, desc bool, value, id string) string {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	return "(" + col + ", " + idCol + ") " + op + " (" + value + ", " + id + ")"
+}
+
+// orderBy сортировка по (col, idCol) в нужном направлении
+func orderBy(col, idCol string, desc bool) string {
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+	return " ORDER BY " + col + " " + dir + ", " + idCol + " " + dir
+}
+
+func whereClause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
+
+// PullRequestCursor позиция PR в списке, отсортированном по sort. Время в RFC3339Nano.
+func PullRequestCursor(pr model.PullRequest, sort string) *Cursor {
+	switch sort {
+	case SortByCreatedAt:
+		var createdAt time.Time
+		if pr.CreatedAt != nil {
+			createdAt = *pr.CreatedAt
+		}
+		return &Cursor{Value: createdAt.UTC().Format(time.RFC3339Nano), ID: pr.ID}
+	case SortByName:
+		return &Cursor{Value: pr.Name, ID: pr.ID}
+	default:
+		return &Cursor{Value: pr.ID, ID: pr.ID}
+	}
+}
+
+// UserCursor позиция пользователя в списке, отсортированном по sort
+func UserCursor(u model.User, sort string) *Cursor {
+	if sort == SortByName {
+		return &Cursor{Value: u.Username, ID: u.ID}
+	}
+	return &Cursor{Value: u.ID, ID: u.ID}
+}
+
+// TeamCursor позиция команды в списке, команды сортируются только по имени
+func TeamCursor(t model.Team, _ string) *Cursor {
+	return &Cursor{Value: t.Name, ID: t.Name}
+}
+
+// Paginate отрезает запись, прочитанную сверх лимита, и возвращает курсор следующей страницы.
+// Хранилища читают limit+1 записей, чтобы узнать, есть ли продолжение.
+func Paginate[T any](items []T, p Page, cursor func(T, string) *Cursor) ([]T, *Cursor) {
+	if len(items) <= p.Limit {
+		return items, nil
+	}
+	items = items[:p.Limit]
+	return items, cursor(items[len(items)-1], p.Sort)
+}
@@ -0,0 +1,154 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+)
+
+// sortableTime время фиксированной ширины, строки сравниваются как моменты времени
+const sortableTime = "2006-01-02T15:04:05.000000000Z"
+
+// sortKey значение поля сортировки и id записи
+type sortKey [2]string
+
+func compareKeys(a, b sortKey) int {
+	if c := strings.Compare(a[0], b[0]); c != 0 {
+		return c
+	}
+	return strings.Compare(a[1], b[1])
+}
+
+// afterKey ключ курсора в том же виде, что и ключи записей
+func afterKey(c *repository.Cursor, sort string) sortKey {
+	if sort == repository.SortByCreatedAt {
+		// курсор проверен сервисом, время в нем корректное
+		t, _ := time.Parse(time.RFC3339Nano, c.Value)
+		return sortKey{t.UTC().Format(sortableTime), c.ID}
+	}
+	return sortKey{c.Value, c.ID}
+}
+
+// paginate сортирует записи по key, пропускает все до курсора и возвращает страницу
+func paginate[T any](items []T, p repository.Page, key func(T) sortKey, cursor func(T, string) *repository.Cursor) ([]T, *repository.Cursor) {
+	dir := 1
+	if p.Desc {
+		dir = -1
+	}
+	slices.SortFunc(items, func(a, b T) int { return dir * compareKeys(key(a), key(b)) })
+
+	if p.After != nil {
+		after := afterKey(p.After, p.Sort)
+		start, _ := slices.BinarySearchFunc(items, after, func(item T, k sortKey) int {
+			// первая запись строго после курсора
+			if dir*compareKeys(key(item), k) <= 0 {
+				return -1
+			}
+			return 1
+		})
+		items = items[start:]
+	}
+	if len(items) > p.Limit+1 {
+		items = items[:p.Limit+1]
+	}
+	return repository.Paginate(items, p, cursor)
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+func (r teamRepo) List(_ context.Context, f repository.TeamFilter, p repository.Page) ([]model.Team, *repository.Cursor, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	teams := make([]model.Team, 0)
+	for name := range r.s.teams {
+		if (f.TeamName == "" || name == f.TeamName) && (f.Name == "" || containsFold(name, f.Name)) {
+			teams = append(teams, model.Team{Name: name})
+		}
+	}
+	teams, next := paginate(teams, p, func(t model.Team) sortKey { return sortKey{t.Name, t.Name} }, repository.TeamCursor)
+
+	for i := range teams {
+		teams[i].Users = make([]model.User, 0)
+		for _, u := range r.s.users {
+			if u.TeamName == teams[i].Name {
+				teams[i].Users = append(teams[i].Users, u.User)
+			}
+		}
+		slices.SortFunc(teams[i].Users, func(a, b model.User) int { return strings.Compare(a.ID, b.ID) })
+	}
+	return teams, next, nil
+}
+
+func (r userRepo) List(_ context.Context, f repository.UserFilter, p repository.Page) ([]model.User, *repository.Cursor, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	users := make([]model.User, 0)
+	for _, u := range r.s.users {
+		if f.TeamName != "" && u.TeamName != f.TeamName {
+			continue
+		}
+		if f.IsActive != nil && u.IsActive != *f.IsActive {
+			continue
+		}
+		if f.Name != "" && !containsFold(u.Username, f.Name) {
+			continue
+		}
+		users = append(users, u.User)
+	}
+
+	key := func(u model.User) sortKey { return sortKey{u.ID, u.ID} }
+	if p.Sort == repository.SortByName {
+		key = func(u model.User) sortKey { return sortKey{u.Username, u.ID} }
+	}
+	users, next := paginate(users, p, key, repository.UserCursor)
+	return users, next, nil
+}
+
+func (r prRepo) List(_ context.Context, f repository.PullRequestFilter, p repository.Page) ([]model.PullRequest, *repository.Cursor, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	prs := make([]model.PullRequest, 0)
+	for _, pr := range r.s.prs {
+		if f.Status != "" && pr.status != f.Status {
+			continue
+		}
+		if f.AuthorID != "" && pr.authorID != f.AuthorID {
+			continue
+		}
+		if f.TeamName != "" && r.s.users[pr.authorID].TeamName != f.TeamName {
+			continue
+		}
+		if f.ReviewerID != "" && !slices.ContainsFunc(pr.reviewers, func(rv reviewer) bool { return rv.userID == f.ReviewerID }) {
+			continue
+		}
+		if f.CreatedFrom != nil && pr.createdAt.Before(*f.CreatedFrom) {
+			continue
+		}
+		if f.CreatedTo != nil && !pr.createdAt.Before(*f.CreatedTo) {
+			continue
+		}
+		if f.Name != "" && !containsFold(pr.name, f.Name) {
+			continue
+		}
+		prs = append(prs, r.s.pullRequest(pr))
+	}
+
+	key := func(pr model.PullRequest) sortKey { return sortKey{pr.ID, pr.ID} }
+	switch p.Sort {
+	case repository.SortByCreatedAt:
+		key = func(pr model.PullRequest) sortKey { return sortKey{pr.CreatedAt.UTC().Format(sortableTime), pr.ID} }
+	case repository.SortByName:
+		key = func(pr model.PullRequest) sortKey { return sortKey{pr.Name, pr.ID} }
+	}
+	prs, next := paginate(prs, p, key, repository.PullRequestCursor)
+	return prs, next, nil
+}
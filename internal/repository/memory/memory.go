@@ -0,0 +1,645 @@
+// Package memory хранилище в памяти процесса для демо и локальной разработки.
+// Повторяет контракт Postgres реализации из пакета repository, данные теряются при остановке.
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+)
+
+type user struct {
+	model.User
+	role string
+}
+
+type reviewer struct {
+	userID     string
+	assignedAt time.Time
+	remindedAt *time.Time
+}
+
+type pullRequest struct {
+	id        string
+	name      string
+	authorID  string
+	status    string
+	createdAt time.Time
+	mergedAt  *time.Time
+	version   int64
+	reviewers []reviewer // в порядке назначения
+}
+
+type idempotencyKey struct {
+	rec       repository.IdempotencyRecord
+	expiresAt time.Time
+	lockedAt  time.Time
+}
+
+// state данные, которые меняются в транзакциях
+type state struct {
+	teams    map[string]struct{}
+	users    map[string]*user
+	prs      map[string]*pullRequest
+	prOrder  []string // порядок создания PR
+	events   []model.PullRequestEvent
+	eventSeq int64
+}
+
+// clone глубокая копия, изменения в ней не видны исходному состоянию
+func (st *state) clone() state {
+	c := state{
+		teams:    make(map[string]struct{}, len(st.teams)),
+		users:    make(map[string]*user, len(st.users)),
+		prs:      make(map[string]*pullRequest, len(st.prs)),
+		prOrder:  slices.Clone(st.prOrder),
+		eventSeq: st.eventSeq,
+		// события только дописываются, копия пишет за пределы исходной длины
+		events: st.events,
+	}
+	for name := range st.teams {
+		c.teams[name] = struct{}{}
+	}
+	for id, u := range st.users {
+		cu := *u
+		c.users[id] = &cu
+	}
+	for id, pr := range st.prs {
+		cpr := *pr
+		cpr.reviewers = slices.Clone(pr.reviewers)
+		c.prs[id] = &cpr
+	}
+	return c
+}
+
+// Store все данные хранилища под одним мьютексом, как в одной транзакции
+type Store struct {
+	mu sync.RWMutex
+
+	state
+	idem map[string]*idempotencyKey
+
+	locker *repository.ProcessLocker
+}
+
+// NewStore создает пустое хранилище
+func NewStore() *Store {
+	return &Store{
+		state: state{
+			teams: make(map[string]struct{}),
+			users: make(map[string]*user),
+			prs:   make(map[string]*pullRequest),
+		},
+		idem:   make(map[string]*idempotencyKey),
+		locker: repository.NewProcessLocker(),
+	}
+}
+
+// NewStorage репозитории поверх нового пустого хранилища
+func NewStorage() repository.Storage {
+	return NewStore().Storage()
+}
+
+// Storage репозитории поверх этого хранилища
+func (s *Store) Storage() repository.Storage {
+	return repository.Storage{
+		Teams:        teamRepo{s},
+		Users:        userRepo{s},
+		PullRequests: prRepo{s},
+		Stats:        statsRepo{s},
+		Idempotency:  idempotencyRepo{s},
+		Tx:           txManager{s},
+		// других реплик у хранилища в памяти нет
+		Locker: s.locker,
+	}
+}
+
+// txManager реализует repository.TxManager. Транзакция держит мьютекс хранилища
+// и работает с копией данных, которая заменяет исходные только при успехе.
+type txManager struct{ s *Store }
+
+func (m txManager) InTx(_ context.Context, fn func(tx repository.Repos) error) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	// у копии свой мьютекс, репозитории внутри транзакции не ждут внешний
+	tx := &Store{state: m.s.state.clone()}
+	if err := fn(repository.Repos{
+		Teams:        teamRepo{tx},
+		Users:        userRepo{tx},
+		PullRequests: prRepo{tx},
+		Stats:        statsRepo{tx},
+	}); err != nil {
+		return err
+	}
+	m.s.state = tx.state
+	return nil
+}
+
+func (s *Store) addEvent(e model.PullRequestEvent) {
+	s.eventSeq++
+	e.ID = s.eventSeq
+	s.events = append(s.events, e)
+}
+
+// teamRepo реализует repository.Teams
+type teamRepo struct{ s *Store }
+
+func (r teamRepo) CreateTeam(_ context.Context, team model.Team) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.teams[team.Name]; ok {
+		return repository.ErrTeamExists
+	}
+	r.s.teams[team.Name] = struct{}{}
+
+	// существующих пользователей переносим в команду, роль сохраняется
+	for _, u := range team.Users {
+		u.TeamName = team.Name
+		if existing, ok := r.s.users[u.ID]; ok {
+			existing.User = u
+			continue
+		}
+		r.s.users[u.ID] = &user{User: u, role: model.RoleMember}
+	}
+	return nil
+}
+
+func (r teamRepo) GetTeam(_ context.Context, teamName string) (model.Team, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	if _, ok := r.s.teams[teamName]; !ok {
+		return model.Team{}, repository.ErrTeamNotFound
+	}
+
+	team := model.Team{Name: teamName, Users: make([]model.User, 0)}
+	for _, u := range r.s.users {
+		if u.TeamName == teamName {
+			team.Users = append(team.Users, u.User)
+		}
+	}
+	sort.Slice(team.Users, func(i, j int) bool { return team.Users[i].ID < team.Users[j].ID })
+	return team, nil
+}
+
+// userRepo реализует repository.Users
+type userRepo struct{ s *Store }
+
+func (r userRepo) SetIsActive(_ context.Context, userID string, isActive bool) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.users[userID]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	u.IsActive = isActive
+	return nil
+}
+
+func (r userRepo) GetByID(_ context.Context, userID string) (model.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	u, ok := r.s.users[userID]
+	if !ok {
+		return model.User{}, repository.ErrUserNotFound
+	}
+	return u.User, nil
+}
+
+func (r userRepo) GetRole(_ context.Context, userID string) (string, string, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	u, ok := r.s.users[userID]
+	if !ok {
+		return "", "", repository.ErrUserNotFound
+	}
+	return u.role, u.TeamName, nil
+}
+
+func (r userRepo) SetRole(_ context.Context, userID, role string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.users[userID]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	if !model.ValidRole(role) {
+		// в Postgres это ограничение CHECK на колонке
+		return fmt.Errorf("invalid role %q", role)
+	}
+	u.role = role
+	return nil
+}
+
+func (r userRepo) GetActiveByTeamExcept(_ context.Context, teamName string, excludeIDs []string) ([]model.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var res []model.User
+	for _, u := range r.s.users {
+		if u.TeamName == teamName && u.IsActive && !slices.Contains(excludeIDs, u.ID) {
+			res = append(res, u.User)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].ID < res[j].ID })
+	return res, nil
+}
+
+// prRepo реализует repository.PullRequests
+type prRepo struct{ s *Store }
+
+func (r prRepo) Create(_ context.Context, pr model.PullRequest, actor string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.prs[pr.ID]; ok {
+		return repository.ErrPRExists
+	}
+	// внешние ключи, как в схеме Postgres
+	if _, ok := r.s.users[pr.AuthorID]; !ok {
+		return fmt.Errorf("author %q: %w", pr.AuthorID, repository.ErrUserNotFound)
+	}
+	for _, u := range pr.Reviewers {
+		if _, ok := r.s.users[u.ID]; !ok {
+			return fmt.Errorf("reviewer %q: %w", u.ID, repository.ErrUserNotFound)
+		}
+	}
+
+	now := time.Now().UTC()
+	if pr.CreatedAt != nil {
+		now = *pr.CreatedAt
+	}
+
+	stored := &pullRequest{
+		id:        pr.ID,
+		name:      pr.Name,
+		authorID:  pr.AuthorID,
+		status:    pr.Status,
+		createdAt: now,
+		version:   max(pr.Version, 1),
+	}
+	r.s.prs[pr.ID] = stored
+	r.s.prOrder = append(r.s.prOrder, pr.ID)
+
+	r.s.addEvent(model.PullRequestEvent{
+		PullRequestID: pr.ID,
+		Type:          model.EventPRCreated,
+		UserID:        pr.AuthorID,
+		Actor:         actor,
+		CreatedAt:     now,
+	})
+	for _, u := range pr.Reviewers {
+		stored.reviewers = append(stored.reviewers, reviewer{userID: u.ID, assignedAt: now})
+		r.s.addEvent(model.PullRequestEvent{
+			PullRequestID: pr.ID,
+			Type:          model.EventReviewerAssigned,
+			UserID:        u.ID,
+			Actor:         actor,
+			CreatedAt:     now,
+		})
+	}
+	return nil
+}
+
+func (r prRepo) GetByID(_ context.Context, prID string) (model.PullRequest, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	pr, ok := r.s.prs[prID]
+	if !ok {
+		return model.PullRequest{}, repository.ErrPRNotFound
+	}
+	return r.s.pullRequest(pr), nil
+}
+
+// pullRequest копия PR с ревьюверами в виде модели
+func (s *Store) pullRequest(pr *pullRequest) model.PullRequest {
+	createdAt := pr.createdAt
+	res := model.PullRequest{
+		ID:        pr.id,
+		Name:      pr.name,
+		AuthorID:  pr.authorID,
+		Status:    pr.status,
+		CreatedAt: &createdAt,
+		Version:   pr.version,
+		Reviewers: make([]model.User, 0, len(pr.reviewers)),
+	}
+	if pr.mergedAt != nil {
+		mergedAt := *pr.mergedAt
+		res.MergedAt = &mergedAt
+	}
+	for _, rv := range pr.reviewers {
+		res.Reviewers = append(res.Reviewers, s.users[rv.userID].User)
+	}
+	return res
+}
+
+func (r prRepo) ReassignReviewer(_ context.Context, prID string, version int64, oldUserID, newUserID, eventType, actor string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	pr, ok := r.s.prs[prID]
+	if !ok || pr.version != version || pr.status != "OPEN" {
+		return repository.ErrVersionConflict
+	}
+
+	idx := slices.IndexFunc(pr.reviewers, func(rv reviewer) bool { return rv.userID == oldUserID })
+	if idx < 0 {
+		return repository.ErrReviewerNotAssigned
+	}
+	if _, ok := r.s.users[newUserID]; !ok {
+		return fmt.Errorf("reviewer %q: %w", newUserID, repository.ErrUserNotFound)
+	}
+	if slices.ContainsFunc(pr.reviewers, func(rv reviewer) bool { return rv.userID == newUserID }) {
+		// в Postgres это нарушение первичного ключа pull_request_reviewers
+		return fmt.Errorf("reviewer %q is already assigned to %q", newUserID, prID)
+	}
+
+	now := time.Now().UTC()
+	pr.reviewers = slices.Delete(pr.reviewers, idx, idx+1)
+	pr.reviewers = append(pr.reviewers, reviewer{userID: newUserID, assignedAt: now})
+	pr.version++
+
+	r.s.addEvent(model.PullRequestEvent{
+		PullRequestID: prID,
+		Type:          eventType,
+		UserID:        newUserID,
+		OldUserID:     oldUserID,
+		Actor:         actor,
+		CreatedAt:     now,
+	})
+	return nil
+}
+
+func (r prRepo) MarkMerged(_ context.Context, prID string, version int64, mergedAt time.Time, actor string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	pr, ok := r.s.prs[prID]
+	if !ok || pr.status != "OPEN" || (version != 0 && pr.version != version) {
+		return repository.ErrVersionConflict
+	}
+
+	pr.status = "MERGED"
+	if pr.mergedAt == nil {
+		pr.mergedAt = &mergedAt
+	}
+	pr.version++
+
+	r.s.addEvent(model.PullRequestEvent{
+		PullRequestID: prID,
+		Type:          model.EventPRMerged,
+		Actor:         actor,
+		CreatedAt:     mergedAt,
+	})
+	return nil
+}
+
+func (r prRepo) GetOpenAssignments(_ context.Context) ([]model.ReviewAssignment, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var res []model.ReviewAssignment
+	for _, id := range r.s.prOrder {
+		pr := r.s.prs[id]
+		if pr.status != "OPEN" {
+			continue
+		}
+		for _, rv := range pr.reviewers {
+			a := model.ReviewAssignment{
+				PullRequestID:   pr.id,
+				PullRequestName: pr.name,
+				AuthorID:        pr.authorID,
+				TeamName:        r.s.users[pr.authorID].TeamName,
+				ReviewerID:      rv.userID,
+				ReviewerName:    r.s.users[rv.userID].Username,
+				AssignedAt:      rv.assignedAt,
+			}
+			if rv.remindedAt != nil {
+				remindedAt := *rv.remindedAt
+				a.RemindedAt = &remindedAt
+			}
+			res = append(res, a)
+		}
+	}
+	sort.SliceStable(res, func(i, j int) bool { return res[i].AssignedAt.Before(res[j].AssignedAt) })
+	return res, nil
+}
+
+func (r prRepo) MarkReminded(_ context.Context, prID, userID string, at time.Time, actor string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	pr, ok := r.s.prs[prID]
+	if !ok {
+		return repository.ErrReviewerNotAssigned
+	}
+	idx := slices.IndexFunc(pr.reviewers, func(rv reviewer) bool { return rv.userID == userID })
+	if idx < 0 {
+		return repository.ErrReviewerNotAssigned
+	}
+	pr.reviewers[idx].remindedAt = &at
+
+	r.s.addEvent(model.PullRequestEvent{
+		PullRequestID: prID,
+		Type:          model.EventReviewerReminded,
+		UserID:        userID,
+		Actor:         actor,
+		CreatedAt:     at,
+	})
+	return nil
+}
+
+func (r prRepo) AddEvent(_ context.Context, event model.PullRequestEvent) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.prs[event.PullRequestID]; !ok {
+		return fmt.Errorf("pull request %q: %w", event.PullRequestID, repository.ErrPRNotFound)
+	}
+	r.s.addEvent(event)
+	return nil
+}
+
+func (r prRepo) GetEvents(_ context.Context, prID string) ([]model.PullRequestEvent, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	events := make([]model.PullRequestEvent, 0)
+	for _, e := range r.s.events {
+		if e.PullRequestID == prID {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (r prRepo) GetReviewQueueEvents(_ context.Context, userID string, afterID int64, limit int) ([]model.ReviewQueueEvent, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	events := make([]model.ReviewQueueEvent, 0)
+	for _, e := range r.s.events {
+		if len(events) == limit {
+			break
+		}
+		if e.ID <= afterID {
+			continue
+		}
+		pr := r.s.prs[e.PullRequestID]
+
+		var t string
+		switch {
+		case e.Type == model.EventPRMerged && slices.ContainsFunc(pr.reviewers, func(rv reviewer) bool { return rv.userID == userID }):
+			t = model.ReviewQueueMerged
+		case model.ReplacesReviewer(e.Type) && e.OldUserID == userID:
+			t = model.ReviewQueueUnassigned
+		case (e.Type == model.EventReviewerAssigned || model.ReplacesReviewer(e.Type)) && e.UserID == userID:
+			t = model.ReviewQueueAssigned
+		default:
+			continue
+		}
+
+		status := "OPEN"
+		if t == model.ReviewQueueMerged {
+			status = "MERGED"
+		}
+		events = append(events, model.ReviewQueueEvent{
+			ID:          e.ID,
+			Type:        t,
+			UserID:      userID,
+			PullRequest: model.PullRequestShort{ID: pr.id, Name: pr.name, AuthorID: pr.authorID, Status: status},
+			At:          e.CreatedAt,
+		})
+	}
+	return events, nil
+}
+
+func (r prRepo) LastEventID(_ context.Context, _ string) (int64, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return r.s.eventSeq, nil
+}
+
+// statsRepo реализует repository.Stats
+type statsRepo struct{ s *Store }
+
+func (r statsRepo) GetAssignmentsByUser(_ context.Context) ([]repository.UserAssignmentsStat, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, pr := range r.s.prs {
+		for _, rv := range pr.reviewers {
+			counts[rv.userID]++
+		}
+	}
+
+	stats := make([]repository.UserAssignmentsStat, 0, len(counts))
+	for id, n := range counts {
+		stats = append(stats, repository.UserAssignmentsStat{UserID: id, AssignedCount: n})
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].UserID < stats[j].UserID })
+	return stats, nil
+}
+
+func (r statsRepo) GetAssignmentsByPR(_ context.Context) ([]repository.PullRequestAssignmentsStat, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	stats := make([]repository.PullRequestAssignmentsStat, 0)
+	for _, id := range r.s.prOrder {
+		if n := len(r.s.prs[id].reviewers); n > 0 {
+			stats = append(stats, repository.PullRequestAssignmentsStat{PullRequestID: id, ReviewersCount: n})
+		}
+	}
+	return stats, nil
+}
+
+// idempotencyRepo реализует repository.IdempotencyKeys
+type idempotencyRepo struct{ s *Store }
+
+func idempotencyID(scope, key string) string {
+	return scope + "\x00" + key
+}
+
+func (r idempotencyRepo) Claim(
+	_ context.Context,
+	scope, key string,
+	rec repository.IdempotencyRecord,
+	ttl, lockTimeout time.Duration,
+) (bool, repository.IdempotencyRecord, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	id := idempotencyID(scope, key)
+	if existing, ok := r.s.idem[id]; ok && !existing.expiresAt.Before(now) {
+		// брошенный запрос с тем же телом занимается заново
+		stale := !existing.rec.Completed() && existing.rec.RequestHash == rec.RequestHash &&
+			existing.lockedAt.Before(now.Add(-lockTimeout))
+		if !stale {
+			res := existing.rec
+			res.ResponseBody = slices.Clone(res.ResponseBody)
+			return false, res, nil
+		}
+	}
+
+	rec.StatusCode, rec.ContentType, rec.ETag, rec.Location, rec.ResponseBody = 0, "", "", "", nil
+	r.s.idem[id] = &idempotencyKey{rec: rec, expiresAt: now.Add(ttl), lockedAt: now}
+	return true, repository.IdempotencyRecord{LockedAt: now}, nil
+}
+
+func (r idempotencyRepo) Complete(_ context.Context, scope, key string, resp repository.IdempotencyRecord) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	// ключ, который после таймаута блокировки занял повтор, не трогаем
+	if k, ok := r.s.idem[idempotencyID(scope, key)]; ok && k.lockedAt.Equal(resp.LockedAt) {
+		k.rec.StatusCode = resp.StatusCode
+		k.rec.ContentType = resp.ContentType
+		k.rec.ETag = resp.ETag
+		k.rec.Location = resp.Location
+		k.rec.ResponseBody = slices.Clone(resp.ResponseBody)
+	}
+	return nil
+}
+
+func (r idempotencyRepo) Release(_ context.Context, scope, key string, lockedAt time.Time) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	id := idempotencyID(scope, key)
+	if k, ok := r.s.idem[id]; ok && k.lockedAt.Equal(lockedAt) {
+		delete(r.s.idem, id)
+	}
+	return nil
+}
+
+func (r idempotencyRepo) DeleteExpired(_ context.Context) (int64, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+	var n int64
+	for id, k := range r.s.idem {
+		if k.expiresAt.Before(now) {
+			delete(r.s.idem, id)
+			n++
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,15 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository/memory"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository/repotest"
+)
+
+func TestStorage(t *testing.T) {
+	repotest.Run(t, func(*testing.T) repository.Storage {
+		return memory.NewStorage()
+	})
+}
@@ -0,0 +1,42 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/config"
+	"github.com/Olzerq/avito-pr-reviewer/internal/migrator"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository/repotest"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TestPostgresStorage прогоняет общий набор на БД из конфига (DB_* / DB_DSN),
+// если она недоступна - тест пропускается
+func TestPostgresStorage(t *testing.T) {
+	cfg, _, err := config.Load(nil)
+	if err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	db, err := pgxpool.New(ctx, cfg.DBConnStr())
+	if err != nil {
+		t.Fatalf("failed to create pool: %v", err)
+	}
+	t.Cleanup(db.Close)
+	if err := db.Ping(ctx); err != nil {
+		t.Skipf("postgres is not available: %v", err)
+	}
+
+	if err := migrator.UpLocked(ctx, db, cfg.DBConnStr()); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+
+	repotest.Run(t, func(*testing.T) repository.Storage {
+		return repository.NewStorage(db)
+	})
+}
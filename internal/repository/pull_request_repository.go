@@ -3,10 +3,10 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/Olzerq/avito-pr-reviewer/internal/model"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 	"time"
 )
 
@@ -14,17 +14,18 @@ var (
 	ErrPRExists            = errors.New("pull request already exists")
 	ErrPRNotFound          = errors.New("pull request not found")
 	ErrReviewerNotAssigned = errors.New("reviewer not assigned to this pull request")
+	ErrVersionConflict     = errors.New("pull request was modified concurrently")
 )
 
 type PullRequestRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
-func NewPullRequestRepository(db *pgxpool.Pool) *PullRequestRepository {
+func NewPullRequestRepository(db DBTX) *PullRequestRepository {
 	return &PullRequestRepository{db: db}
 }
 
-func (r *PullRequestRepository) Create(ctx context.Context, pr model.PullRequest) error {
+func (r *PullRequestRepository) Create(ctx context.Context, pr model.PullRequest, actor string) error {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return err
@@ -32,32 +33,57 @@ func (r *PullRequestRepository) Create(ctx context.Context, pr model.PullRequest
 	defer tx.Rollback(ctx)
 
 	now := time.Now().UTC()
+	if pr.CreatedAt != nil {
+		now = *pr.CreatedAt
+	}
 
 	_, err = tx.Exec(ctx,
-		`INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, merged_at)
-		 VALUES ($1, $2, $3, $4, NULL)`,
-		pr.ID, pr.Name, pr.AuthorID, pr.Status,
+		`INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, merged_at, version)
+		 VALUES ($1, $2, $3, $4, $5, NULL, $6)`,
+		pr.ID, pr.Name, pr.AuthorID, pr.Status, now, max(pr.Version, 1),
 	)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
 			return ErrPRExists
 		}
+		// 23503 — автора нет в users
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return fmt.Errorf("author %q: %w", pr.AuthorID, ErrUserNotFound)
+		}
+		return err
+	}
+
+	if err := insertEvent(ctx, tx, model.PullRequestEvent{
+		PullRequestID: pr.ID,
+		Type:          model.EventPRCreated,
+		UserID:        pr.AuthorID,
+		Actor:         actor,
+		CreatedAt:     now,
+	}); err != nil {
 		return err
 	}
 
 	for _, u := range pr.Reviewers {
 		_, err = tx.Exec(ctx,
-			`INSERT INTO pull_request_reviewers (pull_request_id, user_id)
-             VALUES ($1, $2)`,
-			pr.ID, u.ID,
+			`INSERT INTO pull_request_reviewers (pull_request_id, user_id, assigned_at)
+             VALUES ($1, $2, $3)`,
+			pr.ID, u.ID, now,
 		)
 		if err != nil {
 			return err
 		}
-	}
 
-	_ = now
+		if err := insertEvent(ctx, tx, model.PullRequestEvent{
+			PullRequestID: pr.ID,
+			Type:          model.EventReviewerAssigned,
+			UserID:        u.ID,
+			Actor:         actor,
+			CreatedAt:     now,
+		}); err != nil {
+			return err
+		}
+	}
 
 	return tx.Commit(ctx)
 }
@@ -67,11 +93,11 @@ func (r *PullRequestRepository) GetByID(ctx context.Context, prID string) (model
 	var pr model.PullRequest
 
 	err := r.db.QueryRow(ctx,
-		`SELECT pull_request_id, pull_request_name, author_id, status, merged_at
+		`SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at, version
          FROM pull_requests
          WHERE pull_request_id = $1`,
 		prID,
-	).Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.MergedAt)
+	).Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.Version)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return model.PullRequest{}, ErrPRNotFound
@@ -103,16 +129,32 @@ func (r *PullRequestRepository) GetByID(ctx context.Context, prID string) (model
 	return pr, rows.Err()
 }
 
-// ReassignReviewer Переназначает ревьюера
-func (r *PullRequestRepository) ReassignReviewer(ctx context.Context, prID, oldUserID, newUserID string) error {
+// ReassignReviewer Переназначает ревьюера, если PR открыт и его версия не изменилась с момента чтения.
+// Иначе возвращает ErrVersionConflict, и сервис перечитывает PR.
+func (r *PullRequestRepository) ReassignReviewer(ctx context.Context, prID string, version int64, oldUserID, newUserID, eventType, actor string) error {
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback(ctx)
 
-	// удаляем старого ревьюера
+	// строка PR остается заблокированной до конца транзакции,
+	// параллельные изменения дождутся коммита и не совпадут по версии
 	cmdTag, err := tx.Exec(ctx,
+		`UPDATE pull_requests
+         SET version = version + 1
+         WHERE pull_request_id = $1 AND version = $2 AND status = 'OPEN'`,
+		prID, version,
+	)
+	if err != nil {
+		return err
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrVersionConflict
+	}
+
+	// удаляем старого ревьюера
+	cmdTag, err = tx.Exec(ctx,
 		`DELETE FROM pull_request_reviewers
          WHERE pull_request_id = $1 AND user_id = $2`,
 		prID, oldUserID,
@@ -126,64 +168,384 @@ func (r *PullRequestRepository) ReassignReviewer(ctx context.Context, prID, oldU
 		return ErrReviewerNotAssigned
 	}
 
+	now := time.Now().UTC()
+
 	// добавляем нового
 	_, err = tx.Exec(ctx,
-		`INSERT INTO pull_request_reviewers (pull_request_id, user_id)
-         VALUES ($1, $2)`,
-		prID, newUserID,
+		`INSERT INTO pull_request_reviewers (pull_request_id, user_id, assigned_at)
+         VALUES ($1, $2, $3)`,
+		prID, newUserID, now,
 	)
 	if err != nil {
 		return err
 	}
 
+	if err := insertEvent(ctx, tx, model.PullRequestEvent{
+		PullRequestID: prID,
+		Type:          eventType,
+		UserID:        newUserID,
+		OldUserID:     oldUserID,
+		Actor:         actor,
+		CreatedAt:     now,
+	}); err != nil {
+		return err
+	}
+
 	return tx.Commit(ctx)
 }
 
-// MarkMerged обновляет флаг Merged
-func (r *PullRequestRepository) MarkMerged(ctx context.Context, prID string, mergedAt time.Time) error {
-	cmdTag, err := r.db.Exec(ctx,
+// MarkMerged обновляет флаг Merged, если PR еще открыт и (при version != 0) его версия
+// не изменилась с момента чтения. Иначе возвращает ErrVersionConflict.
+func (r *PullRequestRepository) MarkMerged(ctx context.Context, prID string, version int64, mergedAt time.Time, actor string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	cmdTag, err := tx.Exec(ctx,
 		`UPDATE pull_requests
          SET status = 'MERGED',
-             merged_at = COALESCE(merged_at, $2)
-         WHERE pull_request_id = $1`,
-		prID, mergedAt,
+             merged_at = COALESCE(merged_at, $3),
+             version = version + 1
+         WHERE pull_request_id = $1 AND status = 'OPEN' AND ($2::bigint = 0 OR version = $2)`,
+		prID, version, mergedAt,
 	)
 	if err != nil {
 		return err
 	}
 
 	if cmdTag.RowsAffected() == 0 {
-		return ErrPRNotFound
+		return ErrVersionConflict
+	}
+
+	if err := insertEvent(ctx, tx, model.PullRequestEvent{
+		PullRequestID: prID,
+		Type:          model.EventPRMerged,
+		Actor:         actor,
+		CreatedAt:     mergedAt,
+	}); err != nil {
+		return err
 	}
 
-	return nil
+	return tx.Commit(ctx)
 }
 
-// GetByReviewer получает PR'ы, где пользователь является ревьювером
-func (r *PullRequestRepository) GetByReviewer(ctx context.Context, userID string) ([]model.PullRequestShort, error) {
+// GetOpenAssignments возвращает все назначения ревьюверов на открытые PR
+func (r *PullRequestRepository) GetOpenAssignments(ctx context.Context) ([]model.ReviewAssignment, error) {
 	rows, err := r.db.Query(ctx,
-		`SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status
+		`SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, a.team_name,
+                u.user_id, u.username, prr.assigned_at, prr.reminded_at
          FROM pull_requests pr
-         JOIN pull_request_reviewers prr
-           ON pr.pull_request_id = prr.pull_request_id
-         WHERE prr.user_id = $1`,
-		userID,
+         JOIN users a ON a.user_id = pr.author_id
+         JOIN pull_request_reviewers prr ON prr.pull_request_id = pr.pull_request_id
+         JOIN users u ON u.user_id = prr.user_id
+         WHERE pr.status = 'OPEN'
+         ORDER BY prr.assigned_at`,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var result []model.PullRequestShort
+	var res []model.ReviewAssignment
 	for rows.Next() {
-		var pr model.PullRequestShort
-		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status); err != nil {
+		var a model.ReviewAssignment
+		if err := rows.Scan(&a.PullRequestID, &a.PullRequestName, &a.AuthorID, &a.TeamName,
+			&a.ReviewerID, &a.ReviewerName, &a.AssignedAt, &a.RemindedAt); err != nil {
 			return nil, err
 		}
-		result = append(result, pr)
+		res = append(res, a)
 	}
-	if err := rows.Err(); err != nil {
+	return res, rows.Err()
+}
+
+// MarkReminded фиксирует отправку напоминания ревьюверу
+func (r *PullRequestRepository) MarkReminded(ctx context.Context, prID, userID string, at time.Time, actor string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	cmdTag, err := tx.Exec(ctx,
+		`UPDATE pull_request_reviewers
+         SET reminded_at = $3
+         WHERE pull_request_id = $1 AND user_id = $2`,
+		prID, userID, at,
+	)
+	if err != nil {
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return ErrReviewerNotAssigned
+	}
+
+	if err := insertEvent(ctx, tx, model.PullRequestEvent{
+		PullRequestID: prID,
+		Type:          model.EventReviewerReminded,
+		UserID:        userID,
+		Actor:         actor,
+		CreatedAt:     at,
+	}); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// AddEvent добавляет событие в историю PR
+func (r *PullRequestRepository) AddEvent(ctx context.Context, event model.PullRequestEvent) error {
+	return insertEvent(ctx, r.db, event)
+}
+
+// GetEvents возвращает историю PR в порядке появления событий
+func (r *PullRequestRepository) GetEvents(ctx context.Context, prID string) ([]model.PullRequestEvent, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT id, pull_request_id, event_type, COALESCE(user_id, ''), COALESCE(old_user_id, ''), actor, created_at
+         FROM pull_request_events
+         WHERE pull_request_id = $1
+         ORDER BY id`,
+		prID,
+	)
+	if err != nil {
 		return nil, err
 	}
-	return result, nil
+	defer rows.Close()
+
+	events := make([]model.PullRequestEvent, 0)
+	for rows.Next() {
+		var e model.PullRequestEvent
+		if err := rows.Scan(&e.ID, &e.PullRequestID, &e.Type, &e.UserID, &e.OldUserID, &e.Actor, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// execer общий интерфейс для пула и транзакции
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// reviewQueueLockClass пространство advisory lock очередей ревью, ключ в нем - hashtext(user_id)
+const reviewQueueLockClass int32 = 727003
+
+func insertEvent(ctx context.Context, db execer, e model.PullRequestEvent) error {
+	if !model.ChangesReviewQueue(e.Type) {
+		_, err := db.Exec(ctx,
+			`INSERT INTO pull_request_events (pull_request_id, event_type, user_id, old_user_id, actor, created_at)
+             VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, $6)`,
+			e.PullRequestID, e.Type, e.UserID, e.OldUserID, e.Actor, e.CreatedAt,
+		)
+		return err
+	}
+
+	// Подписчик читает свою очередь по id события, поэтому события одного пользователя
+	// должны становиться видимыми по возрастанию id. Транзакция берет id под блокировками
+	// затронутых пользователей (для merge - всех ревьюверов PR) и держит их до коммита:
+	// событие с меньшим id не появится после того, как читатель увидел большее. Транзакции
+	// с разными пользователями друг друга не ждут. Блокировки берутся по возрастанию ключа,
+	// взаимную блокировку между операциями из нескольких событий разрешает повтор в InTx.
+	users := make([]string, 0, 2)
+	for _, u := range []string{e.UserID, e.OldUserID} {
+		if u != "" {
+			users = append(users, u)
+		}
+	}
+	_, err := db.Exec(ctx,
+		`WITH l AS (
+             SELECT count(pg_advisory_xact_lock($7::int, k)) FROM (
+                 SELECT DISTINCT hashtext(u) AS k FROM (
+                     SELECT unnest($8::text[]) AS u
+                     UNION SELECT user_id FROM pull_request_reviewers WHERE pull_request_id = $1 AND $2 = 'MERGED'
+                 ) affected
+                 ORDER BY k
+             ) keys
+         )
+         INSERT INTO pull_request_events (pull_request_id, event_type, user_id, old_user_id, actor, created_at)
+         SELECT $1::text, $2::text, NULLIF($3::text, ''), NULLIF($4::text, ''), $5::text, $6::timestamp FROM l`,
+		e.PullRequestID, e.Type, e.UserID, e.OldUserID, e.Actor, e.CreatedAt, reviewQueueLockClass, users,
+	)
+	return err
+}
+
+// GetReviewQueueEvents изменения очереди ревью пользователя: назначения, снятия при
+// переназначении и мерж PR, где он ревьювер. Статус PR в событии - статус на момент события.
+func (r *PullRequestRepository) GetReviewQueueEvents(ctx context.Context, userID string, afterID int64, limit int) ([]model.ReviewQueueEvent, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT e.id,
+                CASE WHEN e.event_type = 'MERGED' THEN 'MERGED'
+                     WHEN e.old_user_id = $1 THEN 'UNASSIGNED'
+                     ELSE 'ASSIGNED' END,
+                pr.pull_request_id, pr.pull_request_name, pr.author_id, e.created_at
+         FROM pull_request_events e
+         JOIN pull_requests pr ON pr.pull_request_id = e.pull_request_id
+         WHERE e.id > $2 AND (
+               (e.event_type IN ('REVIEWER_ASSIGNED', 'REVIEWER_REASSIGNED', 'ESCALATED') AND e.user_id = $1)
+            OR (e.event_type IN ('REVIEWER_REASSIGNED', 'ESCALATED') AND e.old_user_id = $1)
+            OR (e.event_type = 'MERGED' AND EXISTS (SELECT 1 FROM pull_request_reviewers prr
+                    WHERE prr.pull_request_id = e.pull_request_id AND prr.user_id = $1)))
+         ORDER BY e.id
+         LIMIT $3`,
+		userID, afterID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]model.ReviewQueueEvent, 0)
+	for rows.Next() {
+		e := model.ReviewQueueEvent{UserID: userID}
+		if err := rows.Scan(&e.ID, &e.Type, &e.PullRequest.ID, &e.PullRequest.Name, &e.PullRequest.AuthorID, &e.At); err != nil {
+			return nil, err
+		}
+		e.PullRequest.Status = reviewQueueEventStatus(e.Type)
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// LastEventID id последнего события истории PR. Разделяемая блокировка пользователя дожидается
+// транзакций, которые уже взяли id для событий его очереди, но еще не закоммитились.
+func (r *PullRequestRepository) LastEventID(ctx context.Context, userID string) (int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock_shared($1::int, hashtext($2))`, reviewQueueLockClass, userID); err != nil {
+		return 0, err
+	}
+	var id int64
+	if err := tx.QueryRow(ctx, `SELECT COALESCE(MAX(id), 0) FROM pull_request_events`).Scan(&id); err != nil {
+		return 0, err
+	}
+	return id, tx.Commit(ctx)
+}
+
+// reviewQueueEventStatus статус PR сразу после изменения очереди
+func reviewQueueEventStatus(eventType string) string {
+	if eventType == model.ReviewQueueMerged {
+		return "MERGED"
+	}
+	return "OPEN"
+}
+
+// List возвращает страницу PR с ревьюверами и курсор следующей страницы (nil, если она последняя)
+func (r *PullRequestRepository) List(ctx context.Context, f PullRequestFilter, p Page) ([]model.PullRequest, *Cursor, error) {
+	var (
+		args  queryArgs
+		conds []string
+	)
+	from := "pull_requests pr"
+	if f.TeamName != "" {
+		from += " JOIN users a ON a.user_id = pr.author_id"
+		conds = append(conds, "a.team_name = "+args.add(f.TeamName))
+	}
+	if f.Status != "" {
+		conds = append(conds, "pr.status = "+args.add(f.Status))
+	}
+	if f.AuthorID != "" {
+		conds = append(conds, "pr.author_id = "+args.add(f.AuthorID))
+	}
+	if f.ReviewerID != "" {
+		conds = append(conds, `EXISTS (SELECT 1 FROM pull_request_reviewers prr
+             WHERE prr.pull_request_id = pr.pull_request_id AND prr.user_id = `+args.add(f.ReviewerID)+`)`)
+	}
+	// created_at хранится как TIMESTAMP в UTC, границы переводим в UTC
+	if f.CreatedFrom != nil {
+		conds = append(conds, "pr.created_at >= "+args.add(f.CreatedFrom.UTC()))
+	}
+	if f.CreatedTo != nil {
+		conds = append(conds, "pr.created_at < "+args.add(f.CreatedTo.UTC()))
+	}
+	if f.Name != "" {
+		conds = append(conds, "pr.pull_request_name ILIKE "+args.add(ContainsPattern(f.Name)))
+	}
+
+	col, cast := "pr.pull_request_id", ""
+	switch p.Sort {
+	case SortByCreatedAt:
+		// значение курсора в UTC, приведение к timestamp не зависит от часового пояса сессии
+		col, cast = "pr.created_at", "::timestamp"
+	case SortByName:
+		col = "pr.pull_request_name"
+	}
+	if p.After != nil {
+		conds = append(conds, keysetCondition(col, "pr.pull_request_id", p.Desc, args.add(p.After.Value)+cast, args.add(p.After.ID)))
+	}
+
+	rows, err := r.db.Query(ctx,
+		`SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at, pr.version
+         FROM `+from+whereClause(conds)+orderBy(col, "pr.pull_request_id", p.Desc)+
+			` LIMIT `+args.add(p.Limit+1),
+		args...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	prs := make([]model.PullRequest, 0)
+	for rows.Next() {
+		var pr model.PullRequest
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt, &pr.Version); err != nil {
+			return nil, nil, err
+		}
+		pr.Reviewers = make([]model.User, 0)
+		prs = append(prs, pr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	prs, next := Paginate(prs, p, PullRequestCursor)
+	if err := r.loadReviewers(ctx, prs); err != nil {
+		return nil, nil, err
+	}
+	return prs, next, nil
+}
+
+// loadReviewers заполняет ревьюверов у страницы PR одним запросом
+func (r *PullRequestRepository) loadReviewers(ctx context.Context, prs []model.PullRequest) error {
+	if len(prs) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(prs))
+	index := make(map[string]int, len(prs))
+	for i, pr := range prs {
+		ids = append(ids, pr.ID)
+		index[pr.ID] = i
+	}
+
+	rows, err := r.db.Query(ctx,
+		`SELECT prr.pull_request_id, u.user_id, u.username, u.team_name, u.is_active
+         FROM pull_request_reviewers prr
+         JOIN users u ON prr.user_id = u.user_id
+         WHERE prr.pull_request_id = ANY($1)
+         ORDER BY prr.assigned_at, u.user_id`,
+		ids,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			prID string
+			u    model.User
+		)
+		if err := rows.Scan(&prID, &u.ID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+			return err
+		}
+		i := index[prID]
+		prs[i].Reviewers = append(prs[i].Reviewers, u)
+	}
+	return rows.Err()
 }
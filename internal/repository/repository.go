@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Контракт хранилища, от которого зависят сервисы. Реализации: Postgres (этот пакет),
+// SQLite (пакет sqlite) и память (пакет memory). Поведение, включая ошибки, проверяет общий набор тестов repotest.
+
+// Teams команды
+type Teams interface {
+	// CreateTeam создает команду и добавляет или переносит в нее пользователей, ErrTeamExists если команда уже есть
+	CreateTeam(ctx context.Context, team model.Team) error
+	// GetTeam возвращает команду с пользователями, ErrTeamNotFound если ее нет
+	GetTeam(ctx context.Context, teamName string) (model.Team, error)
+	// List страница команд с участниками по имени, курсор следующей страницы nil на последней
+	List(ctx context.Context, f TeamFilter, p Page) ([]model.Team, *Cursor, error)
+}
+
+// Users пользователи
+type Users interface {
+	SetIsActive(ctx context.Context, userID string, isActive bool) error
+	GetByID(ctx context.Context, userID string) (model.User, error)
+	GetRole(ctx context.Context, userID string) (role, teamName string, err error)
+	SetRole(ctx context.Context, userID, role string) error
+	GetActiveByTeamExcept(ctx context.Context, teamName string, excludeIDs []string) ([]model.User, error)
+	// List страница пользователей, сортировка по id или username
+	List(ctx context.Context, f UserFilter, p Page) ([]model.User, *Cursor, error)
+}
+
+// PullRequests PR, ревьюверы и история событий
+type PullRequests interface {
+	Create(ctx context.Context, pr model.PullRequest, actor string) error
+	GetByID(ctx context.Context, prID string) (model.PullRequest, error)
+	// ReassignReviewer заменяет ревьювера и пишет в историю событие eventType
+	// (model.EventReviewerReplaced или model.EventReviewerEscalated)
+	ReassignReviewer(ctx context.Context, prID string, version int64, oldUserID, newUserID, eventType, actor string) error
+	MarkMerged(ctx context.Context, prID string, version int64, mergedAt time.Time, actor string) error
+	// List страница PR с ревьюверами, сортировка по id, названию или времени создания
+	List(ctx context.Context, f PullRequestFilter, p Page) ([]model.PullRequest, *Cursor, error)
+	GetOpenAssignments(ctx context.Context) ([]model.ReviewAssignment, error)
+	MarkReminded(ctx context.Context, prID, userID string, at time.Time, actor string) error
+	AddEvent(ctx context.Context, event model.PullRequestEvent) error
+	GetEvents(ctx context.Context, prID string) ([]model.PullRequestEvent, error)
+	// GetReviewQueueEvents изменения очереди ревью userID из истории PR с id больше afterID,
+	// по возрастанию id, не больше limit
+	GetReviewQueueEvents(ctx context.Context, userID string, afterID int64, limit int) ([]model.ReviewQueueEvent, error)
+	// LastEventID id последнего события истории PR, 0 если событий нет. Изменения
+	// очереди userID с меньшими id уже видны, новые получат большие id.
+	LastEventID(ctx context.Context, userID string) (int64, error)
+}
+
+// Stats статистика назначений
+type Stats interface {
+	GetAssignmentsByUser(ctx context.Context) ([]UserAssignmentsStat, error)
+	GetAssignmentsByPR(ctx context.Context) ([]PullRequestAssignmentsStat, error)
+}
+
+// IdempotencyKeys ключи идемпотентности и сохраненные ответы
+type IdempotencyKeys interface {
+	Claim(ctx context.Context, scope, key string, rec IdempotencyRecord, ttl, lockTimeout time.Duration) (bool, IdempotencyRecord, error)
+	Complete(ctx context.Context, scope, key string, resp IdempotencyRecord) error
+	Release(ctx context.Context, scope, key string, lockedAt time.Time) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// Repos репозитории, работающие внутри одной транзакции
+type Repos struct {
+	Teams        Teams
+	Users        Users
+	PullRequests PullRequests
+	Stats        Stats
+}
+
+// TxManager выполняет несколько операций с репозиториями атомарно
+type TxManager interface {
+	// InTx вызывает fn с репозиториями одной транзакции. Изменения фиксируются, если fn
+	// вернула nil, иначе откатываются. Ошибка метода репозитория внутри fn откатывает
+	// только изменения этого метода. При конфликте сериализации fn может быть вызвана повторно,
+	// поэтому побочные эффекты (уведомления, метрики) выполняются после InTx.
+	InTx(ctx context.Context, fn func(tx Repos) error) error
+}
+
+// Locker блокировка фоновых задач, чтобы их выполняла одна реплика
+type Locker interface {
+	// TryLock берет блокировку key, если она занята - возвращает ok=false
+	TryLock(ctx context.Context, key int64) (unlock func(), ok bool, err error)
+}
+
+// ReviewBus доставляет сигналы об изменениях очередей ревью всем репликам
+type ReviewBus interface {
+	// Notify сообщает всем репликам, что очереди userIDs изменились
+	Notify(ctx context.Context, userIDs []string) error
+	// Listen передает в deliver сигналы до отмены ctx или обрыва соединения. deliver(nil)
+	// означает, что сигналы могли потеряться и очереди всех пользователей надо перечитать.
+	Listen(ctx context.Context, deliver func(userIDs []string)) error
+}
+
+// Storage репозитории одного хранилища
+type Storage struct {
+	Teams        Teams
+	Users        Users
+	PullRequests PullRequests
+	Stats        Stats
+	Idempotency  IdempotencyKeys
+	Tx           TxManager
+	Locker       Locker
+	// ReviewBus сигналы об изменениях очередей ревью между репликами, nil у хранилищ одного процесса
+	ReviewBus ReviewBus
+}
+
+// NewStorage репозитории поверх пула Postgres
+func NewStorage(db *pgxpool.Pool) Storage {
+	return Storage{
+		Teams:        NewTeamRepository(db),
+		Users:        NewUserRepository(db),
+		PullRequests: NewPullRequestRepository(db),
+		Stats:        NewStatsRepository(db),
+		Idempotency:  NewIdempotencyRepository(db),
+		Tx:           NewPostgresTxManager(db),
+		Locker:       NewAdvisoryLocker(db),
+		ReviewBus:    NewPostgresReviewBus(db),
+	}
+}
+
+// AdvisoryLocker блокировки на advisory lock в Postgres, работают между репликами
+type AdvisoryLocker struct {
+	db *pgxpool.Pool
+}
+
+func NewAdvisoryLocker(db *pgxpool.Pool) *AdvisoryLocker {
+	return &AdvisoryLocker{db: db}
+}
+
+func (l *AdvisoryLocker) TryLock(ctx context.Context, key int64) (func(), bool, error) {
+	return TryAdvisoryLock(ctx, l.db, key)
+}
+
+// ProcessLocker блокировки внутри одного процесса, для хранилищ без нескольких реплик
+type ProcessLocker struct {
+	mu   sync.Mutex
+	held map[int64]bool
+}
+
+func NewProcessLocker() *ProcessLocker {
+	return &ProcessLocker{held: make(map[int64]bool)}
+}
+
+func (l *ProcessLocker) TryLock(_ context.Context, key int64) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.held[key] {
+		return nil, false, nil
+	}
+	l.held[key] = true
+	return func() {
+		l.mu.Lock()
+		delete(l.held, key)
+		l.mu.Unlock()
+	}, true, nil
+}
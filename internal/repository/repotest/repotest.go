@@ -0,0 +1,1049 @@
+// Package repotest общий набор тестов контракта repository.Storage.
+// Его проходит каждая реализация хранилища, чтобы сервисы вели себя одинаково на любой из них.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+)
+
+// Run запускает набор на хранилище из open. В хранилище уже могут быть данные
+// (общая тестовая БД), поэтому все идентификаторы уникальны для запуска.
+func Run(t *testing.T, open func(t *testing.T) repository.Storage) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, st repository.Storage, id func(string) string)
+	}{
+		{"Teams", testTeams},
+		{"Users", testUsers},
+		{"PullRequests", testPullRequests},
+		{"ReviewAssignments", testReviewAssignments},
+		{"ReviewQueueEvents", testReviewQueueEvents},
+		{"ConcurrentReassign", testConcurrentReassign},
+		{"ConcurrentReassignAndMerge", testConcurrentReassignAndMerge},
+		{"Stats", testStats},
+		{"Idempotency", testIdempotency},
+		{"Locker", testLocker},
+		{"Tx", testTx},
+		{"ListPullRequests", testListPullRequests},
+		{"ListUsersAndTeams", testListUsersAndTeams},
+	}
+
+	run := time.Now().UnixNano()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := open(t)
+			id := func(s string) string { return fmt.Sprintf("%s_%s_%d", tt.name, s, run) }
+			tt.fn(t, st, id)
+		})
+	}
+}
+
+// createTeam создает команду из активных пользователей с указанными id
+func createTeam(t *testing.T, st repository.Storage, team string, userIDs ...string) {
+	t.Helper()
+
+	users := make([]model.User, 0, len(userIDs))
+	for _, uid := range userIDs {
+		users = append(users, model.User{ID: uid, Username: "name " + uid, IsActive: true})
+	}
+	if err := st.Teams.CreateTeam(context.Background(), model.Team{Name: team, Users: users}); err != nil {
+		t.Fatalf("CreateTeam(%s): %v", team, err)
+	}
+}
+
+// createPR создает открытый PR с ревьюверами
+func createPR(t *testing.T, st repository.Storage, prID, authorID string, reviewerIDs ...string) {
+	t.Helper()
+
+	reviewers := make([]model.User, 0, len(reviewerIDs))
+	for _, uid := range reviewerIDs {
+		reviewers = append(reviewers, model.User{ID: uid})
+	}
+	createdAt := time.Now().UTC()
+	pr := model.PullRequest{
+		ID:        prID,
+		Name:      "name " + prID,
+		AuthorID:  authorID,
+		Status:    "OPEN",
+		CreatedAt: &createdAt,
+		Version:   1,
+		Reviewers: reviewers,
+	}
+	if err := st.PullRequests.Create(context.Background(), pr, "test"); err != nil {
+		t.Fatalf("Create(%s): %v", prID, err)
+	}
+}
+
+func reviewerIDs(pr model.PullRequest) []string {
+	ids := make([]string, 0, len(pr.Reviewers))
+	for _, u := range pr.Reviewers {
+		ids = append(ids, u.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func userIDs(users []model.User) []string {
+	ids := make([]string, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func expectErr(t *testing.T, op string, err, want error) {
+	t.Helper()
+	if !errors.Is(err, want) {
+		t.Fatalf("%s: expected %v, got %v", op, want, err)
+	}
+}
+
+func testTeams(t *testing.T, st repository.Storage, id func(string) string) {
+	ctx := context.Background()
+	team, other := id("team"), id("other")
+	u1, u2 := id("u1"), id("u2")
+
+	createTeam(t, st, team, u1, u2)
+
+	got, err := st.Teams.GetTeam(ctx, team)
+	if err != nil {
+		t.Fatalf("GetTeam: %v", err)
+	}
+	if got.Name != team || !slices.Equal(userIDs(got.Users), []string{u1, u2}) {
+		t.Fatalf("GetTeam: unexpected team %+v", got)
+	}
+	for _, u := range got.Users {
+		if u.TeamName != team || !u.IsActive || u.Username != "name "+u.ID {
+			t.Fatalf("GetTeam: unexpected user %+v", u)
+		}
+	}
+
+	err = st.Teams.CreateTeam(ctx, model.Team{Name: team})
+	expectErr(t, "CreateTeam duplicate", err, repository.ErrTeamExists)
+
+	_, err = st.Teams.GetTeam(ctx, id("missing"))
+	expectErr(t, "GetTeam missing", err, repository.ErrTeamNotFound)
+
+	// пользователь из новой команды переезжает в нее
+	createTeam(t, st, other, u2)
+	got, err = st.Teams.GetTeam(ctx, team)
+	if err != nil {
+		t.Fatalf("GetTeam: %v", err)
+	}
+	if !slices.Equal(userIDs(got.Users), []string{u1}) {
+		t.Fatalf("expected %s to move out of %s, got %v", u2, team, userIDs(got.Users))
+	}
+	u, err := st.Users.GetByID(ctx, u2)
+	if err != nil || u.TeamName != other {
+		t.Fatalf("GetByID(%s): team %q, err %v", u2, u.TeamName, err)
+	}
+}
+
+func testUsers(t *testing.T, st repository.Storage, id func(string) string) {
+	ctx := context.Background()
+	team := id("team")
+	u1, u2, u3 := id("u1"), id("u2"), id("u3")
+	createTeam(t, st, team, u1, u2, u3)
+
+	u, err := st.Users.GetByID(ctx, u1)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if u != (model.User{ID: u1, Username: "name " + u1, TeamName: team, IsActive: true}) {
+		t.Fatalf("GetByID: unexpected user %+v", u)
+	}
+	_, err = st.Users.GetByID(ctx, id("missing"))
+	expectErr(t, "GetByID missing", err, repository.ErrUserNotFound)
+
+	if err := st.Users.SetIsActive(ctx, u2, false); err != nil {
+		t.Fatalf("SetIsActive: %v", err)
+	}
+	if u, _ := st.Users.GetByID(ctx, u2); u.IsActive {
+		t.Fatalf("SetIsActive: user is still active")
+	}
+	err = st.Users.SetIsActive(ctx, id("missing"), true)
+	expectErr(t, "SetIsActive missing", err, repository.ErrUserNotFound)
+
+	active, err := st.Users.GetActiveByTeamExcept(ctx, team, nil)
+	if err != nil {
+		t.Fatalf("GetActiveByTeamExcept: %v", err)
+	}
+	if !slices.Equal(userIDs(active), []string{u1, u3}) {
+		t.Fatalf("GetActiveByTeamExcept: expected active users only, got %v", userIDs(active))
+	}
+	active, err = st.Users.GetActiveByTeamExcept(ctx, team, []string{u1})
+	if err != nil {
+		t.Fatalf("GetActiveByTeamExcept: %v", err)
+	}
+	if !slices.Equal(userIDs(active), []string{u3}) {
+		t.Fatalf("GetActiveByTeamExcept: expected excluded users to be skipped, got %v", userIDs(active))
+	}
+
+	role, teamName, err := st.Users.GetRole(ctx, u1)
+	if err != nil || role != model.RoleMember || teamName != team {
+		t.Fatalf("GetRole: expected member of %s, got %q %q %v", team, role, teamName, err)
+	}
+	if err := st.Users.SetRole(ctx, u1, model.RoleTeamLead); err != nil {
+		t.Fatalf("SetRole: %v", err)
+	}
+	if role, _, _ := st.Users.GetRole(ctx, u1); role != model.RoleTeamLead {
+		t.Fatalf("SetRole: role is %q", role)
+	}
+	err = st.Users.SetRole(ctx, id("missing"), model.RoleAdmin)
+	expectErr(t, "SetRole missing", err, repository.ErrUserNotFound)
+	_, _, err = st.Users.GetRole(ctx, id("missing"))
+	expectErr(t, "GetRole missing", err, repository.ErrUserNotFound)
+
+	// повторное добавление в команду не сбрасывает роль
+	createTeam(t, st, id("team2"), u1)
+	if role, _, _ := st.Users.GetRole(ctx, u1); role != model.RoleTeamLead {
+		t.Fatalf("CreateTeam reset role to %q", role)
+	}
+}
+
+func testPullRequests(t *testing.T, st repository.Storage, id func(string) string) {
+	ctx := context.Background()
+	team := id("team")
+	author, r1, r2, r3 := id("author"), id("r1"), id("r2"), id("r3")
+	createTeam(t, st, team, author, r1, r2, r3)
+
+	prID := id("pr")
+	createPR(t, st, prID, author, r1, r2)
+
+	pr, err := st.PullRequests.GetByID(ctx, prID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if pr.Name != "name "+prID || pr.AuthorID != author || pr.Status != "OPEN" || pr.Version != 1 ||
+		pr.CreatedAt == nil || pr.MergedAt != nil {
+		t.Fatalf("GetByID: unexpected PR %+v", pr)
+	}
+	if !slices.Equal(reviewerIDs(pr), []string{r1, r2}) {
+		t.Fatalf("GetByID: unexpected reviewers %v", reviewerIDs(pr))
+	}
+
+	_, err = st.PullRequests.GetByID(ctx, id("missing"))
+	expectErr(t, "GetByID missing", err, repository.ErrPRNotFound)
+	err = st.PullRequests.Create(ctx, model.PullRequest{ID: prID, Name: "dup", AuthorID: author, Status: "OPEN"}, "test")
+	expectErr(t, "Create duplicate", err, repository.ErrPRExists)
+	err = st.PullRequests.Create(ctx, model.PullRequest{ID: id("orphan"), Name: "x", AuthorID: id("nobody"), Status: "OPEN"}, "test")
+	expectErr(t, "Create unknown author", err, repository.ErrUserNotFound)
+
+	// переназначение сверяет версию и меняет ее
+	err = st.PullRequests.ReassignReviewer(ctx, prID, 2, r1, r3, model.EventReviewerReplaced, "test")
+	expectErr(t, "ReassignReviewer stale version", err, repository.ErrVersionConflict)
+	err = st.PullRequests.ReassignReviewer(ctx, prID, 1, r3, r3, model.EventReviewerReplaced, "test")
+	expectErr(t, "ReassignReviewer not assigned", err, repository.ErrReviewerNotAssigned)
+	if err := st.PullRequests.ReassignReviewer(ctx, prID, 1, r1, r3, model.EventReviewerReplaced, "lead"); err != nil {
+		t.Fatalf("ReassignReviewer: %v", err)
+	}
+	pr, _ = st.PullRequests.GetByID(ctx, prID)
+	if pr.Version != 2 || !slices.Equal(reviewerIDs(pr), []string{r2, r3}) {
+		t.Fatalf("ReassignReviewer: version %d, reviewers %v", pr.Version, reviewerIDs(pr))
+	}
+
+	reviews, _, err := st.PullRequests.List(ctx, repository.PullRequestFilter{ReviewerID: r3}, repository.Page{Limit: 10})
+	if err != nil {
+		t.Fatalf("List by reviewer: %v", err)
+	}
+	if len(reviews) != 1 || reviews[0].ID != prID || reviews[0].Name != "name "+prID || reviews[0].AuthorID != author || reviews[0].Status != "OPEN" {
+		t.Fatalf("List by reviewer: unexpected %+v", reviews)
+	}
+	if reviews, _, _ := st.PullRequests.List(ctx, repository.PullRequestFilter{ReviewerID: r1}, repository.Page{Limit: 10}); len(reviews) != 0 {
+		t.Fatalf("List by reviewer: replaced reviewer still has %+v", reviews)
+	}
+
+	// merge без версии проходит для открытого PR, повторный - конфликт
+	err = st.PullRequests.MarkMerged(ctx, prID, 1, time.Now().UTC(), "test")
+	expectErr(t, "MarkMerged stale version", err, repository.ErrVersionConflict)
+	mergedAt := time.Now().UTC()
+	if err := st.PullRequests.MarkMerged(ctx, prID, 0, mergedAt, "lead"); err != nil {
+		t.Fatalf("MarkMerged: %v", err)
+	}
+	pr, _ = st.PullRequests.GetByID(ctx, prID)
+	if pr.Status != "MERGED" || pr.Version != 3 || pr.MergedAt == nil || pr.MergedAt.Sub(mergedAt).Abs() > time.Millisecond {
+		t.Fatalf("MarkMerged: unexpected PR %+v", pr)
+	}
+	err = st.PullRequests.MarkMerged(ctx, prID, 0, time.Now().UTC(), "test")
+	expectErr(t, "MarkMerged twice", err, repository.ErrVersionConflict)
+	err = st.PullRequests.ReassignReviewer(ctx, prID, 3, r2, r1, model.EventReviewerReplaced, "test")
+	expectErr(t, "ReassignReviewer merged", err, repository.ErrVersionConflict)
+
+	events, err := st.PullRequests.GetEvents(ctx, prID)
+	if err != nil {
+		t.Fatalf("GetEvents: %v", err)
+	}
+	var types []string
+	for i, e := range events {
+		if e.PullRequestID != prID || (i > 0 && e.ID <= events[i-1].ID) {
+			t.Fatalf("GetEvents: unexpected event order %+v", events)
+		}
+		types = append(types, e.Type)
+	}
+	want := []string{model.EventPRCreated, model.EventReviewerAssigned, model.EventReviewerAssigned,
+		model.EventReviewerReplaced, model.EventPRMerged}
+	if !slices.Equal(types, want) {
+		t.Fatalf("GetEvents: expected %v, got %v", want, types)
+	}
+	if e := events[3]; e.UserID != r3 || e.OldUserID != r1 || e.Actor != "lead" {
+		t.Fatalf("GetEvents: unexpected reassign event %+v", e)
+	}
+	if events, _ := st.PullRequests.GetEvents(ctx, id("missing")); len(events) != 0 {
+		t.Fatalf("GetEvents: expected no events for missing PR, got %+v", events)
+	}
+}
+
+func testReviewAssignments(t *testing.T, st repository.Storage, id func(string) string) {
+	ctx := context.Background()
+	team := id("team")
+	author, r1, r2 := id("author"), id("r1"), id("r2")
+	createTeam(t, st, team, author, r1, r2)
+
+	openPR, mergedPR := id("open"), id("merged")
+	createPR(t, st, openPR, author, r1, r2)
+	createPR(t, st, mergedPR, author, r1)
+	if err := st.PullRequests.MarkMerged(ctx, mergedPR, 0, time.Now().UTC(), "test"); err != nil {
+		t.Fatalf("MarkMerged: %v", err)
+	}
+
+	remindedAt := time.Now().UTC()
+	if err := st.PullRequests.MarkReminded(ctx, openPR, r1, remindedAt, "scheduler"); err != nil {
+		t.Fatalf("MarkReminded: %v", err)
+	}
+	err := st.PullRequests.MarkReminded(ctx, openPR, author, remindedAt, "scheduler")
+	expectErr(t, "MarkReminded not assigned", err, repository.ErrReviewerNotAssigned)
+
+	all, err := st.PullRequests.GetOpenAssignments(ctx)
+	if err != nil {
+		t.Fatalf("GetOpenAssignments: %v", err)
+	}
+	var own []model.ReviewAssignment
+	for i, a := range all {
+		if i > 0 && a.AssignedAt.Before(all[i-1].AssignedAt) {
+			t.Fatalf("GetOpenAssignments: not ordered by assigned_at")
+		}
+		if a.PullRequestID == mergedPR {
+			t.Fatalf("GetOpenAssignments: merged PR returned")
+		}
+		if a.PullRequestID == openPR {
+			own = append(own, a)
+		}
+	}
+	if len(own) != 2 {
+		t.Fatalf("GetOpenAssignments: expected 2 assignments, got %+v", own)
+	}
+	for _, a := range own {
+		if a.TeamName != team || a.AuthorID != author || a.PullRequestName != "name "+openPR ||
+			a.ReviewerName != "name "+a.ReviewerID || a.AssignedAt.IsZero() {
+			t.Fatalf("GetOpenAssignments: unexpected %+v", a)
+		}
+		switch a.ReviewerID {
+		case r1:
+			if a.RemindedAt == nil || a.RemindedAt.Sub(remindedAt).Abs() > time.Millisecond {
+				t.Fatalf("GetOpenAssignments: reminded_at not saved: %+v", a)
+			}
+		case r2:
+			if a.RemindedAt != nil {
+				t.Fatalf("GetOpenAssignments: unexpected reminded_at: %+v", a)
+			}
+		}
+	}
+
+	err = st.PullRequests.AddEvent(ctx, model.PullRequestEvent{
+		PullRequestID: openPR,
+		Type:          model.EventReviewerEscalated,
+		UserID:        r2,
+		OldUserID:     r1,
+		Actor:         "scheduler",
+		CreatedAt:     time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatalf("AddEvent: %v", err)
+	}
+	events, _ := st.PullRequests.GetEvents(ctx, openPR)
+	if last := events[len(events)-1]; last.Type != model.EventReviewerEscalated || last.UserID != r2 || last.OldUserID != r1 {
+		t.Fatalf("AddEvent: unexpected last event %+v", last)
+	}
+	if events[len(events)-2].Type != model.EventReviewerReminded {
+		t.Fatalf("MarkReminded: expected %s event, got %+v", model.EventReviewerReminded, events)
+	}
+}
+
+// testConcurrentReassign из параллельных записей по одной версии проходит ровно одна
+// reviewQueueTypes типы событий очереди вида "ASSIGNED:pr"
+func reviewQueueTypes(events []model.ReviewQueueEvent) []string {
+	res := make([]string, 0, len(events))
+	for _, e := range events {
+		res = append(res, e.Type+":"+e.PullRequest.ID)
+	}
+	return res
+}
+
+func testReviewQueueEvents(t *testing.T, st repository.Storage, id func(string) string) {
+	ctx := context.Background()
+	team := id("team")
+	author, r1, r2, r3 := id("author"), id("r1"), id("r2"), id("r3")
+	createTeam(t, st, team, author, r1, r2, r3)
+
+	start, err := st.PullRequests.LastEventID(ctx, r1)
+	if err != nil {
+		t.Fatalf("LastEventID: %v", err)
+	}
+
+	pr1, pr2 := id("pr1"), id("pr2")
+	createPR(t, st, pr1, author, r1, r2)
+	createPR(t, st, pr2, author, r1)
+	if err := st.PullRequests.ReassignReviewer(ctx, pr1, 1, r1, r3, model.EventReviewerReplaced, "test"); err != nil {
+		t.Fatalf("ReassignReviewer: %v", err)
+	}
+	if err := st.PullRequests.MarkMerged(ctx, pr1, 0, time.Now().UTC(), "test"); err != nil {
+		t.Fatalf("MarkMerged: %v", err)
+	}
+	// напоминания очередь не меняют
+	if err := st.PullRequests.MarkReminded(ctx, pr2, r1, time.Now().UTC(), "test"); err != nil {
+		t.Fatalf("MarkReminded: %v", err)
+	}
+	// эскалация меняет очереди так же, как переназначение
+	if err := st.PullRequests.ReassignReviewer(ctx, pr2, 1, r1, r2, model.EventReviewerEscalated, "scheduler"); err != nil {
+		t.Fatalf("ReassignReviewer escalated: %v", err)
+	}
+
+	tests := []struct {
+		userID string
+		want   []string
+	}{
+		{r1, []string{"ASSIGNED:" + pr1, "ASSIGNED:" + pr2, "UNASSIGNED:" + pr1, "UNASSIGNED:" + pr2}},
+		{r2, []string{"ASSIGNED:" + pr1, "MERGED:" + pr1, "ASSIGNED:" + pr2}},
+		{r3, []string{"ASSIGNED:" + pr1, "MERGED:" + pr1}},
+		{author, []string{}},
+	}
+	for _, tt := range tests {
+		events, err := st.PullRequests.GetReviewQueueEvents(ctx, tt.userID, start, 100)
+		if err != nil {
+			t.Fatalf("GetReviewQueueEvents(%s): %v", tt.userID, err)
+		}
+		if got := reviewQueueTypes(events); !slices.Equal(got, tt.want) {
+			t.Fatalf("GetReviewQueueEvents(%s): expected %v, got %v", tt.userID, tt.want, got)
+		}
+		for i, e := range events {
+			if e.UserID != tt.userID || e.ID <= start || (i > 0 && e.ID <= events[i-1].ID) || e.At.IsZero() {
+				t.Fatalf("GetReviewQueueEvents(%s): unexpected event %+v", tt.userID, e)
+			}
+		}
+	}
+
+	// статус PR на момент события, а не текущий
+	events, _ := st.PullRequests.GetReviewQueueEvents(ctx, r2, start, 100)
+	if pr := events[0].PullRequest; pr.Status != "OPEN" || pr.Name != "name "+pr1 || pr.AuthorID != author {
+		t.Fatalf("GetReviewQueueEvents: unexpected assigned PR %+v", pr)
+	}
+	if pr := events[1].PullRequest; pr.Status != "MERGED" {
+		t.Fatalf("GetReviewQueueEvents: unexpected merged PR %+v", pr)
+	}
+
+	// чтение продолжается после id и ограничено limit
+	after, err := st.PullRequests.GetReviewQueueEvents(ctx, r2, events[0].ID, 100)
+	if err != nil || !slices.Equal(reviewQueueTypes(after), []string{"MERGED:" + pr1, "ASSIGNED:" + pr2}) {
+		t.Fatalf("GetReviewQueueEvents after %d: %v, %v", events[0].ID, reviewQueueTypes(after), err)
+	}
+	limited, err := st.PullRequests.GetReviewQueueEvents(ctx, r1, start, 2)
+	if err != nil || !slices.Equal(reviewQueueTypes(limited), []string{"ASSIGNED:" + pr1, "ASSIGNED:" + pr2}) {
+		t.Fatalf("GetReviewQueueEvents limit: %v, %v", reviewQueueTypes(limited), err)
+	}
+
+	last, err := st.PullRequests.LastEventID(ctx, r2)
+	if err != nil {
+		t.Fatalf("LastEventID: %v", err)
+	}
+	if last < events[2].ID {
+		t.Fatalf("LastEventID: expected at least %d, got %d", events[2].ID, last)
+	}
+	if events, _ := st.PullRequests.GetReviewQueueEvents(ctx, r2, last, 100); len(events) != 0 {
+		t.Fatalf("GetReviewQueueEvents after last: unexpected %+v", events)
+	}
+}
+
+func testConcurrentReassign(t *testing.T, st repository.Storage, id func(string) string) {
+	ctx := context.Background()
+	team := id("team")
+	author, r1 := id("author"), id("r1")
+	candidates := make([]string, 8)
+	for i := range candidates {
+		candidates[i] = id(fmt.Sprintf("c%d", i))
+	}
+	createTeam(t, st, team, append([]string{author, r1}, candidates...)...)
+
+	prID := id("pr")
+	createPR(t, st, prID, author, r1)
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+	)
+	start := make(chan struct{})
+	for _, c := range candidates {
+		wg.Add(1)
+		go func(c string) {
+			defer wg.Done()
+			<-start
+			err := st.PullRequests.ReassignReviewer(ctx, prID, 1, r1, c, model.EventReviewerReplaced, "test")
+			switch {
+			case err == nil:
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			case !errors.Is(err, repository.ErrVersionConflict):
+				t.Errorf("ReassignReviewer: unexpected error %v", err)
+			}
+		}(c)
+	}
+	close(start)
+	wg.Wait()
+
+	if succeeded != 1 {
+		t.Fatalf("expected exactly one reassign to succeed, got %d", succeeded)
+	}
+	pr, _ := st.PullRequests.GetByID(ctx, prID)
+	if pr.Version != 2 || len(pr.Reviewers) != 1 {
+		t.Fatalf("unexpected PR after concurrent reassign: version %d, reviewers %v", pr.Version, reviewerIDs(pr))
+	}
+}
+
+// Переназначения по версии 1 гонятся с merge без версии: merge проходит всегда,
+// переназначение - не больше одного и только до merge.
+func testConcurrentReassignAndMerge(t *testing.T, st repository.Storage, id func(string) string) {
+	ctx := context.Background()
+	team := id("team")
+	author, r1 := id("author"), id("r1")
+	candidates := make([]string, 8)
+	for i := range candidates {
+		candidates[i] = id(fmt.Sprintf("c%d", i))
+	}
+	createTeam(t, st, team, append([]string{author, r1}, candidates...)...)
+
+	prID := id("pr")
+	createPR(t, st, prID, author, r1)
+
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		reassigned int
+	)
+	start := make(chan struct{})
+	for _, c := range candidates {
+		wg.Add(1)
+		go func(c string) {
+			defer wg.Done()
+			<-start
+			err := st.PullRequests.ReassignReviewer(ctx, prID, 1, r1, c, model.EventReviewerReplaced, "test")
+			switch {
+			case err == nil:
+				mu.Lock()
+				reassigned++
+				mu.Unlock()
+			case !errors.Is(err, repository.ErrVersionConflict):
+				t.Errorf("ReassignReviewer: unexpected error %v", err)
+			}
+		}(c)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-start
+		if err := st.PullRequests.MarkMerged(ctx, prID, 0, time.Now().UTC(), "test"); err != nil {
+			t.Errorf("MarkMerged: %v", err)
+		}
+	}()
+	close(start)
+	wg.Wait()
+
+	if reassigned > 1 {
+		t.Fatalf("expected at most one reassign to succeed, got %d", reassigned)
+	}
+	pr, _ := st.PullRequests.GetByID(ctx, prID)
+	if pr.Status != "MERGED" || pr.Version != int64(2+reassigned) || len(pr.Reviewers) != 1 {
+		t.Fatalf("unexpected PR after concurrent reassign and merge: status %s, version %d, reviewers %v",
+			pr.Status, pr.Version, reviewerIDs(pr))
+	}
+
+	events, err := st.PullRequests.GetEvents(ctx, prID)
+	if err != nil {
+		t.Fatalf("GetEvents: %v", err)
+	}
+	merged := false
+	for _, e := range events {
+		switch e.Type {
+		case model.EventPRMerged:
+			merged = true
+		case model.EventReviewerReplaced:
+			if merged {
+				t.Fatalf("reviewer %s reassigned after merge: %+v", e.UserID, events)
+			}
+		}
+	}
+
+	// клиент с устаревшей версией получает конфликт и после merge
+	err = st.PullRequests.ReassignReviewer(ctx, prID, 1, r1, candidates[0], model.EventReviewerReplaced, "test")
+	expectErr(t, "ReassignReviewer stale version", err, repository.ErrVersionConflict)
+	err = st.PullRequests.MarkMerged(ctx, prID, 1, time.Now().UTC(), "test")
+	expectErr(t, "MarkMerged stale version", err, repository.ErrVersionConflict)
+}
+
+func testStats(t *testing.T, st repository.Storage, id func(string) string) {
+	ctx := context.Background()
+	team := id("team")
+	author, r1, r2 := id("author"), id("r1"), id("r2")
+	createTeam(t, st, team, author, r1, r2)
+
+	pr1, pr2, pr3 := id("pr1"), id("pr2"), id("pr3")
+	createPR(t, st, pr1, author, r1, r2)
+	createPR(t, st, pr2, author, r1)
+	createPR(t, st, pr3, author)
+
+	byUser, err := st.Stats.GetAssignmentsByUser(ctx)
+	if err != nil {
+		t.Fatalf("GetAssignmentsByUser: %v", err)
+	}
+	users := map[string]int{}
+	for _, s := range byUser {
+		users[s.UserID] = s.AssignedCount
+	}
+	if users[r1] != 2 || users[r2] != 1 {
+		t.Fatalf("GetAssignmentsByUser: unexpected counts %v", users)
+	}
+	if _, ok := users[author]; ok {
+		t.Fatalf("GetAssignmentsByUser: author without reviews listed")
+	}
+
+	byPR, err := st.Stats.GetAssignmentsByPR(ctx)
+	if err != nil {
+		t.Fatalf("GetAssignmentsByPR: %v", err)
+	}
+	prs := map[string]int{}
+	for _, s := range byPR {
+		prs[s.PullRequestID] = s.ReviewersCount
+	}
+	if prs[pr1] != 2 || prs[pr2] != 1 {
+		t.Fatalf("GetAssignmentsByPR: unexpected counts %v", prs)
+	}
+	if _, ok := prs[pr3]; ok {
+		t.Fatalf("GetAssignmentsByPR: PR without reviewers listed")
+	}
+}
+
+func testIdempotency(t *testing.T, st repository.Storage, id func(string) string) {
+	ctx := context.Background()
+	scope, key := id("scope"), id("key")
+	rec := repository.IdempotencyRecord{Method: "POST", Path: "/team/add", RequestHash: "hash"}
+
+	claimed, lock, err := st.Idempotency.Claim(ctx, scope, key, rec, time.Hour, time.Hour)
+	if err != nil || !claimed || lock.LockedAt.IsZero() {
+		t.Fatalf("Claim: claimed %v, lock %+v, err %v", claimed, lock, err)
+	}
+
+	// пока ответа нет, ключ занят
+	claimed, existing, err := st.Idempotency.Claim(ctx, scope, key, rec, time.Hour, time.Hour)
+	if err != nil || claimed || existing.Completed() || existing.RequestHash != "hash" {
+		t.Fatalf("Claim in progress: claimed %v, existing %+v, err %v", claimed, existing, err)
+	}
+
+	// тот же ключ в другой области независим
+	claimed, _, err = st.Idempotency.Claim(ctx, id("other"), key, rec, time.Hour, time.Hour)
+	if err != nil || !claimed {
+		t.Fatalf("Claim other scope: claimed %v, err %v", claimed, err)
+	}
+
+	want := repository.IdempotencyRecord{Method: "POST", Path: "/team/add", RequestHash: "hash",
+		StatusCode: 201, ContentType: "application/json", ETag: `"3"`, Location: "/v2/teams/backend", ResponseBody: []byte(`{"ok":true}`),
+		LockedAt: lock.LockedAt}
+	if err := st.Idempotency.Complete(ctx, scope, key, want); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	claimed, existing, err = st.Idempotency.Claim(ctx, scope, key, rec, time.Hour, time.Hour)
+	if err != nil || claimed {
+		t.Fatalf("Claim completed: claimed %v, err %v", claimed, err)
+	}
+	if existing.Method != want.Method || existing.Path != want.Path || existing.StatusCode != want.StatusCode ||
+		existing.ContentType != want.ContentType || existing.ETag != want.ETag || existing.Location != want.Location ||
+		string(existing.ResponseBody) != string(want.ResponseBody) {
+		t.Fatalf("Claim completed: expected %+v, got %+v", want, existing)
+	}
+	// сохраненный ответ таймаут блокировки не отменяет
+	time.Sleep(20 * time.Millisecond)
+	if claimed, _, err := st.Idempotency.Claim(ctx, scope, key, rec, time.Hour, time.Millisecond); err != nil || claimed {
+		t.Fatalf("Claim completed key with short lock timeout: claimed %v, err %v", claimed, err)
+	}
+
+	// после Release ключ можно занять заново
+	if err := st.Idempotency.Release(ctx, scope, key, lock.LockedAt); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	claimed, _, err = st.Idempotency.Claim(ctx, scope, key, rec, time.Hour, time.Hour)
+	if err != nil || !claimed {
+		t.Fatalf("Claim after release: claimed %v, err %v", claimed, err)
+	}
+
+	// незавершенный запрос после lockTimeout забирает повтор с тем же телом,
+	// но не запрос с другим телом
+	stale := id("stale")
+	claimed, staleLock, err := st.Idempotency.Claim(ctx, scope, stale, rec, time.Hour, time.Hour)
+	if err != nil || !claimed {
+		t.Fatalf("Claim stale: claimed %v, err %v", claimed, err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	changed := rec
+	changed.RequestHash = "changed"
+	if claimed, existing, err := st.Idempotency.Claim(ctx, scope, stale, changed, time.Hour, time.Millisecond); err != nil || claimed || existing.RequestHash != "hash" {
+		t.Fatalf("Claim stale with other body: claimed %v, existing %+v, err %v", claimed, existing, err)
+	}
+	claimed, retryLock, err := st.Idempotency.Claim(ctx, scope, stale, rec, time.Hour, time.Millisecond)
+	if err != nil || !claimed || retryLock.LockedAt.Equal(staleLock.LockedAt) {
+		t.Fatalf("Claim over stale lock: claimed %v, lock %+v, err %v", claimed, retryLock, err)
+	}
+	if claimed, _, err := st.Idempotency.Claim(ctx, scope, stale, rec, time.Hour, time.Hour); err != nil || claimed {
+		t.Fatalf("Claim after takeover must see a fresh lock: claimed %v, err %v", claimed, err)
+	}
+
+	// прежний владелец, закончивший после захвата, не трогает ключ повтора
+	staleResp := repository.IdempotencyRecord{StatusCode: 200, ResponseBody: []byte("stale"), LockedAt: staleLock.LockedAt}
+	if err := st.Idempotency.Complete(ctx, scope, stale, staleResp); err != nil {
+		t.Fatalf("Complete by stale owner: %v", err)
+	}
+	if err := st.Idempotency.Release(ctx, scope, stale, staleLock.LockedAt); err != nil {
+		t.Fatalf("Release by stale owner: %v", err)
+	}
+	if claimed, existing, err := st.Idempotency.Claim(ctx, scope, stale, rec, time.Hour, time.Hour); err != nil || claimed || existing.Completed() {
+		t.Fatalf("stale owner must not complete or release the retry's claim: claimed %v, existing %+v, err %v", claimed, existing, err)
+	}
+	retryResp := repository.IdempotencyRecord{StatusCode: 201, ResponseBody: []byte("retry"), LockedAt: retryLock.LockedAt}
+	if err := st.Idempotency.Complete(ctx, scope, stale, retryResp); err != nil {
+		t.Fatalf("Complete by retry: %v", err)
+	}
+	if claimed, existing, err := st.Idempotency.Claim(ctx, scope, stale, rec, time.Hour, time.Hour); err != nil || claimed ||
+		existing.StatusCode != 201 || string(existing.ResponseBody) != "retry" {
+		t.Fatalf("expected the retry's response, got claimed %v, existing %+v, err %v", claimed, existing, err)
+	}
+
+	// истекший ключ занимается как новый и удаляется очисткой
+	expired := id("expired")
+	if claimed, _, err := st.Idempotency.Claim(ctx, scope, expired, rec, -time.Minute, time.Hour); err != nil || !claimed {
+		t.Fatalf("Claim expired: claimed %v, err %v", claimed, err)
+	}
+	other := rec
+	other.RequestHash = "other"
+	claimed, _, err = st.Idempotency.Claim(ctx, scope, expired, other, -time.Minute, time.Hour)
+	if err != nil || !claimed {
+		t.Fatalf("Claim over expired key: claimed %v, err %v", claimed, err)
+	}
+	n, err := st.Idempotency.DeleteExpired(ctx)
+	if err != nil || n < 1 {
+		t.Fatalf("DeleteExpired: deleted %d, err %v", n, err)
+	}
+	claimed, existing, err = st.Idempotency.Claim(ctx, scope, key, rec, time.Hour, time.Hour)
+	if err != nil || claimed || existing.RequestHash != "hash" {
+		t.Fatalf("DeleteExpired removed live key: claimed %v, err %v", claimed, err)
+	}
+}
+
+func testLocker(t *testing.T, st repository.Storage, id func(string) string) {
+	ctx := context.Background()
+	key := time.Now().UnixNano()
+
+	unlock, ok, err := st.Locker.TryLock(ctx, key)
+	if err != nil || !ok {
+		t.Fatalf("TryLock: ok %v, err %v", ok, err)
+	}
+	if _, ok, err := st.Locker.TryLock(ctx, key); err != nil || ok {
+		t.Fatalf("TryLock held: ok %v, err %v", ok, err)
+	}
+	unlock()
+
+	unlock, ok, err = st.Locker.TryLock(ctx, key)
+	if err != nil || !ok {
+		t.Fatalf("TryLock after unlock: ok %v, err %v", ok, err)
+	}
+	unlock()
+}
+
+func testTx(t *testing.T, st repository.Storage, id func(string) string) {
+	ctx := context.Background()
+	team, author, r1, r2 := id("team"), id("author"), id("r1"), id("r2")
+	createTeam(t, st, team, author, r1, r2)
+
+	// фиксация: изменения видны внутри транзакции и после нее
+	prID := id("pr")
+	err := st.Tx.InTx(ctx, func(tx repository.Repos) error {
+		createPR(t, repository.Storage{PullRequests: tx.PullRequests}, prID, author, r1)
+		pr, err := tx.PullRequests.GetByID(ctx, prID)
+		if err != nil {
+			return err
+		}
+		if !slices.Equal(reviewerIDs(pr), []string{r1}) {
+			t.Errorf("GetByID in tx: reviewers %v", reviewerIDs(pr))
+		}
+		return tx.Users.SetIsActive(ctx, r2, false)
+	})
+	if err != nil {
+		t.Fatalf("InTx: %v", err)
+	}
+	if _, err := st.PullRequests.GetByID(ctx, prID); err != nil {
+		t.Fatalf("GetByID after commit: %v", err)
+	}
+	if u, _ := st.Users.GetByID(ctx, r2); u.IsActive {
+		t.Fatal("SetIsActive in tx was not committed")
+	}
+
+	// откат: ошибка fn отменяет все изменения
+	errAbort := errors.New("abort")
+	rolledBackTeam, rolledBackPR := id("team_rb"), id("pr_rb")
+	err = st.Tx.InTx(ctx, func(tx repository.Repos) error {
+		err := tx.Teams.CreateTeam(ctx, model.Team{Name: rolledBackTeam, Users: []model.User{
+			{ID: author, Username: "moved", IsActive: true},
+		}})
+		if err != nil {
+			return err
+		}
+		createPR(t, repository.Storage{PullRequests: tx.PullRequests}, rolledBackPR, author)
+		return errAbort
+	})
+	expectErr(t, "InTx with error", err, errAbort)
+	_, err = st.Teams.GetTeam(ctx, rolledBackTeam)
+	expectErr(t, "GetTeam after rollback", err, repository.ErrTeamNotFound)
+	_, err = st.PullRequests.GetByID(ctx, rolledBackPR)
+	expectErr(t, "GetByID after rollback", err, repository.ErrPRNotFound)
+	if u, _ := st.Users.GetByID(ctx, author); u.TeamName != team {
+		t.Fatalf("user moved by rolled back tx: team %q", u.TeamName)
+	}
+
+	// ошибка метода откатывает только его изменения, транзакция продолжается
+	otherPR := id("pr_other")
+	err = st.Tx.InTx(ctx, func(tx repository.Repos) error {
+		err := tx.PullRequests.Create(ctx, model.PullRequest{ID: prID, Name: "dup", AuthorID: author, Status: "OPEN"}, "test")
+		expectErr(t, "Create duplicate in tx", err, repository.ErrPRExists)
+
+		// версия совпадает, но ревьювер не назначен: повышение версии должно откатиться
+		err = tx.PullRequests.ReassignReviewer(ctx, prID, 1, r2, author, model.EventReviewerReplaced, "test")
+		expectErr(t, "ReassignReviewer in tx", err, repository.ErrReviewerNotAssigned)
+
+		createPR(t, repository.Storage{PullRequests: tx.PullRequests}, otherPR, author)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("InTx after failed methods: %v", err)
+	}
+	if _, err := st.PullRequests.GetByID(ctx, otherPR); err != nil {
+		t.Fatalf("GetByID after commit: %v", err)
+	}
+	pr, _ := st.PullRequests.GetByID(ctx, prID)
+	if pr.Version != 1 {
+		t.Fatalf("failed ReassignReviewer changed version to %d", pr.Version)
+	}
+}
+
+// listAll проходит список постранично и проверяет, что страницы не больше лимита
+func listAll[T any](t *testing.T, limit int, list func(after *repository.Cursor) ([]T, *repository.Cursor, error)) []T {
+	t.Helper()
+
+	var (
+		all   []T
+		after *repository.Cursor
+	)
+	for pages := 0; ; pages++ {
+		if pages > 100 {
+			t.Fatal("pagination does not terminate")
+		}
+		items, next, err := list(after)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		if len(items) > limit {
+			t.Fatalf("List: page of %d items exceeds limit %d", len(items), limit)
+		}
+		all = append(all, items...)
+		if next == nil {
+			return all
+		}
+		if len(items) < limit {
+			t.Fatalf("List: short page of %d items with a next cursor", len(items))
+		}
+		after = next
+	}
+}
+
+func prIDs(prs []model.PullRequest) []string {
+	ids := make([]string, 0, len(prs))
+	for _, pr := range prs {
+		ids = append(ids, pr.ID)
+	}
+	return ids
+}
+
+func testListPullRequests(t *testing.T, st repository.Storage, id func(string) string) {
+	ctx := context.Background()
+	team, other := id("team"), id("other")
+	a1, a2, r1, r2, o1 := id("a1"), id("a2"), id("r1"), id("r2"), id("o1")
+	createTeam(t, st, team, a1, a2, r1, r2)
+	createTeam(t, st, other, o1)
+
+	// 5 PR команды с шагом в минуту, имена идут в обратном порядке времени создания
+	base := time.Now().UTC().Truncate(time.Second).Add(-time.Hour)
+	var ids []string
+	for i := 0; i < 5; i++ {
+		prID := id(fmt.Sprintf("pr%d", i))
+		author, reviewer := a1, r1
+		if i%2 == 1 {
+			author, reviewer = a2, r2
+		}
+		createdAt := base.Add(time.Duration(i) * time.Minute)
+		err := st.PullRequests.Create(ctx, model.PullRequest{
+			ID:        prID,
+			Name:      fmt.Sprintf("Feature %c", 'E'-i),
+			AuthorID:  author,
+			Status:    "OPEN",
+			CreatedAt: &createdAt,
+			Reviewers: []model.User{{ID: reviewer}},
+		}, "test")
+		if err != nil {
+			t.Fatalf("Create(%s): %v", prID, err)
+		}
+		ids = append(ids, prID)
+	}
+	createPR(t, st, id("other_pr"), o1)
+	if err := st.PullRequests.MarkMerged(ctx, ids[4], 0, time.Now().UTC(), "test"); err != nil {
+		t.Fatalf("MarkMerged: %v", err)
+	}
+
+	list := func(f repository.PullRequestFilter, sort string, desc bool, limit int) []string {
+		t.Helper()
+		f.TeamName = team
+		prs := listAll(t, limit, func(after *repository.Cursor) ([]model.PullRequest, *repository.Cursor, error) {
+			return st.PullRequests.List(ctx, f, repository.Page{Limit: limit, Sort: sort, Desc: desc, After: after})
+		})
+		return prIDs(prs)
+	}
+	reversed := slices.Clone(ids)
+	slices.Reverse(reversed)
+
+	// сортировка и пагинация без пропусков и повторов
+	for _, limit := range []int{1, 2, 5, 10} {
+		if got := list(repository.PullRequestFilter{}, repository.SortByCreatedAt, false, limit); !slices.Equal(got, ids) {
+			t.Fatalf("created_at asc, limit %d: %v", limit, got)
+		}
+		if got := list(repository.PullRequestFilter{}, repository.SortByCreatedAt, true, limit); !slices.Equal(got, reversed) {
+			t.Fatalf("created_at desc, limit %d: %v", limit, got)
+		}
+	}
+	if got := list(repository.PullRequestFilter{}, repository.SortByName, false, 2); !slices.Equal(got, reversed) {
+		t.Fatalf("name asc: %v", got)
+	}
+	if got := list(repository.PullRequestFilter{}, repository.SortByID, true, 3); !slices.Equal(got, reversed) {
+		t.Fatalf("id desc: %v", got)
+	}
+
+	// фильтры
+	from, to := base.Add(time.Minute), base.Add(3*time.Minute)
+	cases := []struct {
+		name   string
+		filter repository.PullRequestFilter
+		want   []string
+	}{
+		{"status", repository.PullRequestFilter{Status: "MERGED"}, ids[4:]},
+		{"author", repository.PullRequestFilter{AuthorID: a2}, []string{ids[1], ids[3]}},
+		{"reviewer", repository.PullRequestFilter{ReviewerID: r1}, []string{ids[0], ids[2], ids[4]}},
+		{"created range", repository.PullRequestFilter{CreatedFrom: &from, CreatedTo: &to}, ids[1:3]},
+		{"name", repository.PullRequestFilter{Name: "feature d"}, ids[1:2]},
+		{"name wildcard", repository.PullRequestFilter{Name: "%"}, nil},
+		{"combined", repository.PullRequestFilter{Status: "OPEN", ReviewerID: r1, CreatedFrom: &from}, ids[2:3]},
+	}
+	for _, tc := range cases {
+		if got := list(tc.filter, repository.SortByCreatedAt, false, 2); !slices.Equal(got, tc.want) {
+			t.Fatalf("filter %s: expected %v, got %v", tc.name, tc.want, got)
+		}
+	}
+
+	// ревьюверы загружаются вместе со страницей
+	prs, _, err := st.PullRequests.List(ctx, repository.PullRequestFilter{TeamName: team}, repository.Page{Limit: 10, Sort: repository.SortByCreatedAt})
+	if err != nil || len(prs) != 5 {
+		t.Fatalf("List: %d items, err %v", len(prs), err)
+	}
+	if !slices.Equal(reviewerIDs(prs[1]), []string{r2}) || prs[4].Status != "MERGED" || prs[4].MergedAt == nil {
+		t.Fatalf("List: unexpected item %+v / %+v", prs[1], prs[4])
+	}
+}
+
+func testListUsersAndTeams(t *testing.T, st repository.Storage, id func(string) string) {
+	ctx := context.Background()
+	teamA, teamB := id("team")+"_a", id("team")+"_b"
+	u1, u2, u3, u4 := id("u1"), id("u2"), id("u3"), id("u4")
+	err := st.Teams.CreateTeam(ctx, model.Team{Name: teamA, Users: []model.User{
+		{ID: u1, Username: "Zoe", IsActive: true},
+		{ID: u2, Username: "Adam", IsActive: false},
+		{ID: u3, Username: "Mia", IsActive: true},
+	}})
+	if err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+	createTeam(t, st, teamB, u4)
+
+	listUsers := func(f repository.UserFilter, sort string, desc bool) []string {
+		t.Helper()
+		users := listAll(t, 2, func(after *repository.Cursor) ([]model.User, *repository.Cursor, error) {
+			return st.Users.List(ctx, f, repository.Page{Limit: 2, Sort: sort, Desc: desc, After: after})
+		})
+		// userIDs сортирует, здесь важен порядок списка
+		ids := make([]string, 0, len(users))
+		for _, u := range users {
+			ids = append(ids, u.ID)
+		}
+		return ids
+	}
+	if got := listUsers(repository.UserFilter{TeamName: teamA}, repository.SortByID, false); !slices.Equal(got, []string{u1, u2, u3}) {
+		t.Fatalf("users by id: %v", got)
+	}
+	if got := listUsers(repository.UserFilter{TeamName: teamA}, repository.SortByName, false); !slices.Equal(got, []string{u2, u3, u1}) {
+		t.Fatalf("users by name: %v", got)
+	}
+	if got := listUsers(repository.UserFilter{TeamName: teamA}, repository.SortByName, true); !slices.Equal(got, []string{u1, u3, u2}) {
+		t.Fatalf("users by name desc: %v", got)
+	}
+	active := true
+	if got := listUsers(repository.UserFilter{TeamName: teamA, IsActive: &active}, repository.SortByID, false); !slices.Equal(got, []string{u1, u3}) {
+		t.Fatalf("active users: %v", got)
+	}
+	if got := listUsers(repository.UserFilter{TeamName: teamA, Name: "ZO"}, repository.SortByID, false); !slices.Equal(got, []string{u1}) {
+		t.Fatalf("users by username: %v", got)
+	}
+
+	// имена команд уникальны для запуска, фильтр по подстроке отсекает чужие данные
+	teams := listAll(t, 1, func(after *repository.Cursor) ([]model.Team, *repository.Cursor, error) {
+		return st.Teams.List(ctx, repository.TeamFilter{Name: id("team")}, repository.Page{Limit: 1, Sort: repository.SortByName, After: after})
+	})
+	if len(teams) != 2 || teams[0].Name != teamA || teams[1].Name != teamB {
+		t.Fatalf("teams: %+v", teams)
+	}
+	if !slices.Equal(userIDs(teams[0].Users), []string{u1, u2, u3}) || !slices.Equal(userIDs(teams[1].Users), []string{u4}) {
+		t.Fatalf("team members: %v, %v", userIDs(teams[0].Users), userIDs(teams[1].Users))
+	}
+	teams, _, err = st.Teams.List(ctx, repository.TeamFilter{Name: id("team")}, repository.Page{Limit: 10, Sort: repository.SortByName, Desc: true})
+	if err != nil || len(teams) != 2 || teams[0].Name != teamB {
+		t.Fatalf("teams desc: %+v, err %v", teams, err)
+	}
+	teams, _, err = st.Teams.List(ctx, repository.TeamFilter{TeamName: teamB}, repository.Page{Limit: 10, Sort: repository.SortByName})
+	if err != nil || len(teams) != 1 || teams[0].Name != teamB {
+		t.Fatalf("teams by exact name: %+v, err %v", teams, err)
+	}
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// reviewQueueChannel канал LISTEN/NOTIFY для изменений очередей ревью
+const reviewQueueChannel = "review_queue"
+
+// maxNotifyPayload предел payload NOTIFY с запасом (в Postgres 8000 байт). Больший
+// список пользователей заменяется пустым payload - перечитать все очереди.
+const maxNotifyPayload = 7000
+
+// PostgresReviewBus сигналы об изменениях очередей ревью через LISTEN/NOTIFY, их получают все реплики
+type PostgresReviewBus struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresReviewBus(db *pgxpool.Pool) *PostgresReviewBus {
+	return &PostgresReviewBus{db: db}
+}
+
+func (b *PostgresReviewBus) Notify(ctx context.Context, userIDs []string) error {
+	payload, err := json.Marshal(userIDs)
+	if err != nil {
+		return err
+	}
+	if len(payload) > maxNotifyPayload {
+		payload = nil
+	}
+	_, err = b.db.Exec(ctx, `SELECT pg_notify($1, $2)`, reviewQueueChannel, string(payload))
+	return err
+}
+
+// Listen слушает канал на отдельном соединении вне пула, чтобы долгое ожидание
+// не занимало соединение, нужное запросам
+func (b *PostgresReviewBus) Listen(ctx context.Context, deliver func(userIDs []string)) error {
+	conn, err := pgx.ConnectConfig(ctx, b.db.Config().ConnConfig.Copy())
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.WithoutCancel(ctx))
+
+	if _, err := conn.Exec(ctx, "LISTEN "+reviewQueueChannel); err != nil {
+		return err
+	}
+	// пока соединения не было, сигналы не доходили
+	deliver(nil)
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var userIDs []string
+		if n.Payload != "" {
+			if err := json.Unmarshal([]byte(n.Payload), &userIDs); err != nil {
+				slog.Warn("invalid review queue notification", slog.String("payload", n.Payload), slog.Any("error", err))
+				continue
+			}
+		}
+		// пустой payload - перечитать все очереди
+		deliver(userIDs)
+	}
+}
@@ -0,0 +1,218 @@
+// Package sqlite хранилище в файле SQLite для установки на одном узле без Postgres.
+// Драйвер modernc.org/sqlite написан на Go и не требует cgo.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Open открывает файл БД, создавая его при необходимости.
+// Транзакции берут блокировку на запись сразу (BEGIN IMMEDIATE), параллельные писатели
+// ждут друг друга до busy_timeout вместо ошибки SQLITE_BUSY посреди транзакции.
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	dsn := path + "?_pragma=foreign_keys(1)" +
+		"&_pragma=busy_timeout(5000)" +
+		"&_pragma=journal_mode(WAL)" +
+		"&_pragma=synchronous(NORMAL)" +
+		"&_txlock=immediate"
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	ctxPing, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if err := db.PingContext(ctxPing); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("open sqlite %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// NewStorage репозитории поверх файла SQLite. Реплика у такого хранилища одна,
+// поэтому фоновым задачам достаточно блокировок внутри процесса.
+func NewStorage(db *sql.DB) repository.Storage {
+	return repository.Storage{
+		Teams:        NewTeamRepository(db),
+		Users:        NewUserRepository(db),
+		PullRequests: NewPullRequestRepository(db),
+		Stats:        NewStatsRepository(db),
+		Idempotency:  NewIdempotencyRepository(db),
+		Tx:           NewTxManager(db),
+		Locker:       repository.NewProcessLocker(),
+	}
+}
+
+// CheckSchemaVersion проверяет, что миграции применены не ниже ожидаемой версии и не в грязном состоянии.
+// Более новая схема допустима: при rolling deploy старые реплики продолжают работать,
+// пока новая применяет миграции (миграции обязаны быть обратно совместимыми).
+func CheckSchemaVersion(ctx context.Context, db *sql.DB, expected int64) error {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errors.New("migrations are not applied")
+		}
+		return err
+	}
+
+	if dirty {
+		return fmt.Errorf("migration %d is dirty", version)
+	}
+	if version < expected {
+		return fmt.Errorf("schema version is %d, expected %d", version, expected)
+	}
+	return nil
+}
+
+// isConstraint проверяет, что запрос нарушил ограничение с одним из кодов SQLITE_CONSTRAINT_*
+func isConstraint(err error, codes ...int) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	for _, code := range codes {
+		if sqliteErr.Code() == code {
+			return true
+		}
+	}
+	return false
+}
+
+// isUniqueViolation аналог 23505 в Postgres
+func isUniqueViolation(err error) bool {
+	return isConstraint(err, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY, sqlite3.SQLITE_CONSTRAINT_UNIQUE)
+}
+
+// isForeignKeyViolation аналог 23503 в Postgres
+func isForeignKeyViolation(err error) bool {
+	return isConstraint(err, sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY)
+}
+
+// Время хранится текстом в UTC с фиксированным числом знаков,
+// поэтому строки сравниваются и сортируются так же, как моменты времени
+const timeFormat = "2006-01-02T15:04:05.000000000Z"
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(timeFormat)
+}
+
+func parseTime(s string) (time.Time, error) {
+	return time.Parse(timeFormat, s)
+}
+
+// parseNullTime разбирает колонку, в которой может быть NULL
+func parseNullTime(s sql.NullString) (*time.Time, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	t, err := parseTime(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// dbtx общий интерфейс для БД и транзакции
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// txn транзакция, а внутри внешней транзакции - savepoint, как Begin у pgx.
+// Так методы репозиториев остаются атомарными и в TxManager.InTx.
+type txn struct {
+	dbtx
+	commit   func() error
+	rollback func() error
+	done     bool
+}
+
+func begin(ctx context.Context, db dbtx) (*txn, error) {
+	switch db := db.(type) {
+	case *sql.DB:
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		return &txn{dbtx: tx, commit: tx.Commit, rollback: tx.Rollback}, nil
+	case *sql.Tx:
+		if _, err := db.ExecContext(ctx, `SAVEPOINT repo`); err != nil {
+			return nil, err
+		}
+		return &txn{
+			dbtx: db,
+			commit: func() error {
+				_, err := db.ExecContext(ctx, `RELEASE repo`)
+				return err
+			},
+			rollback: func() error {
+				if _, err := db.ExecContext(ctx, `ROLLBACK TO repo`); err != nil {
+					return err
+				}
+				_, err := db.ExecContext(ctx, `RELEASE repo`)
+				return err
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported connection %T", db)
+	}
+}
+
+func (t *txn) Commit() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	return t.commit()
+}
+
+// Rollback после Commit ничего не делает, поэтому его можно откладывать через defer
+func (t *txn) Rollback() error {
+	if t.done {
+		return sql.ErrTxDone
+	}
+	t.done = true
+	return t.rollback()
+}
+
+// TxManager транзакции SQLite. Транзакция сразу берет блокировку на запись,
+// поэтому выполняется целиком без параллельных изменений.
+type TxManager struct {
+	db *sql.DB
+}
+
+func NewTxManager(db *sql.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+func (m *TxManager) InTx(ctx context.Context, fn func(tx repository.Repos) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(repository.Repos{
+		Teams:        &TeamRepository{db: tx},
+		Users:        &UserRepository{db: tx},
+		PullRequests: &PullRequestRepository{db: tx},
+		Stats:        &StatsRepository{db: tx},
+	}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
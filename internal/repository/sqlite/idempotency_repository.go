@@ -0,0 +1,117 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+)
+
+type IdempotencyRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyRepository(db *sql.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Claim занимает ключ под новый запрос. Если ключ уже занят и не истек,
+// возвращает claimed=false и сохраненную запись. Незавершенный запрос с тем же
+// телом, захваченный раньше lockTimeout назад, считается брошенным упавшим
+// процессом, и ключ занимается заново. Занятый ключ возвращается с LockedAt.
+func (r *IdempotencyRepository) Claim(
+	ctx context.Context,
+	scope, key string,
+	rec repository.IdempotencyRecord,
+	ttl, lockTimeout time.Duration,
+) (claimed bool, existing repository.IdempotencyRecord, err error) {
+	now := time.Now()
+
+	// истекший ключ переиспользуем, как будто его не было
+	err = r.db.QueryRowContext(ctx,
+		`INSERT INTO idempotency_keys (scope, key, method, path, request_hash, created_at, expires_at, locked_at)
+         VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+         ON CONFLICT (scope, key) DO UPDATE
+         SET method        = excluded.method,
+             path          = excluded.path,
+             request_hash  = excluded.request_hash,
+             status_code   = NULL,
+             content_type  = NULL,
+             etag          = NULL,
+             location      = NULL,
+             response_body = NULL,
+             created_at    = excluded.created_at,
+             expires_at    = excluded.expires_at,
+             locked_at     = excluded.locked_at
+         WHERE idempotency_keys.expires_at < excluded.created_at
+            OR (idempotency_keys.status_code IS NULL
+                AND idempotency_keys.request_hash = excluded.request_hash
+                AND idempotency_keys.locked_at < ?)
+         RETURNING 1`,
+		scope, key, rec.Method, rec.Path, rec.RequestHash, formatTime(now), formatTime(now.Add(ttl)), formatTime(now),
+		formatTime(now.Add(-lockTimeout)),
+	).Scan(&claimed)
+	if err == nil {
+		return true, repository.IdempotencyRecord{LockedAt: now}, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, repository.IdempotencyRecord{}, err
+	}
+
+	var (
+		status                      sql.NullInt64
+		contentType, etag, location sql.NullString
+	)
+	err = r.db.QueryRowContext(ctx,
+		`SELECT method, path, request_hash, status_code, content_type, etag, location, response_body
+         FROM idempotency_keys
+         WHERE scope = ? AND key = ?`,
+		scope, key,
+	).Scan(&existing.Method, &existing.Path, &existing.RequestHash, &status, &contentType, &etag, &location, &existing.ResponseBody)
+	if err != nil {
+		// ключ успели удалить между запросами, клиенту достаточно повторить
+		return false, repository.IdempotencyRecord{}, err
+	}
+	existing.StatusCode = int(status.Int64)
+	existing.ContentType = contentType.String
+	existing.ETag = etag.String
+	existing.Location = location.String
+	return false, existing, nil
+}
+
+// Complete сохраняет ответ на запрос: статус, тело и заголовки из resp. Если ключ
+// после таймаута блокировки занял повтор (LockedAt не совпадает), ничего не меняет.
+func (r *IdempotencyRepository) Complete(ctx context.Context, scope, key string, resp repository.IdempotencyRecord) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE idempotency_keys
+         SET status_code = ?, content_type = ?, etag = ?, location = ?, response_body = ?
+         WHERE scope = ? AND key = ? AND locked_at = ?`,
+		resp.StatusCode, resp.ContentType, resp.ETag, resp.Location, resp.ResponseBody, scope, key, formatTime(resp.LockedAt),
+	)
+	return err
+}
+
+// Release освобождает ключ, если запрос не удалось выполнить и его можно повторить.
+// Ключ, который уже занял повтор, не трогает.
+func (r *IdempotencyRepository) Release(ctx context.Context, scope, key string, lockedAt time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		`DELETE FROM idempotency_keys
+         WHERE scope = ? AND key = ? AND locked_at = ?`,
+		scope, key, formatTime(lockedAt),
+	)
+	return err
+}
+
+// DeleteExpired удаляет истекшие ключи
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM idempotency_keys WHERE expires_at < ?`,
+		formatTime(time.Now()),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
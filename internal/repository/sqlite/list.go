@@ -0,0 +1,57 @@
+package sqlite
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+)
+
+// queryArgs аргументы собираемого запроса, add возвращает плейсхолдер ?N
+type queryArgs []any
+
+func (a *queryArgs) add(v any) string {
+	*a = append(*a, v)
+	return "?" + strconv.Itoa(len(*a))
+}
+
+// like условие поиска подстроки, LIKE в SQLite не учитывает регистр для ASCII
+func like(col string, args *queryArgs, substr string) string {
+	return col + " LIKE " + args.add(repository.ContainsPattern(substr)) + ` ESCAPE '\'`
+}
+
+// keysetCondition условие "после курсора" для сортировки по (col, idCol)
+func keysetCondition(col, idCol string, desc bool, value, id string) string {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+	return "(" + col + ", " + idCol + ") " + op + " (" + value + ", " + id + ")"
+}
+
+// orderBy сортировка по (col, idCol) в нужном направлении
+func orderBy(col, idCol string, desc bool) string {
+	dir := "ASC"
+	if desc {
+		dir = "DESC"
+	}
+	return " ORDER BY " + col + " " + dir + ", " + idCol + " " + dir
+}
+
+func whereClause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
+
+// cursorValue значение курсора в формате колонки, время хранится в timeFormat
+func cursorValue(c *repository.Cursor, sort string) string {
+	if sort == repository.SortByCreatedAt {
+		// курсор проверен сервисом, время в нем корректное
+		t, _ := time.Parse(time.RFC3339Nano, c.Value)
+		return formatTime(t)
+	}
+	return c.Value
+}
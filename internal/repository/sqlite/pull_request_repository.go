@@ -0,0 +1,542 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+)
+
+type PullRequestRepository struct {
+	db dbtx
+}
+
+func NewPullRequestRepository(db *sql.DB) *PullRequestRepository {
+	return &PullRequestRepository{db: db}
+}
+
+func (r *PullRequestRepository) Create(ctx context.Context, pr model.PullRequest, actor string) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	if pr.CreatedAt != nil {
+		now = *pr.CreatedAt
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, merged_at, version)
+         VALUES (?, ?, ?, ?, ?, NULL, ?)`,
+		pr.ID, pr.Name, pr.AuthorID, pr.Status, formatTime(now), max(pr.Version, 1),
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return repository.ErrPRExists
+		}
+		// автора нет в users
+		if isForeignKeyViolation(err) {
+			return fmt.Errorf("author %q: %w", pr.AuthorID, repository.ErrUserNotFound)
+		}
+		return err
+	}
+
+	if err := insertEvent(ctx, tx, model.PullRequestEvent{
+		PullRequestID: pr.ID,
+		Type:          model.EventPRCreated,
+		UserID:        pr.AuthorID,
+		Actor:         actor,
+		CreatedAt:     now,
+	}); err != nil {
+		return err
+	}
+
+	for _, u := range pr.Reviewers {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO pull_request_reviewers (pull_request_id, user_id, assigned_at)
+             VALUES (?, ?, ?)`,
+			pr.ID, u.ID, formatTime(now),
+		)
+		if err != nil {
+			return err
+		}
+
+		if err := insertEvent(ctx, tx, model.PullRequestEvent{
+			PullRequestID: pr.ID,
+			Type:          model.EventReviewerAssigned,
+			UserID:        u.ID,
+			Actor:         actor,
+			CreatedAt:     now,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetByID возвращает PR и его ревьюеров
+func (r *PullRequestRepository) GetByID(ctx context.Context, prID string) (model.PullRequest, error) {
+	var (
+		pr        model.PullRequest
+		createdAt string
+		mergedAt  sql.NullString
+	)
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at, version
+         FROM pull_requests
+         WHERE pull_request_id = ?`,
+		prID,
+	).Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &createdAt, &mergedAt, &pr.Version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.PullRequest{}, repository.ErrPRNotFound
+		}
+		return model.PullRequest{}, err
+	}
+
+	created, err := parseTime(createdAt)
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+	pr.CreatedAt = &created
+	if pr.MergedAt, err = parseNullTime(mergedAt); err != nil {
+		return model.PullRequest{}, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT u.user_id, u.username, u.team_name, u.is_active
+         FROM pull_request_reviewers prr
+         JOIN users u ON prr.user_id = u.user_id
+         WHERE prr.pull_request_id = ?`,
+		prID,
+	)
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+
+	reviewers, err := scanUsers(rows)
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+	if reviewers == nil {
+		reviewers = make([]model.User, 0)
+	}
+	pr.Reviewers = reviewers
+
+	return pr, nil
+}
+
+// ReassignReviewer Переназначает ревьюера, если PR открыт и его версия не изменилась с момента чтения.
+// Иначе возвращает ErrVersionConflict, и сервис перечитывает PR.
+func (r *PullRequestRepository) ReassignReviewer(ctx context.Context, prID string, version int64, oldUserID, newUserID, eventType, actor string) error {
+	// транзакция стартует с блокировкой на запись (_txlock=immediate),
+	// параллельные изменения дождутся коммита и не совпадут по версии
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE pull_requests
+         SET version = version + 1
+         WHERE pull_request_id = ? AND version = ? AND status = 'OPEN'`,
+		prID, version,
+	)
+	if err != nil {
+		return err
+	}
+	if err := requireAffected(res, repository.ErrVersionConflict); err != nil {
+		return err
+	}
+
+	// удаляем старого ревьюера
+	res, err = tx.ExecContext(ctx,
+		`DELETE FROM pull_request_reviewers
+         WHERE pull_request_id = ? AND user_id = ?`,
+		prID, oldUserID,
+	)
+	if err != nil {
+		return err
+	}
+
+	// если он не был назначен
+	if err := requireAffected(res, repository.ErrReviewerNotAssigned); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+
+	// добавляем нового
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO pull_request_reviewers (pull_request_id, user_id, assigned_at)
+         VALUES (?, ?, ?)`,
+		prID, newUserID, formatTime(now),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := insertEvent(ctx, tx, model.PullRequestEvent{
+		PullRequestID: prID,
+		Type:          eventType,
+		UserID:        newUserID,
+		OldUserID:     oldUserID,
+		Actor:         actor,
+		CreatedAt:     now,
+	}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MarkMerged обновляет флаг Merged, если PR еще открыт и (при version != 0) его версия
+// не изменилась с момента чтения. Иначе возвращает ErrVersionConflict.
+func (r *PullRequestRepository) MarkMerged(ctx context.Context, prID string, version int64, mergedAt time.Time, actor string) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE pull_requests
+         SET status = 'MERGED',
+             merged_at = COALESCE(merged_at, ?3),
+             version = version + 1
+         WHERE pull_request_id = ?1 AND status = 'OPEN' AND (?2 = 0 OR version = ?2)`,
+		prID, version, formatTime(mergedAt),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := requireAffected(res, repository.ErrVersionConflict); err != nil {
+		return err
+	}
+
+	if err := insertEvent(ctx, tx, model.PullRequestEvent{
+		PullRequestID: prID,
+		Type:          model.EventPRMerged,
+		Actor:         actor,
+		CreatedAt:     mergedAt,
+	}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetOpenAssignments возвращает все назначения ревьюверов на открытые PR
+func (r *PullRequestRepository) GetOpenAssignments(ctx context.Context) ([]model.ReviewAssignment, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, a.team_name,
+                u.user_id, u.username, prr.assigned_at, prr.reminded_at
+         FROM pull_requests pr
+         JOIN users a ON a.user_id = pr.author_id
+         JOIN pull_request_reviewers prr ON prr.pull_request_id = pr.pull_request_id
+         JOIN users u ON u.user_id = prr.user_id
+         WHERE pr.status = 'OPEN'
+         ORDER BY prr.assigned_at`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var res []model.ReviewAssignment
+	for rows.Next() {
+		var (
+			a          model.ReviewAssignment
+			assignedAt string
+			remindedAt sql.NullString
+		)
+		if err := rows.Scan(&a.PullRequestID, &a.PullRequestName, &a.AuthorID, &a.TeamName,
+			&a.ReviewerID, &a.ReviewerName, &assignedAt, &remindedAt); err != nil {
+			return nil, err
+		}
+		if a.AssignedAt, err = parseTime(assignedAt); err != nil {
+			return nil, err
+		}
+		if a.RemindedAt, err = parseNullTime(remindedAt); err != nil {
+			return nil, err
+		}
+		res = append(res, a)
+	}
+	return res, rows.Err()
+}
+
+// MarkReminded фиксирует отправку напоминания ревьюверу
+func (r *PullRequestRepository) MarkReminded(ctx context.Context, prID, userID string, at time.Time, actor string) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE pull_request_reviewers
+         SET reminded_at = ?
+         WHERE pull_request_id = ? AND user_id = ?`,
+		formatTime(at), prID, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := requireAffected(res, repository.ErrReviewerNotAssigned); err != nil {
+		return err
+	}
+
+	if err := insertEvent(ctx, tx, model.PullRequestEvent{
+		PullRequestID: prID,
+		Type:          model.EventReviewerReminded,
+		UserID:        userID,
+		Actor:         actor,
+		CreatedAt:     at,
+	}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AddEvent добавляет событие в историю PR
+func (r *PullRequestRepository) AddEvent(ctx context.Context, event model.PullRequestEvent) error {
+	return insertEvent(ctx, r.db, event)
+}
+
+// GetEvents возвращает историю PR в порядке появления событий
+func (r *PullRequestRepository) GetEvents(ctx context.Context, prID string) ([]model.PullRequestEvent, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, pull_request_id, event_type, COALESCE(user_id, ''), COALESCE(old_user_id, ''), actor, created_at
+         FROM pull_request_events
+         WHERE pull_request_id = ?
+         ORDER BY id`,
+		prID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]model.PullRequestEvent, 0)
+	for rows.Next() {
+		var (
+			e         model.PullRequestEvent
+			createdAt string
+		)
+		if err := rows.Scan(&e.ID, &e.PullRequestID, &e.Type, &e.UserID, &e.OldUserID, &e.Actor, &createdAt); err != nil {
+			return nil, err
+		}
+		if e.CreatedAt, err = parseTime(createdAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// GetReviewQueueEvents изменения очереди ревью пользователя: назначения, снятия при
+// переназначении и мерж PR, где он ревьювер. Запись в SQLite идет по одной транзакции,
+// поэтому id событий становятся видимыми по возрастанию.
+func (r *PullRequestRepository) GetReviewQueueEvents(ctx context.Context, userID string, afterID int64, limit int) ([]model.ReviewQueueEvent, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT e.id,
+                CASE WHEN e.event_type = 'MERGED' THEN 'MERGED'
+                     WHEN e.old_user_id = ?1 THEN 'UNASSIGNED'
+                     ELSE 'ASSIGNED' END,
+                pr.pull_request_id, pr.pull_request_name, pr.author_id, e.created_at
+         FROM pull_request_events e
+         JOIN pull_requests pr ON pr.pull_request_id = e.pull_request_id
+         WHERE e.id > ?2 AND (
+               (e.event_type IN ('REVIEWER_ASSIGNED', 'REVIEWER_REASSIGNED', 'ESCALATED') AND e.user_id = ?1)
+            OR (e.event_type IN ('REVIEWER_REASSIGNED', 'ESCALATED') AND e.old_user_id = ?1)
+            OR (e.event_type = 'MERGED' AND EXISTS (SELECT 1 FROM pull_request_reviewers prr
+                    WHERE prr.pull_request_id = e.pull_request_id AND prr.user_id = ?1)))
+         ORDER BY e.id
+         LIMIT ?3`,
+		userID, afterID, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := make([]model.ReviewQueueEvent, 0)
+	for rows.Next() {
+		var (
+			e  = model.ReviewQueueEvent{UserID: userID}
+			at string
+		)
+		if err := rows.Scan(&e.ID, &e.Type, &e.PullRequest.ID, &e.PullRequest.Name, &e.PullRequest.AuthorID, &at); err != nil {
+			return nil, err
+		}
+		if e.At, err = parseTime(at); err != nil {
+			return nil, err
+		}
+		e.PullRequest.Status = "OPEN"
+		if e.Type == model.ReviewQueueMerged {
+			e.PullRequest.Status = "MERGED"
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// LastEventID id последнего события истории PR, запись в SQLite идет по очереди,
+// поэтому ждать незакоммиченные события пользователя не нужно
+func (r *PullRequestRepository) LastEventID(ctx context.Context, _ string) (int64, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `SELECT COALESCE(MAX(id), 0) FROM pull_request_events`).Scan(&id)
+	return id, err
+}
+
+func insertEvent(ctx context.Context, db dbtx, e model.PullRequestEvent) error {
+	_, err := db.ExecContext(ctx,
+		`INSERT INTO pull_request_events (pull_request_id, event_type, user_id, old_user_id, actor, created_at)
+         VALUES (?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, ?)`,
+		e.PullRequestID, e.Type, e.UserID, e.OldUserID, e.Actor, formatTime(e.CreatedAt),
+	)
+	return err
+}
+
+// List возвращает страницу PR с ревьюверами и курсор следующей страницы (nil, если она последняя)
+func (r *PullRequestRepository) List(ctx context.Context, f repository.PullRequestFilter, p repository.Page) ([]model.PullRequest, *repository.Cursor, error) {
+	var (
+		args  queryArgs
+		conds []string
+	)
+	from := "pull_requests pr"
+	if f.TeamName != "" {
+		from += " JOIN users a ON a.user_id = pr.author_id"
+		conds = append(conds, "a.team_name = "+args.add(f.TeamName))
+	}
+	if f.Status != "" {
+		conds = append(conds, "pr.status = "+args.add(f.Status))
+	}
+	if f.AuthorID != "" {
+		conds = append(conds, "pr.author_id = "+args.add(f.AuthorID))
+	}
+	if f.ReviewerID != "" {
+		conds = append(conds, `EXISTS (SELECT 1 FROM pull_request_reviewers prr
+             WHERE prr.pull_request_id = pr.pull_request_id AND prr.user_id = `+args.add(f.ReviewerID)+`)`)
+	}
+	if f.CreatedFrom != nil {
+		conds = append(conds, "pr.created_at >= "+args.add(formatTime(*f.CreatedFrom)))
+	}
+	if f.CreatedTo != nil {
+		conds = append(conds, "pr.created_at < "+args.add(formatTime(*f.CreatedTo)))
+	}
+	if f.Name != "" {
+		conds = append(conds, like("pr.pull_request_name", &args, f.Name))
+	}
+
+	col := "pr.pull_request_id"
+	switch p.Sort {
+	case repository.SortByCreatedAt:
+		col = "pr.created_at"
+	case repository.SortByName:
+		col = "pr.pull_request_name"
+	}
+	if p.After != nil {
+		conds = append(conds, keysetCondition(col, "pr.pull_request_id", p.Desc,
+			args.add(cursorValue(p.After, p.Sort)), args.add(p.After.ID)))
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at, pr.version
+         FROM `+from+whereClause(conds)+orderBy(col, "pr.pull_request_id", p.Desc)+
+			` LIMIT `+args.add(p.Limit+1),
+		args...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	prs := make([]model.PullRequest, 0)
+	for rows.Next() {
+		var (
+			pr        model.PullRequest
+			createdAt string
+			mergedAt  sql.NullString
+		)
+		if err := rows.Scan(&pr.ID, &pr.Name, &pr.AuthorID, &pr.Status, &createdAt, &mergedAt, &pr.Version); err != nil {
+			return nil, nil, err
+		}
+		created, err := parseTime(createdAt)
+		if err != nil {
+			return nil, nil, err
+		}
+		pr.CreatedAt = &created
+		if pr.MergedAt, err = parseNullTime(mergedAt); err != nil {
+			return nil, nil, err
+		}
+		pr.Reviewers = make([]model.User, 0)
+		prs = append(prs, pr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	rows.Close()
+
+	prs, next := repository.Paginate(prs, p, repository.PullRequestCursor)
+	if err := r.loadReviewers(ctx, prs); err != nil {
+		return nil, nil, err
+	}
+	return prs, next, nil
+}
+
+// loadReviewers заполняет ревьюверов у страницы PR одним запросом
+func (r *PullRequestRepository) loadReviewers(ctx context.Context, prs []model.PullRequest) error {
+	if len(prs) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(prs))
+	index := make(map[string]int, len(prs))
+	for i, pr := range prs {
+		ids = append(ids, pr.ID)
+		index[pr.ID] = i
+	}
+	idsJSON, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT prr.pull_request_id, u.user_id, u.username, u.team_name, u.is_active
+         FROM pull_request_reviewers prr
+         JOIN users u ON prr.user_id = u.user_id
+         WHERE prr.pull_request_id IN (SELECT value FROM json_each(?))
+         ORDER BY prr.assigned_at, u.user_id`,
+		string(idsJSON),
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			prID string
+			u    model.User
+		)
+		if err := rows.Scan(&prID, &u.ID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+			return err
+		}
+		i := index[prID]
+		prs[i].Reviewers = append(prs[i].Reviewers, u)
+	}
+	return rows.Err()
+}
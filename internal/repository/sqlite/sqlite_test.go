@@ -0,0 +1,45 @@
+package sqlite_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/migrator"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository/repotest"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository/sqlite"
+)
+
+func TestStorage(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) repository.Storage {
+		path := filepath.Join(t.TempDir(), "reviewer.db")
+
+		m, err := migrator.NewSQLite(path)
+		if err != nil {
+			t.Fatalf("failed to create migrator: %v", err)
+		}
+		if err := m.Up(); err != nil {
+			t.Fatalf("failed to apply migrations: %v", err)
+		}
+		if err := m.Close(); err != nil {
+			t.Fatalf("failed to close migrator: %v", err)
+		}
+
+		db, err := sqlite.Open(context.Background(), path)
+		if err != nil {
+			t.Fatalf("failed to open db: %v", err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+
+		latest, err := migrator.LatestSQLiteVersion()
+		if err != nil {
+			t.Fatalf("failed to read migrations: %v", err)
+		}
+		if err := sqlite.CheckSchemaVersion(context.Background(), db, int64(latest)); err != nil {
+			t.Fatalf("schema check failed: %v", err)
+		}
+
+		return sqlite.NewStorage(db)
+	})
+}
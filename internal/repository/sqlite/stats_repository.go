@@ -0,0 +1,68 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+)
+
+type StatsRepository struct {
+	db dbtx
+}
+
+func NewStatsRepository(db *sql.DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+// GetAssignmentsByUser Возвращает количество назначений по юзерам
+func (r *StatsRepository) GetAssignmentsByUser(ctx context.Context) ([]repository.UserAssignmentsStat, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT user_id, COUNT(*) AS assigned_count
+         FROM pull_request_reviewers
+         GROUP BY user_id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]repository.UserAssignmentsStat, 0)
+	for rows.Next() {
+		var s repository.UserAssignmentsStat
+		if err := rows.Scan(&s.UserID, &s.AssignedCount); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// GetAssignmentsByPR Возвращает количество ревьюверов по PR
+func (r *StatsRepository) GetAssignmentsByPR(ctx context.Context) ([]repository.PullRequestAssignmentsStat, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT pull_request_id, COUNT(*) AS reviewers_count
+         FROM pull_request_reviewers
+         GROUP BY pull_request_id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]repository.PullRequestAssignmentsStat, 0)
+	for rows.Next() {
+		var s repository.PullRequestAssignmentsStat
+		if err := rows.Scan(&s.PullRequestID, &s.ReviewersCount); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
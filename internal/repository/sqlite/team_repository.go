@@ -0,0 +1,186 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+)
+
+type TeamRepository struct {
+	db dbtx
+}
+
+func NewTeamRepository(db *sql.DB) *TeamRepository {
+	return &TeamRepository{db: db}
+}
+
+// CreateTeam - создает команду
+func (r *TeamRepository) CreateTeam(ctx context.Context, team model.Team) error {
+	tx, err := begin(ctx, r.db)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO teams (team_name)
+         VALUES (?)`,
+		team.Name,
+	)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return repository.ErrTeamExists
+		}
+		return err
+	}
+
+	for _, u := range team.Users {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO users (user_id, username, team_name, is_active)
+             VALUES (?, ?, ?, ?)
+             ON CONFLICT (user_id) DO UPDATE
+             SET username = excluded.username,
+                 team_name = excluded.team_name,
+                 is_active = excluded.is_active`,
+			u.ID, u.Username, team.Name, u.IsActive,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetTeam возвращает команду с ее пользователями
+func (r *TeamRepository) GetTeam(ctx context.Context, teamName string) (model.Team, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		`SELECT EXISTS(
+             SELECT 1 FROM teams WHERE team_name = ?
+         )`,
+		teamName,
+	).Scan(&exists)
+	if err != nil {
+		return model.Team{}, err
+	}
+	if !exists {
+		return model.Team{}, repository.ErrTeamNotFound
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT user_id, username, team_name, is_active
+         FROM users
+         WHERE team_name = ?`,
+		teamName,
+	)
+	if err != nil {
+		return model.Team{}, err
+	}
+
+	users, err := scanUsers(rows)
+	if err != nil {
+		return model.Team{}, err
+	}
+	if users == nil {
+		users = make([]model.User, 0)
+	}
+	return model.Team{Name: teamName, Users: users}, nil
+}
+
+// List возвращает страницу команд с участниками и курсор следующей страницы
+func (r *TeamRepository) List(ctx context.Context, f repository.TeamFilter, p repository.Page) ([]model.Team, *repository.Cursor, error) {
+	var (
+		args  queryArgs
+		conds []string
+	)
+	if f.TeamName != "" {
+		conds = append(conds, "team_name = "+args.add(f.TeamName))
+	}
+	if f.Name != "" {
+		conds = append(conds, like("team_name", &args, f.Name))
+	}
+	if p.After != nil {
+		op := ">"
+		if p.Desc {
+			op = "<"
+		}
+		conds = append(conds, "team_name "+op+" "+args.add(p.After.ID))
+	}
+
+	dir := "ASC"
+	if p.Desc {
+		dir = "DESC"
+	}
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT team_name
+         FROM teams`+whereClause(conds)+
+			` ORDER BY team_name `+dir+
+			` LIMIT `+args.add(p.Limit+1),
+		args...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	teams := make([]model.Team, 0)
+	for rows.Next() {
+		team := model.Team{Users: make([]model.User, 0)}
+		if err := rows.Scan(&team.Name); err != nil {
+			return nil, nil, err
+		}
+		teams = append(teams, team)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	rows.Close()
+
+	teams, next := repository.Paginate(teams, p, repository.TeamCursor)
+	if err := r.loadMembers(ctx, teams); err != nil {
+		return nil, nil, err
+	}
+	return teams, next, nil
+}
+
+// loadMembers заполняет участников у страницы команд одним запросом
+func (r *TeamRepository) loadMembers(ctx context.Context, teams []model.Team) error {
+	if len(teams) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(teams))
+	index := make(map[string]int, len(teams))
+	for i, t := range teams {
+		names = append(names, t.Name)
+		index[t.Name] = i
+	}
+	namesJSON, err := json.Marshal(names)
+	if err != nil {
+		return err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT user_id, username, team_name, is_active
+         FROM users
+         WHERE team_name IN (SELECT value FROM json_each(?))
+         ORDER BY user_id`,
+		string(namesJSON),
+	)
+	if err != nil {
+		return err
+	}
+
+	users, err := scanUsers(rows)
+	if err != nil {
+		return err
+	}
+	for _, u := range users {
+		i := index[u.TeamName]
+		teams[i].Users = append(teams[i].Users, u)
+	}
+	return nil
+}
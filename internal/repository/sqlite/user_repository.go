@@ -0,0 +1,182 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+)
+
+type UserRepository struct {
+	db dbtx
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+// SetIsActive устанавливает флаг активности пользователя
+func (r *UserRepository) SetIsActive(ctx context.Context, userID string, isActive bool) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE users
+         SET is_active = ?
+         WHERE user_id = ?`,
+		isActive, userID,
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res, repository.ErrUserNotFound)
+}
+
+// GetByID возвращает пользователя по ID
+func (r *UserRepository) GetByID(ctx context.Context, userID string) (model.User, error) {
+	var u model.User
+
+	err := r.db.QueryRowContext(ctx,
+		`SELECT user_id, username, team_name, is_active
+         FROM users
+         WHERE user_id = ?`,
+		userID,
+	).Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.User{}, repository.ErrUserNotFound
+		}
+		return model.User{}, err
+	}
+
+	return u, nil
+}
+
+// GetRole возвращает роль пользователя и его команду
+func (r *UserRepository) GetRole(ctx context.Context, userID string) (role, teamName string, err error) {
+	err = r.db.QueryRowContext(ctx,
+		`SELECT role, team_name
+         FROM users
+         WHERE user_id = ?`,
+		userID,
+	).Scan(&role, &teamName)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", "", repository.ErrUserNotFound
+		}
+		return "", "", err
+	}
+
+	return role, teamName, nil
+}
+
+// SetRole меняет роль пользователя
+func (r *UserRepository) SetRole(ctx context.Context, userID, role string) error {
+	res, err := r.db.ExecContext(ctx,
+		`UPDATE users
+         SET role = ?
+         WHERE user_id = ?`,
+		role, userID,
+	)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res, repository.ErrUserNotFound)
+}
+
+// GetActiveByTeamExcept возвращает активных пользователей команды исключая авторов и уже назначенных
+func (r *UserRepository) GetActiveByTeamExcept(ctx context.Context, teamName string, excludeIDs []string) ([]model.User, error) {
+	// массивов в SQLite нет, список передаем JSON массивом.
+	// nil превратился бы в JSON null, а NOT IN (NULL) отсекает всех
+	if excludeIDs == nil {
+		excludeIDs = []string{}
+	}
+	exclude, err := json.Marshal(excludeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT user_id, username, team_name, is_active
+         FROM users
+         WHERE team_name = ?
+           AND is_active = 1
+           AND user_id NOT IN (SELECT value FROM json_each(?))`,
+		teamName, string(exclude),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanUsers(rows)
+}
+
+func scanUsers(rows *sql.Rows) ([]model.User, error) {
+	defer rows.Close()
+
+	var res []model.User
+	for rows.Next() {
+		var u model.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+			return nil, err
+		}
+		res = append(res, u)
+	}
+	return res, rows.Err()
+}
+
+// requireAffected возвращает notFound, если запрос не затронул ни одной строки
+func requireAffected(res sql.Result, notFound error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// List возвращает страницу пользователей и курсор следующей страницы
+func (r *UserRepository) List(ctx context.Context, f repository.UserFilter, p repository.Page) ([]model.User, *repository.Cursor, error) {
+	var (
+		args  queryArgs
+		conds []string
+	)
+	if f.TeamName != "" {
+		conds = append(conds, "team_name = "+args.add(f.TeamName))
+	}
+	if f.IsActive != nil {
+		conds = append(conds, "is_active = "+args.add(*f.IsActive))
+	}
+	if f.Name != "" {
+		conds = append(conds, like("username", &args, f.Name))
+	}
+
+	col := "user_id"
+	if p.Sort == repository.SortByName {
+		col = "username"
+	}
+	if p.After != nil {
+		conds = append(conds, keysetCondition(col, "user_id", p.Desc, args.add(p.After.Value), args.add(p.After.ID)))
+	}
+
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT user_id, username, team_name, is_active
+         FROM users`+whereClause(conds)+orderBy(col, "user_id", p.Desc)+
+			` LIMIT `+args.add(p.Limit+1),
+		args...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	users, err := scanUsers(rows)
+	if err != nil {
+		return nil, nil, err
+	}
+	if users == nil {
+		users = make([]model.User, 0)
+	}
+	users, next := repository.Paginate(users, p, repository.UserCursor)
+	return users, next, nil
+}
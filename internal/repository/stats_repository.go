@@ -2,8 +2,6 @@ package repository
 
 import (
 	"context"
-
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type UserAssignmentsStat struct {
@@ -17,10 +15,10 @@ type PullRequestAssignmentsStat struct {
 }
 
 type StatsRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
-func NewStatsRepository(db *pgxpool.Pool) *StatsRepository {
+func NewStatsRepository(db DBTX) *StatsRepository {
 	return &StatsRepository{db: db}
 }
 
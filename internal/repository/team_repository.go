@@ -5,17 +5,16 @@ import (
 	"errors"
 	"github.com/Olzerq/avito-pr-reviewer/internal/model"
 	"github.com/jackc/pgx/v5/pgconn"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var ErrTeamExists = errors.New("team already exists")
 var ErrTeamNotFound = errors.New("team not found")
 
 type TeamRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
-func NewTeamRepository(db *pgxpool.Pool) *TeamRepository {
+func NewTeamRepository(db DBTX) *TeamRepository {
 	return &TeamRepository{db: db}
 }
 
@@ -105,3 +104,93 @@ func (r *TeamRepository) GetTeam(ctx context.Context, teamName string) (model.Te
 	}
 	return team, nil
 }
+
+// List возвращает страницу команд с участниками и курсор следующей страницы
+func (r *TeamRepository) List(ctx context.Context, f TeamFilter, p Page) ([]model.Team, *Cursor, error) {
+	var (
+		args  queryArgs
+		conds []string
+	)
+	if f.TeamName != "" {
+		conds = append(conds, "team_name = "+args.add(f.TeamName))
+	}
+	if f.Name != "" {
+		conds = append(conds, "team_name ILIKE "+args.add(ContainsPattern(f.Name)))
+	}
+	if p.After != nil {
+		op := ">"
+		if p.Desc {
+			op = "<"
+		}
+		conds = append(conds, "team_name "+op+" "+args.add(p.After.ID))
+	}
+
+	dir := "ASC"
+	if p.Desc {
+		dir = "DESC"
+	}
+	rows, err := r.db.Query(ctx,
+		`SELECT team_name
+         FROM teams`+whereClause(conds)+
+			` ORDER BY team_name `+dir+
+			` LIMIT `+args.add(p.Limit+1),
+		args...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	teams := make([]model.Team, 0)
+	for rows.Next() {
+		team := model.Team{Users: make([]model.User, 0)}
+		if err := rows.Scan(&team.Name); err != nil {
+			return nil, nil, err
+		}
+		teams = append(teams, team)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	teams, next := Paginate(teams, p, TeamCursor)
+	if err := r.loadMembers(ctx, teams); err != nil {
+		return nil, nil, err
+	}
+	return teams, next, nil
+}
+
+// loadMembers заполняет участников у страницы команд одним запросом
+func (r *TeamRepository) loadMembers(ctx context.Context, teams []model.Team) error {
+	if len(teams) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(teams))
+	index := make(map[string]int, len(teams))
+	for i, t := range teams {
+		names = append(names, t.Name)
+		index[t.Name] = i
+	}
+
+	rows, err := r.db.Query(ctx,
+		`SELECT user_id, username, team_name, is_active
+         FROM users
+         WHERE team_name = ANY($1)
+         ORDER BY user_id`,
+		names,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u model.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+			return err
+		}
+		i := index[u.TeamName]
+		teams[i].Users = append(teams[i].Users, u)
+	}
+	return rows.Err()
+}
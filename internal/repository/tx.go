@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DBTX пул или транзакция, репозитории работают с обоими.
+// Begin внутри транзакции создает savepoint, поэтому методы репозиториев
+// остаются атомарными и во внешней транзакции.
+type DBTX interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// maxSerializationRetries сколько раз повторяем транзакцию при конфликте сериализации
+const maxSerializationRetries = 3
+
+// PostgresTxManager транзакции в Postgres. Уровень REPEATABLE READ, чтобы все чтения
+// внутри fn видели один снимок данных.
+type PostgresTxManager struct {
+	db *pgxpool.Pool
+}
+
+func NewPostgresTxManager(db *pgxpool.Pool) *PostgresTxManager {
+	return &PostgresTxManager{db: db}
+}
+
+func (m *PostgresTxManager) InTx(ctx context.Context, fn func(tx Repos) error) error {
+	opts := pgx.TxOptions{IsoLevel: pgx.RepeatableRead}
+	for attempt := 1; ; attempt++ {
+		err := pgx.BeginTxFunc(ctx, m.db, opts, func(tx pgx.Tx) error {
+			return fn(Repos{
+				Teams:        NewTeamRepository(tx),
+				Users:        NewUserRepository(tx),
+				PullRequests: NewPullRequestRepository(tx),
+				Stats:        NewStatsRepository(tx),
+			})
+		})
+		// 40001 — данные, прочитанные в снимке, изменила параллельная транзакция;
+		// 40P01 — взаимная блокировка, например двух пакетов на блокировках очередей ревью
+		var pgErr *pgconn.PgError
+		if attempt < maxSerializationRetries && errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01") {
+			continue
+		}
+		return err
+	}
+}
@@ -5,16 +5,15 @@ import (
 	"errors"
 	"github.com/Olzerq/avito-pr-reviewer/internal/model"
 	"github.com/jackc/pgx/v5"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var ErrUserNotFound = errors.New("user not found")
 
 type UserRepository struct {
-	db *pgxpool.Pool
+	db DBTX
 }
 
-func NewUserRepository(db *pgxpool.Pool) *UserRepository {
+func NewUserRepository(db DBTX) *UserRepository {
 	return &UserRepository{db: db}
 }
 
@@ -57,6 +56,43 @@ func (r *UserRepository) GetByID(ctx context.Context, userID string) (model.User
 	return u, nil
 }
 
+// GetRole возвращает роль пользователя и его команду
+func (r *UserRepository) GetRole(ctx context.Context, userID string) (role, teamName string, err error) {
+	err = r.db.QueryRow(ctx,
+		`SELECT role, team_name
+         FROM users
+         WHERE user_id = $1`,
+		userID,
+	).Scan(&role, &teamName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", "", ErrUserNotFound
+		}
+		return "", "", err
+	}
+
+	return role, teamName, nil
+}
+
+// SetRole меняет роль пользователя
+func (r *UserRepository) SetRole(ctx context.Context, userID, role string) error {
+	cmdTag, err := r.db.Exec(ctx,
+		`UPDATE users
+         SET role = $1
+         WHERE user_id = $2`,
+		role, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if cmdTag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+
+	return nil
+}
+
 // GetActiveByTeamExcept возвращает активных пользователей команды исключая авторов и уже назначенных
 func (r *UserRepository) GetActiveByTeamExcept(ctx context.Context, teamName string, excludeIDs []string) ([]model.User, error) {
 	// Если исключать некого
@@ -107,3 +143,54 @@ func (r *UserRepository) GetActiveByTeamExcept(ctx context.Context, teamName str
 	}
 	return res, rows.Err()
 }
+
+// List возвращает страницу пользователей и курсор следующей страницы
+func (r *UserRepository) List(ctx context.Context, f UserFilter, p Page) ([]model.User, *Cursor, error) {
+	var (
+		args  queryArgs
+		conds []string
+	)
+	if f.TeamName != "" {
+		conds = append(conds, "team_name = "+args.add(f.TeamName))
+	}
+	if f.IsActive != nil {
+		conds = append(conds, "is_active = "+args.add(*f.IsActive))
+	}
+	if f.Name != "" {
+		conds = append(conds, "username ILIKE "+args.add(ContainsPattern(f.Name)))
+	}
+
+	col := "user_id"
+	if p.Sort == SortByName {
+		col = "username"
+	}
+	if p.After != nil {
+		conds = append(conds, keysetCondition(col, "user_id", p.Desc, args.add(p.After.Value), args.add(p.After.ID)))
+	}
+
+	rows, err := r.db.Query(ctx,
+		`SELECT user_id, username, team_name, is_active
+         FROM users`+whereClause(conds)+orderBy(col, "user_id", p.Desc)+
+			` LIMIT `+args.add(p.Limit+1),
+		args...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	users := make([]model.User, 0)
+	for rows.Next() {
+		var u model.User
+		if err := rows.Scan(&u.ID, &u.Username, &u.TeamName, &u.IsActive); err != nil {
+			return nil, nil, err
+		}
+		users = append(users, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	users, next := Paginate(users, p, UserCursor)
+	return users, next, nil
+}
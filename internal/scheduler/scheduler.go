@@ -0,0 +1,176 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+)
+
+// Ключ блокировки, чтобы проход выполняла только одна реплика
+const lockKey int64 = 727001
+
+// Policy SLA на ревью
+type Policy struct {
+	RemindAfter   time.Duration // через сколько напоминать ревьюверу (и повторять с тем же интервалом)
+	EscalateAfter time.Duration // через сколько переназначать ревьювера, 0 - не переназначать
+}
+
+// Scheduler периодически ищет зависшие ревью, напоминает о них и при необходимости переназначает
+type Scheduler struct {
+	locker    repository.Locker
+	prRepo    repository.PullRequests
+	prService *service.PullRequestService
+	notifier  service.Notifier
+	interval  time.Duration
+	defaults  Policy
+	teams     map[string]Policy
+}
+
+func New(
+	locker repository.Locker,
+	prRepo repository.PullRequests,
+	prService *service.PullRequestService,
+	notifier service.Notifier,
+	interval time.Duration,
+	defaults Policy,
+	teams map[string]Policy,
+) *Scheduler {
+	return &Scheduler{
+		locker:    locker,
+		prRepo:    prRepo,
+		prService: prService,
+		notifier:  notifier,
+		interval:  interval,
+		defaults:  defaults,
+		teams:     teams,
+	}
+}
+
+// Run запускает проходы по таймеру до отмены контекста
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.RunOnce(ctx); err != nil {
+				slog.Error("scheduler run failed", slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// RunOnce выполняет один проход, если другая реплика уже выполняет его - ничего не делает
+func (s *Scheduler) RunOnce(ctx context.Context) error {
+	unlock, ok, err := s.locker.TryLock(ctx, lockKey)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+	defer unlock()
+
+	assignments, err := s.prRepo.GetOpenAssignments(ctx)
+	if err != nil {
+		return err
+	}
+
+	ctx = service.WithSystemIdentity(service.WithActor(ctx, service.ActorScheduler))
+	now := time.Now().UTC()
+
+	for _, a := range assignments {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		policy := s.policyFor(a.TeamName)
+		waiting := now.Sub(a.AssignedAt)
+
+		if policy.EscalateAfter > 0 && waiting >= policy.EscalateAfter {
+			escalated, err := s.escalate(ctx, a)
+			if err != nil {
+				slog.Error("scheduler failed to escalate review",
+					slog.String("pull_request_id", a.PullRequestID),
+					slog.String("reviewer_id", a.ReviewerID),
+					slog.Any("error", err))
+			}
+			if escalated {
+				continue
+			}
+		}
+
+		if policy.RemindAfter > 0 && waiting >= policy.RemindAfter &&
+			(a.RemindedAt == nil || now.Sub(*a.RemindedAt) >= policy.RemindAfter) {
+			if err := s.remind(ctx, a, now); err != nil {
+				slog.Error("scheduler failed to send reminder",
+					slog.String("pull_request_id", a.PullRequestID),
+					slog.String("reviewer_id", a.ReviewerID),
+					slog.Any("error", err))
+			}
+		}
+	}
+
+	return nil
+}
+
+// escalate переназначает ревьювера той же логикой, что и ручное переназначение, замена
+// и событие ESCALATED пишутся одной транзакцией. Если замены нет, возвращает false,
+// чтобы ревьюверу ушло обычное напоминание.
+func (s *Scheduler) escalate(ctx context.Context, a model.ReviewAssignment) (bool, error) {
+	_, newReviewerID, err := s.prService.Escalate(ctx, a.PullRequestID, a.ReviewerID)
+	if err != nil {
+		if errors.Is(err, service.ErrNoCandidate) {
+			return false, nil
+		}
+		// PR успели смержить или ревьювера уже сменили - делать нечего
+		if errors.Is(err, service.ErrPRMerged) || errors.Is(err, repository.ErrReviewerNotAssigned) {
+			return true, nil
+		}
+		return false, err
+	}
+
+	slog.Info("scheduler escalated review",
+		slog.String("pull_request_id", a.PullRequestID),
+		slog.String("old_reviewer_id", a.ReviewerID),
+		slog.String("new_reviewer_id", newReviewerID))
+
+	return true, nil
+}
+
+func (s *Scheduler) remind(ctx context.Context, a model.ReviewAssignment, now time.Time) error {
+	if err := s.prRepo.MarkReminded(ctx, a.PullRequestID, a.ReviewerID, now, service.ActorScheduler); err != nil {
+		return err
+	}
+
+	return s.notifier.Notify(ctx, service.Notification{
+		Event:           service.EventReviewReminder,
+		TeamName:        a.TeamName,
+		PullRequestID:   a.PullRequestID,
+		PullRequestName: a.PullRequestName,
+		AuthorID:        a.AuthorID,
+		ReviewerID:      a.ReviewerID,
+		ReviewerName:    a.ReviewerName,
+	})
+}
+
+func (s *Scheduler) policyFor(teamName string) Policy {
+	policy := s.defaults
+	if team, ok := s.teams[teamName]; ok {
+		if team.RemindAfter > 0 {
+			policy.RemindAfter = team.RemindAfter
+		}
+		if team.EscalateAfter > 0 {
+			policy.EscalateAfter = team.EscalateAfter
+		}
+	}
+	return policy
+}
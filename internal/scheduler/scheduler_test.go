@@ -0,0 +1,252 @@
+package scheduler
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository/memory"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+)
+
+// agedPullRequests сдвигает время назначения ревьюверов в прошлое, чтобы не ждать SLA
+type agedPullRequests struct {
+	repository.PullRequests
+	age time.Duration
+}
+
+func (r *agedPullRequests) GetOpenAssignments(ctx context.Context) ([]model.ReviewAssignment, error) {
+	assignments, err := r.PullRequests.GetOpenAssignments(ctx)
+	for i := range assignments {
+		assignments[i].AssignedAt = assignments[i].AssignedAt.Add(-r.age)
+	}
+	return assignments, err
+}
+
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []service.Notification
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification service.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+// reminded ревьюверы, которым ушли напоминания, по порядку
+func (n *recordingNotifier) reminded() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var ids []string
+	for _, s := range n.sent {
+		if s.Event == service.EventReviewReminder {
+			ids = append(ids, s.ReviewerID)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+type fixture struct {
+	st        repository.Storage
+	prs       *agedPullRequests
+	prService *service.PullRequestService
+	notifier  *recordingNotifier
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+
+	st := memory.NewStorage()
+	return &fixture{
+		st:        st,
+		prs:       &agedPullRequests{PullRequests: st.PullRequests},
+		prService: service.NewPullRequestService(st.Tx, st.PullRequests, st.Users, nil, nil),
+		notifier:  &recordingNotifier{},
+	}
+}
+
+func (f *fixture) scheduler(defaults Policy, teams map[string]Policy) *Scheduler {
+	return New(f.st.Locker, f.prs, f.prService, f.notifier, time.Hour, defaults, teams)
+}
+
+// team создает команду, inactive - неактивные участники
+func (f *fixture) team(t *testing.T, name string, active []string, inactive ...string) {
+	t.Helper()
+
+	team := model.Team{Name: name}
+	for _, id := range active {
+		team.Users = append(team.Users, model.User{ID: id, Username: id, TeamName: name, IsActive: true})
+	}
+	for _, id := range inactive {
+		team.Users = append(team.Users, model.User{ID: id, Username: id, TeamName: name})
+	}
+	if err := f.st.Teams.CreateTeam(context.Background(), team); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (f *fixture) createPR(t *testing.T, id, author string) model.PullRequest {
+	t.Helper()
+
+	pr, err := f.prService.Create(service.WithSystemIdentity(context.Background()), id, id, author)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pr
+}
+
+// eventTypes типы событий истории PR, записанных после создания
+func (f *fixture) eventTypes(t *testing.T, prID string) []string {
+	t.Helper()
+
+	events, err := f.st.PullRequests.GetEvents(context.Background(), prID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, e := range events {
+		if e.Type != model.EventPRCreated && e.Type != model.EventReviewerAssigned {
+			types = append(types, e.Type)
+		}
+	}
+	return types
+}
+
+func TestReminderThresholds(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	f.team(t, "backend", []string{"author", "r1", "r2"})
+	f.createPR(t, "pr-1", "author")
+	s := f.scheduler(Policy{RemindAfter: time.Hour}, nil)
+
+	// SLA еще не истек
+	f.prs.age = 30 * time.Minute
+	if err := s.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.notifier.reminded(); len(got) != 0 {
+		t.Fatalf("expected no reminders before SLA, got %v", got)
+	}
+
+	// SLA истек, напоминание каждому ревьюверу
+	f.prs.age = 90 * time.Minute
+	if err := s.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.notifier.reminded(); !slices.Equal(got, []string{"r1", "r2"}) {
+		t.Fatalf("expected reminders for r1 and r2, got %v", got)
+	}
+
+	// повтор не раньше чем через RemindAfter после прошлого напоминания
+	if err := s.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.notifier.reminded(); len(got) != 2 {
+		t.Fatalf("expected reminders not to repeat within SLA, got %v", got)
+	}
+	if got := f.eventTypes(t, "pr-1"); !slices.Equal(got, []string{model.EventReviewerReminded, model.EventReviewerReminded}) {
+		t.Fatalf("unexpected history %v", got)
+	}
+}
+
+func TestEscalation(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	// spare неактивен при создании PR и становится единственной заменой
+	f.team(t, "backend", []string{"author", "r1"}, "spare")
+	pr := f.createPR(t, "pr-1", "author")
+	if err := f.st.Users.SetIsActive(ctx, "spare", true); err != nil {
+		t.Fatal(err)
+	}
+	s := f.scheduler(Policy{RemindAfter: time.Hour}, map[string]Policy{"backend": {EscalateAfter: 2 * time.Hour}})
+
+	f.prs.age = 3 * time.Hour
+	if err := s.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := f.st.PullRequests.GetByID(ctx, pr.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Reviewers) != 1 || got.Reviewers[0].ID != "spare" {
+		t.Fatalf("expected r1 to be replaced by spare, got %v", got.Reviewers)
+	}
+	// замена - одно событие ESCALATED от планировщика
+	events, err := f.st.PullRequests.GetEvents(ctx, pr.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	last := events[len(events)-1]
+	if types := f.eventTypes(t, pr.ID); !slices.Equal(types, []string{model.EventReviewerEscalated}) {
+		t.Fatalf("expected single ESCALATED event, got %v", types)
+	}
+	if last.UserID != "spare" || last.OldUserID != "r1" || last.Actor != service.ActorScheduler {
+		t.Fatalf("unexpected escalation event %+v", last)
+	}
+	// эскалированному ревьюверу напоминание уже не нужно
+	if got := f.notifier.reminded(); len(got) != 0 {
+		t.Fatalf("expected no reminders after escalation, got %v", got)
+	}
+}
+
+func TestEscalationWithoutCandidateFallsBackToReminder(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	// оба активных участника уже ревьюверы, заменить некем
+	f.team(t, "backend", []string{"author", "r1", "r2"})
+	pr := f.createPR(t, "pr-1", "author")
+	s := f.scheduler(Policy{RemindAfter: time.Hour, EscalateAfter: 2 * time.Hour}, nil)
+
+	f.prs.age = 3 * time.Hour
+	if err := s.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := f.notifier.reminded(); !slices.Equal(got, []string{"r1", "r2"}) {
+		t.Fatalf("expected reminders instead of escalation, got %v", got)
+	}
+	if got := f.eventTypes(t, pr.ID); !slices.Equal(got, []string{model.EventReviewerReminded, model.EventReviewerReminded}) {
+		t.Fatalf("unexpected history %v", got)
+	}
+}
+
+func TestRunOnceSkipsWhenLocked(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	f.team(t, "backend", []string{"author", "r1"})
+	f.createPR(t, "pr-1", "author")
+	s := f.scheduler(Policy{RemindAfter: time.Hour}, nil)
+	f.prs.age = 2 * time.Hour
+
+	// проход уже выполняет другая реплика
+	unlock, ok, err := f.st.Locker.TryLock(ctx, lockKey)
+	if err != nil || !ok {
+		t.Fatalf("TryLock: ok=%v err=%v", ok, err)
+	}
+	if err := s.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.notifier.reminded(); len(got) != 0 {
+		t.Fatalf("expected no reminders while lock is held, got %v", got)
+	}
+
+	// после освобождения блокировки проход выполняется
+	unlock()
+	if err := s.RunOnce(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.notifier.reminded(); !slices.Equal(got, []string{"r1"}) {
+		t.Fatalf("expected reminder after unlock, got %v", got)
+	}
+	if _, ok, _ := f.st.Locker.TryLock(ctx, lockKey); !ok {
+		t.Fatal("RunOnce must release the lock")
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/auth"
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+)
+
+// Права проверяются в сервисах, а не в обработчиках, чтобы их нельзя было обойти
+// через другой вход. Вызов без личности в контексте запрещен: маршрут, забывший
+// аутентификацию, не получит полный доступ. Внутренние вызовы (планировщик,
+// prctl --offline) явно получают личность WithSystemIdentity.
+//
+//	admin      - все операции
+//	team_lead  - участники и PR своей команды
+//	member     - свои PR и ревью
+//	read_only  - только чтение
+
+var ErrForbidden = errors.New("forbidden")
+
+// SystemSubject субъект внутренних вызовов с правами администратора
+const SystemSubject = "system"
+
+// WithSystemIdentity помечает контекст как внутренний вызов с правами администратора
+func WithSystemIdentity(ctx context.Context) context.Context {
+	return auth.WithIdentity(ctx, auth.Identity{Subject: SystemSubject, Role: model.RoleAdmin})
+}
+
+// identity личность вызывающего, без нее доступ запрещен
+func identity(ctx context.Context) (auth.Identity, error) {
+	id, ok := auth.FromContext(ctx)
+	if !ok {
+		return auth.Identity{}, forbidden("caller identity is missing")
+	}
+	return id, nil
+}
+
+func forbidden(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrForbidden, fmt.Sprintf(format, args...))
+}
+
+// requireAdmin только администратор
+func requireAdmin(ctx context.Context) error {
+	id, err := identity(ctx)
+	if err != nil || id.IsAdmin() {
+		return err
+	}
+	return forbidden("admin role required")
+}
+
+// requireTeamLead администратор или лид команды team
+func requireTeamLead(ctx context.Context, team string) error {
+	id, err := identity(ctx)
+	if err != nil || id.IsAdmin() {
+		return err
+	}
+	if id.Role == model.RoleTeamLead && id.TeamName == team {
+		return nil
+	}
+	return forbidden("admin or team_lead of team %q required", team)
+}
+
+// requireSelfOrTeamLead изменение от имени пользователя: он сам, лид его команды или администратор
+func requireSelfOrTeamLead(ctx context.Context, user model.User) error {
+	id, err := identity(ctx)
+	if err != nil || id.IsAdmin() {
+		return err
+	}
+	if id.Role == model.RoleReadOnly {
+		return forbidden("read_only role cannot modify data")
+	}
+	if id.Subject == user.ID {
+		return nil
+	}
+	return requireTeamLead(ctx, user.TeamName)
+}
+
+// requireReader чтение данных пользователя: он сам, лид его команды, read_only или администратор
+func requireReader(ctx context.Context, user model.User) error {
+	id, err := identity(ctx)
+	if err != nil || id.IsAdmin() || id.Role == model.RoleReadOnly || id.Subject == user.ID {
+		return err
+	}
+	if id.Role == model.RoleTeamLead && id.TeamName == user.TeamName {
+		return nil
+	}
+	return forbidden("access to user %q is not allowed", user.ID)
+}
+
+// readableTeam ограничивает чтение командой: администратор и read_only видят все команды,
+// лид и участник - только свою. Пустой team у них заменяется на свою команду.
+func readableTeam(ctx context.Context, team string) (string, error) {
+	id, err := identity(ctx)
+	if err != nil {
+		return "", err
+	}
+	if id.IsAdmin() || id.Role == model.RoleReadOnly {
+		return team, nil
+	}
+	if team == "" || team == id.TeamName {
+		return id.TeamName, nil
+	}
+	return "", forbidden("access to team %q is not allowed", team)
+}
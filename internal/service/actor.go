@@ -0,0 +1,23 @@
+package service
+
+import "context"
+
+type actorKey struct{}
+
+const (
+	ActorAPI       = "api"
+	ActorScheduler = "scheduler"
+)
+
+// WithActor сохраняет в контексте, кто выполняет операцию (попадает в историю PR)
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext возвращает инициатора операции, по умолчанию - api
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return ActorAPI
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+)
+
+const (
+	DefaultListLimit = 20
+	MaxListLimit     = 100
+)
+
+// ListQuery параметры страницы в том виде, в каком их передает клиент
+type ListQuery struct {
+	Limit  int    // 0 - DefaultListLimit
+	Sort   string // поле сортировки, "-" в начале - по убыванию
+	Cursor string // next_cursor предыдущей страницы
+}
+
+// ListResult страница списка, NextCursor пустой на последней странице
+type ListResult[T any] struct {
+	Items      []T
+	NextCursor string
+}
+
+// cursorToken содержимое непрозрачного курсора. Сортировка сохраняется в курсоре,
+// чтобы курсор от одной сортировки нельзя было применить к другой.
+type cursorToken struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d,omitempty"`
+	Value string `json:"v"`
+	ID    string `json:"i"`
+}
+
+// page разбирает параметры клиента, allowed - допустимые поля сортировки, первое - по умолчанию.
+// Нарушения добавляются в v.
+func (q ListQuery) page(v *ValidationError, allowed ...string) repository.Page {
+	p := repository.Page{Limit: q.Limit, Sort: allowed[0]}
+	switch {
+	case p.Limit == 0:
+		p.Limit = DefaultListLimit
+	case p.Limit < 0 || p.Limit > MaxListLimit:
+		v.Add("limit", RuleRange, fmt.Sprintf("limit must be between 1 and %d", MaxListLimit))
+	}
+
+	if q.Sort != "" {
+		sort, desc := strings.CutPrefix(q.Sort, "-")
+		if !slices.Contains(allowed, sort) {
+			v.Add("sort", RuleOneOf, "sort must be one of "+strings.Join(allowed, ", "))
+			return p
+		}
+		p.Sort, p.Desc = sort, desc
+	}
+
+	if q.Cursor == "" {
+		return p
+	}
+	c, ok := decodeCursor(q.Cursor)
+	if !ok {
+		v.Add("cursor", RuleFormat, "malformed cursor")
+		return p
+	}
+	if c.Sort != p.Sort || c.Desc != p.Desc {
+		v.Add("cursor", RuleOneOf, "cursor was issued for a different sort")
+		return p
+	}
+	p.After = &repository.Cursor{Value: c.Value, ID: c.ID}
+	return p
+}
+
+func decodeCursor(s string) (cursorToken, bool) {
+	var c cursorToken
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, false
+	}
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" {
+		return c, false
+	}
+	if c.Sort == repository.SortByCreatedAt {
+		if _, err := time.Parse(time.RFC3339Nano, c.Value); err != nil {
+			return c, false
+		}
+	}
+	return c, true
+}
+
+// encodeCursor курсор следующей страницы для клиента
+func encodeCursor(c *repository.Cursor, p repository.Page) string {
+	if c == nil {
+		return ""
+	}
+	raw, _ := json.Marshal(cursorToken{Sort: p.Sort, Desc: p.Desc, Value: c.Value, ID: c.ID})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// validStatus пустой статус не фильтрует
+func validStatus(v *ValidationError, status string) {
+	switch status {
+	case "", "OPEN", "MERGED":
+		return
+	}
+	v.Add("status", RuleOneOf, "status must be OPEN or MERGED")
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"text/template"
+)
+
+type NotificationEvent string
+
+const (
+	// Ревьювер назначен при создании PR
+	EventReviewAssigned NotificationEvent = "ASSIGNED"
+	// Ревьювер назначен вместо другого
+	EventReviewReassigned NotificationEvent = "REASSIGNED"
+	// Напоминание о ревью, которое висит дольше SLA
+	EventReviewReminder NotificationEvent = "REMINDER"
+)
+
+// Notification уведомление ревьюверу
+type Notification struct {
+	Event           NotificationEvent
+	TeamName        string
+	PullRequestID   string
+	PullRequestName string
+	AuthorID        string
+	ReviewerID      string
+	ReviewerName    string
+	ReplacedUserID  string // заполняется только при переназначении
+}
+
+// Notifier доставляет уведомления о назначениях
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Шаблоны сообщений по умолчанию
+var defaultTemplates = map[NotificationEvent]string{
+	EventReviewAssigned: `{{.ReviewerName}}, you were assigned to review "{{.PullRequestName}}" ({{.PullRequestID}}) by {{.AuthorID}}`,
+	EventReviewReassigned: `{{.ReviewerName}}, you were assigned to review "{{.PullRequestName}}" ({{.PullRequestID}}) ` +
+		`by {{.AuthorID}} instead of {{.ReplacedUserID}}`,
+	EventReviewReminder: `{{.ReviewerName}}, "{{.PullRequestName}}" ({{.PullRequestID}}) by {{.AuthorID}} ` +
+		`is still waiting for your review`,
+}
+
+// renderMessage подставляет уведомление в шаблон, пустой шаблон заменяется стандартным
+func renderMessage(tmpl string, n Notification) (string, error) {
+	if tmpl == "" {
+		tmpl = defaultTemplates[n.Event]
+	}
+
+	t, err := template.New(string(n.Event)).Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, n); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// LogNotifier только пишет уведомления в лог
+type LogNotifier struct{}
+
+func NewLogNotifier() *LogNotifier {
+	return &LogNotifier{}
+}
+
+func (n *LogNotifier) Notify(_ context.Context, notification Notification) error {
+	msg, err := renderMessage("", notification)
+	if err != nil {
+		return err
+	}
+	slog.Info("notification",
+		slog.String("event", string(notification.Event)),
+		slog.String("team", notification.TeamName),
+		slog.String("reviewer_id", notification.ReviewerID),
+		slog.String("pull_request_id", notification.PullRequestID),
+		slog.String("message", msg))
+	return nil
+}
+
+// NopNotifier ничего не отправляет
+type NopNotifier struct{}
+
+func (NopNotifier) Notify(context.Context, Notification) error {
+	return nil
+}
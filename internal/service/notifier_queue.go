@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+var ErrNotifyQueueFull = errors.New("notification queue is full")
+
+type notifyJob struct {
+	notification Notification
+	attempt      int
+}
+
+// QueueNotifier складывает уведомления в очередь и отправляет их в фоне с повторами
+type QueueNotifier struct {
+	next        Notifier
+	jobs        chan notifyJob
+	maxAttempts int
+	backoff     time.Duration
+}
+
+func NewQueueNotifier(next Notifier, size, maxAttempts int, backoff time.Duration) *QueueNotifier {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	return &QueueNotifier{
+		next:        next,
+		jobs:        make(chan notifyJob, size),
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+	}
+}
+
+// Notify не блокирует вызывающего, отправка произойдет в Run
+func (q *QueueNotifier) Notify(_ context.Context, n Notification) error {
+	return q.enqueue(notifyJob{notification: n})
+}
+
+func (q *QueueNotifier) enqueue(job notifyJob) error {
+	select {
+	case q.jobs <- job:
+		return nil
+	default:
+		return ErrNotifyQueueFull
+	}
+}
+
+// Run обрабатывает очередь до отмены контекста, после отмены отправляет то, что осталось в очереди
+func (q *QueueNotifier) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			q.drain()
+			return
+		case job := <-q.jobs:
+			q.process(ctx, job)
+		}
+	}
+}
+
+// drain делает одну попытку отправки для оставшихся уведомлений, без повторов
+func (q *QueueNotifier) drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for {
+		select {
+		case job := <-q.jobs:
+			if err := q.next.Notify(ctx, job.notification); err != nil {
+				slog.Warn("notification dropped on shutdown",
+					slog.String("pull_request_id", job.notification.PullRequestID),
+					slog.String("reviewer_id", job.notification.ReviewerID),
+					slog.Any("error", err))
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (q *QueueNotifier) process(ctx context.Context, job notifyJob) {
+	sendCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	err := q.next.Notify(sendCtx, job.notification)
+	cancel()
+	if err == nil {
+		return
+	}
+
+	job.attempt++
+	if job.attempt >= q.maxAttempts {
+		slog.Error("notification dropped after retries",
+			slog.String("pull_request_id", job.notification.PullRequestID),
+			slog.String("reviewer_id", job.notification.ReviewerID),
+			slog.Int("attempts", job.attempt),
+			slog.Any("error", err))
+		return
+	}
+
+	// экспоненциальная задержка перед следующей попыткой
+	delay := q.backoff << (job.attempt - 1)
+	slog.Warn("notification failed, will retry",
+		slog.String("pull_request_id", job.notification.PullRequestID),
+		slog.String("reviewer_id", job.notification.ReviewerID),
+		slog.Int("attempt", job.attempt),
+		slog.Duration("retry_in", delay),
+		slog.Any("error", err))
+
+	time.AfterFunc(delay, func() {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := q.enqueue(job); err != nil {
+			slog.Error("failed to requeue notification",
+				slog.String("pull_request_id", job.notification.PullRequestID),
+				slog.Any("error", err))
+		}
+	})
+}
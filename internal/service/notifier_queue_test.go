@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakySink падает первые failures раз и запоминает время каждой попытки
+type flakySink struct {
+	mu       sync.Mutex
+	failures int
+	attempts []time.Time
+	calls    chan struct{}
+}
+
+func newFlakySink(failures int) *flakySink {
+	return &flakySink{failures: failures, calls: make(chan struct{}, 100)}
+}
+
+func (s *flakySink) Notify(context.Context, Notification) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts = append(s.attempts, time.Now())
+	s.calls <- struct{}{}
+	if len(s.attempts) <= s.failures {
+		return errors.New("sink is down")
+	}
+	return nil
+}
+
+func (s *flakySink) times() []time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]time.Time(nil), s.attempts...)
+}
+
+// waitCalls ждет n попыток отправки
+func (s *flakySink) waitCalls(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-s.calls:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("expected %d attempts, got %d", n, i)
+		}
+	}
+}
+
+func runQueue(t *testing.T, q *QueueNotifier) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		q.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+}
+
+func TestQueueNotifierRetriesWithBackoff(t *testing.T) {
+	const backoff = 20 * time.Millisecond
+	sink := newFlakySink(2)
+	q := NewQueueNotifier(sink, 10, 5, backoff)
+	runQueue(t, q)
+
+	if err := q.Notify(context.Background(), Notification{Event: EventReviewAssigned, PullRequestID: "pr-1"}); err != nil {
+		t.Fatal(err)
+	}
+	sink.waitCalls(t, 3)
+
+	// задержка удваивается после каждой неудачной попытки
+	at := sink.times()
+	for i, want := range []time.Duration{backoff, 2 * backoff} {
+		if gap := at[i+1].Sub(at[i]); gap < want {
+			t.Fatalf("retry %d after %v, expected at least %v", i+1, gap, want)
+		}
+	}
+
+	// третья попытка успешна, повторов больше нет
+	time.Sleep(8 * backoff)
+	if n := len(sink.times()); n != 3 {
+		t.Fatalf("expected 3 attempts, got %d", n)
+	}
+}
+
+func TestQueueNotifierGivesUpAfterMaxAttempts(t *testing.T) {
+	const backoff = 5 * time.Millisecond
+	sink := newFlakySink(100)
+	q := NewQueueNotifier(sink, 10, 3, backoff)
+	runQueue(t, q)
+
+	if err := q.Notify(context.Background(), Notification{Event: EventReviewAssigned, PullRequestID: "pr-1"}); err != nil {
+		t.Fatal(err)
+	}
+	sink.waitCalls(t, 3)
+
+	time.Sleep(20 * backoff)
+	if n := len(sink.times()); n != 3 {
+		t.Fatalf("expected notification to be dropped after 3 attempts, got %d", n)
+	}
+}
+
+func TestQueueNotifierFullQueue(t *testing.T) {
+	// без Run очередь не разбирается
+	q := NewQueueNotifier(NopNotifier{}, 1, 1, time.Millisecond)
+	if err := q.Notify(context.Background(), Notification{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Notify(context.Background(), Notification{}); !errors.Is(err, ErrNotifyQueueFull) {
+		t.Fatalf("expected ErrNotifyQueueFull, got %v", err)
+	}
+}
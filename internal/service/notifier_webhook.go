@@ -0,0 +1,87 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// TeamChannel настройки уведомлений для команды
+type TeamChannel struct {
+	WebhookURL string                       // если пусто - берется общий
+	Channel    string                       // канал, пусто - канал по умолчанию у вебхука
+	Templates  map[NotificationEvent]string // переопределение шаблонов сообщений
+}
+
+// WebhookNotifier отправляет уведомления во входящий вебхук (формат Slack/Mattermost)
+type WebhookNotifier struct {
+	defaultURL string
+	teams      map[string]TeamChannel
+	templates  map[NotificationEvent]string
+	client     *http.Client
+}
+
+func NewWebhookNotifier(
+	defaultURL string,
+	teams map[string]TeamChannel,
+	templates map[NotificationEvent]string,
+) *WebhookNotifier {
+	return &WebhookNotifier{
+		defaultURL: defaultURL,
+		teams:      teams,
+		templates:  templates,
+		client:     &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+type webhookPayload struct {
+	Text    string `json:"text"`
+	Channel string `json:"channel,omitempty"`
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	team := n.teams[notification.TeamName]
+
+	url := team.WebhookURL
+	if url == "" {
+		url = n.defaultURL
+	}
+	// некуда отправлять - команда без канала
+	if url == "" {
+		return nil
+	}
+
+	tmpl := team.Templates[notification.Event]
+	if tmpl == "" {
+		tmpl = n.templates[notification.Event]
+	}
+	msg, err := renderMessage(tmpl, notification)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(webhookPayload{Text: msg, Channel: team.Channel})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package service
+
+import "context"
+
+type expectedVersionKey struct{}
+
+// WithExpectedVersion сохраняет в контексте версию PR, которую видел клиент (If-Match).
+// Если версия в БД другая, изменение PR вернет ErrPreconditionFailed.
+func WithExpectedVersion(ctx context.Context, version int64) context.Context {
+	return context.WithValue(ctx, expectedVersionKey{}, version)
+}
+
+// ExpectedVersionFromContext возвращает ожидаемую версию PR, если она задана
+func ExpectedVersionFromContext(ctx context.Context) (int64, bool) {
+	v, ok := ctx.Value(expectedVersionKey{}).(int64)
+	return v, ok
+}
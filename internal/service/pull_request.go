@@ -3,8 +3,11 @@ package service
 import (
 	"context"
 	"errors"
+	"github.com/Olzerq/avito-pr-reviewer/internal/metrics"
 	"github.com/Olzerq/avito-pr-reviewer/internal/model"
 	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/tracing"
+	"log/slog"
 	"math/rand"
 	"time"
 )
@@ -12,73 +15,193 @@ import (
 var (
 	ErrPRMerged    = errors.New("pull request already merged")
 	ErrNoCandidate = errors.New("no candidate reviewer found")
+	// ErrPRConflict PR изменялся параллельно, и повторы не помогли
+	ErrPRConflict = errors.New("pull request was modified concurrently, retry the request")
+	// ErrPreconditionFailed версия PR не совпала с ожидаемой клиентом (If-Match)
+	ErrPreconditionFailed = errors.New("pull request version does not match")
 )
 
+// maxConflictRetries сколько раз перечитываем PR, если его изменили между чтением и записью
+const maxConflictRetries = 3
+
 type PullRequestService struct {
-	prRepo   *repository.PullRequestRepository
-	userRepo *repository.UserRepository
+	tx       repository.TxManager
+	prRepo   repository.PullRequests
+	userRepo repository.Users
+	notifier Notifier
+	feed     *ReviewFeed
 }
 
-func NewPullRequestService(prRepo *repository.PullRequestRepository, userRepo *repository.UserRepository) *PullRequestService {
+func NewPullRequestService(
+	tx repository.TxManager,
+	prRepo repository.PullRequests,
+	userRepo repository.Users,
+	notifier Notifier,
+	feed *ReviewFeed,
+) *PullRequestService {
+	if notifier == nil {
+		notifier = NopNotifier{}
+	}
+	if feed == nil {
+		feed = NewReviewFeed(nil)
+	}
 	return &PullRequestService{
+		tx:       tx,
 		prRepo:   prRepo,
 		userRepo: userRepo,
+		notifier: notifier,
+		feed:     feed,
 	}
 }
 
 // Create создаёт PR и выбирает случайных ревьюеров
 func (s *PullRequestService) Create(ctx context.Context, id, name, authorID string) (model.PullRequest, error) {
-	author, err := s.userRepo.GetByID(ctx, authorID)
-	if err != nil {
-		if err == repository.ErrUserNotFound {
-			return model.PullRequest{}, err
-		}
-		return model.PullRequest{}, err
-	}
+	ctx, span := tracing.Start(ctx, "PullRequestService.Create")
+	defer span.End()
 
-	candidates, err := s.userRepo.GetActiveByTeamExcept(ctx, author.TeamName, []string{author.ID})
-	if err != nil {
+	var v ValidationError
+	v.Required("pull_request_id", id)
+	v.Required("pull_request_name", name)
+	v.Required("author_id", authorID)
+	if err := v.Err(); err != nil {
 		return model.PullRequest{}, err
 	}
 
-	rand.Seed(time.Now().UnixNano())
-	rand.Shuffle(len(candidates), func(i, j int) {
-		candidates[i], candidates[j] = candidates[j], candidates[i]
-	})
+	// автор, кандидаты и сам PR в одной транзакции: ревьюверы выбираются
+	// из состава команды на момент записи, а не на момент чтения
+	var (
+		pr     model.PullRequest
+		author model.User
+	)
+	err := s.tx.InTx(ctx, func(tx repository.Repos) error {
+		var err error
+		author, err = tx.Users.GetByID(ctx, authorID)
+		if err != nil {
+			return err
+		}
+
+		// участник создает PR только от своего имени
+		if err := requireSelfOrTeamLead(ctx, author); err != nil {
+			return err
+		}
+
+		candidates, err := tx.Users.GetActiveByTeamExcept(ctx, author.TeamName, []string{author.ID})
+		if err != nil {
+			return err
+		}
+
+		rand.Seed(time.Now().UnixNano())
+		rand.Shuffle(len(candidates), func(i, j int) {
+			candidates[i], candidates[j] = candidates[j], candidates[i]
+		})
+
+		n := len(candidates)
+		if n > 2 {
+			n = 2
+		}
+		reviewers := candidates[:n]
+
+		createdAt := time.Now().UTC()
+		pr = model.PullRequest{
+			ID:        id,
+			Name:      name,
+			AuthorID:  author.ID,
+			Status:    "OPEN",
+			CreatedAt: &createdAt,
+			MergedAt:  nil,
+			Version:   1,
+			Reviewers: reviewers,
+		}
 
-	n := len(candidates)
-	if n > 2 {
-		n = 2
+		return tx.PullRequests.Create(ctx, pr, ActorFromContext(ctx))
+	})
+	if err != nil {
+		return model.PullRequest{}, err
 	}
-	reviewers := candidates[:n]
+	metrics.PRsCreated.WithLabelValues(author.TeamName).Inc()
 
-	pr := model.PullRequest{
-		ID:        id,
-		Name:      name,
-		AuthorID:  author.ID,
-		Status:    "OPEN",
-		MergedAt:  nil,
-		Reviewers: reviewers,
+	reviewerIDs := make([]string, 0, len(pr.Reviewers))
+	for _, r := range pr.Reviewers {
+		reviewerIDs = append(reviewerIDs, r.ID)
 	}
+	s.publish(ctx, reviewerIDs...)
 
-	if err := s.prRepo.Create(ctx, pr); err != nil {
-		return model.PullRequest{}, err
+	for _, r := range pr.Reviewers {
+		s.notify(ctx, Notification{
+			Event:           EventReviewAssigned,
+			TeamName:        author.TeamName,
+			PullRequestID:   pr.ID,
+			PullRequestName: pr.Name,
+			AuthorID:        pr.AuthorID,
+			ReviewerID:      r.ID,
+			ReviewerName:    r.Username,
+		})
 	}
 
 	return pr, nil
 }
 
-// Reassign переназначает ревьюера
+// Reassign переназначает ревьюера. Состояние PR проверяется заново, если его изменили
+// параллельно (другое переназначение или merge), поэтому смерженный PR не получит нового ревьюера.
 func (s *PullRequestService) Reassign(
 	ctx context.Context,
 	prID string,
 	oldReviewerID string,
 ) (model.PullRequest, string, error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.Reassign")
+	defer span.End()
+
+	return s.reassignWithRetries(ctx, prID, oldReviewerID, model.EventReviewerReplaced)
+}
+
+// Escalate переназначает ревьювера, не ответившего за SLA, той же логикой, что и Reassign.
+// В истории PR замена записывается одним событием ESCALATED.
+func (s *PullRequestService) Escalate(ctx context.Context, prID, oldReviewerID string) (model.PullRequest, string, error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.Escalate")
+	defer span.End()
+
+	return s.reassignWithRetries(ctx, prID, oldReviewerID, model.EventReviewerEscalated)
+}
+
+func (s *PullRequestService) reassignWithRetries(ctx context.Context, prID, oldReviewerID, eventType string) (model.PullRequest, string, error) {
+	var v ValidationError
+	v.Required("pull_request_id", prID)
+	v.Required("old_user_id", oldReviewerID)
+	if err := v.Err(); err != nil {
+		return model.PullRequest{}, "", err
+	}
+
+	for attempt := 0; attempt < maxConflictRetries; attempt++ {
+		pr, newReviewerID, err := s.reassign(ctx, prID, oldReviewerID, eventType)
+		if !errors.Is(err, repository.ErrVersionConflict) {
+			return pr, newReviewerID, err
+		}
+		if err := conflictError(ctx); err != nil {
+			return model.PullRequest{}, "", err
+		}
+	}
+	return model.PullRequest{}, "", ErrPRConflict
+}
+
+func (s *PullRequestService) reassign(ctx context.Context, prID, oldReviewerID, eventType string) (model.PullRequest, string, error) {
 	pr, err := s.prRepo.GetByID(ctx, prID)
 	if err != nil {
 		return model.PullRequest{}, "", err // ErrPRNotFound пойдёт наверх
 	}
 
+	author, err := s.userRepo.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return model.PullRequest{}, "", err // ErrUserNotFound → 404
+	}
+
+	// ревьювер может отказаться от своего ревью, чужие переназначает лид команды
+	if err := requireSelfOrTeamLead(ctx, model.User{ID: oldReviewerID, TeamName: author.TeamName}); err != nil {
+		return model.PullRequest{}, "", err
+	}
+	if err := checkExpectedVersion(ctx, pr); err != nil {
+		return model.PullRequest{}, "", err
+	}
+
 	if pr.Status == "MERGED" {
 		return model.PullRequest{}, "", ErrPRMerged
 	}
@@ -94,11 +217,6 @@ func (s *PullRequestService) Reassign(
 		return model.PullRequest{}, "", repository.ErrReviewerNotAssigned
 	}
 
-	author, err := s.userRepo.GetByID(ctx, pr.AuthorID)
-	if err != nil {
-		return model.PullRequest{}, "", err // ErrUserNotFound → 404
-	}
-
 	exclude := make([]string, 0, len(pr.Reviewers)+1)
 	exclude = append(exclude, pr.AuthorID)
 	for _, r := range pr.Reviewers {
@@ -110,52 +228,245 @@ func (s *PullRequestService) Reassign(
 		return model.PullRequest{}, "", err
 	}
 	if len(candidates) == 0 {
+		metrics.PRNoCandidate.WithLabelValues(author.TeamName).Inc()
 		return model.PullRequest{}, "", ErrNoCandidate
 	}
 
 	rand.Seed(time.Now().UnixNano())
 	newReviewer := candidates[rand.Intn(len(candidates))]
 
-	if err := s.prRepo.ReassignReviewer(ctx, pr.ID, oldReviewerID, newReviewer.ID); err != nil {
+	// запись пройдет, только если PR не изменился с момента чтения
+	if err := s.prRepo.ReassignReviewer(ctx, pr.ID, pr.Version, oldReviewerID, newReviewer.ID, eventType, ActorFromContext(ctx)); err != nil {
 		return model.PullRequest{}, "", err
 	}
+	metrics.PRReassignments.WithLabelValues(author.TeamName).Inc()
 
 	updatedPR, err := s.prRepo.GetByID(ctx, pr.ID)
 	if err != nil {
 		return model.PullRequest{}, "", err
 	}
 
+	s.publish(ctx, oldReviewerID, newReviewer.ID)
+
+	s.notify(ctx, Notification{
+		Event:           EventReviewReassigned,
+		TeamName:        author.TeamName,
+		PullRequestID:   pr.ID,
+		PullRequestName: pr.Name,
+		AuthorID:        pr.AuthorID,
+		ReviewerID:      newReviewer.ID,
+		ReviewerName:    newReviewer.Username,
+		ReplacedUserID:  oldReviewerID,
+	})
+
 	return updatedPR, newReviewer.ID, nil
 }
 
+// Get возвращает PR с ревьюверами
+func (s *PullRequestService) Get(ctx context.Context, prID string) (model.PullRequest, error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.Get")
+	defer span.End()
+
+	if prID == "" {
+		return model.PullRequest{}, invalidField("pull_request_id", RuleRequired, "pull_request_id is required")
+	}
+
+	pr, err := s.prRepo.GetByID(ctx, prID)
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+	if err := s.requireReadablePR(ctx, pr); err != nil {
+		return model.PullRequest{}, err
+	}
+	return pr, nil
+}
+
+// GetHistory возвращает историю событий PR
+func (s *PullRequestService) GetHistory(ctx context.Context, prID string) ([]model.PullRequestEvent, error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.GetHistory")
+	defer span.End()
+
+	if prID == "" {
+		return nil, invalidField("pull_request_id", RuleRequired, "pull_request_id is required")
+	}
+
+	// проверяем, что PR существует и доступен
+	pr, err := s.prRepo.GetByID(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireReadablePR(ctx, pr); err != nil {
+		return nil, err
+	}
+
+	return s.prRepo.GetEvents(ctx, prID)
+}
+
+// requireReadablePR чтение PR по тем же правилам, что и List: по команде автора
+func (s *PullRequestService) requireReadablePR(ctx context.Context, pr model.PullRequest) error {
+	author, err := s.userRepo.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return err
+	}
+	_, err = readableTeam(ctx, author.TeamName)
+	return err
+}
+
+// notify отправляет уведомление, ошибка доставки не должна ломать операцию с PR
+func (s *PullRequestService) notify(ctx context.Context, n Notification) {
+	if err := s.notifier.Notify(ctx, n); err != nil {
+		slog.Warn("failed to notify reviewer",
+			slog.String("pull_request_id", n.PullRequestID),
+			slog.String("reviewer_id", n.ReviewerID),
+			slog.Any("error", err))
+	}
+}
+
 // Merge обновляет флаг Merged
 func (s *PullRequestService) Merge(ctx context.Context, prID string) (model.PullRequest, error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.Merge")
+	defer span.End()
+
+	if prID == "" {
+		return model.PullRequest{}, invalidField("pull_request_id", RuleRequired, "pull_request_id is required")
+	}
+
+	for attempt := 0; attempt < maxConflictRetries; attempt++ {
+		pr, err := s.merge(ctx, prID)
+		if !errors.Is(err, repository.ErrVersionConflict) {
+			return pr, err
+		}
+		if err := conflictError(ctx); err != nil {
+			return model.PullRequest{}, err
+		}
+	}
+	return model.PullRequest{}, ErrPRConflict
+}
+
+func (s *PullRequestService) merge(ctx context.Context, prID string) (model.PullRequest, error) {
 	// проверяем, что PR существует
 	pr, err := s.prRepo.GetByID(ctx, prID)
 	if err != nil {
 		return model.PullRequest{}, err
 	}
+
+	author, err := s.userRepo.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return model.PullRequest{}, err
+	}
+	if err := requireTeamLead(ctx, author.TeamName); err != nil {
+		return model.PullRequest{}, err
+	}
+	if err := checkExpectedVersion(ctx, pr); err != nil {
+		return model.PullRequest{}, err
+	}
+
 	//если уже merged то возвращаем как есть - идемпотентность
-	if pr.Status != "MERGED" {
-		if err := s.prRepo.MarkMerged(ctx, prID, time.Now().UTC()); err != nil {
-			return model.PullRequest{}, err
-		}
+	if pr.Status == "MERGED" {
+		return pr, nil
+	}
+
+	// от состава ревьюверов merge не зависит, версию сверяем только по If-Match,
+	// а повторный merge отсекает условие на статус
+	var version int64
+	if _, ok := ExpectedVersionFromContext(ctx); ok {
+		version = pr.Version
+	}
+	if err := s.prRepo.MarkMerged(ctx, prID, version, time.Now().UTC(), ActorFromContext(ctx)); err != nil {
+		return model.PullRequest{}, err
 	}
+	metrics.PRsMerged.WithLabelValues(author.TeamName).Inc()
 
 	updated, err := s.prRepo.GetByID(ctx, prID)
 	if err != nil {
 		return model.PullRequest{}, err
 	}
+
+	reviewerIDs := make([]string, 0, len(updated.Reviewers))
+	for _, r := range updated.Reviewers {
+		reviewerIDs = append(reviewerIDs, r.ID)
+	}
+	s.publish(ctx, reviewerIDs...)
+
 	return updated, nil
 }
 
-// GetUserReviews получает все пры где юзер ревьювер
-func (s *PullRequestService) GetUserReviews(ctx context.Context, userID string) ([]model.PullRequestShort, error) {
+// checkExpectedVersion сверяет версию PR с If-Match клиента
+func checkExpectedVersion(ctx context.Context, pr model.PullRequest) error {
+	if v, ok := ExpectedVersionFromContext(ctx); ok && v != pr.Version {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+// conflictError решает, что делать после конфликта версий: клиент с If-Match
+// опирался на старое состояние и получает ErrPreconditionFailed, остальные запросы повторяются
+func conflictError(ctx context.Context) error {
+	if _, ok := ExpectedVersionFromContext(ctx); ok {
+		return ErrPreconditionFailed
+	}
+	metrics.PRVersionConflicts.Inc()
+	return nil
+}
+
+// GetUserReviews получает страницу PR, где юзер ревьювер, status фильтрует по статусу PR
+func (s *PullRequestService) GetUserReviews(ctx context.Context, userID, status string, q ListQuery) (ListResult[model.PullRequestShort], error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.GetUserReviews")
+	defer span.End()
+
+	var v ValidationError
+	v.Required("user_id", userID)
+	validStatus(&v, status)
+	page := q.page(&v, repository.SortByID, repository.SortByCreatedAt)
+	if err := v.Err(); err != nil {
+		return ListResult[model.PullRequestShort]{}, err
+	}
+
 	// проверяем что юзер существует
-	_, err := s.userRepo.GetByID(ctx, userID)
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return nil, repository.ErrUserNotFound
+		return ListResult[model.PullRequestShort]{}, repository.ErrUserNotFound
+	}
+	if err := requireReader(ctx, user); err != nil {
+		return ListResult[model.PullRequestShort]{}, err
 	}
 
-	return s.prRepo.GetByReviewer(ctx, userID)
+	prs, next, err := s.prRepo.List(ctx, repository.PullRequestFilter{ReviewerID: userID, Status: status}, page)
+	if err != nil {
+		return ListResult[model.PullRequestShort]{}, err
+	}
+	res := ListResult[model.PullRequestShort]{
+		Items:      make([]model.PullRequestShort, 0, len(prs)),
+		NextCursor: encodeCursor(next, page),
+	}
+	for _, pr := range prs {
+		res.Items = append(res.Items, shortPR(pr))
+	}
+	return res, nil
+}
+
+// List страница PR по фильтрам. Лид и участник видят только PR своей команды.
+func (s *PullRequestService) List(ctx context.Context, f repository.PullRequestFilter, q ListQuery) (ListResult[model.PullRequest], error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.List")
+	defer span.End()
+
+	var v ValidationError
+	validStatus(&v, f.Status)
+	if f.CreatedFrom != nil && f.CreatedTo != nil && !f.CreatedFrom.Before(*f.CreatedTo) {
+		v.Add("created_to", RuleRange, "created_to must be after created_from")
+	}
+	page := q.page(&v, repository.SortByCreatedAt, repository.SortByID, repository.SortByName)
+	if err := v.Err(); err != nil {
+		return ListResult[model.PullRequest]{}, err
+	}
+	var err error
+	if f.TeamName, err = readableTeam(ctx, f.TeamName); err != nil {
+		return ListResult[model.PullRequest]{}, err
+	}
+
+	prs, next, err := s.prRepo.List(ctx, f, page)
+	if err != nil {
+		return ListResult[model.PullRequest]{}, err
+	}
+	return ListResult[model.PullRequest]{Items: prs, NextCursor: encodeCursor(next, page)}, nil
 }
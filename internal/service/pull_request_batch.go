@@ -0,0 +1,424 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/metrics"
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/tracing"
+)
+
+// MaxBatchSize сколько элементов принимает одна пакетная операция
+const MaxBatchSize = 500
+
+// BatchMode поведение пакета, если часть элементов не выполнилась
+type BatchMode string
+
+const (
+	BatchAtomic     BatchMode = "atomic"      // все или ничего, режим по умолчанию
+	BatchBestEffort BatchMode = "best_effort" // выполняется все, что можно
+)
+
+// Статусы элементов пакета
+const (
+	BatchCreated        = "CREATED"
+	BatchExists         = "EXISTS"
+	BatchAuthorNotFound = "AUTHOR_NOT_FOUND"
+	BatchMerged         = "MERGED"
+	BatchAlreadyMerged  = "ALREADY_MERGED"
+	BatchNotFound       = "NOT_FOUND"
+	// BatchForbidden вызывающий не может действовать от имени автора (создание)
+	// или мержить PR его команды, только в режиме best_effort
+	BatchForbidden = "FORBIDDEN"
+	// BatchConflict PR изменил параллельный запрос во время merge, только в режиме best_effort
+	BatchConflict = "CONFLICT"
+	// BatchRolledBack элемент выполнился бы, но атомарный пакет откатан из-за других элементов
+	BatchRolledBack = "ROLLED_BACK"
+)
+
+// errBatchFailed откатывает транзакцию атомарного пакета, в котором не выполнился элемент
+var errBatchFailed = errors.New("batch failed")
+
+// BatchCreateItem PR в пакетном создании
+type BatchCreateItem struct {
+	ID       string
+	Name     string
+	AuthorID string
+}
+
+// BatchItemResult результат одного элемента пакета, PR заполнен у выполненных и уже смерженных
+type BatchItemResult struct {
+	PullRequestID string
+	Status        string
+	PR            *model.PullRequest
+}
+
+// BatchResult результаты пакета в порядке элементов запроса
+type BatchResult struct {
+	Mode BatchMode
+	// Applied false, если атомарный пакет откатан
+	Applied bool
+	Items   []BatchItemResult
+}
+
+// failed элементы, из-за которых атомарный пакет откатывается
+func (r BatchResult) failed() bool {
+	for _, it := range r.Items {
+		switch it.Status {
+		case BatchExists, BatchAuthorNotFound, BatchNotFound, BatchForbidden, BatchConflict:
+			return true
+		}
+	}
+	return false
+}
+
+// rollBack помечает выполненные элементы откатанными
+func (r *BatchResult) rollBack() {
+	r.Applied = false
+	for i := range r.Items {
+		if r.Items[i].Status == BatchCreated || r.Items[i].Status == BatchMerged {
+			r.Items[i].Status = BatchRolledBack
+			r.Items[i].PR = nil
+		}
+	}
+}
+
+// BatchCreate создает пакет PR в одной транзакции. Авторы и кандидаты команд читаются
+// по одному разу на пакет, ревьюверы распределяются по наименее загруженным в этом пакете.
+// Уже существующие PR и неизвестные авторы отражаются в результатах элементов, а не ошибкой.
+// Автор, от имени которого вызывающий действовать не может, в режиме best_effort дает
+// элементу статус FORBIDDEN, а атомарный пакет отклоняет целиком с ErrForbidden.
+func (s *PullRequestService) BatchCreate(ctx context.Context, items []BatchCreateItem, mode BatchMode) (BatchResult, error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.BatchCreate")
+	defer span.End()
+
+	var v ValidationError
+	mode = validBatchMode(&v, mode)
+	validBatchSize(&v, "items", len(items))
+	seen := make(map[string]int, len(items))
+	for i, it := range items {
+		field := fmt.Sprintf("items[%d]", i)
+		v.Required(field+".pull_request_id", it.ID)
+		v.Required(field+".pull_request_name", it.Name)
+		v.Required(field+".author_id", it.AuthorID)
+		if it.ID == "" {
+			continue
+		}
+		if first, ok := seen[it.ID]; ok {
+			v.Add(field+".pull_request_id", RuleUnique, fmt.Sprintf("pull_request_id %q is already used by items[%d]", it.ID, first))
+			continue
+		}
+		seen[it.ID] = i
+	}
+	if err := v.Err(); err != nil {
+		return BatchResult{}, err
+	}
+
+	var (
+		res     BatchResult
+		authors map[string]model.User
+	)
+	err := s.tx.InTx(ctx, func(tx repository.Repos) error {
+		// InTx может повторить fn, поэтому состояние пакета собирается заново
+		res = BatchResult{Mode: mode, Applied: true, Items: make([]BatchItemResult, len(items))}
+		authors = make(map[string]model.User)
+		forbiddenAuthors := make(map[string]bool)
+		checked := make(map[string]bool, len(items))
+		for _, it := range items {
+			if checked[it.AuthorID] {
+				continue
+			}
+			checked[it.AuthorID] = true
+			author, err := tx.Users.GetByID(ctx, it.AuthorID)
+			if errors.Is(err, repository.ErrUserNotFound) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			// права проверяются до записи, в атомарном пакете запрещенный автор отклоняет весь пакет
+			if err := requireSelfOrTeamLead(ctx, author); err != nil {
+				if mode == BatchAtomic || !errors.Is(err, ErrForbidden) {
+					return err
+				}
+				forbiddenAuthors[author.ID] = true
+				continue
+			}
+			authors[author.ID] = author
+		}
+
+		assigner := newBatchAssigner(tx.Users)
+		createdAt := time.Now().UTC()
+		for i, it := range items {
+			res.Items[i] = BatchItemResult{PullRequestID: it.ID}
+			if forbiddenAuthors[it.AuthorID] {
+				res.Items[i].Status = BatchForbidden
+				continue
+			}
+			author, ok := authors[it.AuthorID]
+			if !ok {
+				res.Items[i].Status = BatchAuthorNotFound
+				continue
+			}
+
+			reviewers, err := assigner.pick(ctx, author)
+			if err != nil {
+				return err
+			}
+			pr := model.PullRequest{
+				ID:        it.ID,
+				Name:      it.Name,
+				AuthorID:  author.ID,
+				Status:    "OPEN",
+				CreatedAt: &createdAt,
+				Version:   1,
+				Reviewers: reviewers,
+			}
+			err = tx.PullRequests.Create(ctx, pr, ActorFromContext(ctx))
+			switch {
+			case errors.Is(err, repository.ErrPRExists):
+				assigner.release(reviewers)
+				res.Items[i].Status = BatchExists
+			case err != nil:
+				return err
+			default:
+				res.Items[i].Status = BatchCreated
+				res.Items[i].PR = &pr
+			}
+		}
+
+		if mode == BatchAtomic && res.failed() {
+			return errBatchFailed
+		}
+		return nil
+	})
+	if errors.Is(err, errBatchFailed) {
+		res.rollBack()
+		return res, nil
+	}
+	if err != nil {
+		return BatchResult{}, err
+	}
+
+	for _, it := range res.Items {
+		if it.Status != BatchCreated {
+			continue
+		}
+		pr := it.PR
+		team := authors[pr.AuthorID].TeamName
+		metrics.PRsCreated.WithLabelValues(team).Inc()
+
+		reviewerIDs := make([]string, 0, len(pr.Reviewers))
+		for _, r := range pr.Reviewers {
+			reviewerIDs = append(reviewerIDs, r.ID)
+		}
+		s.publish(ctx, reviewerIDs...)
+
+		for _, r := range pr.Reviewers {
+			s.notify(ctx, Notification{
+				Event:           EventReviewAssigned,
+				TeamName:        team,
+				PullRequestID:   pr.ID,
+				PullRequestName: pr.Name,
+				AuthorID:        pr.AuthorID,
+				ReviewerID:      r.ID,
+				ReviewerName:    r.Username,
+			})
+		}
+	}
+	return res, nil
+}
+
+// BatchMerge мержит пакет PR в одной транзакции. Уже смерженные PR не считаются ошибкой,
+// неизвестные отражаются в результатах элементов. PR чужой команды в режиме best_effort
+// получает статус FORBIDDEN, а атомарный пакет отклоняется целиком с ErrForbidden.
+// Так же PR, измененный параллельным запросом, получает CONFLICT или отклоняет пакет с ErrPRConflict.
+func (s *PullRequestService) BatchMerge(ctx context.Context, prIDs []string, mode BatchMode) (BatchResult, error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.BatchMerge")
+	defer span.End()
+
+	var v ValidationError
+	mode = validBatchMode(&v, mode)
+	validBatchSize(&v, "pull_request_ids", len(prIDs))
+	seen := make(map[string]int, len(prIDs))
+	for i, id := range prIDs {
+		field := fmt.Sprintf("pull_request_ids[%d]", i)
+		v.Required(field, id)
+		if id == "" {
+			continue
+		}
+		if first, ok := seen[id]; ok {
+			v.Add(field, RuleUnique, fmt.Sprintf("pull_request_id %q is already used by pull_request_ids[%d]", id, first))
+			continue
+		}
+		seen[id] = i
+	}
+	if err := v.Err(); err != nil {
+		return BatchResult{}, err
+	}
+
+	var res BatchResult
+	teams := make(map[string]string) // команда по автору
+	err := s.tx.InTx(ctx, func(tx repository.Repos) error {
+		res = BatchResult{Mode: mode, Applied: true, Items: make([]BatchItemResult, len(prIDs))}
+		mergedAt := time.Now().UTC()
+
+		for i, id := range prIDs {
+			res.Items[i] = BatchItemResult{PullRequestID: id}
+			pr, err := tx.PullRequests.GetByID(ctx, id)
+			if errors.Is(err, repository.ErrPRNotFound) {
+				res.Items[i].Status = BatchNotFound
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			team, ok := teams[pr.AuthorID]
+			if !ok {
+				author, err := tx.Users.GetByID(ctx, pr.AuthorID)
+				if err != nil {
+					return err
+				}
+				team = author.TeamName
+				teams[pr.AuthorID] = team
+			}
+			if err := requireTeamLead(ctx, team); err != nil {
+				if mode == BatchAtomic || !errors.Is(err, ErrForbidden) {
+					return err
+				}
+				res.Items[i].Status = BatchForbidden
+				continue
+			}
+
+			if pr.Status == "MERGED" {
+				res.Items[i].Status = BatchAlreadyMerged
+				res.Items[i].PR = &pr
+				continue
+			}
+			// транзакция видит один снимок, поэтому PR мог смержить только параллельный запрос
+			if err := tx.PullRequests.MarkMerged(ctx, id, 0, mergedAt, ActorFromContext(ctx)); err != nil {
+				if !errors.Is(err, repository.ErrVersionConflict) {
+					return err
+				}
+				if mode == BatchAtomic {
+					return ErrPRConflict
+				}
+				res.Items[i].Status = BatchConflict
+				continue
+			}
+			updated, err := tx.PullRequests.GetByID(ctx, id)
+			if err != nil {
+				return err
+			}
+			res.Items[i].Status = BatchMerged
+			res.Items[i].PR = &updated
+		}
+
+		if mode == BatchAtomic && res.failed() {
+			return errBatchFailed
+		}
+		return nil
+	})
+	if errors.Is(err, errBatchFailed) {
+		res.rollBack()
+		return res, nil
+	}
+	if err != nil {
+		return BatchResult{}, err
+	}
+
+	for _, it := range res.Items {
+		if it.Status != BatchMerged {
+			continue
+		}
+		metrics.PRsMerged.WithLabelValues(teams[it.PR.AuthorID]).Inc()
+
+		reviewerIDs := make([]string, 0, len(it.PR.Reviewers))
+		for _, r := range it.PR.Reviewers {
+			reviewerIDs = append(reviewerIDs, r.ID)
+		}
+		s.publish(ctx, reviewerIDs...)
+	}
+	return res, nil
+}
+
+func validBatchMode(v *ValidationError, mode BatchMode) BatchMode {
+	switch mode {
+	case "":
+		return BatchAtomic
+	case BatchAtomic, BatchBestEffort:
+		return mode
+	}
+	v.Add("mode", RuleOneOf, "mode must be one of atomic, best_effort")
+	return mode
+}
+
+func validBatchSize(v *ValidationError, field string, n int) {
+	switch {
+	case n == 0:
+		v.Add(field, RuleRequired, field+" must not be empty")
+	case n > MaxBatchSize:
+		v.Add(field, RuleRange, fmt.Sprintf("%s must contain at most %d items", field, MaxBatchSize))
+	}
+}
+
+// batchAssigner выбирает ревьюверов внутри пакета. Активные участники команды читаются
+// один раз, каждый PR получает до 2 наименее загруженных в этом пакете кандидатов.
+// Уже открытые ревью вне пакета не учитываются, как и при создании одного PR, где
+// ревьюверы выбираются случайно: пакет выравнивает только собственные назначения.
+type batchAssigner struct {
+	users      repository.Users
+	candidates map[string][]model.User // по команде, в случайном порядке
+	load       map[string]int          // назначений в пакете по пользователю
+}
+
+func newBatchAssigner(users repository.Users) *batchAssigner {
+	return &batchAssigner{
+		users:      users,
+		candidates: make(map[string][]model.User),
+		load:       make(map[string]int),
+	}
+}
+
+func (a *batchAssigner) pick(ctx context.Context, author model.User) ([]model.User, error) {
+	team, ok := a.candidates[author.TeamName]
+	if !ok {
+		var err error
+		team, err = a.users.GetActiveByTeamExcept(ctx, author.TeamName, nil)
+		if err != nil {
+			return nil, err
+		}
+		// случайный порядок разбивает ничьи по загрузке, иначе первые ревьюверы
+		// каждой команды получали бы больше PR
+		rand.Shuffle(len(team), func(i, j int) { team[i], team[j] = team[j], team[i] })
+		a.candidates[author.TeamName] = team
+	}
+
+	pool := make([]model.User, 0, len(team))
+	for _, u := range team {
+		if u.ID != author.ID {
+			pool = append(pool, u)
+		}
+	}
+	slices.SortStableFunc(pool, func(x, y model.User) int { return a.load[x.ID] - a.load[y.ID] })
+
+	reviewers := pool[:min(len(pool), 2)]
+	for _, u := range reviewers {
+		a.load[u.ID]++
+	}
+	return reviewers, nil
+}
+
+// release возвращает загрузку ревьюверов PR, который не создался
+func (a *batchAssigner) release(reviewers []model.User) {
+	for _, u := range reviewers {
+		a.load[u.ID]--
+	}
+}
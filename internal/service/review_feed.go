@@ -0,0 +1,227 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/tracing"
+)
+
+// reviewFeedRetry пауза перед переподключением к шине сигналов
+const reviewFeedRetry = time.Second
+
+// reviewQueueBatch сколько изменений очереди читается из истории за один запрос
+const reviewQueueBatch = 100
+
+// ReviewFeed будит подписчиков на очереди ревью. Сами изменения подписчики читают из
+// истории PR, поэтому потерянный или лишний сигнал не теряет и не дублирует события.
+type ReviewFeed struct {
+	bus repository.ReviewBus // nil - сигналы только внутри процесса
+
+	mu   sync.Mutex
+	subs map[string]map[chan struct{}]struct{}
+}
+
+// NewReviewFeed рассылка сигналов через bus всем репликам, при nil bus - внутри процесса
+func NewReviewFeed(bus repository.ReviewBus) *ReviewFeed {
+	return &ReviewFeed{bus: bus, subs: make(map[string]map[chan struct{}]struct{})}
+}
+
+// Run принимает сигналы от шины до отмены ctx и переподключается при обрыве. Без шины сразу возвращается.
+func (f *ReviewFeed) Run(ctx context.Context) {
+	if f.bus == nil {
+		return
+	}
+	for {
+		err := f.bus.Listen(ctx, f.wake)
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Warn("review queue listener failed, reconnecting", slog.Any("error", err))
+		// сигналы до переподключения потеряны, пусть подписчики перечитают очереди
+		f.wake(nil)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(reviewFeedRetry):
+		}
+	}
+}
+
+// Publish сообщает подписчикам, что очереди userIDs изменились
+func (f *ReviewFeed) Publish(ctx context.Context, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	if f.bus == nil {
+		f.wake(userIDs)
+		return nil
+	}
+	if err := f.bus.Notify(ctx, userIDs); err != nil {
+		// подписчики этой реплики хотя бы узнают об изменении
+		f.wake(userIDs)
+		return err
+	}
+	return nil
+}
+
+// Subscribe канал сигналов об изменениях очереди userID. Сигналы не копятся: пока
+// подписчик не прочитал один, следующие сливаются с ним. cancel освобождает подписку.
+func (f *ReviewFeed) Subscribe(userID string) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	f.mu.Lock()
+	if f.subs[userID] == nil {
+		f.subs[userID] = make(map[chan struct{}]struct{})
+	}
+	f.subs[userID][ch] = struct{}{}
+	f.mu.Unlock()
+
+	return ch, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		delete(f.subs[userID], ch)
+		if len(f.subs[userID]) == 0 {
+			delete(f.subs, userID)
+		}
+	}
+}
+
+// wake будит подписчиков userIDs, nil - всех
+func (f *ReviewFeed) wake(userIDs []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	signal := func(subs map[chan struct{}]struct{}) {
+		for ch := range subs {
+			select {
+			case ch <- struct{}{}:
+			default:
+			}
+		}
+	}
+	if userIDs == nil {
+		for _, subs := range f.subs {
+			signal(subs)
+		}
+		return
+	}
+	for _, id := range userIDs {
+		signal(f.subs[id])
+	}
+}
+
+// ReviewQueueWatch подписка на очередь ревью пользователя
+type ReviewQueueWatch struct {
+	// Snapshot открытые ревью на момент подписки, nil при продолжении после известного события
+	Snapshot []model.PullRequestShort
+	// LastEventID курсор: изменения с id не больше него уже отражены в снимке или прочитаны
+	LastEventID int64
+
+	userID  string
+	prRepo  repository.PullRequests
+	changed <-chan struct{}
+	cancel  func()
+}
+
+// Changed сигнал, что в очереди могли появиться изменения и пора вызвать Next
+func (w *ReviewQueueWatch) Changed() <-chan struct{} {
+	return w.changed
+}
+
+// Next изменения очереди после LastEventID по возрастанию id, сдвигает курсор
+func (w *ReviewQueueWatch) Next(ctx context.Context) ([]model.ReviewQueueEvent, error) {
+	var events []model.ReviewQueueEvent
+	for {
+		batch, err := w.prRepo.GetReviewQueueEvents(ctx, w.userID, w.LastEventID, reviewQueueBatch)
+		if err != nil {
+			return events, err
+		}
+		events = append(events, batch...)
+		if len(batch) > 0 {
+			w.LastEventID = batch[len(batch)-1].ID
+		}
+		if len(batch) < reviewQueueBatch {
+			return events, nil
+		}
+	}
+}
+
+// Close освобождает подписку
+func (w *ReviewQueueWatch) Close() {
+	w.cancel()
+}
+
+// WatchReviewQueue подписка на очередь ревью пользователя. При lastEventID из истории
+// чтение продолжается после него, иначе подписка начинается со снимка открытых ревью.
+// Подписка оформляется до чтения курсора, поэтому изменения между ними не теряются.
+func (s *PullRequestService) WatchReviewQueue(ctx context.Context, userID string, lastEventID int64) (*ReviewQueueWatch, error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.WatchReviewQueue")
+	defer span.End()
+
+	if userID == "" {
+		return nil, invalidField("user_id", RuleRequired, "user_id is required")
+	}
+	if lastEventID < 0 {
+		return nil, invalidField("last_event_id", RuleRange, "last_event_id must not be negative")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := requireReader(ctx, user); err != nil {
+		return nil, err
+	}
+
+	changed, cancel := s.feed.Subscribe(userID)
+	w := &ReviewQueueWatch{userID: userID, prRepo: s.prRepo, changed: changed, cancel: cancel}
+
+	current, err := s.prRepo.LastEventID(ctx, userID)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// id из будущего (например, от другой базы) не продолжить, отдаем снимок
+	if lastEventID > 0 && lastEventID <= current {
+		w.LastEventID = lastEventID
+		return w, nil
+	}
+	w.LastEventID = current
+
+	// изменения после курсора могут повторить снимок, клиент применяет их идемпотентно
+	page := repository.Page{Limit: MaxListLimit, Sort: repository.SortByCreatedAt}
+	w.Snapshot = make([]model.PullRequestShort, 0)
+	for {
+		prs, next, err := s.prRepo.List(ctx, repository.PullRequestFilter{ReviewerID: userID, Status: "OPEN"}, page)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		for _, pr := range prs {
+			w.Snapshot = append(w.Snapshot, shortPR(pr))
+		}
+		if next == nil {
+			break
+		}
+		page.After = next
+	}
+	return w, nil
+}
+
+// publish будит подписчиков очередей userIDs, ошибка рассылки не должна ломать операцию с PR
+func (s *PullRequestService) publish(ctx context.Context, userIDs ...string) {
+	if err := s.feed.Publish(ctx, userIDs); err != nil {
+		slog.Warn("failed to publish review queue change", slog.Any("error", err))
+	}
+}
+
+func shortPR(pr model.PullRequest) model.PullRequestShort {
+	return model.PullRequestShort{ID: pr.ID, Name: pr.Name, AuthorID: pr.AuthorID, Status: pr.Status}
+}
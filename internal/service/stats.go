@@ -4,13 +4,14 @@ import (
 	"context"
 
 	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/tracing"
 )
 
 type StatsService struct {
-	statsRepo *repository.StatsRepository
+	statsRepo repository.Stats
 }
 
-func NewStatsService(statsRepo *repository.StatsRepository) *StatsService {
+func NewStatsService(statsRepo repository.Stats) *StatsService {
 	return &StatsService{statsRepo: statsRepo}
 }
 
@@ -20,6 +21,9 @@ type AssignmentsStats struct {
 }
 
 func (s *StatsService) GetAssignmentsStats(ctx context.Context) (AssignmentsStats, error) {
+	ctx, span := tracing.Start(ctx, "StatsService.GetAssignmentsStats")
+	defer span.End()
+
 	users, err := s.statsRepo.GetAssignmentsByUser(ctx)
 	if err != nil {
 		return AssignmentsStats{}, err
@@ -2,24 +2,92 @@ package service
 
 import (
 	"context"
+	"fmt"
+
 	"github.com/Olzerq/avito-pr-reviewer/internal/model"
 	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/tracing"
 )
 
 type TeamService struct {
-	teamRepo *repository.TeamRepository
+	teamRepo repository.Teams
 }
 
-func NewTeamService(teamRepo *repository.TeamRepository) *TeamService {
+func NewTeamService(teamRepo repository.Teams) *TeamService {
 	return &TeamService{teamRepo: teamRepo}
 }
 
 // CreateTeam создает команду
 func (s *TeamService) CreateTeam(ctx context.Context, team model.Team) error {
+	ctx, span := tracing.Start(ctx, "TeamService.CreateTeam")
+	defer span.End()
+
+	if err := validateTeam(team); err != nil {
+		return err
+	}
+
+	if err := requireTeamLead(ctx, team.Name); err != nil {
+		return err
+	}
+
 	return s.teamRepo.CreateTeam(ctx, team)
 }
 
 // GetTeam возвращает команду
 func (s *TeamService) GetTeam(ctx context.Context, teamName string) (model.Team, error) {
+	ctx, span := tracing.Start(ctx, "TeamService.GetTeam")
+	defer span.End()
+
+	if teamName == "" {
+		return model.Team{}, invalidField("team_name", RuleRequired, "team_name is required")
+	}
+	if _, err := readableTeam(ctx, teamName); err != nil {
+		return model.Team{}, err
+	}
+
 	return s.teamRepo.GetTeam(ctx, teamName)
 }
+
+// List страница команд с участниками, команды сортируются по имени.
+// Лид и участник видят только свою команду.
+func (s *TeamService) List(ctx context.Context, f repository.TeamFilter, q ListQuery) (ListResult[model.Team], error) {
+	ctx, span := tracing.Start(ctx, "TeamService.List")
+	defer span.End()
+
+	var v ValidationError
+	page := q.page(&v, repository.SortByName)
+	if err := v.Err(); err != nil {
+		return ListResult[model.Team]{}, err
+	}
+	var err error
+	if f.TeamName, err = readableTeam(ctx, f.TeamName); err != nil {
+		return ListResult[model.Team]{}, err
+	}
+	teams, next, err := s.teamRepo.List(ctx, f, page)
+	if err != nil {
+		return ListResult[model.Team]{}, err
+	}
+	return ListResult[model.Team]{Items: teams, NextCursor: encodeCursor(next, page)}, nil
+}
+
+// validateTeam проверяет все поля команды и возвращает все нарушения сразу
+func validateTeam(team model.Team) error {
+	var v ValidationError
+	v.Required("team_name", team.Name)
+
+	seen := make(map[string]int, len(team.Users))
+	for i, u := range team.Users {
+		field := fmt.Sprintf("members[%d]", i)
+		v.Required(field+".user_id", u.ID)
+		v.Required(field+".username", u.Username)
+		if u.ID == "" {
+			continue
+		}
+		if first, ok := seen[u.ID]; ok {
+			v.Add(field+".user_id", RuleUnique, fmt.Sprintf("user_id %q is already used by members[%d]", u.ID, first))
+			continue
+		}
+		seen[u.ID] = i
+	}
+	return v.Err()
+}
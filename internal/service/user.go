@@ -2,24 +2,104 @@ package service
 
 import (
 	"context"
+	"errors"
+	"github.com/Olzerq/avito-pr-reviewer/internal/auth"
 	"github.com/Olzerq/avito-pr-reviewer/internal/model"
 	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/tracing"
 )
 
 type UserService struct {
-	userRepo *repository.UserRepository
+	userRepo repository.Users
 }
 
-func NewUserService(userRepo *repository.UserRepository) *UserService {
+func NewUserService(userRepo repository.Users) *UserService {
 	return &UserService{userRepo: userRepo}
 }
 
 func (s *UserService) SetIsActive(ctx context.Context, userID string, isActive bool) error {
+	ctx, span := tracing.Start(ctx, "UserService.SetIsActive")
+	defer span.End()
+
+	if userID == "" {
+		return invalidField("user_id", RuleRequired, "user_id is required")
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if err := requireTeamLead(ctx, user.TeamName); err != nil {
+		return err
+	}
+
 	return s.userRepo.SetIsActive(ctx, userID, isActive)
 }
 
-// GetByID
+// SetRole меняет роль пользователя, доступно только администратору
+func (s *UserService) SetRole(ctx context.Context, userID, role string) error {
+	ctx, span := tracing.Start(ctx, "UserService.SetRole")
+	defer span.End()
+
+	var v ValidationError
+	v.Required("user_id", userID)
+	if !model.ValidRole(role) {
+		v.Add("role", RuleOneOf, "role must be one of admin, team_lead, member, read_only")
+	}
+	if err := v.Err(); err != nil {
+		return err
+	}
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	return s.userRepo.SetRole(ctx, userID, role)
+}
+
+// GetRole возвращает роль и команду пользователя, реализует auth.RoleStore
+func (s *UserService) GetRole(ctx context.Context, userID string) (string, string, error) {
+	ctx, span := tracing.Start(ctx, "UserService.GetRole")
+	defer span.End()
 
+	role, team, err := s.userRepo.GetRole(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return "", "", auth.ErrUnknownUser
+	}
+	return role, team, err
+}
+
+// GetByID возвращает пользователя, лид и участник видят только свою команду, как в List
 func (s *UserService) GetByID(ctx context.Context, userID string) (model.User, error) {
-	return s.userRepo.GetByID(ctx, userID)
+	ctx, span := tracing.Start(ctx, "UserService.GetByID")
+	defer span.End()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return model.User{}, err
+	}
+	if _, err := readableTeam(ctx, user.TeamName); err != nil {
+		return model.User{}, err
+	}
+	return user, nil
+}
+
+// List страница пользователей по фильтрам. Лид и участник видят только свою команду.
+func (s *UserService) List(ctx context.Context, f repository.UserFilter, q ListQuery) (ListResult[model.User], error) {
+	ctx, span := tracing.Start(ctx, "UserService.List")
+	defer span.End()
+
+	var v ValidationError
+	page := q.page(&v, repository.SortByID, repository.SortByName)
+	if err := v.Err(); err != nil {
+		return ListResult[model.User]{}, err
+	}
+	var err error
+	if f.TeamName, err = readableTeam(ctx, f.TeamName); err != nil {
+		return ListResult[model.User]{}, err
+	}
+	users, next, err := s.userRepo.List(ctx, f, page)
+	if err != nil {
+		return ListResult[model.User]{}, err
+	}
+	return ListResult[model.User]{Items: users, NextCursor: encodeCursor(next, page)}, nil
 }
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrValidation входные данные не прошли проверку, подробности по полям в *ValidationError
+var ErrValidation = errors.New("validation failed")
+
+// Правила, которые нарушают поля
+const (
+	RuleRequired = "required" // поле пустое
+	RuleUnique   = "unique"   // значение повторяется
+	RuleOneOf    = "one_of"   // значение не из списка допустимых
+	RuleRange    = "range"    // число вне допустимого диапазона
+	RuleFormat   = "format"   // значение не разбирается
+)
+
+// FieldError нарушение правила в одном поле. Field - путь в запросе, например members[1].user_id
+type FieldError struct {
+	Field   string
+	Rule    string
+	Message string
+}
+
+// ValidationError все найденные нарушения, а не только первое
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Add(field, rule, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Rule: rule, Message: message})
+}
+
+// Required добавляет нарушение, если value пустое
+func (e *ValidationError) Required(field, value string) {
+	if value == "" {
+		e.Add(field, RuleRequired, field+" is required")
+	}
+}
+
+// Err nil, если нарушений нет
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Message)
+	}
+	return ErrValidation.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// invalidField ошибка валидации с одним нарушением
+func invalidField(field, rule, message string) error {
+	var v ValidationError
+	v.Add(field, rule, message)
+	return &v
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/auth"
+	"github.com/Olzerq/avito-pr-reviewer/internal/ratelimit"
+)
+
+func TestAuthenticationMiddleware(t *testing.T) {
+	server, authenticator := setupConformanceServer(t,
+		ratelimit.Limit{RPS: 1000, Burst: 1000}, ratelimit.NewConcurrencyLimiter(100, time.Second), nil)
+	defer server.Close()
+
+	p := t.Name() + "_"
+	member := p + "member"
+	admin := with(nil, "Authorization", "Bearer "+conformanceAdminToken)
+	members := []map[string]any{{"user_id": member, "username": member, "is_active": true}}
+	if resp, body := postJSON(t, server.URL+"/team/add", map[string]any{"team_name": p + "team", "members": members}, admin); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("failed to create team: %v", body)
+	}
+
+	issue := func(a *auth.Authenticator, sub string, ttl time.Duration) string {
+		token, err := a.Issue(sub, ttl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	memberToken := issue(authenticator, member, time.Hour)
+	forged := issue(auth.NewAuthenticator(nil, "another-secret", "", "", nil), member, time.Hour)
+
+	readPath := "/users/getReview?user_id=" + member
+	setRole := map[string]any{"user_id": member, "role": "team_lead"}
+
+	tests := []struct {
+		name          string
+		authorization string
+		method        string
+		status        int
+	}{
+		{"missing header", "", http.MethodGet, http.StatusUnauthorized},
+		{"basic scheme", "Basic " + conformanceAdminToken, http.MethodGet, http.StatusUnauthorized},
+		{"bearer without token", "Bearer", http.MethodGet, http.StatusUnauthorized},
+		{"malformed token", "Bearer not-a-jwt", http.MethodGet, http.StatusUnauthorized},
+		{"bad signature", "Bearer " + forged, http.MethodGet, http.StatusUnauthorized},
+		{"expired", "Bearer " + issue(authenticator, member, -time.Hour), http.MethodGet, http.StatusUnauthorized},
+		{"unknown subject", "Bearer " + issue(authenticator, p+"ghost", time.Hour), http.MethodGet, http.StatusUnauthorized},
+		{"user token", "Bearer " + memberToken, http.MethodGet, http.StatusOK},
+		{"scheme is case-insensitive", "bearer " + memberToken, http.MethodGet, http.StatusOK},
+		{"admin-only route rejects user token", "Bearer " + memberToken, http.MethodPost, http.StatusForbidden},
+		{"static admin token", "Bearer " + conformanceAdminToken, http.MethodPost, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header http.Header
+			if tt.authorization != "" {
+				header = with(nil, "Authorization", tt.authorization)
+			}
+
+			var (
+				resp *http.Response
+				body map[string]any
+			)
+			if tt.method == http.MethodPost {
+				resp, body = postJSON(t, server.URL+"/users/setRole", setRole, header)
+			} else {
+				resp, body = doJSON(t, http.MethodGet, server.URL+readPath, nil, header)
+			}
+			if resp.StatusCode != tt.status {
+				t.Fatalf("expected %d, got %d: %v", tt.status, resp.StatusCode, body)
+			}
+
+			switch tt.status {
+			case http.StatusUnauthorized:
+				if resp.Header.Get("WWW-Authenticate") == "" {
+					t.Error("401 without WWW-Authenticate")
+				}
+				expectCode(t, body, "UNAUTHORIZED")
+			case http.StatusForbidden:
+				expectCode(t, body, "FORBIDDEN")
+			}
+		})
+	}
+}
+
+func expectCode(t *testing.T, body map[string]any, code string) {
+	t.Helper()
+
+	e, _ := body["error"].(map[string]any)
+	if e == nil || e["code"] != code {
+		t.Errorf("expected error code %s, got %v", code, body)
+	}
+}
@@ -0,0 +1,301 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/ratelimit"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+)
+
+// batchStatuses статусы элементов ответа пакетной операции по pull_request_id
+func batchStatuses(t *testing.T, body map[string]any) map[string]string {
+	t.Helper()
+
+	results, ok := body["results"].([]any)
+	if !ok {
+		t.Fatalf("batch response without results: %v", body)
+	}
+	statuses := make(map[string]string, len(results))
+	for _, r := range results {
+		item := r.(map[string]any)
+		statuses[item["pull_request_id"].(string)] = item["status"].(string)
+	}
+	return statuses
+}
+
+func TestBatchCreatePullRequests(t *testing.T) {
+	server := setupTestServer(t)
+	defer server.Close()
+
+	p := t.Name() + "_"
+	author := p + "author"
+	members := []map[string]any{{"user_id": author, "username": author, "is_active": true}}
+	reviewers := make([]string, 0, 4)
+	for i := 0; i < 4; i++ {
+		id := fmt.Sprintf("%sr%d", p, i)
+		reviewers = append(reviewers, id)
+		members = append(members, map[string]any{"user_id": id, "username": id, "is_active": true})
+	}
+	if resp, body := postJSON(t, server.URL+"/team/add", map[string]any{"team_name": p + "team", "members": members}, nil); resp == nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("failed to create team: %v", body)
+	}
+	existing := p + "existing"
+	if resp, body := postJSON(t, server.URL+"/pullRequest/create", map[string]any{"pull_request_id": existing, "pull_request_name": existing, "author_id": author}, nil); resp == nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("failed to create PR: %v", body)
+	}
+
+	item := func(id, authorID string) map[string]any {
+		return map[string]any{"pull_request_id": id, "pull_request_name": id, "author_id": authorID}
+	}
+	batchCreate := func(mode string, items ...map[string]any) map[string]any {
+		t.Helper()
+		resp, body := postJSON(t, server.URL+"/pullRequest/batchCreate", map[string]any{"mode": mode, "items": items}, nil)
+		if resp == nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("batch create failed: %v", body)
+		}
+		return body
+	}
+
+	// best_effort: новые PR создаются, существующий и PR неизвестного автора пропускаются
+	items := []map[string]any{item(existing, author), item(p+"orphan", p+"missing")}
+	var created []string
+	for i := 0; i < 8; i++ {
+		id := fmt.Sprintf("%spr%d", p, i)
+		created = append(created, id)
+		items = append(items, item(id, author))
+	}
+	body := batchCreate("best_effort", items...)
+	if body["applied"] != true || body["mode"] != "best_effort" {
+		t.Fatalf("expected applied best_effort batch, got %v", body)
+	}
+	statuses := batchStatuses(t, body)
+	if statuses[existing] != "EXISTS" || statuses[p+"orphan"] != "AUTHOR_NOT_FOUND" {
+		t.Fatalf("unexpected statuses %v", statuses)
+	}
+
+	// 8 PR по 2 ревьювера распределяются между 4 кандидатами поровну
+	load := make(map[string]int)
+	for _, r := range body["results"].([]any) {
+		res := r.(map[string]any)
+		if res["status"] != "CREATED" {
+			continue
+		}
+		pr := res["pr"].(map[string]any)
+		for _, id := range pr["assigned_reviewers"].([]any) {
+			load[id.(string)]++
+		}
+	}
+	for _, id := range created {
+		if statuses[id] != "CREATED" {
+			t.Fatalf("expected %s to be created, got %v", id, statuses)
+		}
+	}
+	for _, id := range reviewers {
+		if load[id] != 4 {
+			t.Fatalf("expected balanced assignment, got %v", load)
+		}
+	}
+	if load[author] != 0 {
+		t.Fatalf("author must not review own PRs: %v", load)
+	}
+
+	// atomic: существующий PR откатывает весь пакет
+	rolledBack := p + "rolledBack"
+	body = batchCreate("atomic", item(rolledBack, author), item(existing, author))
+	statuses = batchStatuses(t, body)
+	if body["applied"] != false || statuses[rolledBack] != "ROLLED_BACK" || statuses[existing] != "EXISTS" {
+		t.Fatalf("expected rolled back batch, got %v", body)
+	}
+	if resp, _ := getJSON(t, server.URL+"/pullRequest/history?pull_request_id="+rolledBack); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("rolled back PR must not exist, got %d", resp.StatusCode)
+	}
+
+	// режим по умолчанию atomic
+	resp, body := postJSON(t, server.URL+"/pullRequest/batchCreate", map[string]any{"items": []map[string]any{item(rolledBack, author)}}, nil)
+	if resp == nil || resp.StatusCode != http.StatusOK || body["mode"] != "atomic" || body["applied"] != true {
+		t.Fatalf("expected applied atomic batch, got %v", body)
+	}
+
+	// повторяющиеся id отклоняют запрос целиком
+	resp, body = postJSON(t, server.URL+"/pullRequest/batchCreate", map[string]any{"items": []map[string]any{item(p+"dup", author), item(p+"dup", author)}}, nil)
+	if resp == nil || resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for duplicate ids, got %v", body)
+	}
+	details := body["error"].(map[string]any)["details"].([]any)
+	if d := details[0].(map[string]any); d["field"] != "items[1].pull_request_id" || d["rule"] != "unique" {
+		t.Fatalf("unexpected validation details %v", details)
+	}
+
+	// batchMerge
+	batchMerge := func(mode string, ids ...string) map[string]any {
+		t.Helper()
+		resp, body := postJSON(t, server.URL+"/pullRequest/batchMerge", map[string]any{"mode": mode, "pull_request_ids": ids}, nil)
+		if resp == nil || resp.StatusCode != http.StatusOK {
+			t.Fatalf("batch merge failed: %v", body)
+		}
+		return body
+	}
+
+	body = batchMerge("atomic", created[0], p+"missing")
+	statuses = batchStatuses(t, body)
+	if body["applied"] != false || statuses[created[0]] != "ROLLED_BACK" || statuses[p+"missing"] != "NOT_FOUND" {
+		t.Fatalf("expected rolled back merge, got %v", body)
+	}
+
+	body = batchMerge("best_effort", created[0], created[1], p+"missing")
+	statuses = batchStatuses(t, body)
+	if statuses[created[0]] != "MERGED" || statuses[created[1]] != "MERGED" || statuses[p+"missing"] != "NOT_FOUND" {
+		t.Fatalf("unexpected merge statuses %v", statuses)
+	}
+
+	// уже смерженный PR не ломает атомарный пакет
+	body = batchMerge("atomic", created[0], created[2])
+	statuses = batchStatuses(t, body)
+	if body["applied"] != true || statuses[created[0]] != "ALREADY_MERGED" || statuses[created[2]] != "MERGED" {
+		t.Fatalf("unexpected merge statuses %v", body)
+	}
+	for _, r := range body["results"].([]any) {
+		if pr := r.(map[string]any)["pr"].(map[string]any); pr["status"] != "MERGED" {
+			t.Fatalf("expected merged PR, got %v", pr)
+		}
+	}
+}
+
+func TestBatchPermissions(t *testing.T) {
+	server, authenticator := setupConformanceServer(t,
+		ratelimit.Limit{RPS: 1000, Burst: 1000}, ratelimit.NewConcurrencyLimiter(100, time.Second), nil)
+	defer server.Close()
+
+	p := t.Name() + "_"
+	leadA, memberA, memberB := p+"lead_a", p+"member_a", p+"member_b"
+	admin := with(nil, "Authorization", "Bearer "+conformanceAdminToken)
+	addTeam := func(name string, ids ...string) {
+		members := make([]map[string]any, 0, len(ids))
+		for _, id := range ids {
+			members = append(members, map[string]any{"user_id": id, "username": id, "is_active": true})
+		}
+		if resp, body := postJSON(t, server.URL+"/team/add", map[string]any{"team_name": name, "members": members}, admin); resp.StatusCode != http.StatusCreated {
+			t.Fatalf("failed to create team: %v", body)
+		}
+	}
+	addTeam(p+"a", leadA, memberA, p+"reviewer_a")
+	addTeam(p+"b", memberB, p+"reviewer_b")
+	if resp, body := postJSON(t, server.URL+"/users/setRole", map[string]any{"user_id": leadA, "role": model.RoleTeamLead}, admin); resp.StatusCode != http.StatusOK {
+		t.Fatalf("failed to set role: %v", body)
+	}
+	token, err := authenticator.Issue(leadA, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lead := with(nil, "Authorization", "Bearer "+token)
+
+	item := func(id, authorID string) map[string]any {
+		return map[string]any{"pull_request_id": id, "pull_request_name": id, "author_id": authorID}
+	}
+	own, foreign := p+"own", p+"foreign"
+
+	// atomic: автор чужой команды отклоняет весь пакет
+	resp, body := postJSON(t, server.URL+"/pullRequest/batchCreate",
+		map[string]any{"mode": "atomic", "items": []map[string]any{item(own, memberA), item(foreign, memberB)}}, lead)
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d: %v", resp.StatusCode, body)
+	}
+	expectCode(t, body, "FORBIDDEN")
+
+	// best_effort: чужой автор только у своего элемента
+	resp, body = postJSON(t, server.URL+"/pullRequest/batchCreate",
+		map[string]any{"mode": "best_effort", "items": []map[string]any{item(own, memberA), item(foreign, memberB)}}, lead)
+	if resp.StatusCode != http.StatusOK || body["applied"] != true {
+		t.Fatalf("expected applied batch, got %d: %v", resp.StatusCode, body)
+	}
+	if statuses := batchStatuses(t, body); statuses[own] != "CREATED" || statuses[foreign] != "FORBIDDEN" {
+		t.Fatalf("unexpected statuses %v", statuses)
+	}
+
+	if resp, body := postJSON(t, server.URL+"/pullRequest/create", item(foreign, memberB), admin); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("failed to create PR: %v", body)
+	}
+	resp, body = postJSON(t, server.URL+"/pullRequest/batchMerge",
+		map[string]any{"mode": "atomic", "pull_request_ids": []string{own, foreign}}, lead)
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d: %v", resp.StatusCode, body)
+	}
+	resp, body = postJSON(t, server.URL+"/pullRequest/batchMerge",
+		map[string]any{"mode": "best_effort", "pull_request_ids": []string{own, foreign}}, lead)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %v", resp.StatusCode, body)
+	}
+	if statuses := batchStatuses(t, body); statuses[own] != "MERGED" || statuses[foreign] != "FORBIDDEN" {
+		t.Fatalf("unexpected statuses %v", statuses)
+	}
+}
+
+// conflictingTx транзакции, в которых merge PR из ids отвечает ErrVersionConflict,
+// как если бы их изменил параллельный запрос
+type conflictingTx struct {
+	repository.TxManager
+	ids map[string]bool
+}
+
+func (c conflictingTx) InTx(ctx context.Context, fn func(tx repository.Repos) error) error {
+	return c.TxManager.InTx(ctx, func(tx repository.Repos) error {
+		tx.PullRequests = conflictingMerges{PullRequests: tx.PullRequests, ids: c.ids}
+		return fn(tx)
+	})
+}
+
+type conflictingMerges struct {
+	repository.PullRequests
+	ids map[string]bool
+}
+
+func (c conflictingMerges) MarkMerged(ctx context.Context, prID string, version int64, mergedAt time.Time, actor string) error {
+	if c.ids[prID] {
+		return repository.ErrVersionConflict
+	}
+	return c.PullRequests.MarkMerged(ctx, prID, version, mergedAt, actor)
+}
+
+func TestBatchMergeConflict(t *testing.T) {
+	ctx := service.WithSystemIdentity(context.Background())
+	storage := setupStorage(t)
+
+	p := t.Name() + "_"
+	author := model.User{ID: p + "author", Username: "author", TeamName: p + "team", IsActive: true}
+	if err := service.NewTeamService(storage.Teams).CreateTeam(ctx, model.Team{Name: author.TeamName, Users: []model.User{author}}); err != nil {
+		t.Fatal(err)
+	}
+	plain := service.NewPullRequestService(storage.Tx, storage.PullRequests, storage.Users, nil, nil)
+	ok, conflicted, rest := p+"ok", p+"conflicted", p+"rest"
+	for _, id := range []string{ok, conflicted, rest} {
+		if _, err := plain.Create(ctx, id, id, author.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	prs := service.NewPullRequestService(conflictingTx{TxManager: storage.Tx, ids: map[string]bool{conflicted: true}},
+		storage.PullRequests, storage.Users, nil, nil)
+
+	// atomic: конфликт отклоняет весь пакет
+	if _, err := prs.BatchMerge(ctx, []string{rest, conflicted}, service.BatchAtomic); !errors.Is(err, service.ErrPRConflict) {
+		t.Fatalf("expected ErrPRConflict, got %v", err)
+	}
+	if pr, err := plain.Get(ctx, rest); err != nil || pr.Status != "OPEN" {
+		t.Fatalf("atomic batch must be rolled back, got %+v, %v", pr, err)
+	}
+
+	// best_effort: конфликт только у своего элемента
+	res, err := prs.BatchMerge(ctx, []string{ok, conflicted}, service.BatchBestEffort)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Applied || res.Items[0].Status != service.BatchMerged || res.Items[1].Status != service.BatchConflict || res.Items[1].PR != nil {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
@@ -0,0 +1,199 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func postJSON(t *testing.T, url string, body any, header http.Header) (*http.Response, map[string]any) {
+	t.Helper()
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Error(err)
+		return nil, nil
+	}
+	defer resp.Body.Close()
+
+	var out map[string]any
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	return resp, out
+}
+
+// createTeamWithPR создает команду из автора и n участников и PR от автора
+func createTeamWithPR(t *testing.T, serverURL string, n int) (prID string, reviewers []string) {
+	t.Helper()
+
+	prefix := t.Name() + "_"
+	members := []map[string]any{{"user_id": prefix + "author", "username": "Author", "is_active": true}}
+	for i := 0; i < n; i++ {
+		id := fmt.Sprintf("%su%d", prefix, i)
+		members = append(members, map[string]any{"user_id": id, "username": id, "is_active": true})
+	}
+
+	resp, _ := postJSON(t, serverURL+"/team/add", map[string]any{"team_name": prefix + "team", "members": members}, nil)
+	if resp == nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("failed to create team: %v", resp)
+	}
+
+	prID = prefix + "pr"
+	resp, body := postJSON(t, serverURL+"/pullRequest/create", map[string]any{
+		"pull_request_id":   prID,
+		"pull_request_name": "Race",
+		"author_id":         prefix + "author",
+	}, nil)
+	if resp == nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("failed to create PR: %v", resp)
+	}
+	if etag := resp.Header.Get("ETag"); etag != `"1"` {
+		t.Fatalf("expected ETag \"1\" for new PR, got %q", etag)
+	}
+
+	for _, id := range body["pr"].(map[string]any)["assigned_reviewers"].([]any) {
+		reviewers = append(reviewers, id.(string))
+	}
+	return prID, reviewers
+}
+
+// Параллельные переназначения и merge одного PR: смерженный PR не получает новых ревьюверов,
+// а один и тот же кандидат не назначается дважды.
+func TestConcurrentReassignAndMerge(t *testing.T) {
+	server := setupTestServer(t)
+	defer server.Close()
+
+	prID, reviewers := createTeamWithPR(t, server.URL, 10)
+	if len(reviewers) != 2 {
+		t.Fatalf("expected 2 reviewers, got %d", len(reviewers))
+	}
+
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i := 0; i < 8; i++ {
+		for _, old := range reviewers {
+			wg.Add(1)
+			go func(old string) {
+				defer wg.Done()
+				<-start
+				resp, body := postJSON(t, server.URL+"/pullRequest/reassign",
+					map[string]any{"pull_request_id": prID, "old_user_id": old}, nil)
+				if resp == nil {
+					return
+				}
+				// проигравшие гонку получают осмысленный конфликт, а не 500
+				if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusConflict {
+					t.Errorf("reassign: unexpected status %d: %v", resp.StatusCode, body)
+				}
+			}(old)
+		}
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-start
+		resp, body := postJSON(t, server.URL+"/pullRequest/merge", map[string]any{"pull_request_id": prID}, nil)
+		if resp != nil && resp.StatusCode != http.StatusOK {
+			t.Errorf("merge: unexpected status %d: %v", resp.StatusCode, body)
+		}
+	}()
+	close(start)
+	wg.Wait()
+
+	resp, err := http.Get(server.URL + "/pullRequest/history?pull_request_id=" + prID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var history struct {
+		Events []struct {
+			Type      string `json:"event_type"`
+			UserID    string `json:"user_id"`
+			OldUserID string `json:"old_user_id"`
+		} `json:"events"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&history); err != nil {
+		t.Fatal(err)
+	}
+
+	// восстанавливаем состав ревьюверов по истории
+	assigned := map[string]bool{}
+	merged := false
+	for _, e := range history.Events {
+		switch e.Type {
+		case "REVIEWER_ASSIGNED":
+			assigned[e.UserID] = true
+		case "REVIEWER_REASSIGNED":
+			if merged {
+				t.Fatalf("reviewer %s assigned after merge", e.UserID)
+			}
+			if !assigned[e.OldUserID] {
+				t.Fatalf("replaced reviewer %s was not assigned", e.OldUserID)
+			}
+			if assigned[e.UserID] {
+				t.Fatalf("reviewer %s assigned twice", e.UserID)
+			}
+			delete(assigned, e.OldUserID)
+			assigned[e.UserID] = true
+		case "MERGED":
+			merged = true
+		}
+	}
+	if !merged {
+		t.Fatalf("expected PR to be merged")
+	}
+	if len(assigned) != 2 {
+		t.Fatalf("expected 2 reviewers after races, got %d", len(assigned))
+	}
+}
+
+// Изменение с устаревшим If-Match отклоняется с 412
+func TestIfMatchPrecondition(t *testing.T) {
+	server := setupTestServer(t)
+	defer server.Close()
+
+	prID, reviewers := createTeamWithPR(t, server.URL, 4)
+
+	resp, _ := postJSON(t, server.URL+"/pullRequest/reassign",
+		map[string]any{"pull_request_id": prID, "old_user_id": reviewers[0]},
+		http.Header{"If-Match": {`"1"`}})
+	if resp == nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for matching If-Match, got %v", resp)
+	}
+	if etag := resp.Header.Get("ETag"); etag != `"2"` {
+		t.Fatalf("expected ETag \"2\" after reassign, got %q", etag)
+	}
+
+	// клиент не видел переназначения и мержит по старой версии
+	resp, body := postJSON(t, server.URL+"/pullRequest/merge",
+		map[string]any{"pull_request_id": prID},
+		http.Header{"If-Match": {`"1"`}})
+	if resp == nil || resp.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 for stale If-Match, got %v", resp)
+	}
+	if code := body["error"].(map[string]any)["code"]; code != "PRECONDITION_FAILED" {
+		t.Fatalf("expected PRECONDITION_FAILED, got %v", code)
+	}
+
+	resp, _ = postJSON(t, server.URL+"/pullRequest/merge",
+		map[string]any{"pull_request_id": prID},
+		http.Header{"If-Match": {`"2"`}})
+	if resp == nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for current If-Match, got %v", resp)
+	}
+}
@@ -0,0 +1,424 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/auth"
+	httpapi "github.com/Olzerq/avito-pr-reviewer/internal/http"
+	"github.com/Olzerq/avito-pr-reviewer/internal/ratelimit"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	conformanceAdminToken = "conformance-admin"
+	conformanceJWTSecret  = "conformance-secret"
+
+	// inUsePrefix ключи идемпотентности, запрос по которым всегда "еще выполняется"
+	inUsePrefix = "in-use-"
+)
+
+// inUseStore позволяет получить 409 IDEMPOTENCY_KEY_IN_USE без гонки запросов
+type inUseStore struct {
+	httpapi.IdempotencyStore
+}
+
+func (s inUseStore) Claim(ctx context.Context, scope, key string, rec repository.IdempotencyRecord, ttl, lockTimeout time.Duration) (bool, repository.IdempotencyRecord, error) {
+	if strings.HasPrefix(key, inUsePrefix) {
+		return false, rec, nil
+	}
+	return s.IdempotencyStore.Claim(ctx, scope, key, rec, ttl, lockTimeout)
+}
+
+// setupConformanceServer поднимает v1 со всеми middleware из main. Валидатор стоит
+// первым и в строгом режиме, поэтому любой ответ, не описанный в openapi.yml,
+// включая 401/429/503 из middleware, превращается в 500.
+func setupConformanceServer(t *testing.T, limit ratelimit.Limit, inFlight *ratelimit.ConcurrencyLimiter, extra func(chi.Router)) (*httptest.Server, *auth.Authenticator) {
+	t.Helper()
+
+	storage := setupStorage(t)
+	teamService := service.NewTeamService(storage.Teams)
+	userService := service.NewUserService(storage.Users)
+	prService := service.NewPullRequestService(storage.Tx, storage.PullRequests, storage.Users, nil, nil)
+	statsService := service.NewStatsService(storage.Stats)
+
+	v1Handler := httpapi.NewV1Handler(
+		httpapi.NewTeamHandler(teamService),
+		httpapi.NewUserHandler(userService),
+		httpapi.NewPullRequestHandler(prService),
+		httpapi.NewStatsHandler(statsService),
+		httpapi.NewReviewStreamHandler(prService, testSSEHeartbeat),
+	)
+	v2Handler := httpapi.NewV2Handler(teamService, userService, prService, statsService)
+	authenticator := auth.NewAuthenticator([]string{conformanceAdminToken}, conformanceJWTSecret, "", "", userService)
+
+	validator, err := httpapi.OpenAPIValidator(httpapi.OpenAPIValidatorOptions{Responses: true, Strict: true})
+	if err != nil {
+		t.Fatalf("failed to load openapi spec: %v", err)
+	}
+
+	r := chi.NewRouter()
+	r.NotFound(httpapi.NotFound)
+	r.MethodNotAllowed(httpapi.MethodNotAllowed)
+	r.Group(func(r chi.Router) {
+		r.Use(validator)
+		r.Use(httpapi.RateLimit(ratelimit.New(limit, nil), false))
+		r.Use(httpapi.Concurrency(inFlight))
+		r.Use(httpapi.Authenticate(authenticator))
+		r.Use(httpapi.Idempotency(inUseStore{storage.Idempotency}, time.Hour, time.Minute))
+		v1Handler.Routes(r)
+		r.Route("/v2", v2Handler.Routes)
+		if extra != nil {
+			extra(r)
+		}
+	})
+
+	return httptest.NewServer(r), authenticator
+}
+
+type conformanceCase struct {
+	op     string // operationId из openapi.yml
+	status int
+	method string
+	path   string
+	body   any
+	header http.Header
+}
+
+// conformanceRun выполняет запросы и запоминает, какие ответы каждой операции получены
+type conformanceRun struct {
+	t         *testing.T
+	url       string
+	covered   map[string]map[int]bool
+	templates map[string]conformanceCase // первый успешный запрос операции
+}
+
+func (c *conformanceRun) run(tc conformanceCase) map[string]any {
+	c.t.Helper()
+
+	resp, body := doJSON(c.t, tc.method, c.url+tc.path, tc.body, tc.header)
+	if resp.StatusCode != tc.status {
+		c.t.Errorf("%s %s %s: expected %d got %d: %v", tc.op, tc.method, tc.path, tc.status, resp.StatusCode, body)
+		return body
+	}
+	if c.covered[tc.op] == nil {
+		c.covered[tc.op] = make(map[int]bool)
+	}
+	c.covered[tc.op][tc.status] = true
+	if _, ok := c.templates[tc.op]; !ok && tc.status < http.StatusBadRequest {
+		c.templates[tc.op] = tc
+	}
+	return body
+}
+
+// runStream как run для операции, отвечающей потоком text/event-stream: тело не дочитывается,
+// поток закрывается сразу после заголовков ответа
+func (c *conformanceRun) runStream(tc conformanceCase) {
+	c.t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, tc.method, c.url+tc.path, nil)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	req.Header = tc.header.Clone()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != tc.status || resp.Header.Get("Content-Type") != "text/event-stream" {
+		c.t.Errorf("%s %s %s: expected %d event stream got %d %s", tc.op, tc.method, tc.path, tc.status,
+			resp.StatusCode, resp.Header.Get("Content-Type"))
+		return
+	}
+	if c.covered[tc.op] == nil {
+		c.covered[tc.op] = make(map[int]bool)
+	}
+	c.covered[tc.op][tc.status] = true
+	if _, ok := c.templates[tc.op]; !ok {
+		c.templates[tc.op] = tc
+	}
+}
+
+// with заголовки h, дополненные парами ключ-значение
+func with(h http.Header, kv ...string) http.Header {
+	out := h.Clone()
+	if out == nil {
+		out = make(http.Header)
+	}
+	for i := 0; i+1 < len(kv); i += 2 {
+		out.Set(kv[i], kv[i+1])
+	}
+	return out
+}
+
+func TestOpenAPIConformance(t *testing.T) {
+	// покрытие сверяется с исходной спецификацией, а не с копией в сгенерированном коде
+	doc, err := openapi3.NewLoader().LoadFromFile("../../openapi.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server, authenticator := setupConformanceServer(t,
+		ratelimit.Limit{RPS: 1000, Burst: 1000}, ratelimit.NewConcurrencyLimiter(100, time.Second), nil)
+	defer server.Close()
+
+	c := &conformanceRun{t: t, url: server.URL, covered: map[string]map[int]bool{}, templates: map[string]conformanceCase{}}
+
+	p := t.Name() + "_"
+	team, other := p+"team", p+"other"
+	author, member := p+"author", p+"member"
+	missing := p + "missing"
+
+	admin := http.Header{"Authorization": {"Bearer " + conformanceAdminToken}}
+	memberToken, err := authenticator.Issue(member, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asMember := http.Header{"Authorization": {"Bearer " + memberToken}}
+	badVersion := `"999"`
+
+	teamBody := func(name string, ids ...string) map[string]any {
+		members := make([]map[string]any, 0, len(ids))
+		for _, id := range ids {
+			members = append(members, map[string]any{"user_id": id, "username": id, "is_active": true})
+		}
+		return map[string]any{"team_name": name, "members": members}
+	}
+	prBody := func(id, authorID string) map[string]any {
+		return map[string]any{"pull_request_id": id, "pull_request_name": id, "author_id": authorID}
+	}
+
+	// createTeam
+	c.run(conformanceCase{"createTeam", 201, "POST", "/team/add", teamBody(team, author, member, p+"r1", p+"r2", p+"r3"), admin})
+	c.run(conformanceCase{"createTeam", 201, "POST", "/team/add", teamBody(other, p+"o1"), admin})
+	c.run(conformanceCase{"createTeam", 400, "POST", "/team/add", map[string]any{"team_name": p + "nomembers"}, admin})
+	c.run(conformanceCase{"createTeam", 401, "POST", "/team/add", teamBody(p + "anon"), nil})
+	c.run(conformanceCase{"createTeam", 403, "POST", "/team/add", teamBody(p+"byMember", p+"x1"), asMember})
+	c.run(conformanceCase{"createTeam", 409, "POST", "/team/add", teamBody(team, author), admin})
+	c.run(conformanceCase{"createTeam", 201, "POST", "/team/add", teamBody(p+"k1", p+"k1u"), with(admin, "Idempotency-Key", p+"team")})
+	c.run(conformanceCase{"createTeam", 422, "POST", "/team/add", teamBody(p+"k2", p+"k2u"), with(admin, "Idempotency-Key", p+"team")})
+
+	// getTeam
+	c.run(conformanceCase{"getTeam", 200, "GET", "/team/get?team_name=" + team, nil, admin})
+	c.run(conformanceCase{"getTeam", 400, "GET", "/team/get", nil, admin})
+	c.run(conformanceCase{"getTeam", 401, "GET", "/team/get?team_name=" + team, nil, nil})
+	c.run(conformanceCase{"getTeam", 403, "GET", "/team/get?team_name=" + other, nil, asMember})
+	c.run(conformanceCase{"getTeam", 404, "GET", "/team/get?team_name=" + missing, nil, admin})
+
+	// setUserIsActive
+	active := func(id string) map[string]any { return map[string]any{"user_id": id, "is_active": true} }
+	c.run(conformanceCase{"setUserIsActive", 200, "POST", "/users/setIsActive", active(p + "r3"), admin})
+	c.run(conformanceCase{"setUserIsActive", 400, "POST", "/users/setIsActive", map[string]any{"user_id": p + "r3"}, admin})
+	c.run(conformanceCase{"setUserIsActive", 401, "POST", "/users/setIsActive", active(p + "r3"), nil})
+	c.run(conformanceCase{"setUserIsActive", 403, "POST", "/users/setIsActive", active(p + "r3"), asMember})
+	c.run(conformanceCase{"setUserIsActive", 404, "POST", "/users/setIsActive", active(missing), with(admin, "Idempotency-Key", p+"active")})
+	c.run(conformanceCase{"setUserIsActive", 422, "POST", "/users/setIsActive", active(missing + "2"), with(admin, "Idempotency-Key", p+"active")})
+	c.run(conformanceCase{"setUserIsActive", 409, "POST", "/users/setIsActive", active(p + "r3"), with(admin, "Idempotency-Key", inUsePrefix+p)})
+
+	// setUserRole
+	role := func(id, r string) map[string]any { return map[string]any{"user_id": id, "role": r} }
+	c.run(conformanceCase{"setUserRole", 200, "POST", "/users/setRole", role(p+"r3", "member"), admin})
+	c.run(conformanceCase{"setUserRole", 400, "POST", "/users/setRole", role(p+"r3", "boss"), admin})
+	c.run(conformanceCase{"setUserRole", 401, "POST", "/users/setRole", role(p+"r3", "member"), nil})
+	c.run(conformanceCase{"setUserRole", 403, "POST", "/users/setRole", role(member, "admin"), asMember})
+	c.run(conformanceCase{"setUserRole", 404, "POST", "/users/setRole", role(missing, "member"), with(admin, "Idempotency-Key", p+"role")})
+	c.run(conformanceCase{"setUserRole", 422, "POST", "/users/setRole", role(missing+"2", "member"), with(admin, "Idempotency-Key", p+"role")})
+	c.run(conformanceCase{"setUserRole", 409, "POST", "/users/setRole", role(p+"r3", "member"), with(admin, "Idempotency-Key", inUsePrefix+p)})
+
+	// createPullRequest
+	pr1, pr2 := p+"pr1", p+"pr2"
+	body := c.run(conformanceCase{"createPullRequest", 201, "POST", "/pullRequest/create", prBody(pr1, author), admin})
+	c.run(conformanceCase{"createPullRequest", 201, "POST", "/pullRequest/create", prBody(pr2, author), admin})
+	otherPR := p + "pr_other"
+	c.run(conformanceCase{"createPullRequest", 201, "POST", "/pullRequest/create", prBody(otherPR, p+"o1"), admin})
+	c.run(conformanceCase{"createPullRequest", 400, "POST", "/pullRequest/create", map[string]any{"pull_request_id": p + "pr3"}, admin})
+	c.run(conformanceCase{"createPullRequest", 401, "POST", "/pullRequest/create", prBody(p+"pr3", author), nil})
+	c.run(conformanceCase{"createPullRequest", 403, "POST", "/pullRequest/create", prBody(p+"pr3", author), asMember})
+	c.run(conformanceCase{"createPullRequest", 409, "POST", "/pullRequest/create", prBody(pr1, author), admin})
+	c.run(conformanceCase{"createPullRequest", 404, "POST", "/pullRequest/create", prBody(p+"pr3", missing), with(admin, "Idempotency-Key", p+"create")})
+	c.run(conformanceCase{"createPullRequest", 422, "POST", "/pullRequest/create", prBody(p+"pr4", missing), with(admin, "Idempotency-Key", p+"create")})
+
+	// ревьювер pr1, которого member переназначить не может
+	var reviewer string
+	pr, _ := body["pr"].(map[string]any)
+	reviewers, _ := pr["assigned_reviewers"].([]any)
+	for _, r := range reviewers {
+		if id, _ := r.(string); id != member {
+			reviewer = id
+			break
+		}
+	}
+	if reviewer == "" {
+		t.Fatalf("pr1 has no reviewer except member: %v", body)
+	}
+
+	// reassignReviewer
+	reassign := func(prID, old string) map[string]any {
+		return map[string]any{"pull_request_id": prID, "old_user_id": old}
+	}
+	c.run(conformanceCase{"reassignReviewer", 400, "POST", "/pullRequest/reassign", map[string]any{"pull_request_id": pr1}, admin})
+	c.run(conformanceCase{"reassignReviewer", 401, "POST", "/pullRequest/reassign", reassign(pr1, reviewer), nil})
+	c.run(conformanceCase{"reassignReviewer", 403, "POST", "/pullRequest/reassign", reassign(pr1, reviewer), asMember})
+	c.run(conformanceCase{"reassignReviewer", 412, "POST", "/pullRequest/reassign", reassign(pr1, reviewer), with(admin, "If-Match", badVersion)})
+	c.run(conformanceCase{"reassignReviewer", 409, "POST", "/pullRequest/reassign", reassign(pr1, reviewer), with(admin, "Idempotency-Key", inUsePrefix+p)})
+	c.run(conformanceCase{"reassignReviewer", 200, "POST", "/pullRequest/reassign", reassign(pr1, reviewer), admin})
+	c.run(conformanceCase{"reassignReviewer", 404, "POST", "/pullRequest/reassign", reassign(missing, reviewer), with(admin, "Idempotency-Key", p+"reassign")})
+	c.run(conformanceCase{"reassignReviewer", 422, "POST", "/pullRequest/reassign", reassign(missing+"2", reviewer), with(admin, "Idempotency-Key", p+"reassign")})
+
+	// mergePullRequest
+	merge := func(prID string) map[string]any { return map[string]any{"pull_request_id": prID} }
+	c.run(conformanceCase{"mergePullRequest", 400, "POST", "/pullRequest/merge", map[string]any{}, admin})
+	c.run(conformanceCase{"mergePullRequest", 401, "POST", "/pullRequest/merge", merge(pr2), nil})
+	c.run(conformanceCase{"mergePullRequest", 403, "POST", "/pullRequest/merge", merge(pr2), asMember})
+	c.run(conformanceCase{"mergePullRequest", 412, "POST", "/pullRequest/merge", merge(pr2), with(admin, "If-Match", badVersion)})
+	c.run(conformanceCase{"mergePullRequest", 409, "POST", "/pullRequest/merge", merge(pr2), with(admin, "Idempotency-Key", inUsePrefix+p)})
+	c.run(conformanceCase{"mergePullRequest", 200, "POST", "/pullRequest/merge", merge(pr2), admin})
+	c.run(conformanceCase{"mergePullRequest", 404, "POST", "/pullRequest/merge", merge(missing), with(admin, "Idempotency-Key", p+"merge")})
+	c.run(conformanceCase{"mergePullRequest", 422, "POST", "/pullRequest/merge", merge(missing + "2"), with(admin, "Idempotency-Key", p+"merge")})
+
+	// batchCreatePullRequests
+	batchCreate := func(mode string, ids ...string) map[string]any {
+		items := make([]map[string]any, 0, len(ids))
+		for _, id := range ids {
+			items = append(items, prBody(id, author))
+		}
+		return map[string]any{"mode": mode, "items": items}
+	}
+	c.run(conformanceCase{"batchCreatePullRequests", 200, "POST", "/pullRequest/batchCreate", batchCreate("best_effort", pr1, p+"pr5"), admin})
+	c.run(conformanceCase{"batchCreatePullRequests", 400, "POST", "/pullRequest/batchCreate", batchCreate("atomic"), admin})
+	c.run(conformanceCase{"batchCreatePullRequests", 401, "POST", "/pullRequest/batchCreate", batchCreate("atomic", p+"pr6"), nil})
+	c.run(conformanceCase{"batchCreatePullRequests", 403, "POST", "/pullRequest/batchCreate", batchCreate("atomic", p+"pr6"), asMember})
+	c.run(conformanceCase{"batchCreatePullRequests", 409, "POST", "/pullRequest/batchCreate", batchCreate("atomic", p+"pr6"), with(admin, "Idempotency-Key", inUsePrefix+p)})
+	c.run(conformanceCase{"batchCreatePullRequests", 200, "POST", "/pullRequest/batchCreate", batchCreate("atomic", pr1), with(admin, "Idempotency-Key", p+"batchCreate")})
+	c.run(conformanceCase{"batchCreatePullRequests", 422, "POST", "/pullRequest/batchCreate", batchCreate("atomic", pr2), with(admin, "Idempotency-Key", p+"batchCreate")})
+
+	// batchMergePullRequests
+	batchMerge := func(ids ...string) map[string]any { return map[string]any{"pull_request_ids": ids} }
+	c.run(conformanceCase{"batchMergePullRequests", 200, "POST", "/pullRequest/batchMerge", batchMerge(p+"pr5", missing), admin})
+	c.run(conformanceCase{"batchMergePullRequests", 400, "POST", "/pullRequest/batchMerge", batchMerge(pr2, pr2), admin})
+	c.run(conformanceCase{"batchMergePullRequests", 401, "POST", "/pullRequest/batchMerge", batchMerge(pr2), nil})
+	c.run(conformanceCase{"batchMergePullRequests", 403, "POST", "/pullRequest/batchMerge", batchMerge(pr2), asMember})
+	c.run(conformanceCase{"batchMergePullRequests", 409, "POST", "/pullRequest/batchMerge", batchMerge(pr2), with(admin, "Idempotency-Key", inUsePrefix+p)})
+	c.run(conformanceCase{"batchMergePullRequests", 200, "POST", "/pullRequest/batchMerge", batchMerge(pr2), with(admin, "Idempotency-Key", p+"batchMerge")})
+	c.run(conformanceCase{"batchMergePullRequests", 422, "POST", "/pullRequest/batchMerge", batchMerge(pr1), with(admin, "Idempotency-Key", p+"batchMerge")})
+
+	// getPullRequestHistory
+	c.run(conformanceCase{"getPullRequestHistory", 200, "GET", "/pullRequest/history?pull_request_id=" + pr1, nil, admin})
+	c.run(conformanceCase{"getPullRequestHistory", 400, "GET", "/pullRequest/history", nil, admin})
+	c.run(conformanceCase{"getPullRequestHistory", 401, "GET", "/pullRequest/history?pull_request_id=" + pr1, nil, nil})
+	c.run(conformanceCase{"getPullRequestHistory", 403, "GET", "/pullRequest/history?pull_request_id=" + otherPR, nil, asMember})
+	c.run(conformanceCase{"getPullRequestHistory", 404, "GET", "/pullRequest/history?pull_request_id=" + missing, nil, admin})
+
+	// getUserReviews
+	c.run(conformanceCase{"getUserReviews", 200, "GET", "/users/getReview?user_id=" + reviewer, nil, admin})
+	c.run(conformanceCase{"getUserReviews", 400, "GET", "/users/getReview?user_id=" + reviewer + "&status=DRAFT", nil, admin})
+	c.run(conformanceCase{"getUserReviews", 401, "GET", "/users/getReview?user_id=" + reviewer, nil, nil})
+	c.run(conformanceCase{"getUserReviews", 403, "GET", "/users/getReview?user_id=" + reviewer, nil, asMember})
+	c.run(conformanceCase{"getUserReviews", 404, "GET", "/users/getReview?user_id=" + missing, nil, admin})
+
+	// streamUserReviews
+	stream := "/users/reviews/stream?user_id=" + reviewer
+	c.runStream(conformanceCase{"streamUserReviews", 200, "GET", stream, nil, admin})
+	c.run(conformanceCase{"streamUserReviews", 400, "GET", stream, nil, with(admin, "Last-Event-ID", "latest")})
+	c.run(conformanceCase{"streamUserReviews", 401, "GET", stream, nil, nil})
+	c.run(conformanceCase{"streamUserReviews", 403, "GET", stream, nil, asMember})
+	c.run(conformanceCase{"streamUserReviews", 404, "GET", "/users/reviews/stream?user_id=" + missing, nil, admin})
+
+	// listPullRequests
+	c.run(conformanceCase{"listPullRequests", 200, "GET", "/pullRequests?team_name=" + team + "&limit=1", nil, admin})
+	c.run(conformanceCase{"listPullRequests", 400, "GET", "/pullRequests?limit=x", nil, admin})
+	c.run(conformanceCase{"listPullRequests", 401, "GET", "/pullRequests", nil, nil})
+	c.run(conformanceCase{"listPullRequests", 403, "GET", "/pullRequests?team_name=" + other, nil, asMember})
+
+	// listTeams, listUsers
+	c.run(conformanceCase{"listTeams", 200, "GET", "/teams?name=" + p, nil, admin})
+	c.run(conformanceCase{"listTeams", 400, "GET", "/teams?limit=0", nil, admin})
+	c.run(conformanceCase{"listTeams", 401, "GET", "/teams", nil, nil})
+	c.run(conformanceCase{"listUsers", 200, "GET", "/users?team_name=" + team + "&is_active=true", nil, admin})
+	c.run(conformanceCase{"listUsers", 400, "GET", "/users?is_active=maybe", nil, admin})
+	c.run(conformanceCase{"listUsers", 401, "GET", "/users", nil, nil})
+	c.run(conformanceCase{"listUsers", 403, "GET", "/users?team_name=" + other, nil, asMember})
+
+	// getAssignmentStats
+	c.run(conformanceCase{"getAssignmentStats", 200, "GET", "/stats/assignments", nil, admin})
+	c.run(conformanceCase{"getAssignmentStats", 401, "GET", "/stats/assignments", nil, nil})
+
+	// 503 и 429 на отдельном сервере: бюджет в один запрос на токен и один слот,
+	// который занят висящим запросом
+	runOverloaded(t, c)
+
+	for path, item := range doc.Paths.Map() {
+		for method, op := range item.Operations() {
+			for code := range op.Responses.Map() {
+				status, err := strconv.Atoi(code)
+				if err != nil {
+					continue
+				}
+				if !c.covered[op.OperationID][status] {
+					t.Errorf("%s %s %s: documented response %d is not covered", op.OperationID, method, path, status)
+				}
+			}
+		}
+	}
+	for op, codes := range c.covered {
+		var got []int
+		for code := range codes {
+			got = append(got, code)
+		}
+		sort.Ints(got)
+		t.Logf("%s: %v", op, got)
+	}
+}
+
+func runOverloaded(t *testing.T, c *conformanceRun) {
+	t.Helper()
+
+	held, release := make(chan struct{}), make(chan struct{})
+	server, _ := setupConformanceServer(t,
+		ratelimit.Limit{RPS: 0.001, Burst: 1}, ratelimit.NewConcurrencyLimiter(1, 0),
+		func(r chi.Router) {
+			r.Get("/test/hold", func(w http.ResponseWriter, r *http.Request) {
+				close(held)
+				<-release
+			})
+		})
+	defer server.Close()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/test/hold", nil)
+		req.Header.Set("Authorization", "Bearer "+conformanceAdminToken)
+		if resp, err := http.DefaultClient.Do(req); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-held
+	defer wg.Wait()
+	defer close(release)
+
+	limited := &conformanceRun{t: t, url: server.URL, covered: c.covered, templates: map[string]conformanceCase{}}
+	for op, tc := range c.templates {
+		// у каждой операции свой токен, а значит и свой бюджет
+		tc.header = with(tc.header, "Authorization", "Bearer limited-"+op)
+		tc.status = http.StatusServiceUnavailable
+		limited.run(tc)
+		tc.status = http.StatusTooManyRequests
+		limited.run(tc)
+	}
+}
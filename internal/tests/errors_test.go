@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// fieldRules пары field/rule из details ошибки валидации
+func fieldRules(t *testing.T, body map[string]any, key string) map[string]string {
+	t.Helper()
+	details, _ := body[key].([]any)
+	rules := make(map[string]string, len(details))
+	for _, d := range details {
+		m, _ := d.(map[string]any)
+		field, _ := m["field"].(string)
+		rule, _ := m["rule"].(string)
+		rules[field] = rule
+	}
+	return rules
+}
+
+func TestErrorModel(t *testing.T) {
+	server := setupTestServer(t)
+	defer server.Close()
+
+	prefix := t.Name() + "_"
+	team := prefix + "team"
+
+	// все нарушения в составе команды перечислены по полям
+	resp, body := doJSON(t, http.MethodPost, server.URL+"/team/add", map[string]any{
+		"team_name": team,
+		"members": []map[string]any{
+			{"user_id": prefix + "u1", "username": "Alice", "is_active": true},
+			{"user_id": prefix + "u1", "username": "", "is_active": true},
+		},
+	}, nil)
+	expectStatus(t, "invalid team", resp, body, http.StatusBadRequest, "BAD_REQUEST")
+	errBody, _ := body["error"].(map[string]any)
+	rules := fieldRules(t, errBody, "details")
+	if rules["members[1].user_id"] != "unique" || rules["members[1].username"] != "required" {
+		t.Fatalf("invalid team: unexpected details %v", errBody["details"])
+	}
+
+	// конфликт с существующей командой одинаков в v1 и v2
+	teamBody := map[string]any{
+		"team_name": team,
+		"members":   []map[string]any{{"user_id": prefix + "u1", "username": "Alice", "is_active": true}},
+	}
+	resp, body = doJSON(t, http.MethodPost, server.URL+"/team/add", teamBody, nil)
+	expectStatus(t, "create team", resp, body, http.StatusCreated, "")
+	resp, body = doJSON(t, http.MethodPost, server.URL+"/team/add", teamBody, nil)
+	expectStatus(t, "duplicate team v1", resp, body, http.StatusConflict, "TEAM_EXISTS")
+
+	// битый JSON в v1 тоже отдается в общем формате
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/pullRequest/create", strings.NewReader("{"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := raw.Header.Get("Content-Type"); raw.StatusCode != http.StatusBadRequest || ct != "application/json" {
+		t.Fatalf("invalid json: expected 400 application/json, got %d %s", raw.StatusCode, ct)
+	}
+	raw.Body.Close()
+
+	// значение не того типа указывает на поле
+	resp, body = doJSON(t, http.MethodPost, server.URL+"/v2/pull-requests", map[string]any{
+		"pull_request_id": 1,
+	}, nil)
+	expectStatus(t, "wrong type", resp, body, http.StatusBadRequest, "BAD_REQUEST")
+	errBody, _ = body["error"].(map[string]any)
+	if rules := fieldRules(t, errBody, "details"); rules["pull_request_id"] != "type" {
+		t.Fatalf("wrong type: unexpected details %v", errBody["details"])
+	}
+
+	// RFC 7807 по запросу клиента
+	resp, body = doJSON(t, http.MethodGet, server.URL+"/pullRequests?limit=x&status=DRAFT", nil,
+		http.Header{"Accept": {"application/problem+json"}})
+	if ct := resp.Header.Get("Content-Type"); ct != "application/problem+json" {
+		t.Fatalf("problem: unexpected Content-Type %q", ct)
+	}
+	if body["status"] != float64(http.StatusBadRequest) || body["code"] != "BAD_REQUEST" {
+		t.Fatalf("problem: unexpected body %v", body)
+	}
+	if rules := fieldRules(t, body, "errors"); rules["limit"] != "format" {
+		t.Fatalf("problem: unexpected errors %v", body["errors"])
+	}
+
+	// неизвестный маршрут
+	resp, body = doJSON(t, http.MethodGet, server.URL+"/no/such/route", nil, nil)
+	expectStatus(t, "unknown route", resp, body, http.StatusNotFound, "NOT_FOUND")
+}
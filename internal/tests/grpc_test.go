@@ -0,0 +1,472 @@
+package tests
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/auth"
+	grpcapi "github.com/Olzerq/avito-pr-reviewer/internal/grpc"
+	"github.com/Olzerq/avito-pr-reviewer/internal/grpc/pb"
+	httpapi "github.com/Olzerq/avito-pr-reviewer/internal/http"
+	"github.com/Olzerq/avito-pr-reviewer/internal/ratelimit"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"github.com/go-chi/chi/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const grpcAdminToken = "grpc-admin"
+
+type grpcClients struct {
+	teams pb.TeamServiceClient
+	users pb.UserServiceClient
+	prs   pb.PullRequestServiceClient
+	stats pb.StatsServiceClient
+}
+
+// setupGRPCServer поднимает gRPC API в памяти процесса на хранилище из setupStorage
+func setupGRPCServer(t *testing.T) (grpcClients, *auth.Authenticator) {
+	t.Helper()
+	return setupGRPCServerWithLimits(t, grpcapi.Limits{})
+}
+
+// setupGRPCServerWithLimits то же, но с ограничителями нагрузки
+func setupGRPCServerWithLimits(t *testing.T, limits grpcapi.Limits) (grpcClients, *auth.Authenticator) {
+	t.Helper()
+
+	storage := setupStorage(t)
+	teamService := service.NewTeamService(storage.Teams)
+	userService := service.NewUserService(storage.Users)
+	prService := service.NewPullRequestService(storage.Tx, storage.PullRequests, storage.Users, nil, service.NewReviewFeed(nil))
+	statsService := service.NewStatsService(storage.Stats)
+	authenticator := auth.NewAuthenticator([]string{grpcAdminToken}, conformanceJWTSecret, "", "", userService)
+
+	srv := grpcapi.NewServer(authenticator, limits, teamService, userService, prService, statsService)
+	lis := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+	})
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial grpc server: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return grpcClients{
+		teams: pb.NewTeamServiceClient(conn),
+		users: pb.NewUserServiceClient(conn),
+		prs:   pb.NewPullRequestServiceClient(conn),
+		stats: pb.NewStatsServiceClient(conn),
+	}, authenticator
+}
+
+func withToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+// errorReason reason из google.rpc.ErrorInfo в деталях статуса
+func errorReason(t *testing.T, err error) string {
+	t.Helper()
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info.GetReason()
+		}
+	}
+	t.Fatalf("no ErrorInfo in status: %v", err)
+	return ""
+}
+
+func createGRPCTeam(t *testing.T, ctx context.Context, c grpcClients, name string, userIDs ...string) {
+	t.Helper()
+	team := &pb.Team{TeamName: name}
+	for _, id := range userIDs {
+		team.Members = append(team.Members, &pb.TeamMember{UserId: id, Username: id, IsActive: true})
+	}
+	if _, err := c.teams.CreateTeam(ctx, &pb.CreateTeamRequest{Team: team}); err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+}
+
+func TestGRPCFlow(t *testing.T) {
+	c, _ := setupGRPCServer(t)
+	ctx := withToken(context.Background(), grpcAdminToken)
+
+	p := t.Name() + "_"
+	team := p + "team"
+	author, r1, r2, r3 := p+"author", p+"r1", p+"r2", p+"r3"
+	createGRPCTeam(t, ctx, c, team, author, r1, r2, r3)
+
+	got, err := c.teams.GetTeam(ctx, &pb.GetTeamRequest{TeamName: team})
+	if err != nil {
+		t.Fatalf("GetTeam: %v", err)
+	}
+	if len(got.GetMembers()) != 4 {
+		t.Fatalf("expected 4 members, got %d", len(got.GetMembers()))
+	}
+
+	_, err = c.teams.CreateTeam(ctx, &pb.CreateTeamRequest{Team: got})
+	if status.Code(err) != codes.AlreadyExists || errorReason(t, err) != "TEAM_EXISTS" {
+		t.Fatalf("expected AlreadyExists TEAM_EXISTS, got %v", err)
+	}
+
+	// r3 неактивен и не должен попасть в ревьюверы
+	if _, err := c.users.SetUserIsActive(ctx, &pb.SetUserIsActiveRequest{UserId: r3, IsActive: false}); err != nil {
+		t.Fatalf("SetUserIsActive: %v", err)
+	}
+
+	prID := p + "pr"
+	pr, err := c.prs.CreatePullRequest(ctx, &pb.CreatePullRequestRequest{PullRequestId: prID, PullRequestName: "feature", AuthorId: author})
+	if err != nil {
+		t.Fatalf("CreatePullRequest: %v", err)
+	}
+	if pr.GetStatus() != pb.PullRequestStatus_PULL_REQUEST_STATUS_OPEN || len(pr.GetAssignedReviewers()) != 2 {
+		t.Fatalf("unexpected pull request: %v", pr)
+	}
+	if pr.GetCreatedAt() == nil || pr.GetMergedAt() != nil {
+		t.Fatalf("expected created_at without merged_at, got %v", pr)
+	}
+
+	_, err = c.prs.CreatePullRequest(ctx, &pb.CreatePullRequestRequest{PullRequestId: prID, PullRequestName: "feature", AuthorId: author})
+	if status.Code(err) != codes.AlreadyExists || errorReason(t, err) != "PR_EXISTS" {
+		t.Fatalf("expected AlreadyExists PR_EXISTS, got %v", err)
+	}
+
+	// в команде нет свободных активных кандидатов
+	_, err = c.prs.ReassignReviewer(ctx, &pb.ReassignReviewerRequest{PullRequestId: prID, OldUserId: pr.GetAssignedReviewers()[0]})
+	if status.Code(err) != codes.FailedPrecondition || errorReason(t, err) != "NO_CANDIDATE" {
+		t.Fatalf("expected FailedPrecondition NO_CANDIDATE, got %v", err)
+	}
+
+	if _, err := c.users.SetUserIsActive(ctx, &pb.SetUserIsActiveRequest{UserId: r3, IsActive: true}); err != nil {
+		t.Fatalf("SetUserIsActive: %v", err)
+	}
+
+	stale := pr.GetVersion() + 1
+	_, err = c.prs.ReassignReviewer(ctx, &pb.ReassignReviewerRequest{PullRequestId: prID, OldUserId: pr.GetAssignedReviewers()[0], ExpectedVersion: &stale})
+	if status.Code(err) != codes.Aborted || errorReason(t, err) != "PRECONDITION_FAILED" {
+		t.Fatalf("expected Aborted PRECONDITION_FAILED, got %v", err)
+	}
+
+	version := pr.GetVersion()
+	reassigned, err := c.prs.ReassignReviewer(ctx, &pb.ReassignReviewerRequest{PullRequestId: prID, OldUserId: pr.GetAssignedReviewers()[0], ExpectedVersion: &version})
+	if err != nil {
+		t.Fatalf("ReassignReviewer: %v", err)
+	}
+	if reassigned.GetReplacedBy() != r3 {
+		t.Fatalf("expected %s as new reviewer, got %s", r3, reassigned.GetReplacedBy())
+	}
+
+	merged, err := c.prs.MergePullRequest(ctx, &pb.MergePullRequestRequest{PullRequestId: prID})
+	if err != nil {
+		t.Fatalf("MergePullRequest: %v", err)
+	}
+	if merged.GetStatus() != pb.PullRequestStatus_PULL_REQUEST_STATUS_MERGED || merged.GetMergedAt() == nil {
+		t.Fatalf("expected merged pull request, got %v", merged)
+	}
+
+	_, err = c.prs.ReassignReviewer(ctx, &pb.ReassignReviewerRequest{PullRequestId: prID, OldUserId: r3})
+	if status.Code(err) != codes.FailedPrecondition || errorReason(t, err) != "PR_MERGED" {
+		t.Fatalf("expected FailedPrecondition PR_MERGED, got %v", err)
+	}
+
+	reviews, err := c.users.GetUserReviews(ctx, &pb.GetUserReviewsRequest{UserId: r3, Status: pb.PullRequestStatus_PULL_REQUEST_STATUS_MERGED})
+	if err != nil {
+		t.Fatalf("GetUserReviews: %v", err)
+	}
+	if len(reviews.GetPullRequests()) != 1 || reviews.GetPullRequests()[0].GetPullRequestId() != prID {
+		t.Fatalf("expected %s in reviews of %s, got %v", prID, r3, reviews.GetPullRequests())
+	}
+
+	stats, err := c.stats.GetAssignmentStats(ctx, &pb.GetAssignmentStatsRequest{})
+	if err != nil {
+		t.Fatalf("GetAssignmentStats: %v", err)
+	}
+	if len(stats.GetByPullRequest()) == 0 {
+		t.Fatal("expected assignment stats by pull request")
+	}
+
+	_, err = c.prs.GetPullRequest(ctx, &pb.GetPullRequestRequest{PullRequestId: p + "missing"})
+	if status.Code(err) != codes.NotFound || errorReason(t, err) != "NOT_FOUND" {
+		t.Fatalf("expected NotFound, got %v", err)
+	}
+}
+
+func TestGRPCValidationDetails(t *testing.T) {
+	c, _ := setupGRPCServer(t)
+	ctx := withToken(context.Background(), grpcAdminToken)
+
+	_, err := c.prs.CreatePullRequest(ctx, &pb.CreatePullRequestRequest{PullRequestName: "feature"})
+	if status.Code(err) != codes.InvalidArgument || errorReason(t, err) != "BAD_REQUEST" {
+		t.Fatalf("expected InvalidArgument BAD_REQUEST, got %v", err)
+	}
+
+	violations := map[string]string{}
+	for _, d := range status.Convert(err).Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			for _, v := range br.GetFieldViolations() {
+				violations[v.GetField()] = v.GetReason()
+			}
+		}
+	}
+	for _, field := range []string{"pull_request_id", "author_id"} {
+		if violations[field] != service.RuleRequired {
+			t.Errorf("expected %s violation %q, got %v", field, service.RuleRequired, violations)
+		}
+	}
+
+	_, err = c.users.ListUsers(ctx, &pb.ListUsersRequest{Page: &pb.PageRequest{Limit: 1000}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expected InvalidArgument for limit, got %v", err)
+	}
+}
+
+func TestGRPCAuth(t *testing.T) {
+	c, authenticator := setupGRPCServer(t)
+	admin := withToken(context.Background(), grpcAdminToken)
+
+	p := t.Name() + "_"
+	team := p + "team"
+	member := p + "member"
+	createGRPCTeam(t, admin, c, team, member, p+"other")
+
+	_, err := c.teams.GetTeam(context.Background(), &pb.GetTeamRequest{TeamName: team})
+	if status.Code(err) != codes.Unauthenticated || errorReason(t, err) != "UNAUTHORIZED" {
+		t.Fatalf("expected Unauthenticated without token, got %v", err)
+	}
+
+	token, err := authenticator.Issue(member, time.Hour)
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+	ctx := withToken(context.Background(), token)
+
+	if _, err := c.teams.GetTeam(ctx, &pb.GetTeamRequest{TeamName: team}); err != nil {
+		t.Fatalf("member GetTeam: %v", err)
+	}
+
+	_, err = c.users.SetUserRole(ctx, &pb.SetUserRoleRequest{UserId: member, Role: "admin"})
+	if status.Code(err) != codes.PermissionDenied || errorReason(t, err) != "FORBIDDEN" {
+		t.Fatalf("expected PermissionDenied for member, got %v", err)
+	}
+
+	// чужую очередь ревью участник не видит
+	stream, err := c.users.WatchReviewQueue(ctx, &pb.WatchReviewQueueRequest{UserId: p + "other"})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected PermissionDenied for foreign queue, got %v", err)
+	}
+}
+
+func TestGRPCWatchReviewQueue(t *testing.T) {
+	c, _ := setupGRPCServer(t)
+	ctx := withToken(context.Background(), grpcAdminToken)
+
+	p := t.Name() + "_"
+	author, reviewer, spare := p+"author", p+"reviewer", p+"spare"
+
+	// spare неактивен, поэтому единственный ревьювер новых PR - reviewer
+	_, err := c.teams.CreateTeam(ctx, &pb.CreateTeamRequest{Team: &pb.Team{TeamName: p + "team", Members: []*pb.TeamMember{
+		{UserId: author, Username: author, IsActive: true},
+		{UserId: reviewer, Username: reviewer, IsActive: true},
+		{UserId: spare, Username: spare, IsActive: false},
+	}}})
+	if err != nil {
+		t.Fatalf("CreateTeam: %v", err)
+	}
+
+	// PR до подписки попадает в снимок
+	first := p + "pr1"
+	if _, err := c.prs.CreatePullRequest(ctx, &pb.CreatePullRequestRequest{PullRequestId: first, PullRequestName: "first", AuthorId: author}); err != nil {
+		t.Fatalf("CreatePullRequest: %v", err)
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.users.WatchReviewQueue(streamCtx, &pb.WatchReviewQueueRequest{UserId: reviewer})
+	if err != nil {
+		t.Fatalf("WatchReviewQueue: %v", err)
+	}
+
+	recv := func(want pb.ReviewQueueEventType, prID string) *pb.ReviewQueueEvent {
+		t.Helper()
+		e, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		if e.GetType() != want || e.GetUserId() != reviewer || e.GetPullRequest().GetPullRequestId() != prID {
+			t.Fatalf("expected %s of %q for %s, got %v", want, prID, reviewer, e)
+		}
+		return e
+	}
+
+	snapshot := recv(pb.ReviewQueueEventType_REVIEW_QUEUE_EVENT_TYPE_SNAPSHOT, "")
+	if len(snapshot.GetQueue()) != 1 || snapshot.GetQueue()[0].GetPullRequestId() != first {
+		t.Fatalf("expected %s in snapshot, got %v", first, snapshot.GetQueue())
+	}
+
+	second := p + "pr2"
+	if _, err := c.prs.CreatePullRequest(ctx, &pb.CreatePullRequestRequest{PullRequestId: second, PullRequestName: "second", AuthorId: author}); err != nil {
+		t.Fatalf("CreatePullRequest: %v", err)
+	}
+	recv(pb.ReviewQueueEventType_REVIEW_QUEUE_EVENT_TYPE_ASSIGNED, second)
+
+	if _, err := c.prs.MergePullRequest(ctx, &pb.MergePullRequestRequest{PullRequestId: second}); err != nil {
+		t.Fatalf("MergePullRequest: %v", err)
+	}
+	e := recv(pb.ReviewQueueEventType_REVIEW_QUEUE_EVENT_TYPE_MERGED, second)
+	if e.GetPullRequest().GetStatus() != pb.PullRequestStatus_PULL_REQUEST_STATUS_MERGED {
+		t.Fatalf("expected merged pull request in event, got %v", e)
+	}
+
+	// повторный merge ничего не меняет и событий не дает
+	if _, err := c.prs.MergePullRequest(ctx, &pb.MergePullRequestRequest{PullRequestId: second}); err != nil {
+		t.Fatalf("MergePullRequest: %v", err)
+	}
+
+	if _, err := c.users.SetUserIsActive(ctx, &pb.SetUserIsActiveRequest{UserId: spare, IsActive: true}); err != nil {
+		t.Fatalf("SetUserIsActive: %v", err)
+	}
+	if _, err := c.prs.ReassignReviewer(ctx, &pb.ReassignReviewerRequest{PullRequestId: first, OldUserId: reviewer}); err != nil {
+		t.Fatalf("ReassignReviewer: %v", err)
+	}
+	unassigned := recv(pb.ReviewQueueEventType_REVIEW_QUEUE_EVENT_TYPE_UNASSIGNED, first)
+	if unassigned.GetEventId() <= e.GetEventId() || e.GetEventId() <= snapshot.GetEventId() {
+		t.Fatalf("expected increasing event ids, got %d, %d, %d", snapshot.GetEventId(), e.GetEventId(), unassigned.GetEventId())
+	}
+
+	// новый поток с last_event_id продолжает историю без снимка
+	cancel()
+	stream, err = c.users.WatchReviewQueue(ctx, &pb.WatchReviewQueueRequest{UserId: reviewer, LastEventId: e.GetEventId()})
+	if err != nil {
+		t.Fatalf("WatchReviewQueue resume: %v", err)
+	}
+	if resumed := recv(pb.ReviewQueueEventType_REVIEW_QUEUE_EVENT_TYPE_UNASSIGNED, first); resumed.GetEventId() != unassigned.GetEventId() {
+		t.Fatalf("expected resumed event %d, got %v", unassigned.GetEventId(), resumed)
+	}
+}
+
+// rateLimitedStatus проверяет отказ RESOURCE_EXHAUSTED с RetryInfo
+func rateLimitedStatus(t *testing.T, err error) {
+	t.Helper()
+	if status.Code(err) != codes.ResourceExhausted || errorReason(t, err) != "RATE_LIMITED" {
+		t.Fatalf("expected ResourceExhausted RATE_LIMITED, got %v", err)
+	}
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok && info.GetRetryDelay().AsDuration() > 0 {
+			return
+		}
+	}
+	t.Fatalf("no positive RetryInfo in status: %v", err)
+}
+
+// Вызовы gRPC и запросы HTTP одного клиента списываются с общих bucket'ов
+func TestGRPCRateLimitSharedWithHTTP(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Limit{RPS: 0.001, Burst: 2}, map[string]ratelimit.Limit{
+		"POST /pullRequest/create":                                    {RPS: 0.001, Burst: 1},
+		"GRPC " + pb.PullRequestService_GetPullRequest_FullMethodName: {RPS: 0.001, Burst: 1},
+	})
+	c, _ := setupGRPCServerWithLimits(t, grpcapi.Limits{Rate: limiter})
+
+	r := chi.NewRouter()
+	r.Use(httpapi.RateLimit(limiter, false))
+	ok := func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	r.Get("/team/get", ok)
+	r.Post("/pullRequest/create", ok)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+
+	// лимит проверяется до аутентификации, токены могут быть любыми
+	t.Run("default budget", func(t *testing.T) {
+		ctx := withToken(context.Background(), "alice")
+		resp, body := doJSON(t, http.MethodGet, server.URL+"/team/get", nil, with(nil, "Authorization", "Bearer alice"))
+		if resp.StatusCode != http.StatusNoContent {
+			t.Fatalf("expected 204, got %d: %v", resp.StatusCode, body)
+		}
+		if _, err := c.teams.GetTeam(ctx, &pb.GetTeamRequest{TeamName: "x"}); status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("expected Unauthenticated within budget, got %v", err)
+		}
+		_, err := c.teams.ListTeams(ctx, &pb.ListTeamsRequest{})
+		rateLimitedStatus(t, err)
+
+		// другой клиент считается отдельно
+		if _, err := c.teams.GetTeam(withToken(context.Background(), "bob"), &pb.GetTeamRequest{TeamName: "x"}); status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("other client must not be limited, got %v", err)
+		}
+	})
+
+	t.Run("route budget", func(t *testing.T) {
+		ctx := withToken(context.Background(), "carol")
+		_, err := c.prs.CreatePullRequest(ctx, &pb.CreatePullRequestRequest{})
+		if status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("expected Unauthenticated within budget, got %v", err)
+		}
+		resp, body := postJSON(t, server.URL+"/pullRequest/create", map[string]any{}, with(nil, "Authorization", "Bearer carol"))
+		expectRateLimited(t, resp, body)
+
+		// у остальных методов бюджет по умолчанию
+		if _, err := c.teams.GetTeam(ctx, &pb.GetTeamRequest{TeamName: "x"}); status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("expected Unauthenticated within default budget, got %v", err)
+		}
+
+		// метод без пары в v1 задается полным именем
+		if _, err := c.prs.GetPullRequest(ctx, &pb.GetPullRequestRequest{}); status.Code(err) != codes.Unauthenticated {
+			t.Fatalf("expected Unauthenticated within budget, got %v", err)
+		}
+		_, err = c.prs.GetPullRequest(ctx, &pb.GetPullRequestRequest{})
+		rateLimitedStatus(t, err)
+	})
+}
+
+// Слоты одновременных вызовов общие с HTTP, поток очереди ревью отдает свой после подписки
+func TestGRPCConcurrencyLimit(t *testing.T) {
+	inFlight := ratelimit.NewConcurrencyLimiter(1, 0)
+	c, _ := setupGRPCServerWithLimits(t, grpcapi.Limits{InFlight: inFlight})
+	admin := withToken(context.Background(), grpcAdminToken)
+
+	p := t.Name() + "_"
+	createGRPCTeam(t, admin, c, p+"team", p+"u1")
+
+	// слот занят запросом HTTP
+	if !inFlight.Acquire(context.Background()) {
+		t.Fatal("failed to acquire slot")
+	}
+	_, err := c.teams.GetTeam(admin, &pb.GetTeamRequest{TeamName: p + "team"})
+	if status.Code(err) != codes.Unavailable || errorReason(t, err) != "OVERLOADED" {
+		t.Fatalf("expected Unavailable OVERLOADED, got %v", err)
+	}
+	inFlight.Release()
+
+	ctx, cancel := context.WithCancel(admin)
+	defer cancel()
+	stream, err := c.users.WatchReviewQueue(ctx, &pb.WatchReviewQueueRequest{UserId: p + "u1"})
+	if err != nil {
+		t.Fatalf("WatchReviewQueue: %v", err)
+	}
+	if _, err := stream.Recv(); err != nil {
+		t.Fatalf("expected snapshot, got %v", err)
+	}
+	if _, err := c.teams.GetTeam(admin, &pb.GetTeamRequest{TeamName: p + "team"}); err != nil {
+		t.Fatalf("open stream must not hold a slot: %v", err)
+	}
+	if inFlight.InFlight() != 0 {
+		t.Fatalf("expected no slots in use, got %d", inFlight.InFlight())
+	}
+}
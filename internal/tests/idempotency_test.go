@@ -0,0 +1,206 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	httpapi "github.com/Olzerq/avito-pr-reviewer/internal/http"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// setupIdempotencyServer поднимает v2 за Idempotency с заданным таймаутом блокировки ключа,
+// аутентификация выключена, как при AUTH_ENABLED=false
+func setupIdempotencyServer(t *testing.T, lockTimeout time.Duration, extra func(chi.Router)) *httptest.Server {
+	t.Helper()
+
+	storage := setupStorage(t)
+	teamService := service.NewTeamService(storage.Teams)
+	userService := service.NewUserService(storage.Users)
+	prService := service.NewPullRequestService(storage.Tx, storage.PullRequests, storage.Users, nil, nil)
+	v2Handler := httpapi.NewV2Handler(teamService, userService, prService, service.NewStatsService(storage.Stats))
+
+	r := chi.NewRouter()
+	r.Group(func(r chi.Router) {
+		r.Use(httpapi.Authenticate(nil))
+		r.Use(httpapi.Idempotency(storage.Idempotency, time.Hour, lockTimeout))
+		r.Route("/v2", v2Handler.Routes)
+		if extra != nil {
+			extra(r)
+		}
+	})
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	server := setupIdempotencyServer(t, time.Minute, nil)
+
+	team := map[string]any{"team_name": "backend", "members": []map[string]any{
+		{"user_id": "u1", "username": "alice", "is_active": true},
+		{"user_id": "u2", "username": "bob", "is_active": true},
+	}}
+	if resp, body := postJSON(t, server.URL+"/v2/teams", team, nil); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("failed to create team: %v", body)
+	}
+
+	key := with(nil, "Idempotency-Key", "create-pr-1")
+	pr := map[string]any{"pull_request_id": "pr-1", "pull_request_name": "Add search", "author_id": "u1"}
+	first, firstBody := postJSON(t, server.URL+"/v2/pull-requests", pr, key)
+	if first.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %v", first.StatusCode, firstBody)
+	}
+	if first.Header.Get("ETag") == "" || first.Header.Get("Location") == "" {
+		t.Fatalf("create must return ETag and Location, got %v", first.Header)
+	}
+
+	// повтор отдает тот же ответ с теми же заголовками, а не 409 PR_EXISTS
+	replay, replayBody := postJSON(t, server.URL+"/v2/pull-requests", pr, key)
+	if replay.StatusCode != http.StatusCreated || !reflect.DeepEqual(replayBody, firstBody) {
+		t.Fatalf("expected replayed 201 %v, got %d %v", firstBody, replay.StatusCode, replayBody)
+	}
+	for _, h := range []string{"ETag", "Location", "Content-Type"} {
+		if got, want := replay.Header.Get(h), first.Header.Get(h); got != want {
+			t.Errorf("replayed %s: expected %q, got %q", h, want, got)
+		}
+	}
+	if replay.Header.Get(httpapi.IdempotencyReplayedHeader) != "true" {
+		t.Error("replayed response without Idempotency-Replayed header")
+	}
+
+	// тот же ключ со строкой запроса - другой запрос
+	resp, body := postJSON(t, server.URL+"/v2/pull-requests?dry_run=true", pr, key)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422 for another query string, got %d: %v", resp.StatusCode, body)
+	}
+	expectCode(t, body, "IDEMPOTENCY_KEY_REUSED")
+
+	// тот же ключ с другим телом
+	pr["pull_request_name"] = "Add search v2"
+	resp, body = postJSON(t, server.URL+"/v2/pull-requests", pr, key)
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("expected 422, got %d: %v", resp.StatusCode, body)
+	}
+	expectCode(t, body, "IDEMPOTENCY_KEY_REUSED")
+
+	// без аутентификации все клиенты - один субъект, но ключи у каждого свои
+	other := with(nil, "Idempotency-Key", "create-pr-1", "Authorization", "Bearer other-client")
+	if resp, body := postJSON(t, server.URL+"/v2/pull-requests", pr, other); resp.StatusCode == http.StatusUnprocessableEntity ||
+		resp.Header.Get(httpapi.IdempotencyReplayedHeader) != "" {
+		t.Fatalf("another client must not share the key, got %d: %v", resp.StatusCode, body)
+	}
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	var calls atomic.Int32
+	held, release := make(chan struct{}), make(chan struct{})
+	server := setupIdempotencyServer(t, time.Minute, func(r chi.Router) {
+		r.Post("/test/hold", func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				close(held)
+				<-release
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	})
+	key := with(nil, "Idempotency-Key", "hold")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		postJSON(t, server.URL+"/test/hold", map[string]any{}, key)
+	}()
+	<-held
+
+	resp, body := postJSON(t, server.URL+"/test/hold", map[string]any{}, key)
+	if resp.StatusCode != http.StatusConflict || resp.Header.Get("Retry-After") == "" {
+		t.Fatalf("expected 409 with Retry-After, got %d: %v", resp.StatusCode, body)
+	}
+	expectCode(t, body, "IDEMPOTENCY_KEY_IN_USE")
+
+	close(release)
+	wg.Wait()
+	if resp, _ := postJSON(t, server.URL+"/test/hold", map[string]any{}, key); resp.StatusCode != http.StatusNoContent ||
+		resp.Header.Get(httpapi.IdempotencyReplayedHeader) != "true" {
+		t.Fatalf("expected replayed 204, got %d", resp.StatusCode)
+	}
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("handler must run once, ran %d times", n)
+	}
+}
+
+func TestIdempotencyStaleLock(t *testing.T) {
+	// первый запрос завис (так же выглядит запрос упавшего процесса), повтор
+	// после таймаута блокировки выполняется заново вместо 409
+	var calls atomic.Int32
+	held, release := make(chan struct{}), make(chan struct{})
+	server := setupIdempotencyServer(t, 10*time.Millisecond, func(r chi.Router) {
+		r.Post("/test/hold", func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				close(held)
+				<-release
+				w.WriteHeader(http.StatusAccepted)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	})
+	key := with(nil, "Idempotency-Key", "stale")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		postJSON(t, server.URL+"/test/hold", map[string]any{}, key)
+	}()
+	<-held
+
+	time.Sleep(50 * time.Millisecond)
+	if resp, body := postJSON(t, server.URL+"/test/hold", map[string]any{}, key); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected stale lock to be taken over, got %d: %v", resp.StatusCode, body)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected handler to run again, ran %d times", n)
+	}
+
+	// зависший запрос завершается позже и не перезаписывает ответ повтора
+	close(release)
+	wg.Wait()
+	resp, _ := postJSON(t, server.URL+"/test/hold", map[string]any{}, key)
+	if resp.StatusCode != http.StatusNoContent || resp.Header.Get(httpapi.IdempotencyReplayedHeader) != "true" {
+		t.Fatalf("expected the retry's response to be replayed, got %d", resp.StatusCode)
+	}
+}
+
+func TestIdempotencyDoesNotStoreServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := setupIdempotencyServer(t, time.Minute, func(r chi.Router) {
+		r.Post("/test/flaky", func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) == 1 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	})
+	key := with(nil, "Idempotency-Key", "flaky")
+
+	if resp, _ := postJSON(t, server.URL+"/test/flaky", map[string]any{}, key); resp.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", resp.StatusCode)
+	}
+	resp, _ := postJSON(t, server.URL+"/test/flaky", map[string]any{}, key)
+	if resp.StatusCode != http.StatusNoContent || resp.Header.Get(httpapi.IdempotencyReplayedHeader) != "" {
+		t.Fatalf("retry after 5xx must run again, got %d (replayed %q)", resp.StatusCode, resp.Header.Get(httpapi.IdempotencyReplayedHeader))
+	}
+	if n := calls.Load(); n != 2 {
+		t.Fatalf("expected 2 calls, got %d", n)
+	}
+}
@@ -6,46 +6,115 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/Olzerq/avito-pr-reviewer/internal/config"
 	httpapi "github.com/Olzerq/avito-pr-reviewer/internal/http"
+	"github.com/Olzerq/avito-pr-reviewer/internal/migrator"
 	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository/memory"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository/sqlite"
 	"github.com/Olzerq/avito-pr-reviewer/internal/service"
 	"github.com/go-chi/chi/v5"
 )
 
+// setupTestServer поднимает API на хранилище из setupStorage
 func setupTestServer(t *testing.T) *httptest.Server {
 	t.Helper()
 
-	cfg := config.LoadConfig()
-
-	ctx := context.Background()
-	db := repository.NewDB(ctx, cfg.DBConnStr())
-
-	teamRepo := repository.NewTeamRepository(db)
-	userRepo := repository.NewUserRepository(db)
-	prRepo := repository.NewPullRequestRepository(db)
+	storage := setupStorage(t)
 
 	// Services
-	teamService := service.NewTeamService(teamRepo)
-	// userService := service.NewUserService(userRepo)
-	prService := service.NewPullRequestService(prRepo, userRepo)
+	teamService := service.NewTeamService(storage.Teams)
+	userService := service.NewUserService(storage.Users)
+	prService := service.NewPullRequestService(storage.Tx, storage.PullRequests, storage.Users, nil, nil)
+	statsService := service.NewStatsService(storage.Stats)
 
 	// Handlers
-	teamHandler := httpapi.NewTeamHandler(teamService)
-	// userHandler := httpapi.NewUserHandler(userService)
-	prHandler := httpapi.NewPullRequestHandler(prService)
+	v1Handler := httpapi.NewV1Handler(
+		httpapi.NewTeamHandler(teamService),
+		httpapi.NewUserHandler(userService),
+		httpapi.NewPullRequestHandler(prService),
+		httpapi.NewStatsHandler(statsService),
+		httpapi.NewReviewStreamHandler(prService, testSSEHeartbeat),
+	)
+	v2Handler := httpapi.NewV2Handler(teamService, userService, prService, statsService)
+
+	// ответы, не совпавшие с openapi.yml, превращаются в 500 и роняют тесты
+	validator, err := httpapi.OpenAPIValidator(httpapi.OpenAPIValidatorOptions{Responses: true, Strict: true})
+	if err != nil {
+		t.Fatalf("failed to load openapi spec: %v", err)
+	}
 
 	r := chi.NewRouter()
-
-	r.Post("/team/add", teamHandler.TeamAdd)
-	r.Post("/pullRequest/create", prHandler.Create)
-	r.Get("/users/getReview", prHandler.GetUserReviews)
+	r.NotFound(httpapi.NotFound)
+	r.MethodNotAllowed(httpapi.MethodNotAllowed)
+
+	// аутентификация выключена, как при AUTH_ENABLED=false
+	r.Group(func(r chi.Router) {
+		r.Use(httpapi.Authenticate(nil))
+		r.Group(func(r chi.Router) {
+			r.Use(validator)
+			v1Handler.Routes(r)
+		})
+		r.Route("/v2", v2Handler.Routes)
+	})
 
 	return httptest.NewServer(r)
 }
 
+// setupStorage хранилище из TEST_STORAGE: memory (по умолчанию), sqlite во
+// временном файле или postgres из конфига (DB_* / DB_DSN)
+func setupStorage(t *testing.T) repository.Storage {
+	t.Helper()
+
+	storage := memory.NewStorage()
+	switch os.Getenv("TEST_STORAGE") {
+	case "sqlite":
+		path := filepath.Join(t.TempDir(), "reviewer.db")
+		m, err := migrator.NewSQLite(path)
+		if err != nil {
+			t.Fatalf("failed to create migrator: %v", err)
+		}
+		if err := m.Up(); err != nil {
+			t.Fatalf("failed to apply migrations: %v", err)
+		}
+		_ = m.Close()
+
+		db, err := sqlite.Open(context.Background(), path)
+		if err != nil {
+			t.Fatalf("failed to open db: %v", err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+		storage = sqlite.NewStorage(db)
+	case "postgres":
+		cfg, _, err := config.Load(nil)
+		if err != nil {
+			t.Fatalf("failed to load config: %v", err)
+		}
+
+		ctx := context.Background()
+		db, err := repository.NewDB(ctx, cfg.DBConnStr(), repository.PoolOptions{
+			MinConns:        int32(cfg.DBMinConns),
+			MaxConns:        int32(cfg.DBMaxConns),
+			MaxConnLifetime: cfg.DBMaxConnLifetime,
+			MaxConnIdleTime: cfg.DBMaxConnIdleTime,
+		})
+		if err != nil {
+			t.Fatalf("failed to connect to db: %v", err)
+		}
+		t.Cleanup(db.Close)
+
+		if err := migrator.UpLocked(ctx, db, cfg.DBConnStr()); err != nil {
+			t.Fatalf("failed to apply migrations: %v", err)
+		}
+		storage = repository.NewStorage(db)
+	}
+	return storage
+}
+
 func TestFullFlow(t *testing.T) {
 	server := setupTestServer(t)
 	defer server.Close()
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+)
+
+func getJSON(t *testing.T, u string) (*http.Response, map[string]any) {
+	t.Helper()
+
+	resp, err := http.Get(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var out map[string]any
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	return resp, out
+}
+
+// listIDs проходит все страницы списка и собирает значения поля field
+func listIDs(t *testing.T, base string, params url.Values, itemsKey, field string) []string {
+	t.Helper()
+
+	var ids []string
+	for pages := 0; ; pages++ {
+		if pages > 50 {
+			t.Fatal("pagination does not terminate")
+		}
+		resp, body := getJSON(t, base+"?"+params.Encode())
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: expected 200 got %d: %v", base, resp.StatusCode, body)
+		}
+		for _, item := range body[itemsKey].([]any) {
+			ids = append(ids, item.(map[string]any)[field].(string))
+		}
+		next, _ := body["next_cursor"].(string)
+		if next == "" {
+			return ids
+		}
+		params.Set("cursor", next)
+	}
+}
+
+func TestListPagination(t *testing.T) {
+	server := setupTestServer(t)
+	defer server.Close()
+
+	prefix := t.Name() + "_"
+	team := prefix + "team"
+	members := []map[string]any{
+		{"user_id": prefix + "author", "username": "Author", "is_active": true},
+		{"user_id": prefix + "r1", "username": "Reviewer", "is_active": true},
+		{"user_id": prefix + "idle", "username": "Idle", "is_active": false},
+	}
+	resp, _ := postJSON(t, server.URL+"/team/add", map[string]any{"team_name": team, "members": members}, nil)
+	if resp == nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("failed to create team: %v", resp)
+	}
+
+	// единственный активный кандидат r1 назначается на все PR
+	var prIDs []string
+	for i := 0; i < 5; i++ {
+		prID := fmt.Sprintf("%spr%d", prefix, i)
+		resp, body := postJSON(t, server.URL+"/pullRequest/create", map[string]any{
+			"pull_request_id":   prID,
+			"pull_request_name": fmt.Sprintf("Feature %d", i),
+			"author_id":         prefix + "author",
+		}, nil)
+		if resp == nil || resp.StatusCode != http.StatusCreated {
+			t.Fatalf("failed to create PR: %v", body)
+		}
+		prIDs = append(prIDs, prID)
+	}
+	resp, _ = postJSON(t, server.URL+"/pullRequest/merge", map[string]any{"pull_request_id": prIDs[0]}, nil)
+	if resp == nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("failed to merge PR: %v", resp)
+	}
+
+	got := listIDs(t, server.URL+"/pullRequests", url.Values{"team_name": {team}, "sort": {"id"}, "limit": {"2"}}, "items", "pull_request_id")
+	if !slices.Equal(got, prIDs) {
+		t.Fatalf("pullRequests: expected %v, got %v", prIDs, got)
+	}
+	got = listIDs(t, server.URL+"/pullRequests", url.Values{"team_name": {team}, "status": {"MERGED"}}, "items", "pull_request_id")
+	if !slices.Equal(got, prIDs[:1]) {
+		t.Fatalf("pullRequests status=MERGED: got %v", got)
+	}
+	got = listIDs(t, server.URL+"/pullRequests", url.Values{"team_name": {team}, "name": {"feature 3"}}, "items", "pull_request_id")
+	if !slices.Equal(got, prIDs[3:4]) {
+		t.Fatalf("pullRequests name: got %v", got)
+	}
+
+	got = listIDs(t, server.URL+"/users/getReview", url.Values{"user_id": {prefix + "r1"}, "status": {"OPEN"}, "limit": {"3"}}, "pull_requests", "pull_request_id")
+	if !slices.Equal(got, prIDs[1:]) {
+		t.Fatalf("getReview status=OPEN: expected %v, got %v", prIDs[1:], got)
+	}
+
+	got = listIDs(t, server.URL+"/users", url.Values{"team_name": {team}, "is_active": {"true"}, "sort": {"-name"}, "limit": {"1"}}, "items", "user_id")
+	if !slices.Equal(got, []string{prefix + "r1", prefix + "author"}) {
+		t.Fatalf("users: got %v", got)
+	}
+	got = listIDs(t, server.URL+"/teams", url.Values{"name": {team}}, "items", "team_name")
+	if !slices.Equal(got, []string{team}) {
+		t.Fatalf("teams: got %v", got)
+	}
+
+	// курсор привязан к сортировке, мусор и неизвестные параметры - 400
+	_, body := getJSON(t, server.URL+"/pullRequests?"+url.Values{"team_name": {team}, "sort": {"id"}, "limit": {"1"}}.Encode())
+	cursor, _ := body["next_cursor"].(string)
+	if cursor == "" {
+		t.Fatalf("expected next_cursor, got %v", body)
+	}
+	for _, q := range []url.Values{
+		{"sort": {"name"}, "cursor": {cursor}},
+		{"cursor": {"not-a-cursor"}},
+		{"limit": {"1000"}},
+		{"limit": {"abc"}},
+		{"sort": {"author_id"}},
+		{"status": {"CLOSED"}},
+		{"created_from": {"yesterday"}},
+	} {
+		resp, body := getJSON(t, server.URL+"/pullRequests?"+q.Encode())
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("%v: expected 400 got %d", q, resp.StatusCode)
+		}
+		if e, _ := body["error"].(map[string]any); e["code"] != "BAD_REQUEST" {
+			t.Fatalf("%v: expected BAD_REQUEST, got %v", q, body)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+)
+
+// recordingNotifier запоминает отправленные уведомления
+type recordingNotifier struct {
+	mu   sync.Mutex
+	sent []service.Notification
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification service.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.sent = append(n.sent, notification)
+	return nil
+}
+
+// take возвращает накопленные уведомления и очищает список
+func (n *recordingNotifier) take() []service.Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	sent := n.sent
+	n.sent = nil
+	return sent
+}
+
+func TestReviewerNotifications(t *testing.T) {
+	ctx := service.WithSystemIdentity(context.Background())
+	storage := setupStorage(t)
+	notifier := &recordingNotifier{}
+	prService := service.NewPullRequestService(storage.Tx, storage.PullRequests, storage.Users, notifier, nil)
+
+	p := t.Name() + "_"
+	team := p + "team"
+	users := []model.User{{ID: p + "author"}, {ID: p + "r1"}, {ID: p + "r2"}, {ID: p + "r3"}}
+	for i := range users {
+		users[i].Username, users[i].TeamName, users[i].IsActive = users[i].ID, team, true
+	}
+	if err := service.NewTeamService(storage.Teams).CreateTeam(ctx, model.Team{Name: team, Users: users}); err != nil {
+		t.Fatal(err)
+	}
+
+	// по уведомлению ASSIGNED на каждого назначенного ревьювера
+	pr, err := prService.Create(ctx, p+"pr", "Feature", p+"author")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pr.Reviewers) != 2 {
+		t.Fatalf("expected 2 reviewers, got %v", pr.Reviewers)
+	}
+	sent := notifier.take()
+	if len(sent) != len(pr.Reviewers) {
+		t.Fatalf("expected %d notifications, got %v", len(pr.Reviewers), sent)
+	}
+	for i, n := range sent {
+		r := pr.Reviewers[i]
+		if n.Event != service.EventReviewAssigned || n.ReviewerID != r.ID || n.ReviewerName != r.Username ||
+			n.PullRequestID != pr.ID || n.AuthorID != pr.AuthorID || n.TeamName != team {
+			t.Fatalf("unexpected notification %+v for reviewer %s", n, r.ID)
+		}
+	}
+
+	// переназначение уведомляет только нового ревьювера
+	old := pr.Reviewers[0].ID
+	_, newReviewer, err := prService.Reassign(ctx, pr.ID, old)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent = notifier.take()
+	if len(sent) != 1 {
+		t.Fatalf("expected 1 notification on reassign, got %v", sent)
+	}
+	if n := sent[0]; n.Event != service.EventReviewReassigned || n.ReviewerID != newReviewer || n.ReplacedUserID != old {
+		t.Fatalf("unexpected reassign notification %+v", n)
+	}
+
+	// merge ревьюверов не уведомляет
+	if _, err := prService.Merge(ctx, pr.ID); err != nil {
+		t.Fatal(err)
+	}
+	if sent := notifier.take(); len(sent) != 0 {
+		t.Fatalf("expected no notifications on merge, got %v", sent)
+	}
+}
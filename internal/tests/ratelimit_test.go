@@ -0,0 +1,188 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	httpapi "github.com/Olzerq/avito-pr-reviewer/internal/http"
+	"github.com/Olzerq/avito-pr-reviewer/internal/ratelimit"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// setupRateLimitServer поднимает v1 и v2 за ограничителями в том же порядке, что и main:
+// RateLimit стоит до вложенного роутера /v2
+func setupRateLimitServer(t *testing.T, def ratelimit.Limit, routes map[string]ratelimit.Limit, inFlight *ratelimit.ConcurrencyLimiter, extra func(chi.Router)) *httptest.Server {
+	t.Helper()
+
+	storage := setupStorage(t)
+	teamService := service.NewTeamService(storage.Teams)
+	userService := service.NewUserService(storage.Users)
+	prService := service.NewPullRequestService(storage.Tx, storage.PullRequests, storage.Users, nil, nil)
+	statsService := service.NewStatsService(storage.Stats)
+
+	v1Handler := httpapi.NewV1Handler(
+		httpapi.NewTeamHandler(teamService),
+		httpapi.NewUserHandler(userService),
+		httpapi.NewPullRequestHandler(prService),
+		httpapi.NewStatsHandler(statsService),
+		httpapi.NewReviewStreamHandler(prService, testSSEHeartbeat),
+	)
+	v2Handler := httpapi.NewV2Handler(teamService, userService, prService, statsService)
+
+	r := chi.NewRouter()
+	r.Group(func(r chi.Router) {
+		r.Use(httpapi.RateLimit(ratelimit.New(def, routes), false))
+		r.Use(httpapi.Concurrency(inFlight))
+		r.Use(httpapi.Authenticate(nil))
+		v1Handler.Routes(r)
+		r.Route("/v2", v2Handler.Routes)
+		if extra != nil {
+			extra(r)
+		}
+	})
+
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func expectRateLimited(t *testing.T, resp *http.Response, body map[string]any) {
+	t.Helper()
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d: %v", resp.StatusCode, body)
+	}
+	if got := resp.Header.Get("Retry-After"); got == "" || got == "0" {
+		t.Errorf("429 without positive Retry-After: %q", got)
+	}
+	expectCode(t, body, "RATE_LIMITED")
+}
+
+func TestRateLimit(t *testing.T) {
+	server := setupRateLimitServer(t, ratelimit.Limit{RPS: 0.001, Burst: 2}, nil, ratelimit.NewConcurrencyLimiter(100, time.Second), nil)
+	alice := with(nil, "Authorization", "Bearer alice")
+
+	for want := 1; want >= 0; want-- {
+		resp, body := doJSON(t, http.MethodGet, server.URL+"/v2/teams", nil, alice)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %v", resp.StatusCode, body)
+		}
+		if got := resp.Header.Get("X-RateLimit-Remaining"); got != strconv.Itoa(want) {
+			t.Errorf("expected X-RateLimit-Remaining %d, got %q", want, got)
+		}
+		if got := resp.Header.Get("X-RateLimit-Limit"); got != "2" {
+			t.Errorf("expected X-RateLimit-Limit 2, got %q", got)
+		}
+	}
+
+	// бюджет общий для маршрутов без своего бюджета
+	resp, body := doJSON(t, http.MethodGet, server.URL+"/team/get?team_name=x", nil, alice)
+	expectRateLimited(t, resp, body)
+
+	// другой токен и клиент без токена считаются отдельно
+	for _, header := range []http.Header{with(nil, "Authorization", "Bearer bob"), nil} {
+		if resp, body := doJSON(t, http.MethodGet, server.URL+"/v2/teams", nil, header); resp.StatusCode != http.StatusOK {
+			t.Fatalf("other client must not be limited, got %d: %v", resp.StatusCode, body)
+		}
+	}
+}
+
+func TestRateLimitRouteBudgets(t *testing.T) {
+	server := setupRateLimitServer(t, ratelimit.Limit{RPS: 1000, Burst: 1000}, map[string]ratelimit.Limit{
+		"POST /v2/pull-requests":        {RPS: 0.001, Burst: 1},
+		"POST /pullRequest/batchCreate": {RPS: 0.001, Burst: 3},
+		"POST /pullRequest/batchMerge":  {RPS: 0.001, Burst: 3},
+	}, ratelimit.NewConcurrencyLimiter(100, time.Second), nil)
+	client := with(nil, "Authorization", "Bearer client")
+
+	t.Run("v2 routes are keyed by their own pattern", func(t *testing.T) {
+		pr := map[string]any{"pull_request_id": "pr-1", "pull_request_name": "x", "author_id": "ghost"}
+		resp, body := postJSON(t, server.URL+"/v2/pull-requests", pr, client)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			t.Fatalf("first create must not be limited: %v", body)
+		}
+		if got := resp.Header.Get("X-RateLimit-Limit"); got != "1" {
+			t.Errorf("v2 create must use its route budget, got X-RateLimit-Limit %q", got)
+		}
+		resp, body = postJSON(t, server.URL+"/v2/pull-requests", pr, client)
+		expectRateLimited(t, resp, body)
+
+		// остальные маршруты v2 под бюджетом по умолчанию
+		if resp, body := doJSON(t, http.MethodGet, server.URL+"/v2/pull-requests", nil, client); resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %v", resp.StatusCode, body)
+		}
+	})
+
+	t.Run("batch is charged per item", func(t *testing.T) {
+		batch := func(ids ...string) map[string]any {
+			items := make([]map[string]any, 0, len(ids))
+			for _, id := range ids {
+				items = append(items, map[string]any{"pull_request_id": id, "pull_request_name": id, "author_id": "ghost"})
+			}
+			return map[string]any{"mode": "best_effort", "items": items}
+		}
+
+		resp, body := postJSON(t, server.URL+"/pullRequest/batchCreate", batch("b-1", "b-2"), client)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %v", resp.StatusCode, body)
+		}
+		if got := resp.Header.Get("X-RateLimit-Remaining"); got != "1" {
+			t.Errorf("two items must cost two units, got X-RateLimit-Remaining %q", got)
+		}
+		resp, body = postJSON(t, server.URL+"/pullRequest/batchCreate", batch("b-3", "b-4"), client)
+		expectRateLimited(t, resp, body)
+
+		merge := map[string]any{"mode": "best_effort", "pull_request_ids": []string{"m-1", "m-2"}}
+		resp, body = postJSON(t, server.URL+"/pullRequest/batchMerge", merge, client)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d: %v", resp.StatusCode, body)
+		}
+		if got := resp.Header.Get("X-RateLimit-Remaining"); got != "1" {
+			t.Errorf("merging two items must cost two units, got X-RateLimit-Remaining %q", got)
+		}
+		resp, body = postJSON(t, server.URL+"/pullRequest/batchMerge", merge, client)
+		expectRateLimited(t, resp, body)
+	})
+}
+
+func TestConcurrencyShedding(t *testing.T) {
+	held, release := make(chan struct{}), make(chan struct{})
+	server := setupRateLimitServer(t, ratelimit.Limit{RPS: 1000, Burst: 1000}, nil, ratelimit.NewConcurrencyLimiter(1, 0),
+		func(r chi.Router) {
+			r.Get("/test/hold", func(w http.ResponseWriter, r *http.Request) {
+				close(held)
+				<-release
+			})
+		})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if resp, err := http.Get(server.URL + "/test/hold"); err == nil {
+			resp.Body.Close()
+		}
+	}()
+	<-held
+
+	resp, body := doJSON(t, http.MethodGet, server.URL+"/v2/teams", nil, nil)
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d: %v", resp.StatusCode, body)
+	}
+	if got := resp.Header.Get("Retry-After"); got != "1" {
+		t.Errorf("expected Retry-After 1, got %q", got)
+	}
+	expectCode(t, body, "OVERLOADED")
+
+	// слот освобождается вместе с запросом
+	close(release)
+	wg.Wait()
+	if resp, body := doJSON(t, http.MethodGet, server.URL+"/v2/teams", nil, nil); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 after release, got %d: %v", resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,248 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/ratelimit"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+)
+
+// TestRBAC матрица прав: кто из субъектов может выполнить операцию над данными команды A
+func TestRBAC(t *testing.T) {
+	server, authenticator := setupConformanceServer(t,
+		ratelimit.Limit{RPS: 1000, Burst: 1000}, ratelimit.NewConcurrencyLimiter(100, time.Second), nil)
+	defer server.Close()
+
+	p := t.Name() + "_"
+	teamA, teamB := p+"a", p+"b"
+	dbAdmin, leadA, memberA, memberA2, readOnly := p+"db_admin", p+"lead_a", p+"member_a", p+"member_a2", p+"read_only"
+	leadB, memberB := p+"lead_b", p+"member_b"
+
+	admin := with(nil, "Authorization", "Bearer "+conformanceAdminToken)
+	addTeam := func(name string, ids ...string) {
+		members := make([]map[string]any, 0, len(ids))
+		for _, id := range ids {
+			members = append(members, map[string]any{"user_id": id, "username": id, "is_active": true})
+		}
+		if resp, body := postJSON(t, server.URL+"/team/add", map[string]any{"team_name": name, "members": members}, admin); resp.StatusCode != http.StatusCreated {
+			t.Fatalf("failed to create team: %v", body)
+		}
+	}
+	addTeam(teamA, dbAdmin, leadA, memberA, memberA2, readOnly)
+	addTeam(teamB, leadB, memberB)
+	for user, role := range map[string]string{
+		dbAdmin:  model.RoleAdmin,
+		leadA:    model.RoleTeamLead,
+		leadB:    model.RoleTeamLead,
+		readOnly: model.RoleReadOnly,
+	} {
+		if resp, body := postJSON(t, server.URL+"/users/setRole", map[string]any{"user_id": user, "role": role}, admin); resp.StatusCode != http.StatusOK {
+			t.Fatalf("failed to set role: %v", body)
+		}
+	}
+
+	// субъекты запросов: статический токен или JWT пользователя
+	subjects := map[string]http.Header{"static_admin": admin}
+	for _, user := range []string{dbAdmin, leadA, memberA, memberA2, readOnly, leadB, p + "ghost"} {
+		token, err := authenticator.Issue(user, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+		subjects[user] = with(nil, "Authorization", "Bearer "+token)
+	}
+	const (
+		ok        = http.StatusOK
+		created   = http.StatusCreated
+		forbidden = http.StatusForbidden
+		unauth    = http.StatusUnauthorized
+	)
+
+	// PR автора memberA, который смержит subject
+	mergeTarget := func(subject string) string {
+		id := p + "merge_" + subject
+		body := map[string]any{"pull_request_id": id, "pull_request_name": id, "author_id": memberA}
+		if resp, b := postJSON(t, server.URL+"/pullRequest/create", body, admin); resp.StatusCode != http.StatusCreated {
+			t.Fatalf("failed to create PR: %v", b)
+		}
+		return id
+	}
+
+	// PR команды A для проверок чтения
+	readPR := p + "read"
+	if resp, b := postJSON(t, server.URL+"/pullRequest/create", map[string]any{"pull_request_id": readPR, "pull_request_name": readPR, "author_id": memberA}, admin); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("failed to create PR: %v", b)
+	}
+	// чтение данных команды A: все ее участники, read_only и администраторы
+	readersOfA := func() map[string]int {
+		return map[string]int{
+			"static_admin": ok, dbAdmin: ok, leadA: ok, memberA: ok, memberA2: ok, readOnly: ok,
+			leadB: forbidden, p + "ghost": unauth,
+		}
+	}
+
+	tests := []struct {
+		op      string
+		request func(subject string) (method, path string, body any)
+		want    map[string]int
+	}{
+		{
+			op: "create PR as member_a",
+			request: func(subject string) (string, string, any) {
+				id := p + "create_" + subject
+				return http.MethodPost, "/pullRequest/create", map[string]any{"pull_request_id": id, "pull_request_name": id, "author_id": memberA}
+			},
+			want: map[string]int{
+				"static_admin": created, dbAdmin: created, leadA: created, memberA: created,
+				memberA2: forbidden, readOnly: forbidden, leadB: forbidden, p + "ghost": unauth,
+			},
+		},
+		{
+			op: "merge PR of team a",
+			request: func(subject string) (string, string, any) {
+				return http.MethodPost, "/pullRequest/merge", map[string]any{"pull_request_id": mergeTarget(subject)}
+			},
+			want: map[string]int{
+				"static_admin": ok, dbAdmin: ok, leadA: ok,
+				memberA: forbidden, memberA2: forbidden, readOnly: forbidden, leadB: forbidden, p + "ghost": unauth,
+			},
+		},
+		{
+			op: "set member_a2 active",
+			request: func(string) (string, string, any) {
+				return http.MethodPost, "/users/setIsActive", map[string]any{"user_id": memberA2, "is_active": true}
+			},
+			want: map[string]int{
+				"static_admin": ok, dbAdmin: ok, leadA: ok,
+				memberA: forbidden, memberA2: forbidden, readOnly: forbidden, leadB: forbidden, p + "ghost": unauth,
+			},
+		},
+		{
+			op: "set role of member_b",
+			request: func(string) (string, string, any) {
+				return http.MethodPost, "/users/setRole", map[string]any{"user_id": memberB, "role": model.RoleMember}
+			},
+			want: map[string]int{
+				"static_admin": ok, dbAdmin: ok,
+				leadA: forbidden, memberA: forbidden, memberA2: forbidden, readOnly: forbidden, leadB: forbidden, p + "ghost": unauth,
+			},
+		},
+		{
+			op: "read reviews of member_a2",
+			request: func(string) (string, string, any) {
+				return http.MethodGet, "/users/getReview?user_id=" + memberA2, nil
+			},
+			want: map[string]int{
+				"static_admin": ok, dbAdmin: ok, leadA: ok, memberA2: ok, readOnly: ok,
+				memberA: forbidden, leadB: forbidden, p + "ghost": unauth,
+			},
+		},
+		{
+			op: "read PR of team a",
+			request: func(string) (string, string, any) {
+				return http.MethodGet, "/v2/pull-requests/" + readPR, nil
+			},
+			want: readersOfA(),
+		},
+		{
+			op: "read history of PR of team a",
+			request: func(string) (string, string, any) {
+				return http.MethodGet, "/pullRequest/history?pull_request_id=" + readPR, nil
+			},
+			want: readersOfA(),
+		},
+		{
+			op: "read team a",
+			request: func(string) (string, string, any) {
+				return http.MethodGet, "/team/get?team_name=" + teamA, nil
+			},
+			want: readersOfA(),
+		},
+		{
+			op: "read member_a2",
+			request: func(string) (string, string, any) {
+				return http.MethodGet, "/v2/users/" + memberA2, nil
+			},
+			want: readersOfA(),
+		},
+		{
+			op: "list users of team a",
+			request: func(string) (string, string, any) {
+				return http.MethodGet, "/v2/users?team_name=" + teamA, nil
+			},
+			want: readersOfA(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.op, func(t *testing.T) {
+			for subject, status := range tt.want {
+				method, path, body := tt.request(subject)
+				resp, respBody := doJSON(t, method, server.URL+path, body, subjects[subject])
+				if resp.StatusCode != status {
+					t.Errorf("%s: expected %d, got %d: %v", subject, status, resp.StatusCode, respBody)
+				}
+			}
+		})
+	}
+
+	// без фильтра лид и участник видят только свою команду
+	resp, body := doJSON(t, http.MethodGet, server.URL+"/v2/teams?name="+p, nil, subjects[leadB])
+	if items, _ := body["items"].([]any); resp.StatusCode != http.StatusOK || len(items) != 1 || items[0].(map[string]any)["team_name"] != teamB {
+		t.Fatalf("team lead must see only own team, got %d: %v", resp.StatusCode, body)
+	}
+	resp, body = doJSON(t, http.MethodGet, server.URL+"/v2/teams?name="+p, nil, subjects[readOnly])
+	if items, _ := body["items"].([]any); resp.StatusCode != http.StatusOK || len(items) != 2 {
+		t.Fatalf("read_only must see all teams, got %d: %v", resp.StatusCode, body)
+	}
+
+	// понижение роли действует на уже выпущенный токен
+	if resp, body := postJSON(t, server.URL+"/users/setRole", map[string]any{"user_id": dbAdmin, "role": model.RoleMember}, admin); resp.StatusCode != http.StatusOK {
+		t.Fatalf("failed to set role: %v", body)
+	}
+	resp, body = postJSON(t, server.URL+"/users/setRole", map[string]any{"user_id": memberB, "role": model.RoleMember}, subjects[dbAdmin])
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("demoted admin must lose admin rights, got %d: %v", resp.StatusCode, body)
+	}
+}
+
+// TestServicesRequireIdentity вызов сервиса без личности в контексте запрещен,
+// внутренние вызовы явно получают системную личность
+func TestServicesRequireIdentity(t *testing.T) {
+	storage := setupStorage(t)
+	teams := service.NewTeamService(storage.Teams)
+	users := service.NewUserService(storage.Users)
+	prs := service.NewPullRequestService(storage.Tx, storage.PullRequests, storage.Users, nil, nil)
+
+	team := t.Name() + "_team"
+	author := model.User{ID: t.Name() + "_author", Username: "author", TeamName: team, IsActive: true}
+	if err := teams.CreateTeam(context.Background(), model.Team{Name: team, Users: []model.User{author}}); !errors.Is(err, service.ErrForbidden) {
+		t.Fatalf("expected ErrForbidden without identity, got %v", err)
+	}
+
+	system := service.WithSystemIdentity(context.Background())
+	if err := teams.CreateTeam(system, model.Team{Name: team, Users: []model.User{author}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := prs.Create(system, t.Name()+"_pr", "pr", author.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	for name, call := range map[string]func() error{
+		"get team":   func() error { _, err := teams.GetTeam(ctx, team); return err },
+		"list teams": func() error { _, err := teams.List(ctx, repository.TeamFilter{}, service.ListQuery{}); return err },
+		"get user":   func() error { _, err := users.GetByID(ctx, author.ID); return err },
+		"list users": func() error { _, err := users.List(ctx, repository.UserFilter{}, service.ListQuery{}); return err },
+		"get PR":     func() error { _, err := prs.Get(ctx, t.Name()+"_pr"); return err },
+		"merge PR":   func() error { _, err := prs.Merge(ctx, t.Name()+"_pr"); return err },
+	} {
+		if err := call(); !errors.Is(err, service.ErrForbidden) {
+			t.Errorf("%s: expected ErrForbidden without identity, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	httpapi "github.com/Olzerq/avito-pr-reviewer/internal/http"
+	"github.com/go-chi/chi/v5"
+)
+
+// logBuffer собирает JSON записи логгера по умолчанию
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// requests записи "http request"
+func (b *logBuffer) requests(t *testing.T) []map[string]any {
+	t.Helper()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]any
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("malformed log line %q: %v", line, err)
+		}
+		if rec["msg"] == "http request" {
+			records = append(records, rec)
+		}
+	}
+	return records
+}
+
+// captureLogs подменяет логгер по умолчанию на запись в буфер
+func captureLogs(t *testing.T) *logBuffer {
+	t.Helper()
+
+	logs := &logBuffer{}
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return logs
+}
+
+func TestRequestIDInResponseAndLogs(t *testing.T) {
+	api := setupTestServer(t)
+	defer api.Close()
+	r := chi.NewRouter()
+	r.Use(httpapi.RequestID)
+	r.Use(httpapi.RequestLogger)
+	r.Mount("/", api.Config.Handler)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	user := t.Name() + "_user"
+	members := []map[string]any{{"user_id": user, "username": user, "is_active": true}}
+	if resp, body := postJSON(t, server.URL+"/team/add", map[string]any{"team_name": t.Name() + "_team", "members": members}, nil); resp == nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("failed to create team: %v", body)
+	}
+
+	generated := regexp.MustCompile(`^[0-9a-f]{32}$`)
+	tests := []struct {
+		name   string
+		header string
+		want   func(id string) bool
+	}{
+		{"generated", "", generated.MatchString},
+		{"from client", "client-req-1", func(id string) bool { return id == "client-req-1" }},
+		{"too long", strings.Repeat("x", 129), generated.MatchString},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+
+			var header http.Header
+			if tt.header != "" {
+				header = with(nil, httpapi.RequestIDHeader, tt.header)
+			}
+			resp, body := postJSON(t, server.URL+"/users/setIsActive", map[string]any{"user_id": user, "is_active": true}, header)
+			if resp == nil || resp.StatusCode != http.StatusOK {
+				t.Fatalf("setIsActive failed: %v", body)
+			}
+			id := resp.Header.Get(httpapi.RequestIDHeader)
+			if !tt.want(id) {
+				t.Fatalf("unexpected %s %q", httpapi.RequestIDHeader, id)
+			}
+
+			records := logs.requests(t)
+			if len(records) != 1 {
+				t.Fatalf("expected one request log record, got %v", records)
+			}
+			rec := records[0]
+			if rec["request_id"] != id {
+				t.Errorf("log request_id %v, response %q", rec["request_id"], id)
+			}
+			// поля, добавленные обработчиком, попадают в ту же запись
+			if rec["user_id"] != user || rec["route"] != "/users/setIsActive" || rec["status"] != float64(http.StatusOK) {
+				t.Errorf("unexpected log attributes %v", rec)
+			}
+		})
+	}
+}
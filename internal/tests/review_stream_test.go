@@ -0,0 +1,250 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	httpapi "github.com/Olzerq/avito-pr-reviewer/internal/http"
+	"github.com/Olzerq/avito-pr-reviewer/internal/model"
+	"github.com/Olzerq/avito-pr-reviewer/internal/repository"
+	"github.com/Olzerq/avito-pr-reviewer/internal/service"
+	"github.com/go-chi/chi/v5"
+)
+
+// testSSEHeartbeat интервал heartbeat потоков в тестовых серверах
+const testSSEHeartbeat = 50 * time.Millisecond
+
+type sseEvent struct {
+	id   int64
+	name string
+	data map[string]any
+}
+
+// sseStream читает события потока /users/reviews/stream
+type sseStream struct {
+	t          *testing.T
+	r          *bufio.Reader
+	heartbeats int
+}
+
+// openReviewStream открывает поток очереди userID, header - дополнительные заголовки
+func openReviewStream(t *testing.T, ctx context.Context, serverURL, userID string, header http.Header) (*http.Response, *sseStream) {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, serverURL+"/users/reviews/stream?user_id="+userID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp, &sseStream{t: t, r: bufio.NewReader(resp.Body)}
+}
+
+// next следующее событие, комментарии считаются как heartbeat и пропускаются
+func (s *sseStream) next() sseEvent {
+	s.t.Helper()
+
+	var e sseEvent
+	for {
+		line, err := s.r.ReadString('\n')
+		if err != nil {
+			s.t.Fatalf("read stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "":
+			if e.name != "" {
+				return e
+			}
+		case strings.HasPrefix(line, ":"):
+			s.heartbeats++
+		case strings.HasPrefix(line, "id: "):
+			e.id, _ = strconv.ParseInt(strings.TrimPrefix(line, "id: "), 10, 64)
+		case strings.HasPrefix(line, "event: "):
+			e.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &e.data); err != nil {
+				s.t.Fatalf("invalid event data %q: %v", line, err)
+			}
+		}
+	}
+}
+
+// expect следующее событие name по PR prID
+func (s *sseStream) expect(name, prID string) sseEvent {
+	s.t.Helper()
+
+	e := s.next()
+	pr, _ := e.data["pull_request"].(map[string]any)
+	if e.name != name || pr["pull_request_id"] != prID {
+		s.t.Fatalf("expected %s of %s, got %s %v", name, prID, e.name, e.data)
+	}
+	return e
+}
+
+func TestReviewQueueStream(t *testing.T) {
+	server := setupTestServer(t)
+	defer server.Close()
+
+	p := t.Name() + "_"
+	author, reviewer, spare := p+"author", p+"reviewer", p+"spare"
+
+	// spare неактивен, поэтому единственный ревьювер новых PR - reviewer
+	resp, _ := postJSON(t, server.URL+"/team/add", map[string]any{"team_name": p + "team", "members": []map[string]any{
+		{"user_id": author, "username": author, "is_active": true},
+		{"user_id": reviewer, "username": reviewer, "is_active": true},
+		{"user_id": spare, "username": spare, "is_active": false},
+	}}, nil)
+	if resp == nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("failed to create team: %v", resp)
+	}
+	createPR := func(id string) {
+		t.Helper()
+		resp, body := postJSON(t, server.URL+"/pullRequest/create",
+			map[string]any{"pull_request_id": id, "pull_request_name": id, "author_id": author}, nil)
+		if resp == nil || resp.StatusCode != http.StatusCreated {
+			t.Fatalf("failed to create %s: %v", id, body)
+		}
+	}
+
+	// PR до подписки попадает в снимок
+	first, second := p+"pr1", p+"pr2"
+	createPR(first)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	resp, stream := openReviewStream(t, ctx, server.URL, reviewer, nil)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("expected event stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	snapshot := stream.next()
+	queue, _ := snapshot.data["pull_requests"].([]any)
+	if snapshot.name != "snapshot" || snapshot.data["user_id"] != reviewer || len(queue) != 1 ||
+		queue[0].(map[string]any)["pull_request_id"] != first {
+		t.Fatalf("unexpected snapshot %v", snapshot)
+	}
+
+	createPR(second)
+	assigned := stream.expect("assigned", second)
+	if assigned.id <= snapshot.id || assigned.data["occurred_at"] == nil {
+		t.Fatalf("unexpected assigned event %v after snapshot %d", assigned, snapshot.id)
+	}
+
+	if resp, body := postJSON(t, server.URL+"/pullRequest/merge", map[string]any{"pull_request_id": second}, nil); resp == nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("failed to merge: %v", body)
+	}
+	merged := stream.expect("merged", second)
+	if pr := merged.data["pull_request"].(map[string]any); pr["status"] != "MERGED" {
+		t.Fatalf("expected merged pull request, got %v", pr)
+	}
+
+	// тишина в потоке заполняется heartbeat
+	time.Sleep(3 * testSSEHeartbeat)
+	if resp, _ := postJSON(t, server.URL+"/users/setIsActive", map[string]any{"user_id": spare, "is_active": true}, nil); resp == nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("failed to activate %s", spare)
+	}
+	if resp, body := postJSON(t, server.URL+"/pullRequest/reassign", map[string]any{"pull_request_id": first, "old_user_id": reviewer}, nil); resp == nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("failed to reassign: %v", body)
+	}
+	unassigned := stream.expect("unassigned", first)
+	if stream.heartbeats == 0 {
+		t.Fatal("expected heartbeat comments while the stream was idle")
+	}
+
+	// переподключение с Last-Event-ID продолжает историю без снимка. Потоки
+	// закрываются до server.Close, иначе он ждал бы их завершения.
+	cancel()
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	resp, resumed := openReviewStream(t, ctx, server.URL, reviewer,
+		http.Header{"Last-Event-ID": {strconv.FormatInt(assigned.id, 10)}})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected resumed stream, got %d", resp.StatusCode)
+	}
+	if e := resumed.expect("merged", second); e.id != merged.id {
+		t.Fatalf("expected merged event %d, got %d", merged.id, e.id)
+	}
+	if e := resumed.expect("unassigned", first); e.id != unassigned.id {
+		t.Fatalf("expected unassigned event %d, got %d", unassigned.id, e.id)
+	}
+
+	// id, которого нет в истории, начинает поток со снимка
+	_, fresh := openReviewStream(t, ctx, server.URL, reviewer,
+		http.Header{"Last-Event-ID": {strconv.FormatInt(unassigned.id+1000, 10)}})
+	if e := fresh.next(); e.name != "snapshot" || len(e.data["pull_requests"].([]any)) != 0 {
+		t.Fatalf("expected empty snapshot, got %s %v", e.name, e.data)
+	}
+}
+
+// countingQueueReads считает чтения очередей ревью из истории
+type countingQueueReads struct {
+	repository.PullRequests
+	reads atomic.Int32
+}
+
+func (c *countingQueueReads) GetReviewQueueEvents(ctx context.Context, userID string, afterID int64, limit int) ([]model.ReviewQueueEvent, error) {
+	c.reads.Add(1)
+	return c.PullRequests.GetReviewQueueEvents(ctx, userID, afterID, limit)
+}
+
+func TestReviewQueueStreamHeartbeatDoesNotReadHistory(t *testing.T) {
+	storage := setupStorage(t)
+	prs := &countingQueueReads{PullRequests: storage.PullRequests}
+	prService := service.NewPullRequestService(storage.Tx, prs, storage.Users, nil, nil)
+
+	reviewer := t.Name() + "_reviewer"
+	team := model.Team{Name: t.Name() + "_team", Users: []model.User{{ID: reviewer, Username: reviewer, IsActive: true}}}
+	if err := service.NewTeamService(storage.Teams).CreateTeam(service.WithSystemIdentity(context.Background()), team); err != nil {
+		t.Fatal(err)
+	}
+
+	v1Handler := httpapi.NewV1Handler(
+		httpapi.NewTeamHandler(service.NewTeamService(storage.Teams)),
+		httpapi.NewUserHandler(service.NewUserService(storage.Users)),
+		httpapi.NewPullRequestHandler(prService),
+		httpapi.NewStatsHandler(service.NewStatsService(storage.Stats)),
+		httpapi.NewReviewStreamHandler(prService, 5*time.Millisecond),
+	)
+	r := chi.NewRouter()
+	r.Use(httpapi.Authenticate(nil))
+	v1Handler.Routes(r)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, stream := openReviewStream(t, ctx, server.URL, reviewer, nil)
+	if e := stream.next(); e.name != "snapshot" {
+		t.Fatalf("expected snapshot, got %s", e.name)
+	}
+
+	// несколько heartbeat без изменений очереди не перечитывают историю
+	for heartbeats := 0; heartbeats < 5; {
+		line, err := stream.r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read stream: %v", err)
+		}
+		if strings.HasPrefix(line, ":") {
+			heartbeats++
+		}
+	}
+	if n := prs.reads.Load(); n != 1 {
+		t.Fatalf("expected a single history read after subscribing, got %d", n)
+	}
+}
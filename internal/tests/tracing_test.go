@@ -0,0 +1,133 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	httpapi "github.com/Olzerq/avito-pr-reviewer/internal/http"
+	"github.com/Olzerq/avito-pr-reviewer/internal/tracing"
+	"github.com/go-chi/chi/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans подменяет глобальный TracerProvider на запись спанов в память
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		_ = tp.Shutdown(context.Background())
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+	return recorder
+}
+
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracingSpans(t *testing.T) {
+	recorder := recordSpans(t)
+
+	api := setupTestServer(t)
+	defer api.Close()
+	r := chi.NewRouter()
+	r.Use(httpapi.Tracing)
+	r.Mount("/", api.Config.Handler)
+	server := httptest.NewServer(r)
+	defer server.Close()
+
+	team := t.Name() + "_team"
+	author := t.Name() + "_author"
+	members := []map[string]any{{"user_id": author, "username": author, "is_active": true}}
+	if resp, body := postJSON(t, server.URL+"/team/add", map[string]any{"team_name": team, "members": members}, nil); resp == nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("failed to create team: %v", body)
+	}
+
+	// трейс продолжается из traceparent клиента
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+	prID := t.Name() + "_pr"
+	resp, body := postJSON(t, server.URL+"/pullRequest/create",
+		map[string]any{"pull_request_id": prID, "pull_request_name": prID, "author_id": author},
+		with(nil, "traceparent", "00-"+traceID+"-"+spanID+"-01"))
+	if resp == nil || resp.StatusCode != http.StatusCreated {
+		t.Fatalf("failed to create PR: %v", body)
+	}
+
+	spans := recorder.Ended()
+	byName := make(map[string]sdktrace.ReadOnlySpan, len(spans))
+	for _, s := range spans {
+		byName[s.Name()] = s
+	}
+
+	root, ok := byName["POST /pullRequest/create"]
+	if !ok {
+		t.Fatalf("no server span for route, got %d spans", len(spans))
+	}
+	if root.SpanKind() != trace.SpanKindServer {
+		t.Errorf("expected server span, got %v", root.SpanKind())
+	}
+	if got := root.SpanContext().TraceID().String(); got != traceID {
+		t.Errorf("expected trace %s to continue, got %s", traceID, got)
+	}
+	if got := root.Parent().SpanID().String(); got != spanID || !root.Parent().IsRemote() {
+		t.Errorf("expected remote parent %s, got %s", spanID, got)
+	}
+	if got := spanAttr(root, "http.route").AsString(); got != "/pullRequest/create" {
+		t.Errorf("unexpected http.route %q", got)
+	}
+	if got := spanAttr(root, "http.response.status_code").AsInt64(); got != http.StatusCreated {
+		t.Errorf("unexpected status attribute %d", got)
+	}
+
+	// спан сервиса вложен в спан запроса
+	svc, ok := byName["PullRequestService.Create"]
+	if !ok {
+		t.Fatal("no service span")
+	}
+	if svc.Parent().SpanID() != root.SpanContext().SpanID() {
+		t.Errorf("service span is not a child of the request span")
+	}
+}
+
+func TestTracingSetupExporters(t *testing.T) {
+	ctx := context.Background()
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+	})
+
+	for _, exporter := range []string{"", "none", "stdout"} {
+		shutdown, err := tracing.Setup(ctx, exporter, "", "test")
+		if err != nil {
+			t.Fatalf("exporter %q: %v", exporter, err)
+		}
+		if err := shutdown(ctx); err != nil {
+			t.Fatalf("exporter %q shutdown: %v", exporter, err)
+		}
+	}
+	if _, err := tracing.Setup(ctx, "zipkin", "", "test"); err == nil {
+		t.Fatal("expected error for unknown exporter")
+	}
+}
@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func doJSON(t *testing.T, method, url string, body any, header http.Header) (*http.Response, map[string]any) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req, err := http.NewRequest(method, url, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var out map[string]any
+	_ = json.NewDecoder(resp.Body).Decode(&out)
+	return resp, out
+}
+
+func expectStatus(t *testing.T, op string, resp *http.Response, body map[string]any, status int, code string) {
+	t.Helper()
+	if resp.StatusCode != status {
+		t.Fatalf("%s: expected %d got %d: %v", op, status, resp.StatusCode, body)
+	}
+	if code == "" {
+		return
+	}
+	if e, _ := body["error"].(map[string]any); e["code"] != code {
+		t.Fatalf("%s: expected error %s, got %v", op, code, body)
+	}
+}
+
+func TestV2Flow(t *testing.T) {
+	server := setupTestServer(t)
+	defer server.Close()
+
+	prefix := t.Name() + "_"
+	team := prefix + "team"
+	v2 := server.URL + "/v2"
+
+	teamBody := map[string]any{
+		"team_name": team,
+		"members": []map[string]any{
+			{"user_id": prefix + "u1", "username": "Alice", "is_active": true},
+			{"user_id": prefix + "u2", "username": "Bob", "is_active": true},
+		},
+	}
+	resp, body := doJSON(t, http.MethodPost, v2+"/teams", teamBody, nil)
+	expectStatus(t, "create team", resp, body, http.StatusCreated, "")
+	if loc := resp.Header.Get("Location"); loc != "/v2/teams/"+team {
+		t.Fatalf("create team: unexpected Location %q", loc)
+	}
+	resp, body = doJSON(t, http.MethodPost, v2+"/teams", teamBody, nil)
+	expectStatus(t, "duplicate team", resp, body, http.StatusConflict, "TEAM_EXISTS")
+
+	resp, body = doJSON(t, http.MethodGet, v2+"/teams/"+team, nil, nil)
+	expectStatus(t, "get team", resp, body, http.StatusOK, "")
+	if members := body["members"].([]any); len(members) != 2 {
+		t.Fatalf("get team: unexpected members %v", members)
+	}
+	resp, body = doJSON(t, http.MethodGet, v2+"/teams/"+prefix+"missing", nil, nil)
+	expectStatus(t, "missing team", resp, body, http.StatusNotFound, "NOT_FOUND")
+
+	// старое имя поля из v1 в v2 не принимается молча
+	resp, body = doJSON(t, http.MethodPost, v2+"/pull-requests", map[string]any{
+		"pull_request_id": prefix + "pr", "pull_request_name": "Add search", "author_id": prefix + "u1", "old_user_id": "x",
+	}, nil)
+	expectStatus(t, "unknown field", resp, body, http.StatusBadRequest, "BAD_REQUEST")
+
+	resp, body = doJSON(t, http.MethodPost, v2+"/pull-requests", map[string]any{
+		"pull_request_id": prefix + "pr", "pull_request_name": "Add search", "author_id": prefix + "u1",
+	}, nil)
+	expectStatus(t, "create PR", resp, body, http.StatusCreated, "")
+	if body["created_at"] == nil || body["merged_at"] != nil || resp.Header.Get("ETag") == "" {
+		t.Fatalf("create PR: unexpected response %v, ETag %q", body, resp.Header.Get("ETag"))
+	}
+	if reviewers := body["assigned_reviewers"].([]any); len(reviewers) != 1 || reviewers[0] != prefix+"u2" {
+		t.Fatalf("create PR: unexpected reviewers %v", reviewers)
+	}
+	etag := resp.Header.Get("ETag")
+
+	resp, body = doJSON(t, http.MethodGet, v2+"/pull-requests/"+prefix+"pr/reviewers", nil, nil)
+	expectStatus(t, "list reviewers", resp, body, http.StatusOK, "")
+	if items := body["items"].([]any); len(items) != 1 || items[0].(map[string]any)["user_id"] != prefix+"u2" {
+		t.Fatalf("list reviewers: unexpected %v", items)
+	}
+
+	// кандидатов на замену нет
+	resp, body = doJSON(t, http.MethodPost, v2+"/pull-requests/"+prefix+"pr/reviewers/"+prefix+"u2/reassign", nil, nil)
+	expectStatus(t, "reassign", resp, body, http.StatusConflict, "NO_CANDIDATE")
+
+	resp, body = doJSON(t, http.MethodPost, v2+"/pull-requests/"+prefix+"pr/merge", nil, http.Header{"If-Match": {`"999"`}})
+	expectStatus(t, "merge stale", resp, body, http.StatusPreconditionFailed, "PRECONDITION_FAILED")
+	resp, body = doJSON(t, http.MethodPost, v2+"/pull-requests/"+prefix+"pr/merge", nil, http.Header{"If-Match": {etag}})
+	expectStatus(t, "merge", resp, body, http.StatusOK, "")
+	if body["status"] != "MERGED" || body["merged_at"] == nil {
+		t.Fatalf("merge: unexpected %v", body)
+	}
+
+	resp, body = doJSON(t, http.MethodGet, v2+"/pull-requests/"+prefix+"pr/events", nil, nil)
+	expectStatus(t, "events", resp, body, http.StatusOK, "")
+	if items := body["items"].([]any); len(items) != 3 {
+		t.Fatalf("events: expected created, assigned and merged, got %v", items)
+	}
+
+	resp, body = doJSON(t, http.MethodGet, v2+"/users/"+prefix+"u2/reviews?status=MERGED", nil, nil)
+	expectStatus(t, "reviews", resp, body, http.StatusOK, "")
+	if items := body["items"].([]any); len(items) != 1 || body["next_cursor"] != nil {
+		t.Fatalf("reviews: unexpected %v", body)
+	}
+
+	resp, body = doJSON(t, http.MethodPatch, v2+"/users/"+prefix+"u2", map[string]any{"is_active": false}, nil)
+	expectStatus(t, "deactivate", resp, body, http.StatusOK, "")
+	if body["is_active"] != false || body["team_name"] != team {
+		t.Fatalf("deactivate: unexpected %v", body)
+	}
+	resp, body = doJSON(t, http.MethodPatch, v2+"/users/"+prefix+"u2", map[string]any{}, nil)
+	expectStatus(t, "empty patch", resp, body, http.StatusBadRequest, "BAD_REQUEST")
+	resp, body = doJSON(t, http.MethodPatch, v2+"/users/"+prefix+"u2", map[string]any{"role": "owner"}, nil)
+	expectStatus(t, "invalid role", resp, body, http.StatusBadRequest, "BAD_REQUEST")
+
+	resp, body = doJSON(t, http.MethodGet, v2+"/stats/assignments", nil, nil)
+	expectStatus(t, "stats", resp, body, http.StatusOK, "")
+	if _, ok := body["by_pull_request"].([]any); !ok {
+		t.Fatalf("stats: unexpected %v", body)
+	}
+}
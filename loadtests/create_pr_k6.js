@@ -2,6 +2,7 @@ import http from 'k6/http';
 import { check, sleep } from 'k6';
 
 const BASE_URL = __ENV.BASE_URL || 'http://localhost:8080';
+const TOKEN = __ENV.TOKEN || 'dev-admin-token';
 
 export const options = {
     stages: [
@@ -22,7 +23,10 @@ export default function () {
         author_id: 'u1',
     });
 
-    const headers = { 'Content-Type': 'application/json' };
+    const headers = {
+        'Content-Type': 'application/json',
+        'Authorization': `Bearer ${TOKEN}`,
+    };
 
     const res = http.post(`${BASE_URL}/pullRequest/create`, payload, { headers });
 
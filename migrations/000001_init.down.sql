@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_reviewers_user;
+DROP INDEX IF EXISTS idx_users_team_active;
+
+DROP TABLE IF EXISTS pull_request_reviewers;
+DROP TABLE IF EXISTS pull_requests;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS teams;
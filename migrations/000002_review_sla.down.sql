@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_pr_status;
+DROP TABLE IF EXISTS pull_request_events;
+
+ALTER TABLE pull_request_reviewers
+    DROP COLUMN IF EXISTS reminded_at,
+    DROP COLUMN IF EXISTS assigned_at;
+
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS created_at;
@@ -0,0 +1,19 @@
+ALTER TABLE pull_requests
+    ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC');
+
+ALTER TABLE pull_request_reviewers
+    ADD COLUMN assigned_at TIMESTAMP NOT NULL DEFAULT (NOW() AT TIME ZONE 'UTC'),
+    ADD COLUMN reminded_at TIMESTAMP NULL;
+
+CREATE TABLE pull_request_events (
+                                     id              BIGSERIAL PRIMARY KEY,
+                                     pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+                                     event_type      TEXT NOT NULL,
+                                     user_id         TEXT NULL,
+                                     old_user_id     TEXT NULL,
+                                     actor           TEXT NOT NULL,
+                                     created_at      TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_pr_events_pr ON pull_request_events(pull_request_id, id);
+CREATE INDEX idx_pr_status ON pull_requests(status);
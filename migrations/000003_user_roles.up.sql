@@ -0,0 +1,4 @@
+-- Роли пользователей: admin, team_lead, member, read_only
+ALTER TABLE users
+    ADD COLUMN role TEXT NOT NULL DEFAULT 'member'
+        CHECK (role IN ('admin', 'team_lead', 'member', 'read_only'));
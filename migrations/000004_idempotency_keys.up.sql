@@ -0,0 +1,16 @@
+-- Ответы на запросы с Idempotency-Key, повтор в течение TTL отдает сохраненный ответ
+CREATE TABLE idempotency_keys (
+    scope          TEXT        NOT NULL, -- кто отправил запрос (sub токена)
+    key            TEXT        NOT NULL,
+    method         TEXT        NOT NULL,
+    path           TEXT        NOT NULL,
+    request_hash   TEXT        NOT NULL,
+    status_code    INT,                  -- NULL пока запрос выполняется
+    content_type   TEXT,
+    response_body  BYTEA,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at     TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires ON idempotency_keys (expires_at);
@@ -0,0 +1,3 @@
+-- Версия PR для оптимистичных блокировок, растет при каждом изменении
+ALTER TABLE pull_requests
+    ADD COLUMN version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_users_username_trgm;
+DROP INDEX IF EXISTS idx_teams_name_trgm;
+DROP INDEX IF EXISTS idx_pr_name_trgm;
+
+DROP INDEX IF EXISTS idx_users_username;
+DROP INDEX IF EXISTS idx_pr_status_created;
+CREATE INDEX idx_pr_status ON pull_requests (status);
+DROP INDEX IF EXISTS idx_pr_author_created;
+DROP INDEX IF EXISTS idx_pr_name;
+DROP INDEX IF EXISTS idx_pr_created;
@@ -0,0 +1,27 @@
+-- Индексы для списков с keyset пагинацией: (поле сортировки, id) и частые фильтры
+CREATE INDEX idx_pr_created ON pull_requests (created_at, pull_request_id);
+CREATE INDEX idx_pr_name ON pull_requests (pull_request_name, pull_request_id);
+CREATE INDEX idx_pr_author_created ON pull_requests (author_id, created_at, pull_request_id);
+DROP INDEX idx_pr_status;
+CREATE INDEX idx_pr_status_created ON pull_requests (status, created_at, pull_request_id);
+CREATE INDEX idx_users_username ON users (username, user_id);
+
+-- Поиск по подстроке (ILIKE '%...%') ускоряют индексы pg_trgm. Если пользователь миграций
+-- не может создать расширение (managed Postgres без прав), индексы пропускаются: поиск
+-- работает и без них, но полным просмотром. Как создать их позже, описано в README.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_trgm') THEN
+        BEGIN
+            CREATE EXTENSION IF NOT EXISTS pg_trgm;
+        EXCEPTION WHEN insufficient_privilege OR undefined_file OR feature_not_supported THEN
+            RAISE WARNING 'pg_trgm is not available (%), substring search indexes are skipped', SQLERRM;
+            RETURN;
+        END;
+    END IF;
+
+    CREATE INDEX IF NOT EXISTS idx_pr_name_trgm ON pull_requests USING gin (pull_request_name gin_trgm_ops);
+    CREATE INDEX IF NOT EXISTS idx_teams_name_trgm ON teams USING gin (team_name gin_trgm_ops);
+    CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING gin (username gin_trgm_ops);
+END
+$$;
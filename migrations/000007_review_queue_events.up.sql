@@ -0,0 +1,3 @@
+-- Чтение изменений очереди ревью пользователя по истории PR после заданного id
+CREATE INDEX idx_pr_events_user ON pull_request_events (user_id, id);
+CREATE INDEX idx_pr_events_old_user ON pull_request_events (old_user_id, id) WHERE old_user_id IS NOT NULL;
@@ -0,0 +1,4 @@
+ALTER TABLE idempotency_keys
+    DROP COLUMN IF EXISTS location,
+    DROP COLUMN IF EXISTS etag,
+    DROP COLUMN IF EXISTS locked_at;
@@ -0,0 +1,14 @@
+// Package migrations содержит SQL миграции, встроенные в бинарник
+package migrations
+
+import "embed"
+
+// FS миграции Postgres
+//
+//go:embed *.sql
+var FS embed.FS
+
+// SQLiteFS миграции SQLite, лежат в каталоге sqlite
+//
+//go:embed sqlite/*.sql
+var SQLiteFS embed.FS
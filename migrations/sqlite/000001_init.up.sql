@@ -0,0 +1,62 @@
+-- Схема для SQLite, соответствует версии 000005 миграций Postgres.
+-- Время хранится текстом в UTC фиксированной ширины, поэтому сравнивается и сортируется как строка.
+CREATE TABLE teams (
+    team_name TEXT PRIMARY KEY
+);
+
+CREATE TABLE users (
+    user_id   TEXT PRIMARY KEY,
+    username  TEXT NOT NULL,
+    team_name TEXT NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    is_active INTEGER NOT NULL DEFAULT 1,
+    role      TEXT NOT NULL DEFAULT 'member'
+        CHECK (role IN ('admin', 'team_lead', 'member', 'read_only'))
+);
+
+CREATE TABLE pull_requests (
+    pull_request_id   TEXT PRIMARY KEY,
+    pull_request_name TEXT NOT NULL,
+    author_id         TEXT NOT NULL REFERENCES users(user_id),
+    status            TEXT NOT NULL CHECK (status IN ('OPEN', 'MERGED')),
+    created_at        TEXT NOT NULL,
+    merged_at         TEXT NULL,
+    version           INTEGER NOT NULL DEFAULT 1
+);
+
+CREATE TABLE pull_request_reviewers (
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    user_id         TEXT NOT NULL REFERENCES users(user_id),
+    assigned_at     TEXT NOT NULL,
+    reminded_at     TEXT NULL,
+    PRIMARY KEY (pull_request_id, user_id)
+);
+
+CREATE TABLE pull_request_events (
+    id              INTEGER PRIMARY KEY AUTOINCREMENT,
+    pull_request_id TEXT NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    event_type      TEXT NOT NULL,
+    user_id         TEXT NULL,
+    old_user_id     TEXT NULL,
+    actor           TEXT NOT NULL,
+    created_at      TEXT NOT NULL
+);
+
+CREATE TABLE idempotency_keys (
+    scope         TEXT NOT NULL,
+    key           TEXT NOT NULL,
+    method        TEXT NOT NULL,
+    path          TEXT NOT NULL,
+    request_hash  TEXT NOT NULL,
+    status_code   INTEGER,
+    content_type  TEXT,
+    response_body BLOB,
+    created_at    TEXT NOT NULL,
+    expires_at    TEXT NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idx_users_team_active ON users(team_name, is_active);
+CREATE INDEX idx_reviewers_user ON pull_request_reviewers(user_id);
+CREATE INDEX idx_pr_events_pr ON pull_request_events(pull_request_id, id);
+CREATE INDEX idx_pr_status ON pull_requests(status);
+CREATE INDEX idx_idempotency_keys_expires ON idempotency_keys(expires_at);
@@ -0,0 +1,8 @@
+-- Индексы для списков с keyset пагинацией: (поле сортировки, id) и частые фильтры.
+-- Поиск по подстроке в SQLite идет перебором, для одной небольшой установки этого достаточно.
+CREATE INDEX idx_pr_created ON pull_requests (created_at, pull_request_id);
+CREATE INDEX idx_pr_name ON pull_requests (pull_request_name, pull_request_id);
+CREATE INDEX idx_pr_author_created ON pull_requests (author_id, created_at, pull_request_id);
+DROP INDEX idx_pr_status;
+CREATE INDEX idx_pr_status_created ON pull_requests (status, created_at, pull_request_id);
+CREATE INDEX idx_users_username ON users (username, user_id);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_pr_events_old_user;
+DROP INDEX IF EXISTS idx_pr_events_user;
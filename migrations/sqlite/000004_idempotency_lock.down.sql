@@ -0,0 +1,3 @@
+ALTER TABLE idempotency_keys DROP COLUMN location;
+ALTER TABLE idempotency_keys DROP COLUMN etag;
+ALTER TABLE idempotency_keys DROP COLUMN locked_at;
@@ -0,0 +1,8 @@
+-- Время захвата ключа: запрос упавшего процесса не держит ключ до конца TTL,
+-- по истечении блокировки его забирает повтор с тем же телом.
+-- ETag и Location отдаются при повторе вместе с телом ответа.
+ALTER TABLE idempotency_keys ADD COLUMN locked_at TEXT NOT NULL DEFAULT '';
+ALTER TABLE idempotency_keys ADD COLUMN etag TEXT;
+ALTER TABLE idempotency_keys ADD COLUMN location TEXT;
+
+UPDATE idempotency_keys SET locked_at = created_at;